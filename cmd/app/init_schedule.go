@@ -12,16 +12,31 @@ func initSchedule(cr *cron.Cron, servs *service.Service) {
 		Initial executions
 	*/
 
+	servs.ExecutionsService.RecoverStaleExecutions()
+	servs.RestorationsService.RecoverStaleRestorations()
+	servs.JobsService.RecoverStaleJobs()
 	servs.ExecutionsService.SoftDeleteExpiredExecutions()
 	servs.AuthService.DeleteOldSessions()
 	servs.DatabasesService.TestAllDatabases()
 	servs.DestinationsService.TestAllDestinations()
+	servs.PITRService.SyncReceivers()
 
 	/*
 		Schedules
 	*/
 
-	err := cr.UpsertJob(uuid.New(), "UTC", "*/10 * * * *", func() {
+	err := cr.UpsertJob(uuid.New(), "UTC", "* * * * *", func() {
+		servs.ExecutionsService.RecoverStaleExecutions()
+		servs.RestorationsService.RecoverStaleRestorations()
+		servs.JobsService.RecoverStaleJobs()
+	})
+	if err != nil {
+		logger.FatalError(
+			"error scheduling recovery of stale executions", logger.KV{"error": err},
+		)
+	}
+
+	err = cr.UpsertJob(uuid.New(), "UTC", "*/10 * * * *", func() {
 		servs.ExecutionsService.SoftDeleteExpiredExecutions()
 	})
 	if err != nil {
@@ -31,6 +46,15 @@ func initSchedule(cr *cron.Cron, servs *service.Service) {
 		)
 	}
 
+	err = cr.UpsertJob(uuid.New(), "UTC", "45 * * * *", func() {
+		servs.JobsService.DeleteFinishedJobs()
+	})
+	if err != nil {
+		logger.FatalError(
+			"error scheduling deletion of finished jobs", logger.KV{"error": err},
+		)
+	}
+
 	err = cr.UpsertJob(uuid.New(), "UTC", "*/10 * * * *", func() {
 		servs.AuthService.DeleteOldSessions()
 	})
@@ -58,5 +82,34 @@ func initSchedule(cr *cron.Cron, servs *service.Service) {
 		)
 	}
 
+	err = cr.UpsertJob(uuid.New(), "UTC", "30 * * * *", func() {
+		servs.ExecutionsService.VerifyExecutions()
+	})
+	if err != nil {
+		logger.FatalError(
+			"error scheduling executions verification", logger.KV{"error": err},
+		)
+	}
+
+	err = cr.UpsertJob(uuid.New(), "UTC", "* * * * *", func() {
+		servs.PITRService.SyncReceivers()
+	})
+	if err != nil {
+		logger.FatalError(
+			"error scheduling WAL archiving", logger.KV{"error": err},
+		)
+	}
+
+	err = cr.UpsertJob(uuid.New(), "UTC", "15 * * * *", func() {
+		servs.PITRService.PruneSegments()
+	})
+	if err != nil {
+		logger.FatalError(
+			"error scheduling WAL segments pruning", logger.KV{"error": err},
+		)
+	}
+
+	servs.JobsService.Start()
 	servs.BackupsService.ScheduleAll()
+	servs.DrillsService.ScheduleAll()
 }
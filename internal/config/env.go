@@ -2,6 +2,7 @@ package config
 
 import (
 	"sync"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
@@ -13,6 +14,15 @@ type Env struct {
 	PBW_LISTEN_HOST          string `env:"PBW_LISTEN_HOST" envDefault:"0.0.0.0"`
 	PBW_LISTEN_PORT          string `env:"PBW_LISTEN_PORT" envDefault:"8085"`
 	PBW_PATH_PREFIX          string `env:"PBW_PATH_PREFIX" envDefault:""`
+
+	PBW_LOCAL_BACKUPS_ENCRYPTION_KEY string `env:"PBW_LOCAL_BACKUPS_ENCRYPTION_KEY" envDefault:""`
+	PBW_REQUEUE_INTERRUPTED_BACKUPS  bool   `env:"PBW_REQUEUE_INTERRUPTED_BACKUPS" envDefault:"false"`
+	PBW_ENABLE_COMMAND_HOOKS         bool   `env:"PBW_ENABLE_COMMAND_HOOKS" envDefault:"false"`
+
+	PBW_MAX_CONCURRENT_BACKUPS            int           `env:"PBW_MAX_CONCURRENT_BACKUPS" envDefault:"4"`
+	PBW_MAX_CONCURRENT_BACKUPS_PER_SERVER int           `env:"PBW_MAX_CONCURRENT_BACKUPS_PER_SERVER" envDefault:"2"`
+	PBW_BACKUP_MAX_ATTEMPTS               int           `env:"PBW_BACKUP_MAX_ATTEMPTS" envDefault:"1"`
+	PBW_BACKUP_RETRY_BACKOFF              time.Duration `env:"PBW_BACKUP_RETRY_BACKOFF" envDefault:"1m"`
 }
 
 var (
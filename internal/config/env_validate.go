@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
 	"github.com/eduardolat/pgbackweb/internal/validate"
 )
 
@@ -20,5 +22,25 @@ func validateEnv(env Env) error {
 		return fmt.Errorf("invalid path prefix %s, must start with / and not end with / (or be empty)", env.PBW_PATH_PREFIX)
 	}
 
+	if err := encryption.ValidateKey(env.PBW_LOCAL_BACKUPS_ENCRYPTION_KEY); err != nil {
+		return fmt.Errorf("invalid local backups encryption key: %w", err)
+	}
+
+	if env.PBW_MAX_CONCURRENT_BACKUPS < 1 {
+		return fmt.Errorf("invalid max concurrent backups %d, must be at least 1", env.PBW_MAX_CONCURRENT_BACKUPS)
+	}
+
+	if env.PBW_MAX_CONCURRENT_BACKUPS_PER_SERVER < 1 {
+		return fmt.Errorf("invalid max concurrent backups per server %d, must be at least 1", env.PBW_MAX_CONCURRENT_BACKUPS_PER_SERVER)
+	}
+
+	if env.PBW_BACKUP_MAX_ATTEMPTS < 1 || env.PBW_BACKUP_MAX_ATTEMPTS > 10 {
+		return fmt.Errorf("invalid backup max attempts %d, valid values are 1-10", env.PBW_BACKUP_MAX_ATTEMPTS)
+	}
+
+	if env.PBW_BACKUP_RETRY_BACKOFF < time.Second || env.PBW_BACKUP_RETRY_BACKOFF > 24*time.Hour {
+		return fmt.Errorf("invalid backup retry backoff %s, must be between 1s and 24h", env.PBW_BACKUP_RETRY_BACKOFF)
+	}
+
 	return nil
 }
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_tokens (
+  id UUID NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+  name TEXT NOT NULL,
+  token_hash TEXT NOT NULL, -- bcrypt hash of the token secret
+  scope TEXT NOT NULL CHECK (scope IN ('read_only', 'run_backups', 'admin')),
+
+  expires_at TIMESTAMPTZ,
+  last_used_at TIMESTAMPTZ,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ
+);
+
+CREATE TRIGGER api_tokens_change_updated_at
+BEFORE UPDATE ON api_tokens FOR EACH ROW EXECUTE FUNCTION change_updated_at();
+
+CREATE INDEX IF NOT EXISTS
+idx_api_tokens_user_id ON api_tokens(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_tokens;
+-- +goose StatementEnd
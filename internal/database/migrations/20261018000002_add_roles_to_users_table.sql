@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Existing users keep full access, so they become admins
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'admin'
+CHECK (role IN ('admin', 'operator', 'viewer'));
+ALTER TABLE users ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Operators can only restore into databases not flagged as production
+ALTER TABLE databases ADD COLUMN is_production BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE databases DROP COLUMN is_production;
+ALTER TABLE users DROP COLUMN is_active;
+ALTER TABLE users DROP COLUMN role;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Existing backups keep producing plain SQL dumps
+ALTER TABLE backups ADD COLUMN opt_format TEXT NOT NULL DEFAULT 'plain'
+CHECK (opt_format IN ('plain', 'custom', 'directory', 'tar'));
+ALTER TABLE backups ADD COLUMN opt_jobs SMALLINT NOT NULL DEFAULT 1
+CHECK (opt_jobs BETWEEN 1 AND 64);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups DROP COLUMN opt_jobs;
+ALTER TABLE backups DROP COLUMN opt_format;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Existing backups keep producing ZIP files
+ALTER TABLE backups ADD COLUMN compression TEXT NOT NULL DEFAULT 'zip'
+CHECK (compression IN ('zip', 'zstd', 'gzip', 'none'));
+ALTER TABLE backups ADD COLUMN compression_level SMALLINT NOT NULL DEFAULT 0
+CHECK (compression_level BETWEEN 0 AND 19);
+
+-- Existing executions are ZIP files
+ALTER TABLE executions ADD COLUMN compression TEXT NOT NULL DEFAULT 'zip';
+ALTER TABLE executions ADD COLUMN file_extension TEXT NOT NULL DEFAULT '.zip';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE executions DROP COLUMN file_extension;
+ALTER TABLE executions DROP COLUMN compression;
+ALTER TABLE backups DROP COLUMN compression_level;
+ALTER TABLE backups DROP COLUMN compression;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Key used to encrypt the backups stored in the destination, it is stored
+-- encrypted with PBW_ENCRYPTION_KEY like the rest of the credentials
+ALTER TABLE destinations ADD COLUMN backup_encryption_key BYTEA;
+
+-- Fingerprint of the key used to encrypt the file of the execution, NULL
+-- when the file is not encrypted
+ALTER TABLE executions ADD COLUMN encryption_key_fingerprint TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE executions DROP COLUMN encryption_key_fingerprint;
+ALTER TABLE destinations DROP COLUMN backup_encryption_key;
+-- +goose StatementEnd
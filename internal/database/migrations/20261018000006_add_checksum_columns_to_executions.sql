@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SHA-256 of the stored file, computed while it is uploaded
+ALTER TABLE executions ADD COLUMN checksum TEXT;
+
+-- Result of the last time the stored file was read again and compared with
+-- the checksum
+ALTER TABLE executions ADD COLUMN verify_status TEXT
+CHECK (verify_status IN ('ok', 'mismatch', 'error'));
+ALTER TABLE executions ADD COLUMN verify_message TEXT;
+ALTER TABLE executions ADD COLUMN verified_at TIMESTAMPTZ;
+
+ALTER TABLE webhooks DROP CONSTRAINT IF EXISTS webhooks_event_type_check;
+ALTER TABLE webhooks ADD CONSTRAINT webhooks_event_type_check
+CHECK (event_type IN (
+  'database_healthy', 'database_unhealthy',
+  'destination_healthy', 'destination_unhealthy',
+  'execution_success', 'execution_failed',
+  'execution_integrity_failed'
+));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM webhooks WHERE event_type = 'execution_integrity_failed';
+ALTER TABLE webhooks DROP CONSTRAINT IF EXISTS webhooks_event_type_check;
+ALTER TABLE webhooks ADD CONSTRAINT webhooks_event_type_check
+CHECK (event_type IN (
+  'database_healthy', 'database_unhealthy',
+  'destination_healthy', 'destination_unhealthy',
+  'execution_success', 'execution_failed'
+));
+
+ALTER TABLE executions DROP COLUMN verified_at;
+ALTER TABLE executions DROP COLUMN verify_message;
+ALTER TABLE executions DROP COLUMN verify_status;
+ALTER TABLE executions DROP COLUMN checksum;
+-- +goose StatementEnd
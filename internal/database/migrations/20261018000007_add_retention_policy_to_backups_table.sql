@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups
+ADD COLUMN retention_keep_last SMALLINT NOT NULL DEFAULT 0
+CHECK (retention_keep_last >= 0),
+ADD COLUMN retention_keep_daily SMALLINT NOT NULL DEFAULT 0
+CHECK (retention_keep_daily >= 0),
+ADD COLUMN retention_keep_weekly SMALLINT NOT NULL DEFAULT 0
+CHECK (retention_keep_weekly >= 0),
+ADD COLUMN retention_keep_monthly SMALLINT NOT NULL DEFAULT 0
+CHECK (retention_keep_monthly >= 0),
+ADD COLUMN retention_keep_yearly SMALLINT NOT NULL DEFAULT 0
+CHECK (retention_keep_yearly >= 0),
+-- The most recent successful executions are never deleted by the retention
+ADD COLUMN retention_min_keep SMALLINT NOT NULL DEFAULT 1
+CHECK (retention_min_keep >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups
+DROP COLUMN retention_keep_last,
+DROP COLUMN retention_keep_daily,
+DROP COLUMN retention_keep_weekly,
+DROP COLUMN retention_keep_monthly,
+DROP COLUMN retention_keep_yearly,
+DROP COLUMN retention_min_keep;
+-- +goose StatementEnd
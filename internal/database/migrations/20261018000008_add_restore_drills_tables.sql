@@ -0,0 +1,81 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS restore_drills (
+  id UUID NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+  backup_id UUID NOT NULL REFERENCES backups(id) ON DELETE CASCADE,
+
+  -- The scratch databases are created and dropped on the server of this
+  -- database, using its connection string
+  database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+
+  name TEXT NOT NULL CHECK (name <> ''),
+  cron_expression TEXT NOT NULL CHECK (cron_expression <> ''),
+  time_zone TEXT NOT NULL CHECK (time_zone <> ''),
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+
+  -- SQL statements separated by semicolons, every one of them must return
+  -- true for the drill to succeed
+  assertions TEXT NOT NULL DEFAULT '',
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ
+);
+
+CREATE TRIGGER restore_drills_change_updated_at
+BEFORE UPDATE ON restore_drills FOR EACH ROW EXECUTE FUNCTION change_updated_at();
+
+CREATE INDEX IF NOT EXISTS
+idx_restore_drills_backup_id ON restore_drills(backup_id);
+
+CREATE TABLE IF NOT EXISTS restore_drill_runs (
+  id UUID NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+  drill_id UUID NOT NULL REFERENCES restore_drills(id) ON DELETE CASCADE,
+  execution_id UUID REFERENCES executions(id) ON DELETE SET NULL,
+
+  status TEXT NOT NULL CHECK (
+    status IN ('running', 'success', 'failed')
+  ) DEFAULT 'running',
+  message TEXT,
+  scratch_database TEXT NOT NULL DEFAULT '',
+
+  -- Array of {"query", "passed", "output"} objects, one per assertion
+  assertion_results JSONB NOT NULL DEFAULT '[]',
+
+  started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ
+);
+
+CREATE TRIGGER restore_drill_runs_change_updated_at
+BEFORE UPDATE ON restore_drill_runs FOR EACH ROW EXECUTE FUNCTION change_updated_at();
+
+CREATE INDEX IF NOT EXISTS
+idx_restore_drill_runs_drill_id ON restore_drill_runs(drill_id);
+
+ALTER TABLE webhooks DROP CONSTRAINT IF EXISTS webhooks_event_type_check;
+ALTER TABLE webhooks ADD CONSTRAINT webhooks_event_type_check
+CHECK (event_type IN (
+  'database_healthy', 'database_unhealthy',
+  'destination_healthy', 'destination_unhealthy',
+  'execution_success', 'execution_failed',
+  'execution_integrity_failed',
+  'restore_drill_success', 'restore_drill_failed'
+));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM webhooks
+WHERE event_type IN ('restore_drill_success', 'restore_drill_failed');
+ALTER TABLE webhooks DROP CONSTRAINT IF EXISTS webhooks_event_type_check;
+ALTER TABLE webhooks ADD CONSTRAINT webhooks_event_type_check
+CHECK (event_type IN (
+  'database_healthy', 'database_unhealthy',
+  'destination_healthy', 'destination_unhealthy',
+  'execution_success', 'execution_failed',
+  'execution_integrity_failed'
+));
+
+DROP TABLE IF EXISTS restore_drill_runs;
+DROP TABLE IF EXISTS restore_drills;
+-- +goose StatementEnd
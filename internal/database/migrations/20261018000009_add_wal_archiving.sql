@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS mode TEXT NOT NULL
+DEFAULT 'logical' CHECK (mode IN ('logical', 'physical'));
+
+-- WAL positions of the base backups taken by the physical backups
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS wal_start_lsn TEXT;
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS wal_end_lsn TEXT;
+
+CREATE TABLE IF NOT EXISTS wal_segments (
+  id UUID NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+  backup_id UUID NOT NULL REFERENCES backups(id) ON DELETE CASCADE,
+
+  -- Name of the WAL segment or timeline history file as PostgreSQL names it
+  file_name TEXT NOT NULL CHECK (file_name <> ''),
+  path TEXT NOT NULL CHECK (path <> ''),
+  file_size BIGINT NOT NULL DEFAULT 0,
+  compression TEXT NOT NULL DEFAULT 'none',
+  file_extension TEXT NOT NULL DEFAULT '',
+  encryption_key_fingerprint TEXT,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  UNIQUE (backup_id, file_name)
+);
+
+ALTER TABLE restorations ADD COLUMN IF NOT EXISTS data_directory TEXT;
+ALTER TABLE restorations ADD COLUMN IF NOT EXISTS recovery_target_time TIMESTAMPTZ;
+ALTER TABLE restorations ADD COLUMN IF NOT EXISTS recovery_target_lsn TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE restorations DROP COLUMN IF EXISTS recovery_target_lsn;
+ALTER TABLE restorations DROP COLUMN IF EXISTS recovery_target_time;
+ALTER TABLE restorations DROP COLUMN IF EXISTS data_directory;
+
+DROP TABLE IF EXISTS wal_segments;
+
+ALTER TABLE executions DROP COLUMN IF EXISTS wal_end_lsn;
+ALTER TABLE executions DROP COLUMN IF EXISTS wal_start_lsn;
+
+ALTER TABLE backups DROP COLUMN IF EXISTS mode;
+-- +goose StatementEnd
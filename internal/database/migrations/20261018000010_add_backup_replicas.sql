@@ -0,0 +1,79 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Additional destinations where every execution of a backup is copied, next
+-- to the destination (or local directory) of the backup itself
+CREATE TABLE IF NOT EXISTS backup_replicas (
+  id UUID NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+  backup_id UUID NOT NULL REFERENCES backups(id) ON DELETE CASCADE,
+  destination_id UUID REFERENCES destinations(id) ON DELETE CASCADE,
+  is_local BOOLEAN NOT NULL DEFAULT FALSE,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+  CONSTRAINT backup_replicas_destination_check CHECK (
+    (is_local = TRUE AND destination_id IS NULL) OR
+    (is_local = FALSE AND destination_id IS NOT NULL)
+  ),
+  UNIQUE (backup_id, destination_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS
+idx_backup_replicas_local ON backup_replicas(backup_id) WHERE is_local;
+
+-- Every file uploaded by an execution, one per destination
+CREATE TABLE IF NOT EXISTS execution_copies (
+  id UUID NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+  execution_id UUID NOT NULL REFERENCES executions(id) ON DELETE CASCADE,
+  destination_id UUID REFERENCES destinations(id) ON DELETE CASCADE,
+  is_local BOOLEAN NOT NULL DEFAULT FALSE,
+  -- The copy stored in the destination of the backup itself
+  is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+
+  status TEXT NOT NULL CHECK (
+    status IN ('running', 'success', 'failed', 'deleted')
+  ) DEFAULT 'running',
+  message TEXT,
+  path TEXT,
+  file_size BIGINT,
+  file_extension TEXT NOT NULL DEFAULT '',
+  encryption_key_fingerprint TEXT,
+  checksum TEXT,
+  verify_status TEXT,
+  verified_at TIMESTAMPTZ,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMPTZ,
+
+  CONSTRAINT execution_copies_destination_check CHECK (
+    (is_local = TRUE AND destination_id IS NULL) OR
+    (is_local = FALSE AND destination_id IS NOT NULL)
+  )
+);
+
+CREATE INDEX IF NOT EXISTS
+idx_execution_copies_execution_id ON execution_copies(execution_id);
+
+-- The files of the existing executions are the primary copies
+INSERT INTO execution_copies (
+  execution_id, destination_id, is_local, is_primary, status, message, path,
+  file_size, file_extension, encryption_key_fingerprint, checksum,
+  verify_status, verified_at, created_at, finished_at
+)
+SELECT
+  executions.id, backups.destination_id, backups.is_local, TRUE,
+  CASE WHEN executions.status IN ('running', 'success', 'deleted')
+  THEN executions.status ELSE 'failed' END,
+  executions.message, executions.path, executions.file_size,
+  executions.file_extension, executions.encryption_key_fingerprint,
+  executions.checksum, executions.verify_status, executions.verified_at,
+  executions.started_at, executions.finished_at
+FROM executions
+INNER JOIN backups ON backups.id = executions.backup_id
+WHERE executions.path IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS execution_copies;
+DROP TABLE IF EXISTS backup_replicas;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The storage backend of the destination. The existing columns are shared by
+-- the types: endpoint is the host of SFTP servers and the URL of WebDAV
+-- servers, access_key and secret_key are their username and password
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS type TEXT NOT NULL
+DEFAULT 's3' CHECK (type IN ('s3', 'sftp', 'webdav', 'local'));
+
+-- Directory where the files are stored, for SFTP, WebDAV and local
+-- destinations
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS base_path TEXT NOT NULL
+DEFAULT '';
+
+-- Private key of the SFTP user, encrypted like the rest of the credentials
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS private_key BYTEA;
+
+-- Public key of the SFTP server in authorized_keys format, the key of the
+-- server is not verified when it is empty
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS host_key TEXT NOT NULL
+DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE destinations DROP COLUMN IF EXISTS host_key;
+ALTER TABLE destinations DROP COLUMN IF EXISTS private_key;
+ALTER TABLE destinations DROP COLUMN IF EXISTS base_path;
+ALTER TABLE destinations DROP COLUMN IF EXISTS type;
+-- +goose StatementEnd
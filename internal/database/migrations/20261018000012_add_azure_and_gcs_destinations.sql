@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Azure Blob destinations use bucket_name as the container, access_key as the
+-- storage account name and secret_key as the shared key or a SAS token. GCS
+-- destinations use bucket_name and store the service account JSON in the
+-- encrypted private_key column. The endpoint is optional for both, it's used
+-- to reach emulators and sovereign clouds
+ALTER TABLE destinations DROP CONSTRAINT IF EXISTS destinations_type_check;
+ALTER TABLE destinations ADD CONSTRAINT destinations_type_check
+CHECK (type IN ('s3', 'sftp', 'webdav', 'local', 'azure', 'gcs'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Deleting the destinations would cascade to their backups and executions,
+-- they must be removed by hand before rolling back
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM destinations WHERE type IN ('azure', 'gcs')) THEN
+    RAISE EXCEPTION 'there are Azure or GCS destinations, delete them before rolling back this migration';
+  END IF;
+END $$;
+ALTER TABLE destinations DROP CONSTRAINT IF EXISTS destinations_type_check;
+ALTER TABLE destinations ADD CONSTRAINT destinations_type_check
+CHECK (type IN ('s3', 'sftp', 'webdav', 'local'));
+-- +goose StatementEnd
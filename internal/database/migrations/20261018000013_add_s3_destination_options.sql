@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Server-side encryption of the uploaded objects: '' (bucket default),
+-- 'AES256' (SSE-S3), 'aws:kms' (SSE-KMS) or 'SSE-C' (customer key)
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_server_side_encryption
+TEXT NOT NULL DEFAULT '' CHECK (
+  s3_server_side_encryption IN ('', 'AES256', 'aws:kms', 'SSE-C')
+);
+
+-- KMS key used by SSE-KMS, the AWS managed key is used when it is empty
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_kms_key_id TEXT NOT NULL
+DEFAULT '';
+
+-- Base64 encoded 256-bit key used by SSE-C, encrypted like the rest of the
+-- credentials
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_sse_customer_key BYTEA;
+
+-- Storage class of the uploaded objects, the bucket default when empty
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_storage_class TEXT
+NOT NULL DEFAULT '';
+
+-- Path-style addressing (endpoint/bucket/key) is used by default because it
+-- is supported by every S3 compatible storage
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_virtual_hosted_style
+BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Object Lock retention applied to every uploaded object, the bucket must
+-- have Object Lock enabled
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_object_lock_mode TEXT
+NOT NULL DEFAULT '' CHECK (
+  s3_object_lock_mode IN ('', 'GOVERNANCE', 'COMPLIANCE')
+);
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_object_lock_days INTEGER
+NOT NULL DEFAULT 0 CHECK (s3_object_lock_days >= 0);
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_object_lock_legal_hold
+BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_object_lock_legal_hold;
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_object_lock_days;
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_object_lock_mode;
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_virtual_hosted_style;
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_storage_class;
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_sse_customer_key;
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_kms_key_id;
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_server_side_encryption;
+-- +goose StatementEnd
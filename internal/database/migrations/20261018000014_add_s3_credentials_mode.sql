@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- How S3 destinations get their credentials: 'static' uses access_key and
+-- secret_key, 'default' uses the default AWS chain of the server (env vars,
+-- web identity, instance profile) and 'assume_role' assumes s3_role_arn
+-- using the static keys when they are set or the default chain otherwise.
+-- The keys of the non-static modes are stored as encrypted empty strings
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_credentials_mode TEXT
+NOT NULL DEFAULT 'static' CHECK (
+  s3_credentials_mode IN ('static', 'default', 'assume_role')
+);
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_role_arn TEXT NOT NULL
+DEFAULT '';
+ALTER TABLE destinations ADD COLUMN IF NOT EXISTS s3_external_id TEXT NOT NULL
+DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_external_id;
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_role_arn;
+ALTER TABLE destinations DROP COLUMN IF EXISTS s3_credentials_mode;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Executions running for longer than this are stopped and marked as
+-- timed_out, 0 means no limit
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS max_duration_minutes INTEGER
+NOT NULL DEFAULT 0 CHECK (max_duration_minutes >= 0);
+
+-- Executions can be cancelled by a user or stopped by the max duration
+ALTER TABLE executions DROP CONSTRAINT IF EXISTS executions_status_check;
+ALTER TABLE executions ADD CONSTRAINT executions_status_check CHECK (
+  status IN ('running', 'success', 'failed', 'deleted', 'cancelled', 'timed_out')
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE executions SET status = 'failed'
+WHERE status IN ('cancelled', 'timed_out');
+ALTER TABLE executions DROP CONSTRAINT IF EXISTS executions_status_check;
+ALTER TABLE executions ADD CONSTRAINT executions_status_check CHECK (
+  status IN ('running', 'success', 'failed', 'deleted')
+);
+
+ALTER TABLE backups DROP COLUMN IF EXISTS max_duration_minutes;
+-- +goose StatementEnd
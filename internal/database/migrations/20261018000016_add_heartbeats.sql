@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The running executions and restorations refresh heartbeat_at periodically,
+-- the ones whose heartbeat stopped belong to a process that died and are
+-- marked as failed
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ;
+ALTER TABLE restorations ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS
+idx_executions_running ON executions(started_at) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS
+idx_restorations_running ON restorations(started_at) WHERE status = 'running';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_restorations_running;
+DROP INDEX IF EXISTS idx_executions_running;
+ALTER TABLE restorations DROP COLUMN IF EXISTS heartbeat_at;
+ALTER TABLE executions DROP COLUMN IF EXISTS heartbeat_at;
+-- +goose StatementEnd
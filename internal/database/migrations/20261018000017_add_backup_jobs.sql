@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The queued jobs of the backups with a higher priority run first
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL
+DEFAULT 0 CHECK (priority BETWEEN 0 AND 100);
+
+-- Every scheduled or manual run of a backup goes through this queue, a job
+-- runs when a slot is free under the global and per server concurrency
+-- limits. Failed jobs wait until run_after to be retried
+CREATE TABLE IF NOT EXISTS backup_jobs (
+  id UUID NOT NULL DEFAULT uuid_generate_v4() PRIMARY KEY,
+  backup_id UUID NOT NULL REFERENCES backups(id) ON DELETE CASCADE,
+  -- The execution of the last attempt
+  execution_id UUID REFERENCES executions(id) ON DELETE SET NULL,
+  -- The host and port of the database server of the backup
+  server TEXT NOT NULL,
+  source TEXT NOT NULL CHECK (
+    source IN ('schedule', 'manual', 'recovery')
+  ),
+  priority SMALLINT NOT NULL DEFAULT 0,
+
+  status TEXT NOT NULL CHECK (
+    status IN ('queued', 'waiting', 'running', 'success', 'failed', 'cancelled')
+  ) DEFAULT 'queued',
+  message TEXT,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL DEFAULT 1,
+  run_after TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  heartbeat_at TIMESTAMPTZ,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ,
+  started_at TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ
+);
+
+CREATE TRIGGER backup_jobs_change_updated_at
+BEFORE UPDATE ON backup_jobs FOR EACH ROW EXECUTE FUNCTION change_updated_at();
+
+CREATE INDEX IF NOT EXISTS
+idx_backup_jobs_backup_id ON backup_jobs(backup_id);
+CREATE INDEX IF NOT EXISTS
+idx_backup_jobs_pending ON backup_jobs(priority DESC, run_after ASC)
+WHERE status IN ('queued', 'waiting', 'running');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS backup_jobs;
+ALTER TABLE backups DROP COLUMN IF EXISTS priority;
+-- +goose StatementEnd
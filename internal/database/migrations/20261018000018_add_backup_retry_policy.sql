@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A failed or timed out run of a backup is retried up to retry_max_attempts
+-- times in total, waiting retry_backoff_seconds before the first retry and
+-- twice as long before every next one. NULL uses the server default, set
+-- with PBW_BACKUP_MAX_ATTEMPTS and PBW_BACKUP_RETRY_BACKOFF
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS retry_max_attempts SMALLINT
+CHECK (retry_max_attempts BETWEEN 1 AND 10);
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS retry_backoff_seconds INTEGER
+CHECK (retry_backoff_seconds BETWEEN 1 AND 86400);
+
+ALTER TABLE backup_jobs ADD COLUMN IF NOT EXISTS backoff_seconds INTEGER
+NOT NULL DEFAULT 60;
+
+-- The retries of an execution point to the execution of the first attempt
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS parent_execution_id UUID
+REFERENCES executions(id) ON DELETE SET NULL;
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS attempt INTEGER NOT NULL
+DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS
+idx_executions_parent_execution_id ON executions(parent_execution_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_executions_parent_execution_id;
+ALTER TABLE executions DROP COLUMN IF EXISTS attempt;
+ALTER TABLE executions DROP COLUMN IF EXISTS parent_execution_id;
+ALTER TABLE backup_jobs DROP COLUMN IF EXISTS backoff_seconds;
+ALTER TABLE backups DROP COLUMN IF EXISTS retry_backoff_seconds;
+ALTER TABLE backups DROP COLUMN IF EXISTS retry_max_attempts;
+-- +goose StatementEnd
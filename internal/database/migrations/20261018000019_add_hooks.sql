@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The SQL statements and commands run before and after the dump of a backup
+-- or the restore of a restoration, a JSON array of objects with the stage
+-- ('pre' or 'post'), type ('sql' or 'command'), script and on_failure
+-- ('abort' or 'continue') of every hook
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS hooks JSONB NOT NULL
+DEFAULT '[]'::JSONB;
+ALTER TABLE restorations ADD COLUMN IF NOT EXISTS hooks JSONB NOT NULL
+DEFAULT '[]'::JSONB;
+
+-- The output of the hooks that ran
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS log TEXT;
+ALTER TABLE restorations ADD COLUMN IF NOT EXISTS log TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE restorations DROP COLUMN IF EXISTS log;
+ALTER TABLE executions DROP COLUMN IF EXISTS log;
+ALTER TABLE restorations DROP COLUMN IF EXISTS hooks;
+ALTER TABLE backups DROP COLUMN IF EXISTS hooks;
+-- +goose StatementEnd
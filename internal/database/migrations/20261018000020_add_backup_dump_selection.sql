@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The pg_dump patterns of --schema, --exclude-schema, --table, --exclude-table
+-- and --exclude-table-data, an empty array means the option is not used
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS opt_schemas TEXT[] NOT NULL
+DEFAULT '{}';
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS opt_exclude_schemas TEXT[] NOT NULL
+DEFAULT '{}';
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS opt_tables TEXT[] NOT NULL
+DEFAULT '{}';
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS opt_exclude_tables TEXT[] NOT NULL
+DEFAULT '{}';
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS opt_exclude_table_data TEXT[]
+NOT NULL DEFAULT '{}';
+
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS opt_no_owner BOOLEAN NOT NULL
+DEFAULT FALSE;
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS opt_no_privileges BOOLEAN NOT NULL
+DEFAULT FALSE;
+
+-- The role of --role, empty means the role of the connection
+ALTER TABLE backups ADD COLUMN IF NOT EXISTS opt_role TEXT NOT NULL
+DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_role;
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_no_privileges;
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_no_owner;
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_exclude_table_data;
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_exclude_tables;
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_tables;
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_exclude_schemas;
+ALTER TABLE backups DROP COLUMN IF EXISTS opt_schemas;
+-- +goose StatementEnd
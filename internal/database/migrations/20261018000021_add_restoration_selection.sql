@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The schemas and tables picked from the backup, a JSON object with the
+-- schemas, tables, content ('all', 'schema' or 'data') and target_schema of
+-- the selection, an empty object means everything was restored
+ALTER TABLE restorations ADD COLUMN IF NOT EXISTS selection JSONB NOT NULL
+DEFAULT '{}'::JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE restorations DROP COLUMN IF EXISTS selection;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The database created for the restoration, a JSON object with the name,
+-- template, owner, no_owner and role_map of the new database, an empty
+-- object means the backup was restored into an existing database
+ALTER TABLE restorations ADD COLUMN IF NOT EXISTS new_database JSONB NOT NULL
+DEFAULT '{}'::JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE restorations DROP COLUMN IF EXISTS new_database;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A protected database requires its name to be typed to confirm a restore
+ALTER TABLE databases ADD COLUMN IF NOT EXISTS is_protected BOOLEAN NOT NULL
+DEFAULT FALSE;
+
+-- A dry run only checks the backup and the target, nothing is written
+ALTER TABLE restorations ADD COLUMN IF NOT EXISTS dry_run BOOLEAN NOT NULL
+DEFAULT FALSE;
+
+-- The backup of the target database taken right before the restore, it can
+-- be restored to roll back a bad restore
+ALTER TABLE restorations ADD COLUMN IF NOT EXISTS pre_restore_execution_id UUID
+REFERENCES executions(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE restorations DROP COLUMN IF EXISTS pre_restore_execution_id;
+ALTER TABLE restorations DROP COLUMN IF EXISTS dry_run;
+ALTER TABLE databases DROP COLUMN IF EXISTS is_protected;
+-- +goose StatementEnd
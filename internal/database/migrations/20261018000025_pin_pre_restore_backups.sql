@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pinned executions are never deleted by the retention policy of their
+-- backup, the pre-restore backups are pinned so a restore can always be
+-- rolled back
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS is_pinned BOOLEAN NOT NULL
+DEFAULT FALSE;
+
+-- The execution of a job that pins it is created pinned
+ALTER TABLE backup_jobs ADD COLUMN IF NOT EXISTS pin_execution BOOLEAN
+NOT NULL DEFAULT FALSE;
+
+ALTER TABLE backup_jobs DROP CONSTRAINT IF EXISTS backup_jobs_source_check;
+ALTER TABLE backup_jobs ADD CONSTRAINT backup_jobs_source_check
+CHECK (source IN ('schedule', 'manual', 'recovery', 'pre_restore'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE backup_jobs SET source = 'manual' WHERE source = 'pre_restore';
+ALTER TABLE backup_jobs DROP CONSTRAINT IF EXISTS backup_jobs_source_check;
+ALTER TABLE backup_jobs ADD CONSTRAINT backup_jobs_source_check
+CHECK (source IN ('schedule', 'manual', 'recovery'));
+
+ALTER TABLE backup_jobs DROP COLUMN IF EXISTS pin_execution;
+ALTER TABLE executions DROP COLUMN IF EXISTS is_pinned;
+-- +goose StatementEnd
@@ -2,6 +2,7 @@ package clickhouse
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"net/url"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
 	"github.com/eduardolat/pgbackweb/internal/integration/database"
 	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 )
@@ -133,8 +135,11 @@ func (Client) Test(version string, connString string) error {
 	return nil
 }
 
-// DumpZip creates a backup using clickhouse-backup and returns it as a ZIP-compressed io.Reader
-func (c *Client) DumpZip(version string, connString string, params database.DumpParams) io.Reader {
+// DumpZip creates a backup using clickhouse-backup and returns it as a compressed io.Reader
+// together with the file extension. With the ZIP codec the backup files are stored in a ZIP,
+// other codecs compress a tar archive of the backup files. clickhouse-backup is killed when ctx is done
+func (c *Client) DumpZip(ctx context.Context, version string, connString string, params database.DumpParams, comp compression.Params) (io.Reader, string) {
+	isZip := comp.Codec == "" || comp.Codec == compression.CodecZip
 	reader, writer := io.Pipe()
 
 	go func() {
@@ -175,9 +180,13 @@ func (c *Client) DumpZip(version string, connString string, params database.Dump
 		}
 
 		// Run clickhouse-backup create
-		cmd := exec.Command("clickhouse-backup", args...)
+		cmd := exec.CommandContext(ctx, "clickhouse-backup", args...)
 		cmd.Dir = workDir
 		output, err := cmd.CombinedOutput()
+		if err != nil && ctx.Err() != nil {
+			writer.CloseWithError(context.Cause(ctx))
+			return
+		}
 		if err != nil {
 			writer.CloseWithError(fmt.Errorf(
 				"error running clickhouse-backup create v%s: %s",
@@ -192,6 +201,23 @@ func (c *Client) DumpZip(version string, connString string, params database.Dump
 			return
 		}
 
+		if !isZip {
+			compressor, err := compression.NewWriter(writer, comp.Codec, comp.Level)
+			if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+			if err := compression.WriteTarDir(compressor, backupPath, ""); err != nil {
+				_ = compressor.Close()
+				writer.CloseWithError(fmt.Errorf("error creating tar from backup: %w", err))
+				return
+			}
+			if err := compressor.Close(); err != nil {
+				writer.CloseWithError(fmt.Errorf("error compressing backup: %w", err))
+			}
+			return
+		}
+
 		// Create ZIP archive from backup directory
 		zipWriter := zip.NewWriter(writer)
 		defer zipWriter.Close()
@@ -235,49 +261,46 @@ func (c *Client) DumpZip(version string, connString string, params database.Dump
 		}
 	}()
 
-	return reader
+	if isZip {
+		return reader, compression.Extension("", compression.CodecZip)
+	}
+	return reader, compression.Extension("tar", comp.Codec)
 }
 
-// RestoreZip restores a ClickHouse database from a ZIP backup file
-func (Client) RestoreZip(version string, connString string, isLocal bool, zipURLOrPath string) error {
+// RestoreZip restores a ClickHouse database from a backup file created by DumpZip
+// The backup file is extracted as it is read, only a ZIP file that can only be
+// read in order is buffered first
+func (Client) RestoreZip(ctx context.Context, version string, connString string, file *database.BackupFile, fileExtension string, params database.RestoreParams) error {
+	codec, _ := compression.ParseExtension(fileExtension)
+	isZip := fileExtension == "" || codec == compression.CodecZip
+
 	workDir, err := os.MkdirTemp("", "ch-restore-*")
 	if err != nil {
 		return fmt.Errorf("error creating temp dir: %w", err)
 	}
 	defer os.RemoveAll(workDir)
 
-	zipPath := strutil.CreatePath(true, workDir, "backup.zip")
 	backupPath := strutil.CreatePath(true, workDir, "backup")
 
-	// Download or copy ZIP file
-	if isLocal {
-		cmd := exec.Command("cp", zipURLOrPath, zipPath)
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("error copying ZIP file to temp dir: %s", output)
+	// Extract the ZIP file, or decompress and extract the tar archive
+	if isZip {
+		err = file.Buffer()
+		if err == nil {
+			err = compression.ExtractZip(file, file.Size(), backupPath)
 		}
 	} else {
-		cmd := exec.Command("wget", "--no-verbose", "-O", zipPath, zipURLOrPath)
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("error downloading ZIP file: %s", output)
-		}
+		err = extractCompressedTar(file, backupPath, codec)
 	}
-
-	if _, err := os.Stat(zipPath); os.IsNotExist(err) {
-		return fmt.Errorf("zip file not found: %s", zipPath)
+	if err != nil && ctx.Err() != nil {
+		return context.Cause(ctx)
 	}
-
-	// Extract ZIP file
-	cmd := exec.Command("unzip", "-o", zipPath, "-d", backupPath)
-	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("error unzipping ZIP file: %s", output)
+		return err
 	}
 
 	// Check if backup directory exists
 	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
-		return fmt.Errorf("backup directory not found in ZIP file: %s", zipPath)
+		return fmt.Errorf("backup directory not found in backup file")
 	}
 
 	// Get backup name from directory (should be the first directory in backupPath)
@@ -307,8 +330,11 @@ func (Client) RestoreZip(version string, connString string, isLocal bool, zipURL
 
 	// Run clickhouse-backup restore
 	restorePath := filepath.Join(backupPath, backupName)
-	cmd = exec.Command("clickhouse-backup", "restore", restorePath)
-	output, err = cmd.CombinedOutput()
+	cmd := exec.CommandContext(ctx, "clickhouse-backup", "restore", restorePath)
+	output, err := cmd.CombinedOutput()
+	if err != nil && ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
 	if err != nil {
 		return fmt.Errorf(
 			"error running clickhouse-backup restore v%s: %s",
@@ -319,6 +345,18 @@ func (Client) RestoreZip(version string, connString string, isLocal bool, zipURL
 	return nil
 }
 
+// extractCompressedTar decompresses the tar archive read from r with the
+// codec and extracts it into dir.
+func extractCompressedTar(r io.Reader, dir string, codec string) error {
+	reader, err := compression.NewReader(r, codec)
+	if err != nil {
+		return fmt.Errorf("error decompressing backup file: %w", err)
+	}
+	defer reader.Close()
+
+	return compression.ExtractTar(reader, dir)
+}
+
 type Client struct{}
 
 func New() *Client {
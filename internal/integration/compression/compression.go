@@ -0,0 +1,114 @@
+package compression
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Codecs used to compress backup files.
+const (
+	// CodecZip stores the dump files inside a ZIP archive, it is the codec used
+	// by backups created before codecs were configurable.
+	CodecZip = "zip"
+	// CodecZstd compresses the dump with zstd, it is the fastest and produces
+	// the smallest files.
+	CodecZstd = "zstd"
+	// CodecGzip compresses the dump with gzip.
+	CodecGzip = "gzip"
+	// CodecNone stores the dump without compression.
+	CodecNone = "none"
+)
+
+// Codecs maps every supported codec to its human readable name.
+var Codecs = map[string]string{
+	CodecZstd: "Zstandard",
+	CodecGzip: "Gzip",
+	CodecZip:  "ZIP",
+	CodecNone: "None",
+}
+
+// Maximum compression level for each codec, zstd levels above 19 need a lot
+// of memory so they are not allowed.
+const (
+	MaxLevelZstd = 19
+	MaxLevelGzip = 9
+)
+
+// Params contains the compression settings of a backup.
+type Params struct {
+	// Codec is one of the Codec* constants. Empty means CodecZip.
+	Codec string
+	// Level is the compression level, 0 means the default level of the codec.
+	Level int
+}
+
+// Validate checks that the codec is supported and that the level can be used
+// with it. Only zstd and gzip accept a level.
+func Validate(codec string, level int) error {
+	if _, ok := Codecs[codec]; !ok {
+		return fmt.Errorf("invalid compression codec %q", codec)
+	}
+
+	maxLevel := 0
+	switch codec {
+	case CodecZstd:
+		maxLevel = MaxLevelZstd
+	case CodecGzip:
+		maxLevel = MaxLevelGzip
+	}
+
+	if level < 0 || level > maxLevel {
+		if maxLevel == 0 {
+			return fmt.Errorf("compression codec %q does not accept a level", codec)
+		}
+		return fmt.Errorf(
+			"compression level for %q must be between 0 and %d", codec, maxLevel,
+		)
+	}
+
+	return nil
+}
+
+// suffix returns the extension added by the codec to the compressed file.
+func suffix(codec string) string {
+	switch codec {
+	case CodecZstd:
+		return ".zst"
+	case CodecGzip:
+		return ".gz"
+	case CodecZip:
+		return ".zip"
+	default:
+		return ""
+	}
+}
+
+// Extension returns the extension of a file of the given kind compressed with
+// the codec. The kind is the extension of the uncompressed file without the
+// leading dot, for example "sql" or "tar". ZIP files always use ".zip"
+// because the files inside keep their own names.
+func Extension(kind, codec string) string {
+	if codec == CodecZip || codec == "" {
+		return suffix(CodecZip)
+	}
+	return "." + kind + suffix(codec)
+}
+
+// ParseExtension returns the codec and the kind of a file extension created
+// with Extension. The kind is empty for ZIP files.
+func ParseExtension(ext string) (codec string, kind string) {
+	if ext == suffix(CodecZip) {
+		return CodecZip, ""
+	}
+
+	codec = CodecNone
+	for _, c := range []string{CodecZstd, CodecGzip} {
+		if strings.HasSuffix(ext, suffix(c)) {
+			codec = c
+			ext = strings.TrimSuffix(ext, suffix(c))
+			break
+		}
+	}
+
+	return codec, strings.TrimPrefix(ext, ".")
+}
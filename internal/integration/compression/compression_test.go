@@ -0,0 +1,150 @@
+package compression
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   string
+		level   int
+		wantErr bool
+	}{
+		{"zstd default", CodecZstd, 0, false},
+		{"zstd max", CodecZstd, MaxLevelZstd, false},
+		{"zstd too high", CodecZstd, MaxLevelZstd + 1, true},
+		{"gzip level", CodecGzip, 6, false},
+		{"gzip too high", CodecGzip, MaxLevelGzip + 1, true},
+		{"negative level", CodecGzip, -1, true},
+		{"zip", CodecZip, 0, false},
+		{"zip with level", CodecZip, 3, true},
+		{"none", CodecNone, 0, false},
+		{"unknown", "brotli", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.codec, tt.level)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExtension(t *testing.T) {
+	tests := []struct {
+		kind  string
+		codec string
+		want  string
+	}{
+		{"sql", CodecZip, ".zip"},
+		{"sql", "", ".zip"},
+		{"sql", CodecZstd, ".sql.zst"},
+		{"dump", CodecGzip, ".dump.gz"},
+		{"dir.tar", CodecZstd, ".dir.tar.zst"},
+		{"tar", CodecNone, ".tar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			ext := Extension(tt.kind, tt.codec)
+			assert.Equal(t, tt.want, ext)
+
+			codec, kind := ParseExtension(ext)
+			if tt.codec == CodecZip || tt.codec == "" {
+				assert.Equal(t, CodecZip, codec)
+				assert.Equal(t, "", kind)
+				return
+			}
+			assert.Equal(t, tt.codec, codec)
+			assert.Equal(t, tt.kind, kind)
+		})
+	}
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	codecs := []string{CodecZstd, CodecGzip, CodecNone}
+
+	content := bytes.Repeat([]byte("SELECT 1;\n"), 1000)
+
+	for _, codec := range codecs {
+		t.Run(codec, func(t *testing.T) {
+			compressed, err := io.ReadAll(
+				Compress(bytes.NewReader(content), codec, 0),
+			)
+			assert.NoError(t, err)
+
+			reader, err := NewReader(bytes.NewReader(compressed), codec)
+			assert.NoError(t, err)
+			defer reader.Close()
+
+			got, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			assert.Equal(t, content, got)
+		})
+	}
+}
+
+func TestTarRoundTrip(t *testing.T) {
+	srcDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(srcDir, "sub"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "toc.dat"), []byte("toc"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "sub", "1.dat"), []byte("data"), 0o644))
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteTarDir(buf, srcDir, "dump"))
+
+	dstDir := t.TempDir()
+	assert.NoError(t, ExtractTar(buf, dstDir))
+
+	toc, err := os.ReadFile(filepath.Join(dstDir, "dump", "toc.dat"))
+	assert.NoError(t, err)
+	assert.Equal(t, "toc", string(toc))
+
+	data, err := os.ReadFile(filepath.Join(dstDir, "dump", "sub", "1.dat"))
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+}
+
+func TestExtractZip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(buf)
+	for name, content := range map[string]string{
+		"backup/metadata.json": "{}", "backup/shadow/1.bin": "data",
+	} {
+		w, err := zipWriter.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zipWriter.Close())
+
+	dstDir := t.TempDir()
+	reader := bytes.NewReader(buf.Bytes())
+	assert.NoError(t, ExtractZip(reader, reader.Size(), dstDir))
+
+	data, err := os.ReadFile(filepath.Join(dstDir, "backup", "shadow", "1.bin"))
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+
+	buf.Reset()
+	zipWriter = zip.NewWriter(buf)
+	_, err = zipWriter.Create("../escape.txt")
+	require.NoError(t, err)
+	require.NoError(t, zipWriter.Close())
+
+	reader = bytes.NewReader(buf.Bytes())
+	assert.Error(t, ExtractZip(reader, reader.Size(), t.TempDir()))
+}
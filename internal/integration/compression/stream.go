@@ -0,0 +1,93 @@
+package compression
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// NewWriter returns a writer that compresses everything written to it with
+// the codec and writes the result to w. Close must be called to flush the
+// remaining data, it does not close w.
+//
+// The zstd levels are mapped to the closest speed of the Go zstd encoder.
+//
+// CodecZip is not a stream codec and is not supported by NewWriter.
+func NewWriter(w io.Writer, codec string, level int) (io.WriteCloser, error) {
+	switch codec {
+	case CodecZstd:
+		options := []zstd.EOption{}
+		if level > 0 {
+			options = append(
+				options, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+			)
+		}
+		return zstd.NewWriter(w, options...)
+	case CodecGzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	case CodecNone:
+		return nopWriteCloser{w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported stream compression codec %q", codec)
+	}
+}
+
+// NewReader returns a reader that decompresses r with the codec.
+//
+// CodecZip is not a stream codec and is not supported by NewReader.
+func NewReader(r io.Reader, codec string) (io.ReadCloser, error) {
+	switch codec {
+	case CodecZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case CodecGzip:
+		return gzip.NewReader(r)
+	case CodecNone:
+		return io.NopCloser(r), nil
+	default:
+		return nil, fmt.Errorf("unsupported stream compression codec %q", codec)
+	}
+}
+
+// Compress returns a reader with the content of r compressed with the codec.
+// Errors reading from r or compressing are returned by the reader.
+func Compress(r io.Reader, codec string, level int) io.Reader {
+	reader, writer := io.Pipe()
+
+	go func() {
+		defer writer.Close()
+
+		compressor, err := NewWriter(writer, codec, level)
+		if err != nil {
+			writer.CloseWithError(err)
+			return
+		}
+
+		if _, err := io.Copy(compressor, r); err != nil {
+			_ = compressor.Close()
+			writer.CloseWithError(fmt.Errorf("error compressing dump: %w", err))
+			return
+		}
+
+		if err := compressor.Close(); err != nil {
+			writer.CloseWithError(fmt.Errorf("error compressing dump: %w", err))
+			return
+		}
+	}()
+
+	return reader
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,45 @@
+package compression
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractZip extracts the ZIP archive of the given size read from r into
+// dir. Entries that would be written outside dir are rejected.
+func ExtractZip(r io.ReaderAt, size int64, dir string) error {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("error opening ZIP archive: %w", err)
+	}
+
+	for _, file := range zipReader.File {
+		target := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path in ZIP archive: %s", file.Name)
+		}
+
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("error reading ZIP archive: %w", err)
+		}
+		err = extractTarFile(reader, target)
+		_ = reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
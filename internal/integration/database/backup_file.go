@@ -0,0 +1,375 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+)
+
+// ProgressInterval is the min time between two progress reports of a
+// BackupFile, the end of the file is always reported.
+const ProgressInterval = 30 * time.Second
+
+// downloadClient downloads the remote backup files. It has no overall timeout
+// because a download lasts as long as the restore reading it, only connecting
+// and waiting for the server to respond are limited.
+var downloadClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: time.Minute,
+		IdleConnTimeout:       90 * time.Second,
+	},
+}
+
+// ProgressFunc receives the bytes read from a backup file and its size, the
+// size is -1 when it's unknown.
+type ProgressFunc func(read int64, size int64)
+
+// BackupFile is a backup file read from the local disk, downloaded from its
+// URL or read from a stream while it is read, it is not copied to disk.
+// Remote files are read with HTTP range requests, a sequential read is served
+// by a single request. Streams, like the decrypted content of an encrypted
+// backup, can only be read in order, see Buffer.
+type BackupFile struct {
+	reader   io.ReaderAt
+	stream   io.Reader
+	closer   io.Closer
+	size     int64
+	offset   int64
+	progress ProgressFunc
+	tempPath string
+
+	mu           sync.Mutex
+	read         int64
+	lastReport   time.Time
+	reportedDone bool
+}
+
+// OpenBackupFile opens the backup file of the local path or the download URL,
+// the progress function is called while the file is read and can be nil.
+func OpenBackupFile(
+	ctx context.Context, isLocal bool, urlOrPath string, progress ProgressFunc,
+) (*BackupFile, error) {
+	file := &BackupFile{progress: progress, lastReport: time.Now()}
+
+	if isLocal {
+		local, err := os.Open(urlOrPath)
+		if err != nil {
+			return nil, fmt.Errorf("error opening backup file: %w", err)
+		}
+		info, err := local.Stat()
+		if err != nil {
+			_ = local.Close()
+			return nil, fmt.Errorf("error opening backup file: %w", err)
+		}
+		file.reader, file.closer, file.size = local, local, info.Size()
+		return file, nil
+	}
+
+	remote, err := openHTTPFile(ctx, urlOrPath)
+	if err != nil {
+		return nil, err
+	}
+	file.reader, file.closer, file.size = remote, remote, remote.size
+	return file, nil
+}
+
+// NewStreamBackupFile returns the backup file read from the stream, the file
+// is closed with the stream. The size is only used to report the progress,
+// it can be approximate or -1 when it's unknown.
+func NewStreamBackupFile(
+	stream io.ReadCloser, size int64, progress ProgressFunc,
+) *BackupFile {
+	return &BackupFile{
+		stream:     stream,
+		closer:     stream,
+		size:       size,
+		progress:   progress,
+		lastReport: time.Now(),
+	}
+}
+
+// Size returns the size in bytes of the backup file, -1 when it's a stream
+// of unknown size.
+func (f *BackupFile) Size() int64 {
+	return f.size
+}
+
+// CanReadAt reports whether the file can be read at any offset, streams can
+// only be read in order until they are buffered.
+func (f *BackupFile) CanReadAt() bool {
+	return f.reader != nil
+}
+
+// Buffer copies a stream that wasn't read yet into a temp file so it can be
+// read at any offset, it's needed by the ZIP backups whose index is at the
+// end of the file. The temp file is deleted on Close.
+func (f *BackupFile) Buffer() error {
+	if f.CanReadAt() {
+		return nil
+	}
+
+	temp, err := os.CreateTemp("", "pbw-backup-*")
+	if err != nil {
+		return fmt.Errorf("error creating temp file: %w", err)
+	}
+	f.tempPath = temp.Name()
+
+	size, err := io.Copy(temp, f)
+	if err != nil {
+		_ = temp.Close()
+		return fmt.Errorf("error reading backup file: %w", err)
+	}
+
+	stream := f.closer
+	f.reader, f.closer, f.size, f.offset = temp, temp, size, 0
+	f.stream = nil
+	_ = stream.Close()
+	return nil
+}
+
+// ReadAt implements io.ReaderAt, streams must be buffered first.
+func (f *BackupFile) ReadAt(p []byte, off int64) (int, error) {
+	if !f.CanReadAt() {
+		return 0, fmt.Errorf("the backup file can only be read in order")
+	}
+
+	n, err := f.reader.ReadAt(p, off)
+	f.report(int64(n), false)
+	return n, err
+}
+
+// Read implements io.Reader, reading the file from the start.
+func (f *BackupFile) Read(p []byte) (int, error) {
+	if !f.CanReadAt() {
+		n, err := f.stream.Read(p)
+		f.offset += int64(n)
+		f.report(int64(n), err == io.EOF)
+		return n, err
+	}
+
+	if f.offset >= f.size {
+		return 0, io.EOF
+	}
+	if remaining := f.size - f.offset; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Close implements io.Closer.
+func (f *BackupFile) Close() error {
+	err := f.closer.Close()
+	if f.tempPath != "" {
+		_ = os.Remove(f.tempPath)
+	}
+	return err
+}
+
+// report adds the read bytes and calls the progress function if it's time
+// to. The bytes read more than once, like the index of a ZIP file, are
+// counted again so the count is capped at the size. The size of a stream is
+// approximate, it's only known once its end is read.
+func (f *BackupFile) report(n int64, end bool) {
+	if f.progress == nil || (n == 0 && !end) {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.read += n
+	if end {
+		f.size = f.read
+	}
+	done := end
+	if f.stream == nil {
+		f.read = min(f.read, f.size)
+		done = f.read == f.size
+	}
+	if done && f.reportedDone {
+		return
+	}
+	if !done && time.Since(f.lastReport) < ProgressInterval {
+		return
+	}
+
+	f.lastReport = time.Now()
+	f.reportedDone = done
+	f.progress(f.read, f.size)
+}
+
+// FormatProgress returns a human readable progress report of a backup file.
+func FormatProgress(read int64, size int64) string {
+	if size < 0 {
+		return fmt.Sprintf(
+			"read %s of the backup file", strutil.FormatFileSize(read),
+		)
+	}
+
+	percent := int64(100)
+	if size > 0 {
+		percent = read * 100 / size
+	}
+	return fmt.Sprintf(
+		"read %s of %s of the backup file (%d%%)",
+		strutil.FormatFileSize(read), strutil.FormatFileSize(size), percent,
+	)
+}
+
+// httpFile reads a remote file with HTTP range requests. The response of the
+// last request is kept open, reads that continue where the previous one
+// stopped don't need a new request.
+type httpFile struct {
+	ctx  context.Context
+	url  string
+	size int64
+
+	mu     sync.Mutex
+	body   io.ReadCloser
+	offset int64
+}
+
+// openHTTPFile requests the whole file, the response gives its size and is
+// kept to serve the first sequential read.
+func openHTTPFile(ctx context.Context, url string) (*httpFile, error) {
+	file := &httpFile{ctx: ctx, url: url}
+
+	res, err := file.request(0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		file.size = res.ContentLength
+	case http.StatusPartialContent:
+		file.size, err = contentRangeSize(res.Header.Get("Content-Range"))
+	}
+	if err == nil && file.size < 0 {
+		err = fmt.Errorf("the size of the backup file is unknown")
+	}
+	if err != nil {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("error downloading backup file: %w", err)
+	}
+
+	file.body = res.Body
+	return file, nil
+}
+
+// request requests the file from the offset to its end.
+func (f *httpFile) request(offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading backup file: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+
+	res, err := downloadClient.Do(req)
+	if err != nil && f.ctx.Err() != nil {
+		return nil, context.Cause(f.ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error downloading backup file: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusPartialContent {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf(
+			"error downloading backup file: unexpected status %s", res.Status,
+		)
+	}
+	if res.StatusCode == http.StatusOK && offset > 0 {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf(
+			"error downloading backup file: the server doesn't support range requests",
+		)
+	}
+
+	return res, nil
+}
+
+// ReadAt implements io.ReaderAt.
+func (f *httpFile) ReadAt(p []byte, off int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if off >= f.size {
+		return 0, io.EOF
+	}
+
+	if f.body == nil || off != f.offset {
+		if f.body != nil {
+			_ = f.body.Close()
+			f.body = nil
+		}
+		res, err := f.request(off)
+		if err != nil {
+			return 0, err
+		}
+		f.body, f.offset = res.Body, off
+	}
+
+	n, err := io.ReadFull(f.body, p)
+	f.offset += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	if err != nil && err != io.EOF {
+		if f.ctx.Err() != nil {
+			err = context.Cause(f.ctx)
+		}
+		_ = f.body.Close()
+		f.body = nil
+		return n, fmt.Errorf("error downloading backup file: %w", err)
+	}
+	return n, err
+}
+
+// Close implements io.Closer.
+func (f *httpFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.body == nil {
+		return nil
+	}
+	err := f.body.Close()
+	f.body = nil
+	return err
+}
+
+// contentRangeSize returns the complete length of a Content-Range header
+// such as "bytes 0-99/1234".
+func contentRangeSize(header string) (int64, error) {
+	_, size, ok := strings.Cut(header, "/")
+	if !ok || size == "*" {
+		return 0, fmt.Errorf("invalid Content-Range header %q", header)
+	}
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Content-Range header %q", header)
+	}
+	return n, nil
+}
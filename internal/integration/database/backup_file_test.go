@@ -0,0 +1,220 @@
+package database
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenBackupFile(t *testing.T) {
+	content := []byte(strings.Repeat("pgbackweb", 1000))
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "backup.sql")
+	require.NoError(t, os.WriteFile(path, content, 0o644))
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "backup.sql", time.Time{}, bytes.NewReader(content))
+		},
+	))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		isLocal   bool
+		urlOrPath string
+	}{
+		{name: "local", isLocal: true, urlOrPath: path},
+		{name: "remote", isLocal: false, urlOrPath: server.URL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var read, size int64
+			file, err := OpenBackupFile(
+				context.Background(), tt.isLocal, tt.urlOrPath,
+				func(r int64, s int64) { read, size = r, s },
+			)
+			require.NoError(t, err)
+			defer file.Close()
+
+			assert.Equal(t, int64(len(content)), file.Size())
+
+			part := make([]byte, 9)
+			n, err := file.ReadAt(part, 9)
+			require.NoError(t, err)
+			assert.Equal(t, 9, n)
+			assert.Equal(t, "pgbackweb", string(part))
+
+			got, err := io.ReadAll(file)
+			require.NoError(t, err)
+			assert.Equal(t, content, got)
+
+			assert.Equal(t, int64(len(content)), read)
+			assert.Equal(t, int64(len(content)), size)
+		})
+	}
+}
+
+func TestOpenBackupFileZip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(buf)
+	writer, err := zipWriter.Create("dump.sql")
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("SELECT 1;"))
+	require.NoError(t, err)
+	require.NoError(t, zipWriter.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "backup.zip", time.Time{}, bytes.NewReader(buf.Bytes()))
+		},
+	))
+	defer server.Close()
+
+	file, err := OpenBackupFile(context.Background(), false, server.URL, nil)
+	require.NoError(t, err)
+	defer file.Close()
+
+	zipReader, err := zip.NewReader(file, file.Size())
+	require.NoError(t, err)
+	require.Len(t, zipReader.File, 1)
+
+	reader, err := zipReader.File[0].Open()
+	require.NoError(t, err)
+	defer reader.Close()
+
+	got, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT 1;", string(got))
+}
+
+func TestOpenBackupFileWithoutRanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("pgbackweb"))
+		},
+	))
+	defer server.Close()
+
+	file, err := OpenBackupFile(context.Background(), false, server.URL, nil)
+	require.NoError(t, err)
+	defer file.Close()
+
+	got, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, "pgbackweb", string(got))
+
+	_, err = file.ReadAt(make([]byte, 3), 3)
+	assert.ErrorContains(t, err, "doesn't support range requests")
+}
+
+func TestNewStreamBackupFile(t *testing.T) {
+	content := []byte(strings.Repeat("pgbackweb", 1000))
+
+	t.Run("sequential", func(t *testing.T) {
+		var read, size int64
+		file := NewStreamBackupFile(
+			io.NopCloser(bytes.NewReader(content)), -1,
+			func(r int64, s int64) { read, size = r, s },
+		)
+		defer file.Close()
+
+		assert.False(t, file.CanReadAt())
+		_, err := file.ReadAt(make([]byte, 9), 9)
+		assert.ErrorContains(t, err, "can only be read in order")
+
+		got, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, content, got)
+
+		assert.Equal(t, int64(len(content)), read)
+		assert.Equal(t, int64(len(content)), size)
+		assert.Equal(t, int64(len(content)), file.Size())
+	})
+
+	t.Run("buffered", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		zipWriter := zip.NewWriter(buf)
+		writer, err := zipWriter.Create("dump.sql")
+		require.NoError(t, err)
+		_, err = writer.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, zipWriter.Close())
+
+		file := NewStreamBackupFile(io.NopCloser(buf), 10, nil)
+		require.NoError(t, file.Buffer())
+		assert.True(t, file.CanReadAt())
+		tempPath := file.tempPath
+
+		zipReader, err := zip.NewReader(file, file.Size())
+		require.NoError(t, err)
+		reader, err := zipReader.File[0].Open()
+		require.NoError(t, err)
+		got, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, content, got)
+
+		require.NoError(t, file.Close())
+		_, err = os.Stat(tempPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestFormatProgress(t *testing.T) {
+	tests := []struct {
+		name string
+		read int64
+		size int64
+		want string
+	}{
+		{name: "half", read: 512, size: 1024, want: "(50%)"},
+		{name: "done", read: 1024, size: 1024, want: "(100%)"},
+		{name: "empty", read: 0, size: 0, want: "(100%)"},
+		{name: "unknown size", read: 1024, size: -1, want: "1.00 KB of the backup file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, strings.HasSuffix(FormatProgress(tt.read, tt.size), tt.want))
+		})
+	}
+}
+
+func TestContentRangeSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int64
+		wantErr bool
+	}{
+		{name: "valid", header: "bytes 0-99/1234", want: 1234},
+		{name: "unknown size", header: "bytes 0-99/*", wantErr: true},
+		{name: "missing size", header: "bytes 0-99", wantErr: true},
+		{name: "invalid size", header: "bytes 0-99/abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := contentRangeSize(tt.header)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
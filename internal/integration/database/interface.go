@@ -1,23 +1,36 @@
 package database
 
-import "io"
+import (
+	"context"
+	"io"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+)
 
 // DumpParams represents parameters for database dump operations
 // Different database types can have their own specific parameters
 type DumpParams interface{}
 
+// RestoreParams represents parameters for database restore operations
+// Different database types can have their own specific parameters
+type RestoreParams interface{}
+
 // DatabaseClient is the interface that all database clients must implement
 type DatabaseClient interface {
 	// Test tests the connection to the database
 	Test(version string, connString string) error
 
 	// DumpZip creates a compressed backup of the database and returns it as an io.Reader
-	// The backup format is ZIP containing the dump file(s)
-	DumpZip(version string, connString string, params DumpParams) io.Reader
-
-	// RestoreZip restores a database from a ZIP backup file
-	// isLocal indicates whether the zip file is local (true) or a URL (false)
-	RestoreZip(version string, connString string, isLocal bool, zipURLOrPath string) error
+	// together with the extension of the backup file. With the ZIP codec the backup is a
+	// ZIP containing the dump file(s), other codecs compress the dump as a stream.
+	// The dump is stopped and the reader fails with the cause of ctx when ctx is done
+	DumpZip(ctx context.Context, version string, connString string, params DumpParams, comp compression.Params) (io.Reader, string)
+
+	// RestoreZip restores a database from a backup file created by DumpZip
+	// file is read as the restore goes and is closed by the caller
+	// fileExtension is the extension returned by DumpZip, it selects the decoder
+	// The restore is stopped when ctx is done
+	RestoreZip(ctx context.Context, version string, connString string, file *BackupFile, fileExtension string, params RestoreParams) error
 
 	// ParseVersion validates and parses the version string for the database type
 	ParseVersion(version string) (interface{}, error)
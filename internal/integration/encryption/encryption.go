@@ -0,0 +1,68 @@
+// Package encryption encrypts backup files before they leave the process.
+//
+// Files are encrypted with AES-256-GCM in chunks so they can be streamed
+// without knowing their size. The format is:
+//
+//	magic   "PBWENC1\n"
+//	salt    32 random bytes
+//	chunks  every chunk is up to 64 KiB of plaintext sealed with a 16 bytes tag
+//
+// Every file uses its own key, HMAC-SHA256(key, salt). The nonce of a chunk
+// is its index as an 11 bytes big endian counter followed by a byte that is
+// 1 for the last chunk and 0 for the rest, so reordered, truncated or
+// appended chunks are detected.
+package encryption
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Extension is appended to the file extension of encrypted backups.
+const Extension = ".enc"
+
+// MinKeyLength is the minimum length of the secret used to derive a key.
+const MinKeyLength = 32
+
+// Key is the AES-256 key used to encrypt backups.
+type Key [32]byte
+
+// ParseKey derives the key from a secret of at least MinKeyLength
+// characters.
+func ParseKey(secret string) (Key, error) {
+	if len(secret) < MinKeyLength {
+		return Key{}, fmt.Errorf(
+			"encryption key must be at least %d characters long", MinKeyLength,
+		)
+	}
+	return Key(sha256.Sum256([]byte(secret))), nil
+}
+
+// ValidateKey checks that secret can be used as a key, an empty secret is
+// valid and means that backups are not encrypted.
+func ValidateKey(secret string) error {
+	if secret == "" {
+		return nil
+	}
+	_, err := ParseKey(secret)
+	return err
+}
+
+// Fingerprint returns a short identifier of the key that can be stored
+// next to the encrypted files without revealing the key.
+func (k Key) Fingerprint() string {
+	sum := sha256.Sum256(k[:])
+	return hex.EncodeToString(sum[:8])
+}
+
+// IsEncrypted reports whether a file with the given extension is encrypted.
+func IsEncrypted(fileExtension string) bool {
+	return strings.HasSuffix(fileExtension, Extension)
+}
+
+// TrimExtension returns the extension of the file once decrypted.
+func TrimExtension(fileExtension string) string {
+	return strings.TrimSuffix(fileExtension, Extension)
+}
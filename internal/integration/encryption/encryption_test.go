@@ -0,0 +1,88 @@
+package encryption
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testKey(t *testing.T, secret string) Key {
+	key, err := ParseKey(secret)
+	assert.NoError(t, err)
+	return key
+}
+
+func TestParseKey(t *testing.T) {
+	_, err := ParseKey("short")
+	assert.Error(t, err)
+
+	a := testKey(t, strings.Repeat("a", MinKeyLength))
+	b := testKey(t, strings.Repeat("b", MinKeyLength))
+	assert.NotEqual(t, a.Fingerprint(), b.Fingerprint())
+	assert.Len(t, a.Fingerprint(), 16)
+
+	assert.NoError(t, ValidateKey(""))
+	assert.Error(t, ValidateKey("short"))
+}
+
+func TestExtension(t *testing.T) {
+	assert.True(t, IsEncrypted(".sql.zst.enc"))
+	assert.False(t, IsEncrypted(".sql.zst"))
+	assert.Equal(t, ".sql.zst", TrimExtension(".sql.zst.enc"))
+	assert.Equal(t, ".zip", TrimExtension(".zip"))
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := testKey(t, strings.Repeat("k", MinKeyLength))
+
+	sizes := []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 7}
+	for _, size := range sizes {
+		content := bytes.Repeat([]byte{'x'}, size)
+
+		encrypted, err := io.ReadAll(Encrypt(bytes.NewReader(content), key))
+		assert.NoError(t, err)
+		assert.NotContains(t, string(encrypted), strings.Repeat("x", 32))
+
+		reader, err := NewReader(bytes.NewReader(encrypted), key)
+		assert.NoError(t, err)
+
+		got, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, content, got)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	key := testKey(t, strings.Repeat("k", MinKeyLength))
+	content := bytes.Repeat([]byte{'x'}, 2*chunkSize+10)
+
+	encrypted, err := io.ReadAll(Encrypt(bytes.NewReader(content), key))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data []byte
+		key  Key
+	}{
+		{"wrong key", encrypted, testKey(t, strings.Repeat("w", MinKeyLength))},
+		{"truncated", encrypted[:len(encrypted)-20], key},
+		{"truncated at chunk boundary", encrypted[:len(magic)+saltSize+chunkSize+tagSize], key},
+		{"modified", append(append([]byte{}, encrypted[:100]...), append([]byte{0}, encrypted[101:]...)...), key},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(bytes.NewReader(tt.data), tt.key)
+			assert.NoError(t, err)
+
+			_, err = io.ReadAll(reader)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err = NewReader(strings.NewReader(strings.Repeat("z", 64)), key)
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	magic     = "PBWENC1\n"
+	saltSize  = 32
+	chunkSize = 64 * 1024
+	tagSize   = 16
+	nonceSize = 12
+)
+
+func newAEAD(key Key, salt []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write(salt)
+
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, nonceSize)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// writer encrypts everything written to it, the last chunk is only sealed
+// on Close.
+type writer struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+	closed  bool
+}
+
+// NewWriter returns a writer that encrypts everything written to it with the
+// key and writes the result to w. Close must be called to write the last
+// chunk, it does not close w.
+func NewWriter(w io.Writer, key Key) (io.WriteCloser, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("error generating salt: %w", err)
+	}
+
+	aead, err := newAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.WriteString(w, magic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(salt); err != nil {
+		return nil, err
+	}
+
+	return &writer{w: w, aead: aead, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed encryption writer")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only flushed when more data arrives, so the last
+		// chunk is always the one sealed by Close.
+		if len(w.buf) == chunkSize {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+func (w *writer) flush(last bool) error {
+	sealed := w.aead.Seal(nil, chunkNonce(w.counter, last), w.buf, nil)
+	if _, err := w.w.Write(sealed); err != nil {
+		return err
+	}
+	w.counter++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+// reader decrypts a stream written by writer.
+type reader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	buf     []byte
+	plain   []byte
+	counter uint64
+	done    bool
+}
+
+// NewReader returns a reader that decrypts r with the key. An error is
+// returned by Read if the content was modified, truncated or encrypted with
+// a different key.
+func NewReader(r io.Reader, key Key) (io.Reader, error) {
+	header := make([]byte, len(magic)+saltSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("error reading encryption header: %w", err)
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, errors.New("file is not an encrypted backup")
+	}
+
+	aead, err := newAEAD(key, header[len(magic):])
+	if err != nil {
+		return nil, err
+	}
+
+	return &reader{
+		r:    bufio.NewReaderSize(r, chunkSize+tagSize),
+		aead: aead,
+		buf:  make([]byte, chunkSize+tagSize),
+	}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *reader) next() error {
+	n, err := io.ReadFull(r.r, r.buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		if err == io.EOF {
+			return errors.New("encrypted backup is truncated")
+		}
+		return err
+	}
+
+	last := n < len(r.buf)
+	if !last {
+		if _, err := r.r.Peek(1); err == io.EOF {
+			last = true
+		}
+	}
+
+	plain, err := r.aead.Open(
+		r.buf[:0], chunkNonce(r.counter, last), r.buf[:n], nil,
+	)
+	if err != nil {
+		return errors.New(
+			"error decrypting backup, the key is wrong or the file is corrupted",
+		)
+	}
+
+	r.counter++
+	r.plain = plain
+	r.done = last
+	return nil
+}
+
+// Encrypt returns a reader with the content of r encrypted with the key.
+// Errors reading from r or encrypting are returned by the reader.
+func Encrypt(r io.Reader, key Key) io.Reader {
+	reader, writer := io.Pipe()
+
+	go func() {
+		defer writer.Close()
+
+		encrypter, err := NewWriter(writer, key)
+		if err != nil {
+			writer.CloseWithError(fmt.Errorf("error encrypting dump: %w", err))
+			return
+		}
+
+		if _, err := io.Copy(encrypter, r); err != nil {
+			writer.CloseWithError(fmt.Errorf("error encrypting dump: %w", err))
+			return
+		}
+
+		if err := encrypter.Close(); err != nil {
+			writer.CloseWithError(fmt.Errorf("error encrypting dump: %w", err))
+			return
+		}
+	}()
+
+	return reader
+}
@@ -0,0 +1,101 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// commandPath is the only PATH of the commands
+const commandPath = "/usr/local/bin:/usr/bin:/bin"
+
+// runCommand runs a command hook with sh: it starts in an empty temporary
+// directory that is removed afterwards, it only gets a minimal environment
+// (PATH, HOME, TMPDIR and the PBW_* variables of env) so the secrets of PG Back
+// Web are not passed to it, and its output is truncated. It is not isolated,
+// it runs as the user of PG Back Web with access to its files and network.
+// The command runs in its own process group, so the processes it starts are
+// killed with it when ctx is done
+func runCommand(
+	ctx context.Context, script string, env map[string]string,
+) (string, error) {
+	workDir, err := os.MkdirTemp("", "pbw-hook-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating hook directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	output := &limitedBuffer{limit: maxOutput}
+	cmd := exec.CommandContext(ctx, "sh", "-c", script)
+	cmd.Dir = workDir
+	cmd.Env = commandEnv(workDir, env)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	// Children that keep the output open don't block the hook once it's killed
+	cmd.WaitDelay = 5 * time.Second
+
+	err = cmd.Run()
+	if err != nil && ctx.Err() != nil {
+		err = context.Cause(ctx)
+	}
+	return output.String(), err
+}
+
+// commandEnv returns the environment of a command, the keys of env that
+// don't start with PBW_ are ignored
+func commandEnv(workDir string, env map[string]string) []string {
+	vars := []string{
+		"PATH=" + commandPath,
+		"HOME=" + workDir,
+		"TMPDIR=" + workDir,
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		if strings.HasPrefix(key, "PBW_") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		vars = append(vars, key+"="+env[key])
+	}
+
+	return vars
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest, noting that the output was truncated
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n[output truncated]"
+	}
+	return b.buf.String()
+}
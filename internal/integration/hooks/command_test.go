@@ -0,0 +1,65 @@
+package hooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandEnv(t *testing.T) {
+	env := commandEnv("/tmp/hook", map[string]string{
+		"PBW_BACKUP_ID":     "1",
+		"PBW_DATABASE_NAME": "app",
+		"AWS_SECRET":        "secret",
+	})
+
+	assert.Equal(t, []string{
+		"PATH=" + commandPath,
+		"HOME=/tmp/hook",
+		"TMPDIR=/tmp/hook",
+		"PBW_BACKUP_ID=1",
+		"PBW_DATABASE_NAME=app",
+	}, env)
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{limit: 5}
+	n, err := b.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	n, err = b.Write([]byte("defgh"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	assert.Equal(t, "abcde\n[output truncated]", b.String())
+}
+
+func TestRunCommand(t *testing.T) {
+	output, err := runCommand(
+		context.Background(), `echo "$PBW_STAGE" && pwd && echo fail >&2 && exit 3`,
+		map[string]string{"PBW_STAGE": "pre"},
+	)
+
+	assert.Error(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "pre", lines[0])
+	assert.Contains(t, lines[1], "pbw-hook-")
+	assert.Equal(t, "fail", lines[2])
+}
+
+func TestRunCommandKillsChildren(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := runCommand(ctx, `sleep 30 & sleep 30`, nil)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
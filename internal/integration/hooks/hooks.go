@@ -0,0 +1,211 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Stages of the hooks
+const (
+	StagePre  = "pre"
+	StagePost = "post"
+)
+
+// Types of the hooks
+const (
+	TypeSQL     = "sql"
+	TypeCommand = "command"
+)
+
+// Failure policies of the hooks
+const (
+	OnFailureAbort    = "abort"
+	OnFailureContinue = "continue"
+)
+
+// Timeout is the max duration of a hook
+const Timeout = 10 * time.Minute
+
+// MaxHooks is the max number of hooks of a backup or restoration
+const MaxHooks = 10
+
+// maxOutput is the max number of bytes of the output of a hook kept in the
+// log, the rest is discarded
+const maxOutput = 16 << 10
+
+// ErrCommandsDisabled is returned when a command hook is saved or run while
+// PBW_ENABLE_COMMAND_HOOKS is not set
+var ErrCommandsDisabled = errors.New(
+	"command hooks are disabled, set PBW_ENABLE_COMMAND_HOOKS to enable them",
+)
+
+// Hook is a SQL script or a shell command run before or after a dump or a
+// restore
+type Hook struct {
+	Stage     string `json:"stage"`
+	Type      string `json:"type"`
+	Script    string `json:"script"`
+	OnFailure string `json:"on_failure"`
+}
+
+// Hooks are the hooks of a backup or a restoration in the order they run
+type Hooks []Hook
+
+// Parse decodes the hooks stored in the database, no content means no hooks
+func Parse(data []byte) (Hooks, error) {
+	hooks := Hooks{}
+	if len(data) == 0 {
+		return hooks, nil
+	}
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("error parsing hooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// Marshal encodes the hooks to be stored in the database
+func (h Hooks) Marshal() []byte {
+	if h == nil {
+		h = Hooks{}
+	}
+	data, _ := json.Marshal(h)
+	return data
+}
+
+// Validate checks the hooks before they are saved, the command hooks are
+// only accepted when commandsEnabled is true
+func (h Hooks) Validate(commandsEnabled bool) error {
+	if len(h) > MaxHooks {
+		return fmt.Errorf("a maximum of %d hooks is allowed", MaxHooks)
+	}
+
+	for i, hook := range h {
+		if hook.Stage != StagePre && hook.Stage != StagePost {
+			return fmt.Errorf("hook %d: unsupported stage: %s", i+1, hook.Stage)
+		}
+		if hook.Type != TypeSQL && hook.Type != TypeCommand {
+			return fmt.Errorf("hook %d: unsupported type: %s", i+1, hook.Type)
+		}
+		if hook.OnFailure != OnFailureAbort && hook.OnFailure != OnFailureContinue {
+			return fmt.Errorf(
+				"hook %d: unsupported failure policy: %s", i+1, hook.OnFailure,
+			)
+		}
+		if strings.TrimSpace(hook.Script) == "" {
+			return fmt.Errorf("hook %d: the script is required", i+1)
+		}
+		if hook.Type == TypeCommand && !commandsEnabled {
+			return fmt.Errorf("hook %d: %w", i+1, ErrCommandsDisabled)
+		}
+	}
+
+	return nil
+}
+
+// Stage returns the hooks of the stage
+func (h Hooks) Stage(stage string) Hooks {
+	hooks := Hooks{}
+	for _, hook := range h {
+		if hook.Stage == stage {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+// Find returns the first hook of the stage and type, used by the forms that
+// show one hook of each kind
+func (h Hooks) Find(stage string, hookType string) (Hook, bool) {
+	for _, hook := range h {
+		if hook.Stage == stage && hook.Type == hookType {
+			return hook, true
+		}
+	}
+	return Hook{}, false
+}
+
+// Target is what the hooks of a run act on
+type Target struct {
+	// RunSQL runs a SQL script in the database and returns its output, nil
+	// when the database doesn't support SQL hooks
+	RunSQL func(ctx context.Context, script string) (string, error)
+	// CommandsEnabled allows running the command hooks
+	CommandsEnabled bool
+	// Env are the PBW_* variables passed to the commands
+	Env map[string]string
+}
+
+// Run runs the hooks one after the other and writes an entry with the output
+// of each one to log. It stops at the first failed hook whose policy is
+// abort and returns its error, the failures of the rest are only logged
+func Run(ctx context.Context, hooks Hooks, target Target, log func(string)) error {
+	for _, hook := range hooks {
+		start := time.Now()
+		output, err := runHook(ctx, hook, target)
+		log(logEntry(hook, output, err, time.Since(start)))
+
+		if err != nil && hook.OnFailure != OnFailureContinue {
+			return fmt.Errorf("%s-%s hook failed: %w", hook.Stage, hook.Type, err)
+		}
+	}
+	return nil
+}
+
+func runHook(ctx context.Context, hook Hook, target Target) (string, error) {
+	ctx, cancel := context.WithTimeoutCause(
+		ctx, Timeout, fmt.Errorf("the hook timed out after %s", Timeout),
+	)
+	defer cancel()
+
+	switch hook.Type {
+	case TypeSQL:
+		if target.RunSQL == nil {
+			return "", errors.New("SQL hooks are only supported for PostgreSQL databases")
+		}
+		output, err := target.RunSQL(ctx, hook.Script)
+		if err != nil && ctx.Err() != nil {
+			err = context.Cause(ctx)
+		}
+		return output, err
+	case TypeCommand:
+		if !target.CommandsEnabled {
+			return "", ErrCommandsDisabled
+		}
+		return runCommand(ctx, hook.Script, target.Env)
+	default:
+		return "", fmt.Errorf("unsupported hook type: %s", hook.Type)
+	}
+}
+
+// logEntry formats the outcome of a hook for the log of its run
+func logEntry(hook Hook, output string, err error, took time.Duration) string {
+	b := strings.Builder{}
+
+	result := "ok"
+	if err != nil {
+		result = "failed"
+		if hook.OnFailure == OnFailureContinue {
+			result = "failed, continuing"
+		}
+	}
+	fmt.Fprintf(
+		&b, "[%s-%s hook] %s in %s\n",
+		hook.Stage, hook.Type, result, took.Round(time.Millisecond),
+	)
+
+	if output = strings.TrimSpace(output); output != "" {
+		b.WriteString(output)
+		b.WriteString("\n")
+	}
+	if err != nil {
+		b.WriteString("error: ")
+		b.WriteString(err.Error())
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooksValidate(t *testing.T) {
+	sqlHook := Hook{
+		Stage: StagePre, Type: TypeSQL, Script: "CHECKPOINT;", OnFailure: OnFailureAbort,
+	}
+	commandHook := Hook{
+		Stage: StagePost, Type: TypeCommand, Script: "echo done", OnFailure: OnFailureContinue,
+	}
+
+	tests := []struct {
+		name            string
+		hooks           Hooks
+		commandsEnabled bool
+		wantErr         bool
+	}{
+		{name: "no hooks", hooks: Hooks{}, wantErr: false},
+		{name: "sql hook", hooks: Hooks{sqlHook}, wantErr: false},
+		{name: "command hook enabled", hooks: Hooks{sqlHook, commandHook}, commandsEnabled: true, wantErr: false},
+		{name: "command hook disabled", hooks: Hooks{commandHook}, wantErr: true},
+		{name: "invalid stage", hooks: Hooks{{Stage: "during", Type: TypeSQL, Script: "SELECT 1", OnFailure: OnFailureAbort}}, wantErr: true},
+		{name: "invalid type", hooks: Hooks{{Stage: StagePre, Type: "http", Script: "SELECT 1", OnFailure: OnFailureAbort}}, wantErr: true},
+		{name: "invalid policy", hooks: Hooks{{Stage: StagePre, Type: TypeSQL, Script: "SELECT 1", OnFailure: "retry"}}, wantErr: true},
+		{name: "empty script", hooks: Hooks{{Stage: StagePre, Type: TypeSQL, Script: "  ", OnFailure: OnFailureAbort}}, wantErr: true},
+		{name: "too many hooks", hooks: make(Hooks, MaxHooks+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hooks.Validate(tt.commandsEnabled)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	hooks, err := Parse(nil)
+	require.NoError(t, err)
+	assert.Empty(t, hooks)
+
+	want := Hooks{{
+		Stage: StagePre, Type: TypeSQL, Script: "CHECKPOINT;", OnFailure: OnFailureAbort,
+	}}
+	hooks, err = Parse(want.Marshal())
+	require.NoError(t, err)
+	assert.Equal(t, want, hooks)
+
+	_, err = Parse([]byte("{"))
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	failing := errors.New("boom")
+	target := Target{
+		RunSQL: func(_ context.Context, script string) (string, error) {
+			if script == "fail" {
+				return "ERROR: boom", failing
+			}
+			return "CHECKPOINT", nil
+		},
+	}
+
+	t.Run("abort stops at the failed hook", func(t *testing.T) {
+		entries := []string{}
+		err := Run(context.Background(), Hooks{
+			{Stage: StagePre, Type: TypeSQL, Script: "fail", OnFailure: OnFailureAbort},
+			{Stage: StagePre, Type: TypeSQL, Script: "CHECKPOINT", OnFailure: OnFailureAbort},
+		}, target, func(entry string) { entries = append(entries, entry) })
+
+		assert.ErrorIs(t, err, failing)
+		require.Len(t, entries, 1)
+		assert.Contains(t, entries[0], "[pre-sql hook] failed")
+		assert.Contains(t, entries[0], "ERROR: boom")
+	})
+
+	t.Run("continue runs the rest", func(t *testing.T) {
+		entries := []string{}
+		err := Run(context.Background(), Hooks{
+			{Stage: StagePost, Type: TypeSQL, Script: "fail", OnFailure: OnFailureContinue},
+			{Stage: StagePost, Type: TypeSQL, Script: "CHECKPOINT", OnFailure: OnFailureAbort},
+		}, target, func(entry string) { entries = append(entries, entry) })
+
+		assert.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Contains(t, entries[0], "failed, continuing")
+		assert.Contains(t, entries[1], "[post-sql hook] ok")
+	})
+
+	t.Run("commands disabled", func(t *testing.T) {
+		err := Run(context.Background(), Hooks{
+			{Stage: StagePre, Type: TypeCommand, Script: "true", OnFailure: OnFailureAbort},
+		}, target, func(string) {})
+
+		assert.ErrorIs(t, err, ErrCommandsDisabled)
+	})
+
+	t.Run("sql not supported", func(t *testing.T) {
+		err := Run(context.Background(), Hooks{
+			{Stage: StagePre, Type: TypeSQL, Script: "SELECT 1", OnFailure: OnFailureAbort},
+		}, Target{}, func(string) {})
+
+		assert.Error(t, err)
+	})
+}
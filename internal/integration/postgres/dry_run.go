@@ -0,0 +1,291 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+)
+
+// maxListedNames is the max number of names listed in the errors of a dry
+// run, the rest are only counted.
+const maxListedNames = 5
+
+// RestoreCheck is the outcome of a dry run of a restore.
+type RestoreCheck struct {
+	// Format is the format of the dump, one of the DumpFormat* constants
+	Format  string
+	Schemas int
+	Tables  int
+	// ServerVersion is the major version of the target server
+	ServerVersion int
+}
+
+// Describe returns a human readable summary of the check.
+func (c RestoreCheck) Describe() string {
+	return fmt.Sprintf(
+		"the %s dump has %d schemas and %d tables and the target server runs PostgreSQL %d",
+		DumpFormats[c.Format], c.Schemas, c.Tables, c.ServerVersion,
+	)
+}
+
+// CheckRestore runs a dry run of a restore: the dump is fetched and listed
+// the same way it would be restored, and the target is checked without
+// writing anything to it.
+//
+// The target server can't be older than the dump. A new database must not
+// exist yet and its template and roles must exist. Otherwise the tables of
+// the restore must not exist in the target database unless the dump drops
+// them first, or they must all exist when only the data is restored.
+func (c Client) CheckRestore(
+	ctx context.Context, version PGVersion, connString string,
+	file *database.BackupFile, fileExtension string, params RestoreParams,
+	newDatabase NewDatabase,
+) (RestoreCheck, error) {
+	workDir, err := os.MkdirTemp("", "pbw-check-*")
+	if err != nil {
+		return RestoreCheck{}, fmt.Errorf("error creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	format, dumpPath, err := fetchDump(ctx, file, fileExtension, workDir)
+	if err != nil {
+		return RestoreCheck{}, err
+	}
+
+	entries, err := dumpEntries(ctx, version, format, dumpPath)
+	if err != nil {
+		return RestoreCheck{}, err
+	}
+	objects := archiveObjects(entries)
+
+	check := RestoreCheck{Format: format}
+	for _, object := range objects {
+		if object.Type == ArchiveObjectSchema {
+			check.Schemas++
+		} else {
+			check.Tables++
+		}
+	}
+
+	if !params.Selection.IsEmpty() {
+		if err := params.Selection.Check(objects); err != nil {
+			return check, err
+		}
+	}
+
+	check.ServerVersion, err = c.serverVersion(ctx, version, connString)
+	if err != nil {
+		return check, err
+	}
+	dumpVersion, _ := strconv.Atoi(version.Value.Version)
+	if check.ServerVersion < dumpVersion {
+		return check, fmt.Errorf(
+			"the target server runs PostgreSQL %d, older than the PostgreSQL %d of the backup",
+			check.ServerVersion, dumpVersion,
+		)
+	}
+
+	if !newDatabase.IsEmpty() {
+		return check, c.checkNewDatabase(ctx, version, connString, newDatabase)
+	}
+
+	// The dump creates its own database, the target only gives the server
+	if params.Create {
+		return check, nil
+	}
+
+	return check, c.checkRestoreTables(
+		ctx, version, connString, restoredTables(objects, params.Selection),
+		params,
+	)
+}
+
+// serverVersion returns the major version of the server of the connection
+// string.
+func (c Client) serverVersion(
+	ctx context.Context, version PGVersion, connString string,
+) (int, error) {
+	output, err := c.QueryValue(ctx, version, connString, "SHOW server_version_num")
+	if err != nil {
+		return 0, err
+	}
+	number, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected server version %q", output)
+	}
+	return number / 10000, nil
+}
+
+// checkNewDatabase checks that the new database doesn't exist yet and that
+// its template, owner and mapped roles exist.
+func (c Client) checkNewDatabase(
+	ctx context.Context, version PGVersion, connString string, newDatabase NewDatabase,
+) error {
+	problems := []string{
+		fmt.Sprintf("the database %q already exists", newDatabase.Name),
+	}
+	conditions := []string{
+		"NOT " + databaseExistsCondition(newDatabase.Name),
+	}
+
+	if newDatabase.Template != "" {
+		problems = append(problems, fmt.Sprintf(
+			"the template %q doesn't exist", newDatabase.Template,
+		))
+		conditions = append(conditions, databaseExistsCondition(newDatabase.Template))
+	}
+
+	roles := []string{}
+	if newDatabase.Owner != "" {
+		roles = append(roles, newDatabase.Owner)
+	}
+	for _, role := range newDatabase.RoleMap {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		problems = append(problems, fmt.Sprintf("the role %q doesn't exist", role))
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = %s)",
+			quoteLiteral(role),
+		))
+	}
+
+	failed, err := c.failedConditions(ctx, version, connString, conditions)
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		messages := make([]string, len(failed))
+		for i, index := range failed {
+			messages[i] = problems[index]
+		}
+		return fmt.Errorf("%s", strings.Join(messages, ", "))
+	}
+
+	return nil
+}
+
+// databaseExistsCondition returns the SQL condition that is true when the
+// database exists in the server.
+func databaseExistsCondition(name string) string {
+	return fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM pg_catalog.pg_database WHERE datname = %s)",
+		quoteLiteral(name),
+	)
+}
+
+// tableExistsCondition returns the SQL condition that is true when the table
+// exists in the database.
+func tableExistsCondition(table TableName) string {
+	return fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM pg_catalog.pg_class c "+
+			"JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace "+
+			"WHERE n.nspname = %s AND c.relname = %s)",
+		quoteLiteral(table.Schema), quoteLiteral(table.Name),
+	)
+}
+
+// restoredTables returns the tables of the dump written by a restore of the
+// selection, in the target schema of the selection if it has one.
+func restoredTables(
+	objects []ArchiveObject, selection RestoreSelection,
+) []TableName {
+	schemas := map[string]bool{}
+	for _, schema := range selection.Schemas {
+		schemas[schema] = true
+	}
+	tables := map[TableName]bool{}
+	for _, table := range selection.Tables {
+		tables[table] = true
+	}
+
+	restored := []TableName{}
+	for _, object := range objects {
+		if object.Type != ArchiveObjectTable {
+			continue
+		}
+		table := TableName{Schema: object.Schema, Name: object.Name}
+		if !selection.IsEmpty() && !schemas[table.Schema] && !tables[table] {
+			continue
+		}
+		if selection.TargetSchema != "" {
+			table.Schema = selection.TargetSchema
+		}
+		restored = append(restored, table)
+	}
+	return restored
+}
+
+// checkRestoreTables checks the restored tables against the target database.
+// A data only restore needs all of them, the other restores need none of
+// them unless the dump drops them first.
+func (c Client) checkRestoreTables(
+	ctx context.Context, version PGVersion, connString string, tables []TableName,
+	params RestoreParams,
+) error {
+	selection := params.Selection
+	dataOnly := selection.content() == RestoreContentData
+
+	if selection.TargetSchema != "" && !dataOnly {
+		failed, err := c.failedConditions(ctx, version, connString, []string{fmt.Sprintf(
+			"NOT EXISTS (SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = %s)",
+			quoteLiteral(selection.TargetSchema),
+		)})
+		if err != nil {
+			return err
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf(
+				"the target schema %q already exists in the target database",
+				selection.TargetSchema,
+			)
+		}
+		return nil
+	}
+
+	if params.Clean && !dataOnly {
+		return nil
+	}
+
+	conditions := make([]string, len(tables))
+	for i, table := range tables {
+		if dataOnly {
+			conditions[i] = tableExistsCondition(table)
+		} else {
+			conditions[i] = "NOT " + tableExistsCondition(table)
+		}
+	}
+
+	failed, err := c.failedConditions(ctx, version, connString, conditions)
+	if err != nil {
+		return err
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	names := []string{}
+	for _, index := range failed[:min(len(failed), maxListedNames)] {
+		names = append(names, tables[index].Schema+"."+tables[index].Name)
+	}
+	if len(failed) > maxListedNames {
+		names = append(names, fmt.Sprintf("and %d more", len(failed)-maxListedNames))
+	}
+
+	if dataOnly {
+		return fmt.Errorf(
+			"%d tables of the backup don't exist in the target database to restore their data into: %s",
+			len(failed), strings.Join(names, ", "),
+		)
+	}
+	return fmt.Errorf(
+		"%d tables of the backup already exist in the target database and the backup doesn't drop them (clean option): %s",
+		len(failed), strings.Join(names, ", "),
+	)
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoredTables(t *testing.T) {
+	objects := []ArchiveObject{
+		{Type: ArchiveObjectSchema, Name: "public"},
+		{Type: ArchiveObjectSchema, Name: "sales"},
+		{Type: ArchiveObjectTable, Schema: "public", Name: "users"},
+		{Type: ArchiveObjectTable, Schema: "public", Name: "posts"},
+		{Type: ArchiveObjectTable, Schema: "sales", Name: "orders"},
+	}
+
+	tests := []struct {
+		name      string
+		selection RestoreSelection
+		want      []TableName
+	}{
+		{
+			"everything",
+			RestoreSelection{},
+			[]TableName{
+				{Schema: "public", Name: "users"},
+				{Schema: "public", Name: "posts"},
+				{Schema: "sales", Name: "orders"},
+			},
+		},
+		{
+			"schema and table",
+			RestoreSelection{
+				Schemas: []string{"sales"},
+				Tables:  []TableName{{Schema: "public", Name: "posts"}},
+			},
+			[]TableName{
+				{Schema: "public", Name: "posts"},
+				{Schema: "sales", Name: "orders"},
+			},
+		},
+		{
+			"target schema",
+			RestoreSelection{
+				Tables:       []TableName{{Schema: "public", Name: "users"}},
+				TargetSchema: "restored",
+			},
+			[]TableName{{Schema: "restored", Name: "users"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, restoredTables(objects, tt.selection))
+		})
+	}
+}
+
+func TestRestoreCheckDescribe(t *testing.T) {
+	check := RestoreCheck{
+		Format: DumpFormatCustom, Schemas: 2, Tables: 14, ServerVersion: 16,
+	}
+	assert.Equal(
+		t,
+		"the Custom dump has 2 schemas and 14 tables and the target server runs PostgreSQL 16",
+		check.Describe(),
+	)
+}
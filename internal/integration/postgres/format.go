@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formats supported by pg_dump, see the --format option.
+const (
+	// DumpFormatPlain outputs a plain-text SQL script restored with psql.
+	DumpFormatPlain = "plain"
+	// DumpFormatCustom outputs a compressed archive suitable for pg_restore.
+	DumpFormatCustom = "custom"
+	// DumpFormatDirectory outputs a directory with one file per table, it is
+	// the only format that supports parallel dumps.
+	DumpFormatDirectory = "directory"
+	// DumpFormatTar outputs a tar archive suitable for pg_restore.
+	DumpFormatTar = "tar"
+)
+
+// DumpFormats maps every supported dump format to its human readable name.
+var DumpFormats = map[string]string{
+	DumpFormatPlain:     "Plain SQL",
+	DumpFormatCustom:    "Custom",
+	DumpFormatDirectory: "Directory",
+	DumpFormatTar:       "Tar",
+}
+
+// MaxJobs is the maximum number of parallel jobs allowed for pg_dump and
+// pg_restore.
+const MaxJobs = 64
+
+// Names used for the dump inside the ZIP file. Directory dumps are stored
+// as every file of the directory under the dumpDirName prefix.
+const (
+	dumpFileNamePlain  = "dump.sql"
+	dumpFileNameCustom = "dump.dump"
+	dumpFileNameTar    = "dump.tar"
+	dumpDirName        = "dump"
+)
+
+// ValidateDumpFormat checks that the format is supported and that the number
+// of parallel jobs can be used with it. Only the custom and directory formats
+// can be restored in parallel, and only the directory format can be dumped in
+// parallel.
+func ValidateDumpFormat(format string, jobs int) error {
+	if _, ok := DumpFormats[format]; !ok {
+		return fmt.Errorf("invalid dump format %q", format)
+	}
+
+	if jobs < 1 || jobs > MaxJobs {
+		return fmt.Errorf("jobs must be between 1 and %d", MaxJobs)
+	}
+
+	if jobs > 1 && format != DumpFormatCustom && format != DumpFormatDirectory {
+		return fmt.Errorf(
+			"parallel jobs are only supported by the custom and directory formats",
+		)
+	}
+
+	return nil
+}
+
+// Kinds of the uncompressed dump files used in the file extension of the
+// backups that are not ZIP files, see compression.Extension.
+const (
+	dumpKindPlain     = "sql"
+	dumpKindCustom    = "dump"
+	dumpKindTar       = "tar"
+	dumpKindDirectory = "dir.tar"
+)
+
+// dumpKind returns the kind of the dump file for the given format.
+func dumpKind(format string) string {
+	switch format {
+	case DumpFormatCustom:
+		return dumpKindCustom
+	case DumpFormatTar:
+		return dumpKindTar
+	case DumpFormatDirectory:
+		return dumpKindDirectory
+	default:
+		return dumpKindPlain
+	}
+}
+
+// formatFromKind returns the format of a dump file of the given kind.
+func formatFromKind(kind string) (string, error) {
+	switch kind {
+	case dumpKindPlain:
+		return DumpFormatPlain, nil
+	case dumpKindCustom:
+		return DumpFormatCustom, nil
+	case dumpKindTar:
+		return DumpFormatTar, nil
+	case dumpKindDirectory:
+		return DumpFormatDirectory, nil
+	default:
+		return "", fmt.Errorf("unknown dump file kind %q", kind)
+	}
+}
+
+// dumpFileName returns the name of the dump inside the ZIP file for the
+// formats that produce a single file.
+func dumpFileName(format string) string {
+	switch format {
+	case DumpFormatCustom:
+		return dumpFileNameCustom
+	case DumpFormatTar:
+		return dumpFileNameTar
+	default:
+		return dumpFileNamePlain
+	}
+}
+
+// detectArchiveFormat returns the format of the dump stored in a ZIP file
+// given the names of the files inside it. ZIP files created before formats
+// were supported only contain a dump.sql file, so they are detected as plain.
+func detectArchiveFormat(names []string) (string, error) {
+	for _, name := range names {
+		switch {
+		case name == dumpFileNamePlain:
+			return DumpFormatPlain, nil
+		case name == dumpFileNameCustom:
+			return DumpFormatCustom, nil
+		case name == dumpFileNameTar:
+			return DumpFormatTar, nil
+		case strings.HasPrefix(name, dumpDirName+"/"):
+			return DumpFormatDirectory, nil
+		}
+	}
+
+	return "", fmt.Errorf("no dump found in ZIP file")
+}
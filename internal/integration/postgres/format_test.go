@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDumpFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		jobs    int
+		wantErr bool
+	}{
+		{"plain", DumpFormatPlain, 1, false},
+		{"custom", DumpFormatCustom, 1, false},
+		{"directory", DumpFormatDirectory, 1, false},
+		{"tar", DumpFormatTar, 1, false},
+		{"custom parallel", DumpFormatCustom, 8, false},
+		{"directory parallel", DumpFormatDirectory, MaxJobs, false},
+		{"plain parallel", DumpFormatPlain, 2, true},
+		{"tar parallel", DumpFormatTar, 2, true},
+		{"zero jobs", DumpFormatCustom, 0, true},
+		{"too many jobs", DumpFormatDirectory, MaxJobs + 1, true},
+		{"unknown format", "zip", 1, true},
+		{"empty format", "", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDumpFormat(tt.format, tt.jobs)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDetectArchiveFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		want    string
+		wantErr bool
+	}{
+		{"legacy plain", []string{"dump.sql"}, DumpFormatPlain, false},
+		{"custom", []string{"dump.dump"}, DumpFormatCustom, false},
+		{"tar", []string{"dump.tar"}, DumpFormatTar, false},
+		{
+			"directory",
+			[]string{"dump/toc.dat", "dump/3456.dat.gz"},
+			DumpFormatDirectory,
+			false,
+		},
+		{"empty", []string{}, "", true},
+		{"unknown files", []string{"backup.bak", "dumps/toc.dat"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := detectArchiveFormat(tt.names)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDumpKind(t *testing.T) {
+	for format := range DumpFormats {
+		got, err := formatFromKind(dumpKind(format))
+		assert.NoError(t, err)
+		assert.Equal(t, format, got)
+	}
+
+	_, err := formatFromKind("zip")
+	assert.Error(t, err)
+}
@@ -0,0 +1,234 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxIdentifierLength is the max length in bytes of a PostgreSQL identifier,
+// longer names are truncated by the server.
+const maxIdentifierLength = 63
+
+// NewDatabase are the settings of a restore into a database created for it
+// in the server of the connection string. The database is dropped again if
+// the restore fails.
+type NewDatabase struct {
+	Name string `json:"name"`
+	// Template is the database copied, empty uses the server default
+	Template string `json:"template,omitempty"`
+	// Owner is the role that owns the database, empty is the connected role
+	Owner string `json:"owner,omitempty"`
+	// NoOwner leaves the restored objects owned by the connected role
+	NoOwner bool `json:"no_owner,omitempty"`
+	// RoleMap renames the roles of the owners and grants of the dump, from
+	// the role of the dump to the role of the new database
+	RoleMap map[string]string `json:"role_map,omitempty"`
+}
+
+// ParseNewDatabase decodes the settings stored in the database, no content
+// means no new database.
+func ParseNewDatabase(data []byte) (NewDatabase, error) {
+	newDatabase := NewDatabase{}
+	if len(data) == 0 {
+		return newDatabase, nil
+	}
+	if err := json.Unmarshal(data, &newDatabase); err != nil {
+		return newDatabase, fmt.Errorf("error parsing new database: %w", err)
+	}
+	return newDatabase, nil
+}
+
+// Marshal encodes the settings to be stored in the database.
+func (d NewDatabase) Marshal() []byte {
+	data, _ := json.Marshal(d)
+	return data
+}
+
+// IsEmpty reports whether the restore goes into an existing database.
+func (d NewDatabase) IsEmpty() bool {
+	return d.Name == ""
+}
+
+// Validate checks the settings before the database is created.
+func (d NewDatabase) Validate() error {
+	if err := validateIdentifier(d.Name); err != nil {
+		return fmt.Errorf("invalid database name %q: %w", d.Name, err)
+	}
+	if d.Template != "" {
+		if err := validateIdentifier(d.Template); err != nil {
+			return fmt.Errorf("invalid template %q: %w", d.Template, err)
+		}
+		if d.Template == d.Name {
+			return fmt.Errorf("the database can't be its own template")
+		}
+	}
+	if d.Owner != "" {
+		if err := validateIdentifier(d.Owner); err != nil {
+			return fmt.Errorf("invalid owner %q: %w", d.Owner, err)
+		}
+	}
+
+	if len(d.RoleMap) > MaxPatterns {
+		return fmt.Errorf("a maximum of %d role mappings is allowed", MaxPatterns)
+	}
+	for from, to := range d.RoleMap {
+		if err := validateIdentifier(from); err != nil {
+			return fmt.Errorf("invalid role %q: %w", from, err)
+		}
+		if err := validateIdentifier(to); err != nil {
+			return fmt.Errorf("invalid role %q: %w", to, err)
+		}
+	}
+
+	return nil
+}
+
+// validateIdentifier checks the characters and length of a database object
+// name.
+func validateIdentifier(name string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if len(name) > maxIdentifierLength {
+		return fmt.Errorf("it can't be longer than %d bytes", maxIdentifierLength)
+	}
+	return nil
+}
+
+// Describe returns a human readable summary of the settings.
+func (d NewDatabase) Describe() string {
+	description := d.Name
+	details := []string{}
+	if d.Template != "" {
+		details = append(details, "template "+d.Template)
+	}
+	if d.Owner != "" {
+		details = append(details, "owner "+d.Owner)
+	}
+	if d.NoOwner {
+		details = append(details, "no object owners")
+	}
+	if len(d.RoleMap) > 0 {
+		details = append(details, "roles "+FormatRoleMap(d.RoleMap, ", "))
+	}
+	if len(details) > 0 {
+		description += " (" + strings.Join(details, "; ") + ")"
+	}
+	return description
+}
+
+// ParseRoleMap parses the role mappings written one per line as
+// old_role=new_role, the surrounding spaces and the empty lines are ignored.
+func ParseRoleMap(text string) (map[string]string, error) {
+	roleMap := map[string]string{}
+	for _, line := range ParsePatternList(text) {
+		from, to, ok := strings.Cut(line, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf(
+				"invalid role mapping %q, use the old_role=new_role format", line,
+			)
+		}
+		if _, ok := roleMap[from]; ok {
+			return nil, fmt.Errorf("the role %q is mapped more than once", from)
+		}
+		roleMap[from] = to
+	}
+	return roleMap, nil
+}
+
+// FormatRoleMap returns the role mappings sorted by the old role, in the
+// format of ParseRoleMap joined with the separator.
+func FormatRoleMap(roleMap map[string]string, separator string) string {
+	lines := make([]string, 0, len(roleMap))
+	for from, to := range roleMap {
+		lines = append(lines, from+"="+to)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, separator)
+}
+
+// roleClauseRegex matches the roles written by pg_dump in the ownership and
+// privilege statements.
+var roleClauseRegex = regexp.MustCompile(
+	`\b(OWNER TO|TO|FROM|FOR ROLE|GRANTED BY)(\s+)("(?:[^"]|"")*"|[a-z_][a-z0-9_$]*)`,
+)
+
+// remapRoles renames the roles of a statement found in the role map.
+func remapRoles(line string, roleMap map[string]string) string {
+	return roleClauseRegex.ReplaceAllStringFunc(line, func(match string) string {
+		parts := roleClauseRegex.FindStringSubmatch(match)
+		role := parts[3]
+		if strings.HasPrefix(role, `"`) {
+			role = strings.ReplaceAll(role[1:len(role)-1], `""`, `"`)
+		}
+
+		to, ok := roleMap[role]
+		if !ok {
+			return match
+		}
+		return parts[1] + parts[2] + QuoteIdentifier(to)
+	})
+}
+
+// isOwnerStatement reports whether a line of a script sets the owner of an
+// object.
+func isOwnerStatement(line string) bool {
+	return strings.HasPrefix(line, "ALTER ") && strings.Contains(line, " OWNER TO ")
+}
+
+// isPrivilegeStatement reports whether a line of a script grants or revokes
+// privileges.
+func isPrivilegeStatement(line string) bool {
+	return strings.HasPrefix(line, "GRANT ") ||
+		strings.HasPrefix(line, "REVOKE ") ||
+		strings.HasPrefix(line, "ALTER DEFAULT PRIVILEGES ")
+}
+
+// lineRewriter rewrites a statement line of a script, it returns false to
+// leave the line out.
+type lineRewriter func(line string) (string, bool)
+
+// scriptRewriter returns the rewriter of the statements of a script restored
+// with the parameters. The ownership and privileges are left out following
+// NoOwner and NoPrivileges, as pg_restore does for the archive formats, the
+// roles are renamed following RoleMap, and the schemas are created only if
+// they don't exist yet, like the public schema of a new database.
+func scriptRewriter(params RestoreParams) lineRewriter {
+	return func(line string) (string, bool) {
+		if params.NoOwner && isOwnerStatement(line) {
+			return "", false
+		}
+		if params.NoPrivileges && isPrivilegeStatement(line) {
+			return "", false
+		}
+
+		if len(params.RoleMap) > 0 &&
+			(isOwnerStatement(line) || isPrivilegeStatement(line)) {
+			return remapRoles(line, params.RoleMap), true
+		}
+
+		if rest, ok := strings.CutPrefix(line, "CREATE SCHEMA "); ok &&
+			!strings.HasPrefix(rest, "IF NOT EXISTS ") {
+			return "CREATE SCHEMA IF NOT EXISTS " + rest, true
+		}
+
+		return line, true
+	}
+}
+
+// isDatabaseEntry reports whether an entry of a script belongs to the
+// database of the dump instead of its objects, like the CREATE DATABASE and
+// \connect commands written by --create.
+func isDatabaseEntry(entry tocEntry) bool {
+	switch entry.desc {
+	case "DATABASE", "DATABASE PROPERTIES":
+		return true
+	case "COMMENT", "ACL", "SECURITY LABEL":
+		return strings.HasPrefix(entry.tag, "DATABASE ")
+	}
+	return false
+}
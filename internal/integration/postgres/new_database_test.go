@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoleMap(t *testing.T) {
+	roleMap, err := ParseRoleMap("  app = app_staging\n\nreporting=Analyst \n")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app": "app_staging", "reporting": "Analyst",
+	}, roleMap)
+	assert.Equal(t, "app=app_staging, reporting=Analyst", FormatRoleMap(roleMap, ", "))
+
+	_, err = ParseRoleMap("app")
+	assert.Error(t, err)
+	_, err = ParseRoleMap("=app")
+	assert.Error(t, err)
+	_, err = ParseRoleMap("app=a\napp=b")
+	assert.Error(t, err)
+}
+
+func TestNewDatabaseValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		newDatabase NewDatabase
+		wantErr     bool
+	}{
+		{"name only", NewDatabase{Name: "app_copy"}, false},
+		{
+			"all settings",
+			NewDatabase{
+				Name: "app_copy", Template: "template0", Owner: "app",
+				NoOwner: true, RoleMap: map[string]string{"old": "new"},
+			},
+			false,
+		},
+		{"empty name", NewDatabase{}, true},
+		{"long name", NewDatabase{Name: strings.Repeat("a", 64)}, true},
+		{"own template", NewDatabase{Name: "a", Template: "a"}, true},
+		{"invalid owner", NewDatabase{Name: "a", Owner: "a\nb"}, true},
+		{
+			"invalid role map",
+			NewDatabase{Name: "a", RoleMap: map[string]string{"old": ""}},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.newDatabase.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewDatabaseMarshal(t *testing.T) {
+	newDatabase := NewDatabase{
+		Name: "app_copy", Owner: "app",
+		RoleMap: map[string]string{"old": "new"},
+	}
+	parsed, err := ParseNewDatabase(newDatabase.Marshal())
+	assert.NoError(t, err)
+	assert.Equal(t, newDatabase, parsed)
+	assert.Equal(t, "app_copy (owner app; roles old=new)", parsed.Describe())
+
+	parsed, err = ParseNewDatabase([]byte("{}"))
+	assert.NoError(t, err)
+	assert.True(t, parsed.IsEmpty())
+}
+
+func TestRemapRoles(t *testing.T) {
+	roleMap := map[string]string{"app": "app_staging", "Old Role": "new"}
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{
+			"ALTER TABLE public.users OWNER TO app;",
+			`ALTER TABLE public.users OWNER TO "app_staging";`,
+		},
+		{
+			`ALTER SCHEMA audit OWNER TO "Old Role";`,
+			`ALTER SCHEMA audit OWNER TO "new";`,
+		},
+		{
+			"GRANT SELECT ON TABLE public.users TO app;",
+			`GRANT SELECT ON TABLE public.users TO "app_staging";`,
+		},
+		{
+			"REVOKE ALL ON TABLE public.users FROM app;",
+			`REVOKE ALL ON TABLE public.users FROM "app_staging";`,
+		},
+		{
+			"ALTER DEFAULT PRIVILEGES FOR ROLE app IN SCHEMA public GRANT SELECT ON TABLES TO reporting;",
+			`ALTER DEFAULT PRIVILEGES FOR ROLE "app_staging" IN SCHEMA public GRANT SELECT ON TABLES TO reporting;`,
+		},
+		{
+			"GRANT USAGE ON SCHEMA public TO PUBLIC;",
+			"GRANT USAGE ON SCHEMA public TO PUBLIC;",
+		},
+		{
+			"ALTER TABLE app.users OWNER TO postgres;",
+			"ALTER TABLE app.users OWNER TO postgres;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			assert.Equal(t, tt.want, remapRoles(tt.line, roleMap))
+		})
+	}
+}
+
+func TestScriptRewriter(t *testing.T) {
+	rewrite := scriptRewriter(RestoreParams{
+		NoPrivileges: true,
+		RoleMap:      map[string]string{"app": "staging"},
+	})
+
+	line, ok := rewrite("ALTER TABLE public.users OWNER TO app;\n")
+	assert.True(t, ok)
+	assert.Equal(t, "ALTER TABLE public.users OWNER TO \"staging\";\n", line)
+
+	_, ok = rewrite("GRANT ALL ON TABLE public.users TO app;\n")
+	assert.False(t, ok)
+
+	line, ok = rewrite("CREATE SCHEMA public;\n")
+	assert.True(t, ok)
+	assert.Equal(t, "CREATE SCHEMA IF NOT EXISTS public;\n", line)
+
+	rewrite = scriptRewriter(RestoreParams{NoOwner: true})
+	_, ok = rewrite("ALTER TABLE public.users OWNER TO app;\n")
+	assert.False(t, ok)
+}
+
+const testCreateScript = `SET statement_timeout = 0;
+DROP DATABASE app;
+
+--
+-- Name: app; Type: DATABASE; Schema: -; Owner: app
+--
+
+CREATE DATABASE app WITH TEMPLATE = template0;
+
+
+ALTER DATABASE app OWNER TO app;
+
+\connect app
+
+SET statement_timeout = 0;
+
+--
+-- Name: DATABASE app; Type: COMMENT; Schema: -; Owner: app
+--
+
+COMMENT ON DATABASE app IS 'The app';
+
+--
+-- Name: users; Type: TABLE; Schema: public; Owner: app
+--
+
+CREATE TABLE public.users (id integer);
+
+ALTER TABLE public.users OWNER TO app;
+
+--
+-- Data for Name: users; Type: TABLE DATA; Schema: public; Owner: app
+--
+
+COPY public.users (id) FROM stdin;
+ALTER TABLE public.users OWNER TO app;
+\.
+`
+
+func TestWriteSectionsSkipDatabase(t *testing.T) {
+	sections, err := scanScript(strings.NewReader(testCreateScript))
+	require.NoError(t, err)
+
+	keep := make([]bool, len(sections))
+	for i, section := range sections {
+		keep[i] = !isDatabaseEntry(section.entry)
+	}
+
+	out := &bytes.Buffer{}
+	rewrite := scriptRewriter(RestoreParams{RoleMap: map[string]string{"app": "staging"}})
+	require.NoError(t, writeSections(
+		strings.NewReader(testCreateScript), out, keep, rewrite,
+	))
+
+	assert.NotContains(t, out.String(), "DROP DATABASE")
+	assert.NotContains(t, out.String(), "CREATE DATABASE")
+	assert.NotContains(t, out.String(), `\connect`)
+	assert.NotContains(t, out.String(), "COMMENT ON DATABASE")
+	assert.Contains(t, out.String(), "CREATE TABLE public.users")
+	assert.Contains(t, out.String(), "ALTER TABLE public.users OWNER TO \"staging\";")
+	// The rows of the COPY blocks are never rewritten
+	assert.Contains(t, out.String(), "ALTER TABLE public.users OWNER TO app;\n\\.")
+}
@@ -0,0 +1,396 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+)
+
+// Backup modes of the PostgreSQL backups.
+const (
+	// BackupModeLogical dumps the database with pg_dump.
+	BackupModeLogical = "logical"
+	// BackupModePhysical takes base backups of the whole cluster with
+	// pg_basebackup and archives the WAL continuously with pg_receivewal, so
+	// the cluster can be recovered to any point in time.
+	BackupModePhysical = "physical"
+)
+
+// BackupModes maps every backup mode to its human readable name.
+var BackupModes = map[string]string{
+	BackupModeLogical:  "Logical (pg_dump)",
+	BackupModePhysical: "Physical (pg_basebackup + WAL archiving)",
+}
+
+// BaseBackupKind is the kind of the base backup files used in their file
+// extension, see compression.Extension.
+const BaseBackupKind = "base.tar"
+
+// WALSegmentSize is the size of the WAL segments. Only clusters initialized
+// with the default segment size are supported.
+const WALSegmentSize = 16 * 1024 * 1024
+
+// ValidateBackupMode checks that the backup mode is supported by the
+// database type and the compression codec. Base backups are tar streams, so
+// they can't be stored inside a ZIP file.
+func ValidateBackupMode(mode string, databaseType string, codec string) error {
+	if _, ok := BackupModes[mode]; !ok {
+		return fmt.Errorf("invalid backup mode %q", mode)
+	}
+
+	if mode != BackupModePhysical {
+		return nil
+	}
+
+	if databaseType != database.DatabaseTypePostgreSQL {
+		return fmt.Errorf("physical backups are only supported by PostgreSQL")
+	}
+
+	if codec == compression.CodecZip || codec == "" {
+		return fmt.Errorf(
+			"physical backups need the zstd, gzip or none compression",
+		)
+	}
+
+	return nil
+}
+
+// BaseBackupInfo contains the WAL positions of a base backup, they are only
+// known once the base backup reader reaches EOF.
+type BaseBackupInfo struct {
+	// StartLSN is the position the recovery has to start replaying from.
+	StartLSN string
+	// EndLSN is the first position the cluster is consistent at.
+	EndLSN string
+}
+
+var (
+	baseBackupStartRegexp = regexp.MustCompile(
+		`write-ahead log start point: ([0-9A-Fa-f]+/[0-9A-Fa-f]+)`,
+	)
+	baseBackupEndRegexp = regexp.MustCompile(
+		`write-ahead log end point: ([0-9A-Fa-f]+/[0-9A-Fa-f]+)`,
+	)
+)
+
+// parseBaseBackupOutput returns the WAL positions printed by pg_basebackup
+// in verbose mode.
+func parseBaseBackupOutput(output string) BaseBackupInfo {
+	info := BaseBackupInfo{}
+	if match := baseBackupStartRegexp.FindStringSubmatch(output); match != nil {
+		info.StartLSN = match[1]
+	}
+	if match := baseBackupEndRegexp.FindStringSubmatch(output); match != nil {
+		info.EndLSN = match[1]
+	}
+	return info
+}
+
+// BaseBackup runs pg_basebackup and returns the base backup of the cluster as
+// a tar archive compressed with a stream codec, together with the extension
+// of the resulting file. The WAL needed to make the base backup consistent is
+// included in the archive.
+//
+// The returned info is filled once the reader reaches EOF. Clusters with
+// additional tablespaces are not supported. pg_basebackup is killed when ctx
+// is done.
+func (Client) BaseBackup(
+	ctx context.Context, version PGVersion, connString string,
+	comp compression.Params,
+) (io.Reader, string, *BaseBackupInfo) {
+	info := &BaseBackupInfo{}
+	errorBuffer := &bytes.Buffer{}
+	reader, writer := io.Pipe()
+
+	cmd := exec.CommandContext(
+		ctx, version.Value.PGBaseBackup,
+		"--dbname="+connString,
+		"--pgdata=-",
+		"--format=tar",
+		"--wal-method=fetch",
+		"--checkpoint=fast",
+		"--no-manifest",
+		"--no-password",
+		"--verbose",
+	)
+	cmd.Stdout = writer
+	cmd.Stderr = errorBuffer
+
+	go func() {
+		defer writer.Close()
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				writer.CloseWithError(context.Cause(ctx))
+				return
+			}
+			writer.CloseWithError(fmt.Errorf(
+				"error running pg_basebackup v%s: %s",
+				version.Value.Version, strings.TrimSpace(errorBuffer.String()),
+			))
+			return
+		}
+		*info = parseBaseBackupOutput(errorBuffer.String())
+	}()
+
+	ext := compression.Extension(BaseBackupKind, comp.Codec)
+	return compression.Compress(reader, comp.Codec, comp.Level), ext, info
+}
+
+// ParseLSN parses a WAL position written as two hexadecimal numbers
+// separated by a slash, for example 16/B374D848.
+func ParseLSN(lsn string) (uint64, error) {
+	high, low, ok := strings.Cut(strings.TrimSpace(lsn), "/")
+	if !ok || high == "" || low == "" {
+		return 0, fmt.Errorf("invalid LSN %q", lsn)
+	}
+
+	h, err := strconv.ParseUint(high, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q", lsn)
+	}
+	l, err := strconv.ParseUint(low, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q", lsn)
+	}
+
+	return h<<32 | l, nil
+}
+
+// FormatLSN formats a WAL position the way PostgreSQL prints it.
+func FormatLSN(lsn uint64) string {
+	return fmt.Sprintf("%X/%X", lsn>>32, lsn&0xFFFFFFFF)
+}
+
+var (
+	walSegmentRegexp = regexp.MustCompile(`^[0-9A-F]{24}$`)
+	walHistoryRegexp = regexp.MustCompile(`^[0-9A-F]{8}\.history$`)
+)
+
+// IsWALFileName reports whether the name is the name of a complete WAL
+// segment or a timeline history file. Partial segments are not included.
+func IsWALFileName(name string) bool {
+	return walSegmentRegexp.MatchString(name) || walHistoryRegexp.MatchString(name)
+}
+
+// walSegmentStart returns the first position of the WAL segment, false is
+// returned for history files and invalid names.
+func walSegmentStart(name string) (uint64, bool) {
+	if !walSegmentRegexp.MatchString(name) {
+		return 0, false
+	}
+
+	logID, err := strconv.ParseUint(name[8:16], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	segID, err := strconv.ParseUint(name[16:24], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return logID<<32 + segID*WALSegmentSize, true
+}
+
+// WALFilesFrom returns, sorted, the WAL files needed to replay the WAL from
+// the given position: the segments that end after it and every timeline
+// history file.
+func WALFilesFrom(names []string, lsn uint64) []string {
+	files := []string{}
+	for _, name := range names {
+		if walHistoryRegexp.MatchString(name) {
+			files = append(files, name)
+			continue
+		}
+		start, ok := walSegmentStart(name)
+		if ok && start+WALSegmentSize > lsn {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// WALFilesBefore returns, sorted, the WAL segments that end before the given
+// position, which are not needed to replay the WAL from it. History files
+// are never included.
+func WALFilesBefore(names []string, lsn uint64) []string {
+	files := []string{}
+	for _, name := range names {
+		start, ok := walSegmentStart(name)
+		if ok && start+WALSegmentSize <= lsn {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// CreateReplicationSlot creates the physical replication slot used by
+// ReceiveWAL, it doesn't fail if the slot already exists.
+func (Client) CreateReplicationSlot(
+	version PGVersion, connString string, slot string,
+) error {
+	cmd := exec.Command(
+		version.Value.PGReceiveWAL,
+		"--dbname="+connString,
+		"--slot="+slot,
+		"--create-slot",
+		"--if-not-exists",
+		"--no-password",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"error creating replication slot with pg_receivewal v%s: %s",
+			version.Value.Version, strings.TrimSpace(string(output)),
+		)
+	}
+	return nil
+}
+
+// DropReplicationSlot drops the physical replication slot created by
+// CreateReplicationSlot.
+func (Client) DropReplicationSlot(
+	version PGVersion, connString string, slot string,
+) error {
+	cmd := exec.Command(
+		version.Value.PGReceiveWAL,
+		"--dbname="+connString,
+		"--slot="+slot,
+		"--drop-slot",
+		"--no-password",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"error dropping replication slot with pg_receivewal v%s: %s",
+			version.Value.Version, strings.TrimSpace(string(output)),
+		)
+	}
+	return nil
+}
+
+// ReceiveWAL runs pg_receivewal streaming the WAL of the cluster into dir
+// using the replication slot, until the context is cancelled or the
+// connection is lost. Completed segments are left in dir with their final
+// name and the segment being written ends with .partial.
+func (Client) ReceiveWAL(
+	ctx context.Context, version PGVersion, connString string, slot string,
+	dir string,
+) error {
+	errorBuffer := &bytes.Buffer{}
+	cmd := exec.CommandContext(
+		ctx,
+		version.Value.PGReceiveWAL,
+		"--dbname="+connString,
+		"--directory="+dir,
+		"--slot="+slot,
+		"--no-loop",
+		"--no-password",
+	)
+	cmd.Stderr = errorBuffer
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf(
+			"error running pg_receivewal v%s: %s",
+			version.Value.Version, strings.TrimSpace(errorBuffer.String()),
+		)
+	}
+	return nil
+}
+
+// RecoveryTarget is the point where the recovery of a physical backup stops.
+// When both fields are empty the whole archived WAL is replayed.
+type RecoveryTarget struct {
+	Time time.Time
+	LSN  string
+}
+
+// Validate checks that only one target is set and that the LSN is valid.
+func (t RecoveryTarget) Validate() error {
+	if !t.Time.IsZero() && t.LSN != "" {
+		return fmt.Errorf("the recovery target can be a time or an LSN, not both")
+	}
+	if t.LSN != "" {
+		if _, err := ParseLSN(t.LSN); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quoteConfigValue quotes a value of postgresql.conf.
+func quoteConfigValue(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// recoveryConfig returns the settings appended to postgresql.auto.conf to
+// recover the cluster using the WAL files stored in walDir.
+func recoveryConfig(walDir string, target RecoveryTarget) string {
+	lines := []string{
+		"# Added by PG Back Web to recover the cluster to a point in time",
+		"restore_command = " + quoteConfigValue(
+			fmt.Sprintf(`cp "%s/%%f" "%%p"`, walDir),
+		),
+		"recovery_target_timeline = 'latest'",
+	}
+
+	if !target.Time.IsZero() {
+		lines = append(lines, "recovery_target_time = "+quoteConfigValue(
+			target.Time.UTC().Format("2006-01-02 15:04:05.999999-07:00"),
+		))
+	}
+	if target.LSN != "" {
+		lines = append(lines, "recovery_target_lsn = "+quoteConfigValue(target.LSN))
+	}
+	if !target.Time.IsZero() || target.LSN != "" {
+		lines = append(lines, "recovery_target_action = 'promote'")
+	}
+
+	return "\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// WriteRecoveryConfig prepares the data directory of a restored base backup
+// so PostgreSQL recovers it up to the target when it is started, reading the
+// WAL files from walDir.
+func WriteRecoveryConfig(
+	dataDir string, walDir string, target RecoveryTarget,
+) error {
+	if strings.ContainsAny(walDir, `"'`) {
+		return fmt.Errorf("the WAL directory can't contain quotes")
+	}
+
+	signalPath := filepath.Join(dataDir, "recovery.signal")
+	if err := os.WriteFile(signalPath, nil, 0o600); err != nil {
+		return fmt.Errorf("error creating recovery.signal: %w", err)
+	}
+
+	confPath := filepath.Join(dataDir, "postgresql.auto.conf")
+	file, err := os.OpenFile(confPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening postgresql.auto.conf: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(recoveryConfig(walDir, target)); err != nil {
+		return fmt.Errorf("error writing postgresql.auto.conf: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBackupMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		dbType  string
+		codec   string
+		wantErr bool
+	}{
+		{"logical", BackupModeLogical, database.DatabaseTypePostgreSQL, compression.CodecZip, false},
+		{"logical clickhouse", BackupModeLogical, database.DatabaseTypeClickHouse, compression.CodecZip, false},
+		{"physical zstd", BackupModePhysical, database.DatabaseTypePostgreSQL, compression.CodecZstd, false},
+		{"physical none", BackupModePhysical, database.DatabaseTypePostgreSQL, compression.CodecNone, false},
+		{"physical zip", BackupModePhysical, database.DatabaseTypePostgreSQL, compression.CodecZip, true},
+		{"physical clickhouse", BackupModePhysical, database.DatabaseTypeClickHouse, compression.CodecZstd, true},
+		{"unknown mode", "incremental", database.DatabaseTypePostgreSQL, compression.CodecZstd, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBackupMode(tt.mode, tt.dbType, tt.codec)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseBaseBackupOutput(t *testing.T) {
+	output := `pg_basebackup: initiating base backup, waiting for checkpoint to complete
+pg_basebackup: checkpoint completed
+pg_basebackup: write-ahead log start point: 0/2000028 on timeline 1
+pg_basebackup: write-ahead log end point: 0/2000138
+pg_basebackup: base backup completed`
+
+	assert.Equal(t, BaseBackupInfo{
+		StartLSN: "0/2000028",
+		EndLSN:   "0/2000138",
+	}, parseBaseBackupOutput(output))
+	assert.Equal(t, BaseBackupInfo{}, parseBaseBackupOutput("error"))
+}
+
+func TestParseLSN(t *testing.T) {
+	tests := []struct {
+		lsn     string
+		want    uint64
+		wantErr bool
+	}{
+		{"0/0", 0, false},
+		{"0/2000028", 0x2000028, false},
+		{"16/B374D848", 0x16B374D848, false},
+		{"16/b374d848", 0x16B374D848, false},
+		{"FFFFFFFF/FFFFFFFF", 0xFFFFFFFFFFFFFFFF, false},
+		{"", 0, true},
+		{"16B374D848", 0, true},
+		{"/1", 0, true},
+		{"1/", 0, true},
+		{"G/1", 0, true},
+		{"100000000/0", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lsn, func(t *testing.T) {
+			got, err := ParseLSN(tt.lsn)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want, must(ParseLSN(FormatLSN(got))))
+		})
+	}
+}
+
+func must(v uint64, err error) uint64 {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestIsWALFileName(t *testing.T) {
+	assert.True(t, IsWALFileName("000000010000000000000002"))
+	assert.True(t, IsWALFileName("00000002.history"))
+	assert.False(t, IsWALFileName("000000010000000000000002.partial"))
+	assert.False(t, IsWALFileName("00000001000000000000000g"))
+	assert.False(t, IsWALFileName("backup_label"))
+}
+
+func TestWALFiles(t *testing.T) {
+	names := []string{
+		"000000010000000000000003",
+		"000000010000000000000001",
+		"00000002.history",
+		"000000020000000100000000",
+		"000000010000000000000002",
+		"000000010000000000000004.partial",
+	}
+
+	// 0/2000028 is inside the segment 000000010000000000000002
+	lsn := must(ParseLSN("0/2000028"))
+
+	assert.Equal(t, []string{
+		"000000010000000000000002",
+		"000000010000000000000003",
+		"00000002.history",
+		"000000020000000100000000",
+	}, WALFilesFrom(names, lsn))
+
+	assert.Equal(t, []string{
+		"000000010000000000000001",
+	}, WALFilesBefore(names, lsn))
+
+	assert.Empty(t, WALFilesBefore(names, 0))
+}
+
+func TestRecoveryTargetValidate(t *testing.T) {
+	assert.NoError(t, RecoveryTarget{}.Validate())
+	assert.NoError(t, RecoveryTarget{Time: time.Now()}.Validate())
+	assert.NoError(t, RecoveryTarget{LSN: "0/2000028"}.Validate())
+	assert.Error(t, RecoveryTarget{LSN: "2000028"}.Validate())
+	assert.Error(t, RecoveryTarget{Time: time.Now(), LSN: "0/2000028"}.Validate())
+}
+
+func TestRecoveryConfig(t *testing.T) {
+	target := time.Date(2026, 10, 18, 10, 30, 0, 0, time.FixedZone("", 2*3600))
+
+	assert.Equal(t, `
+# Added by PG Back Web to recover the cluster to a point in time
+restore_command = 'cp "/data/pbw_wal/%f" "%p"'
+recovery_target_timeline = 'latest'
+recovery_target_time = '2026-10-18 08:30:00+00:00'
+recovery_target_action = 'promote'
+`, recoveryConfig("/data/pbw_wal", RecoveryTarget{Time: target}))
+
+	assert.Equal(t, `
+# Added by PG Back Web to recover the cluster to a point in time
+restore_command = 'cp "/data/pbw_wal/%f" "%p"'
+recovery_target_timeline = 'latest'
+recovery_target_lsn = '0/2000028'
+recovery_target_action = 'promote'
+`, recoveryConfig("/data/pbw_wal", RecoveryTarget{LSN: "0/2000028"}))
+
+	assert.Equal(t, `
+# Added by PG Back Web to recover the cluster to a point in time
+restore_command = 'cp "/data/pbw_wal/%f" "%p"'
+recovery_target_timeline = 'latest'
+`, recoveryConfig("/data/pbw_wal", RecoveryTarget{}))
+}
@@ -3,13 +3,15 @@ package postgres
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
 	"github.com/eduardolat/pgbackweb/internal/integration/database"
-	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 	"github.com/orsinium-labs/enum"
 )
 
@@ -22,43 +24,64 @@ import (
 */
 
 type version struct {
-	Version string
-	PGDump  string
-	PSQL    string
+	Version      string
+	PGDump       string
+	PGRestore    string
+	PSQL         string
+	PGBaseBackup string
+	PGReceiveWAL string
 }
 
 type PGVersion enum.Member[version]
 
 var (
 	PG13 = PGVersion{version{
-		Version: "13",
-		PGDump:  "/usr/lib/postgresql/13/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/13/bin/psql",
+		Version:      "13",
+		PGDump:       "/usr/lib/postgresql/13/bin/pg_dump",
+		PGRestore:    "/usr/lib/postgresql/13/bin/pg_restore",
+		PSQL:         "/usr/lib/postgresql/13/bin/psql",
+		PGBaseBackup: "/usr/lib/postgresql/13/bin/pg_basebackup",
+		PGReceiveWAL: "/usr/lib/postgresql/13/bin/pg_receivewal",
 	}}
 	PG14 = PGVersion{version{
-		Version: "14",
-		PGDump:  "/usr/lib/postgresql/14/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/14/bin/psql",
+		Version:      "14",
+		PGDump:       "/usr/lib/postgresql/14/bin/pg_dump",
+		PGRestore:    "/usr/lib/postgresql/14/bin/pg_restore",
+		PSQL:         "/usr/lib/postgresql/14/bin/psql",
+		PGBaseBackup: "/usr/lib/postgresql/14/bin/pg_basebackup",
+		PGReceiveWAL: "/usr/lib/postgresql/14/bin/pg_receivewal",
 	}}
 	PG15 = PGVersion{version{
-		Version: "15",
-		PGDump:  "/usr/lib/postgresql/15/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/15/bin/psql",
+		Version:      "15",
+		PGDump:       "/usr/lib/postgresql/15/bin/pg_dump",
+		PGRestore:    "/usr/lib/postgresql/15/bin/pg_restore",
+		PSQL:         "/usr/lib/postgresql/15/bin/psql",
+		PGBaseBackup: "/usr/lib/postgresql/15/bin/pg_basebackup",
+		PGReceiveWAL: "/usr/lib/postgresql/15/bin/pg_receivewal",
 	}}
 	PG16 = PGVersion{version{
-		Version: "16",
-		PGDump:  "/usr/lib/postgresql/16/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/16/bin/psql",
+		Version:      "16",
+		PGDump:       "/usr/lib/postgresql/16/bin/pg_dump",
+		PGRestore:    "/usr/lib/postgresql/16/bin/pg_restore",
+		PSQL:         "/usr/lib/postgresql/16/bin/psql",
+		PGBaseBackup: "/usr/lib/postgresql/16/bin/pg_basebackup",
+		PGReceiveWAL: "/usr/lib/postgresql/16/bin/pg_receivewal",
 	}}
 	PG17 = PGVersion{version{
-		Version: "17",
-		PGDump:  "/usr/lib/postgresql/17/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/17/bin/psql",
+		Version:      "17",
+		PGDump:       "/usr/lib/postgresql/17/bin/pg_dump",
+		PGRestore:    "/usr/lib/postgresql/17/bin/pg_restore",
+		PSQL:         "/usr/lib/postgresql/17/bin/psql",
+		PGBaseBackup: "/usr/lib/postgresql/17/bin/pg_basebackup",
+		PGReceiveWAL: "/usr/lib/postgresql/17/bin/pg_receivewal",
 	}}
 	PG18 = PGVersion{version{
-		Version: "18",
-		PGDump:  "/usr/lib/postgresql/18/bin/pg_dump",
-		PSQL:    "/usr/lib/postgresql/18/bin/psql",
+		Version:      "18",
+		PGDump:       "/usr/lib/postgresql/18/bin/pg_dump",
+		PGRestore:    "/usr/lib/postgresql/18/bin/pg_restore",
+		PSQL:         "/usr/lib/postgresql/18/bin/psql",
+		PGBaseBackup: "/usr/lib/postgresql/18/bin/pg_basebackup",
+		PGReceiveWAL: "/usr/lib/postgresql/18/bin/pg_receivewal",
 	}}
 
 	PGVersions     = []PGVersion{PG13, PG14, PG15, PG16, PG17, PG18}
@@ -165,47 +188,133 @@ type DumpParams struct {
 
 	// NoComments (--no-comments): Do not dump comments.
 	NoComments bool
-}
 
-// Dump runs the pg_dump command with the given parameters. It returns the SQL
-// dump as an io.Reader.
-func (Client) Dump(
-	version PGVersion, connString string, params ...DumpParams,
-) io.Reader {
-	pickedParams := DumpParams{}
-	if len(params) > 0 {
-		pickedParams = params[0]
-	}
+	// Format (--format): Selects the format of the output, one of the
+	// DumpFormat* constants. Empty means plain. For the archive formats the
+	// Clean, IfExists and Create options are applied by pg_restore instead.
+	Format string
+
+	// Jobs (--jobs): Run the dump in parallel by dumping this many tables
+	// simultaneously. Only used by the directory format.
+	Jobs int
+
+	// Schemas (--schema): Dump only the schemas matching these patterns, and
+	// the objects inside them.
+	Schemas []string
+
+	// ExcludeSchemas (--exclude-schema): Do not dump the schemas matching these
+	// patterns.
+	ExcludeSchemas []string
+
+	// Tables (--table): Dump only the tables, views, sequences and foreign
+	// tables matching these patterns.
+	Tables []string
 
+	// ExcludeTables (--exclude-table): Do not dump the tables matching these
+	// patterns.
+	ExcludeTables []string
+
+	// ExcludeTableData (--exclude-table-data): Do not dump the data of the
+	// tables matching these patterns, their definitions are still dumped.
+	ExcludeTableData []string
+
+	// NoOwner (--no-owner): Do not output commands to set the ownership of the
+	// objects. For the archive formats it is applied by pg_restore instead.
+	NoOwner bool
+
+	// NoPrivileges (--no-privileges): Do not dump the access privileges (grant
+	// and revoke commands). For the archive formats it is applied by
+	// pg_restore instead.
+	NoPrivileges bool
+
+	// Role (--role): Role name used to create the dump, pg_dump issues a SET
+	// ROLE after connecting.
+	Role string
+}
+
+// dumpArgs returns the pg_dump arguments for the given parameters, without
+// the output file.
+func dumpArgs(connString string, params DumpParams) []string {
 	args := []string{connString}
-	if pickedParams.DataOnly {
+	if params.DataOnly {
 		args = append(args, "--data-only")
 	}
-	if pickedParams.SchemaOnly {
+	if params.SchemaOnly {
 		args = append(args, "--schema-only")
 	}
-	if pickedParams.Clean {
+	if params.Clean {
 		args = append(args, "--clean")
 	}
-	if pickedParams.IfExists {
+	if params.IfExists {
 		args = append(args, "--if-exists")
 	}
-	if pickedParams.Create {
+	if params.Create {
 		args = append(args, "--create")
 	}
-	if pickedParams.NoComments {
+	if params.NoComments {
 		args = append(args, "--no-comments")
 	}
+	if params.Format != "" && params.Format != DumpFormatPlain {
+		args = append(args, "--format="+params.Format)
+	}
+	if params.Format == DumpFormatDirectory && params.Jobs > 1 {
+		args = append(args, fmt.Sprintf("--jobs=%d", params.Jobs))
+	}
+	for _, pattern := range params.Schemas {
+		args = append(args, "--schema="+pattern)
+	}
+	for _, pattern := range params.ExcludeSchemas {
+		args = append(args, "--exclude-schema="+pattern)
+	}
+	for _, pattern := range params.Tables {
+		args = append(args, "--table="+pattern)
+	}
+	for _, pattern := range params.ExcludeTables {
+		args = append(args, "--exclude-table="+pattern)
+	}
+	for _, pattern := range params.ExcludeTableData {
+		args = append(args, "--exclude-table-data="+pattern)
+	}
+	if params.NoOwner {
+		args = append(args, "--no-owner")
+	}
+	if params.NoPrivileges {
+		args = append(args, "--no-privileges")
+	}
+	if params.Role != "" {
+		args = append(args, "--role="+params.Role)
+	}
+	return args
+}
+
+// Dump runs the pg_dump command with the given parameters. It returns the
+// dump as an io.Reader. pg_dump is killed when ctx is done.
+//
+// The directory format can't be written to stdout, use DumpZipPG for it.
+func (Client) Dump(
+	ctx context.Context, version PGVersion, connString string,
+	params ...DumpParams,
+) io.Reader {
+	pickedParams := DumpParams{}
+	if len(params) > 0 {
+		pickedParams = params[0]
+	}
 
 	errorBuffer := &bytes.Buffer{}
 	reader, writer := io.Pipe()
-	cmd := exec.Command(version.Value.PGDump, args...)
+	cmd := exec.CommandContext(
+		ctx, version.Value.PGDump, dumpArgs(connString, pickedParams)...,
+	)
 	cmd.Stdout = writer
 	cmd.Stderr = errorBuffer
 
 	go func() {
 		defer writer.Close()
 		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				writer.CloseWithError(context.Cause(ctx))
+				return
+			}
 			writer.CloseWithError(fmt.Errorf(
 				"error running pg_dump v%s: %s",
 				version.Value.Version, errorBuffer.String(),
@@ -217,12 +326,30 @@ func (Client) Dump(
 }
 
 // DumpZipPG runs the pg_dump command with the given parameters and returns the
-// ZIP-compressed SQL dump as an io.Reader using PGVersion
-// This is kept for backward compatibility with existing code
+// ZIP-compressed dump as an io.Reader using PGVersion
+//
+// Plain, custom and tar dumps are stored as a single dump.sql, dump.dump or
+// dump.tar file. Directory dumps are stored with all their files under the
+// dump/ folder.
 func (c *Client) DumpZipPG(
-	version PGVersion, connString string, params ...DumpParams,
+	ctx context.Context, version PGVersion, connString string,
+	params ...DumpParams,
 ) io.Reader {
-	dumpReader := c.Dump(version, connString, params...)
+	pickedParams := DumpParams{}
+	if len(params) > 0 {
+		pickedParams = params[0]
+	}
+
+	if pickedParams.Format == DumpFormatDirectory {
+		return c.dumpDirectory(
+			ctx, version, connString, pickedParams,
+			func(w io.Writer, dumpPath string) error {
+				return writeZipDir(w, dumpPath, dumpDirName)
+			},
+		)
+	}
+
+	dumpReader := c.Dump(ctx, version, connString, pickedParams)
 	reader, writer := io.Pipe()
 
 	go func() {
@@ -231,7 +358,7 @@ func (c *Client) DumpZipPG(
 		zipWriter := zip.NewWriter(writer)
 		defer zipWriter.Close()
 
-		fileWriter, err := zipWriter.Create("dump.sql")
+		fileWriter, err := zipWriter.Create(dumpFileName(pickedParams.Format))
 		if err != nil {
 			writer.CloseWithError(fmt.Errorf("error creating zip file: %w", err))
 			return
@@ -246,8 +373,130 @@ func (c *Client) DumpZipPG(
 	return reader
 }
 
+// DumpCompressedPG runs the pg_dump command with the given parameters and
+// returns the dump compressed with a stream codec (zstd, gzip or none) as an
+// io.Reader, together with the extension of the resulting file.
+//
+// Plain, custom and tar dumps are compressed as they are. Directory dumps are
+// stored in a tar archive before compressing them.
+func (c *Client) DumpCompressedPG(
+	ctx context.Context, version PGVersion, connString string,
+	comp compression.Params, params ...DumpParams,
+) (io.Reader, string) {
+	pickedParams := DumpParams{}
+	if len(params) > 0 {
+		pickedParams = params[0]
+	}
+	ext := compression.Extension(dumpKind(pickedParams.Format), comp.Codec)
+
+	if pickedParams.Format == DumpFormatDirectory {
+		return c.dumpDirectory(
+			ctx, version, connString, pickedParams,
+			func(w io.Writer, dumpPath string) error {
+				compressor, err := compression.NewWriter(w, comp.Codec, comp.Level)
+				if err != nil {
+					return err
+				}
+				if err := compression.WriteTarDir(compressor, dumpPath, dumpDirName); err != nil {
+					_ = compressor.Close()
+					return err
+				}
+				return compressor.Close()
+			},
+		), ext
+	}
+
+	dumpReader := c.Dump(ctx, version, connString, pickedParams)
+	return compression.Compress(dumpReader, comp.Codec, comp.Level), ext
+}
+
+// dumpDirectory runs pg_dump with the directory format into a temp dir and
+// returns the output of writeArchive, which packs the generated directory.
+func (Client) dumpDirectory(
+	ctx context.Context, version PGVersion, connString string,
+	params DumpParams, writeArchive func(w io.Writer, dumpPath string) error,
+) io.Reader {
+	reader, writer := io.Pipe()
+
+	go func() {
+		defer writer.Close()
+
+		workDir, err := os.MkdirTemp("", "pbw-dump-*")
+		if err != nil {
+			writer.CloseWithError(fmt.Errorf("error creating temp dir: %w", err))
+			return
+		}
+		defer os.RemoveAll(workDir)
+		dumpPath := filepath.Join(workDir, dumpDirName)
+
+		args := append(dumpArgs(connString, params), "--file="+dumpPath)
+		cmd := exec.CommandContext(ctx, version.Value.PGDump, args...)
+		output, err := cmd.CombinedOutput()
+		if err != nil && ctx.Err() != nil {
+			writer.CloseWithError(context.Cause(ctx))
+			return
+		}
+		if err != nil {
+			writer.CloseWithError(fmt.Errorf(
+				"error running pg_dump v%s: %s",
+				version.Value.Version, output,
+			))
+			return
+		}
+
+		if err := writeArchive(writer, dumpPath); err != nil {
+			writer.CloseWithError(fmt.Errorf("error archiving dump: %w", err))
+			return
+		}
+	}()
+
+	return reader
+}
+
+// writeZipDir writes all the files inside dir to w as a ZIP archive, every
+// file is stored under prefix.
+func writeZipDir(w io.Writer, dir string, prefix string) error {
+	zipWriter := zip.NewWriter(w)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		fileWriter, err := zipWriter.Create(filepath.ToSlash(filepath.Join(prefix, relPath)))
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(fileWriter, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return zipWriter.Close()
+}
+
 // DumpZip implements DatabaseClient interface
-func (c *Client) DumpZip(version string, connString string, params database.DumpParams) io.Reader {
+func (c *Client) DumpZip(
+	ctx context.Context, version string, connString string,
+	params database.DumpParams, comp compression.Params,
+) (io.Reader, string) {
 	pgVersion, err := c.ParseVersionPG(version)
 	if err != nil {
 		// Return a reader that will error on read
@@ -255,7 +504,7 @@ func (c *Client) DumpZip(version string, connString string, params database.Dump
 		go func() {
 			writer.CloseWithError(fmt.Errorf("error parsing PostgreSQL version: %w", err))
 		}()
-		return reader
+		return reader, ""
 	}
 
 	var dumpParams DumpParams
@@ -263,66 +512,112 @@ func (c *Client) DumpZip(version string, connString string, params database.Dump
 		dumpParams = pgParams
 	}
 
-	return c.DumpZipPG(pgVersion, connString, dumpParams)
+	if comp.Codec == "" || comp.Codec == compression.CodecZip {
+		return c.DumpZipPG(ctx, pgVersion, connString, dumpParams), compression.Extension("", compression.CodecZip)
+	}
+
+	return c.DumpCompressedPG(ctx, pgVersion, connString, comp, dumpParams)
 }
 
-// RestoreZipPG downloads or copies the ZIP from the given url or path, unzips it,
-// and runs the psql command to restore the database using PGVersion
-//
-// The ZIP file must contain a dump.sql file with the SQL dump to restore.
-//
-//   - version: PostgreSQL version to use for the restore
-//   - connString: connection string to the database
-//   - isLocal: whether the ZIP file is local or a URL
-//   - zipURLOrPath: URL or path to the ZIP file
-//
-// This is kept for backward compatibility with existing code
-func (Client) RestoreZipPG(
-	version PGVersion, connString string, isLocal bool, zipURLOrPath string,
-) error {
-	workDir, err := os.MkdirTemp("", "pbw-restore-*")
-	if err != nil {
-		return fmt.Errorf("error creating temp dir: %w", err)
+// RestoreParams contains the parameters for the pg_restore command. They are
+// not used when restoring plain SQL dumps because those options are already
+// part of the SQL script.
+type RestoreParams struct {
+	// Jobs (--jobs): Run the most time-consuming steps of the restore
+	// concurrently using this many jobs. Only used for the custom and directory
+	// formats.
+	Jobs int
+
+	// Clean (--clean): Drop database objects before recreating them.
+	Clean bool
+
+	// IfExists (--if-exists): Use DROP ... IF EXISTS commands to drop objects
+	// in --clean mode.
+	IfExists bool
+
+	// Create (--create): Create the database before restoring into it.
+	Create bool
+
+	// NoOwner (--no-owner): Do not set the ownership of the restored objects.
+	NoOwner bool
+
+	// NoPrivileges (--no-privileges): Do not restore the access privileges.
+	NoPrivileges bool
+
+	// Selection restores only some schemas and tables of the dump, an empty
+	// selection restores everything. See RestoreSelection.
+	Selection RestoreSelection
+	// RoleMap renames the roles of the owners and grants of the dump, from the
+	// role of the dump to the role of the target database. The dump is
+	// restored as a SQL script when it is used.
+	RoleMap map[string]string
+
+	// SkipDatabase leaves out the commands of the dump that drop, create or
+	// connect to its own database, so a plain SQL dump taken with --create is
+	// restored into the database of the connection string. Archives only
+	// create it with Create.
+	SkipDatabase bool
+}
+
+// restoreArgs returns the pg_restore arguments for the given parameters and
+// archive format, without the archive path.
+func restoreArgs(connString, format string, params RestoreParams) []string {
+	args := []string{"--dbname=" + connString}
+	if params.Clean {
+		args = append(args, "--clean")
+	}
+	if params.IfExists {
+		args = append(args, "--if-exists")
+	}
+	if params.Create {
+		args = append(args, "--create")
 	}
-	defer os.RemoveAll(workDir)
-	zipPath := strutil.CreatePath(true, workDir, "dump.zip")
-	dumpPath := strutil.CreatePath(true, workDir, "dump.sql")
+	if params.NoOwner {
+		args = append(args, "--no-owner")
+	}
+	if params.NoPrivileges {
+		args = append(args, "--no-privileges")
+	}
+	if params.Jobs > 1 && format != DumpFormatTar {
+		args = append(args, fmt.Sprintf("--jobs=%d", params.Jobs))
+	}
+	return args
+}
 
-	if isLocal {
-		cmd := exec.Command("cp", zipURLOrPath, zipPath)
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("error copying ZIP file to temp dir: %s", output)
-		}
+// restoreDump restores the already extracted dump found in dumpPath, plain
+// SQL dumps are restored with psql and the rest with pg_restore.
+func restoreDump(
+	ctx context.Context, version PGVersion, connString string, format string,
+	dumpPath string, params RestoreParams,
+) error {
+	if needsScript(format, params) {
+		return restoreScript(ctx, version, connString, format, dumpPath, params)
 	}
 
-	if !isLocal {
-		cmd := exec.Command("wget", "--no-verbose", "-O", zipPath, zipURLOrPath)
+	if format == DumpFormatPlain {
+		cmd := exec.CommandContext(ctx, version.Value.PSQL, connString, "-f", dumpPath)
 		output, err := cmd.CombinedOutput()
+		if err != nil && ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
 		if err != nil {
-			return fmt.Errorf("error downloading ZIP file: %s", output)
+			return fmt.Errorf(
+				"error running psql v%s command: %s",
+				version.Value.Version, output,
+			)
 		}
+		return nil
 	}
 
-	if _, err := os.Stat(zipPath); os.IsNotExist(err) {
-		return fmt.Errorf("zip file not found: %s", zipPath)
-	}
-
-	cmd := exec.Command("unzip", "-o", zipPath, "dump.sql", "-d", workDir)
+	args := append(restoreArgs(connString, format, params), dumpPath)
+	cmd := exec.CommandContext(ctx, version.Value.PGRestore, args...)
 	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("error unzipping ZIP file: %s", output)
-	}
-
-	if _, err := os.Stat(dumpPath); os.IsNotExist(err) {
-		return fmt.Errorf("dump.sql file not found in ZIP file: %s", zipPath)
+	if err != nil && ctx.Err() != nil {
+		return context.Cause(ctx)
 	}
-
-	cmd = exec.Command(version.Value.PSQL, connString, "-f", dumpPath)
-	output, err = cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf(
-			"error running psql v%s command: %s",
+			"error running pg_restore v%s command: %s",
 			version.Value.Version, output,
 		)
 	}
@@ -330,11 +625,72 @@ func (Client) RestoreZipPG(
 	return nil
 }
 
+// RestoreZipPG reads the ZIP backup file and restores the database using
+// PGVersion
+//
+// Plain SQL dumps (dump.sql) are restored with psql, custom, directory and tar
+// dumps are restored with pg_restore. The format is detected from the files
+// inside the ZIP so backups taken before formats were supported keep working.
+//
+//   - ctx: the restore is stopped when it's done
+//   - version: PostgreSQL version to use for the restore
+//   - connString: connection string to the database
+//   - file: the ZIP file, closed by the caller
+//   - params: optional pg_restore parameters
+func (Client) RestoreZipPG(
+	ctx context.Context, version PGVersion, connString string,
+	file *database.BackupFile, params ...RestoreParams,
+) error {
+	pickedParams := RestoreParams{}
+	if len(params) > 0 {
+		pickedParams = params[0]
+	}
+
+	return restoreBackup(
+		ctx, version, connString, file, "", pickedParams,
+	)
+}
+
+// RestoreCompressedPG reads the backup file created by DumpCompressedPG,
+// decompresses it and restores the database using PGVersion
+//
+// The codec and the dump format are taken from fileExtension, which must be
+// the extension returned by DumpCompressedPG.
+func (Client) RestoreCompressedPG(
+	ctx context.Context, version PGVersion, connString string,
+	file *database.BackupFile, fileExtension string, params ...RestoreParams,
+) error {
+	pickedParams := RestoreParams{}
+	if len(params) > 0 {
+		pickedParams = params[0]
+	}
+
+	return restoreBackup(
+		ctx, version, connString, file, fileExtension, pickedParams,
+	)
+}
+
 // RestoreZip implements DatabaseClient interface
-func (c Client) RestoreZip(version string, connString string, isLocal bool, zipURLOrPath string) error {
+func (c Client) RestoreZip(
+	ctx context.Context, version string, connString string,
+	file *database.BackupFile, fileExtension string, params database.RestoreParams,
+) error {
 	pgVersion, err := c.ParseVersionPG(version)
 	if err != nil {
 		return fmt.Errorf("error parsing PostgreSQL version: %w", err)
 	}
-	return c.RestoreZipPG(pgVersion, connString, isLocal, zipURLOrPath)
+
+	var restoreParams RestoreParams
+	if pgParams, ok := params.(RestoreParams); ok {
+		restoreParams = pgParams
+	}
+
+	codec, _ := compression.ParseExtension(fileExtension)
+	if fileExtension == "" || codec == compression.CodecZip {
+		return c.RestoreZipPG(ctx, pgVersion, connString, file, restoreParams)
+	}
+
+	return c.RestoreCompressedPG(
+		ctx, pgVersion, connString, file, fileExtension, restoreParams,
+	)
 }
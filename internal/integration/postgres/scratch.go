@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// WithDatabase returns the connection string pointing to another database of
+// the same server. Both the URI (postgresql://...) and the key/value
+// (host=... dbname=...) forms are supported.
+func WithDatabase(connString string, dbName string) (string, error) {
+	if strings.HasPrefix(connString, "postgres://") ||
+		strings.HasPrefix(connString, "postgresql://") {
+		u, err := url.Parse(connString)
+		if err != nil {
+			return "", fmt.Errorf("error parsing connection string: %w", err)
+		}
+		u.Path = "/" + dbName
+		u.RawPath = ""
+		return u.String(), nil
+	}
+
+	value := "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(dbName) + "'"
+	fields := splitKeyValues(connString)
+	replaced := false
+	for i, field := range fields {
+		if strings.HasPrefix(field, "dbname=") {
+			fields[i] = "dbname=" + value
+			replaced = true
+		}
+	}
+	if !replaced {
+		fields = append(fields, "dbname="+value)
+	}
+
+	return strings.Join(fields, " "), nil
+}
+
+// splitKeyValues splits a key/value connection string into its key=value
+// pairs, keeping the quoted values that contain spaces together.
+func splitKeyValues(connString string) []string {
+	fields := []string{}
+	current := strings.Builder{}
+	inQuotes := false
+	escaped := false
+
+	for _, r := range connString {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '\'':
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+
+	return fields
+}
+
+// QuoteIdentifier quotes a SQL identifier such as a database name.
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// psqlCommand runs psql with the given arguments and returns its unaligned
+// output without the trailing new line. Notices printed to stderr are not
+// part of the output.
+func psqlCommand(
+	ctx context.Context, version PGVersion, connString string, args ...string,
+) (string, error) {
+	args = append([]string{
+		connString, "--no-psqlrc", "--quiet", "--tuples-only", "--no-align",
+		"-v", "ON_ERROR_STOP=1",
+	}, args...)
+
+	errorBuffer := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, version.Value.PSQL, args...)
+	cmd.Stderr = errorBuffer
+	output, err := cmd.Output()
+	if err != nil && ctx.Err() != nil {
+		return "", context.Cause(ctx)
+	}
+	if err != nil {
+		return "", fmt.Errorf(
+			"error running psql v%s: %s",
+			version.Value.Version, strings.TrimSpace(errorBuffer.String()),
+		)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// CreateDatabaseParams are the optional settings of a new database.
+type CreateDatabaseParams struct {
+	// Template is the database copied, empty uses the server default
+	Template string
+	// Owner is the role that owns the database, empty is the connected role
+	Owner string
+}
+
+// createDatabaseQuery returns the CREATE DATABASE statement of the database.
+func createDatabaseQuery(dbName string, params CreateDatabaseParams) string {
+	query := "CREATE DATABASE " + QuoteIdentifier(dbName)
+	if params.Template != "" {
+		query += " TEMPLATE " + QuoteIdentifier(params.Template)
+	}
+	if params.Owner != "" {
+		query += " OWNER " + QuoteIdentifier(params.Owner)
+	}
+	return query
+}
+
+// CreateDatabase creates an empty database in the server of the connection
+// string.
+func (Client) CreateDatabase(
+	ctx context.Context, version PGVersion, connString string, dbName string,
+	params ...CreateDatabaseParams,
+) error {
+	pickedParams := CreateDatabaseParams{}
+	if len(params) > 0 {
+		pickedParams = params[0]
+	}
+
+	_, err := psqlCommand(
+		ctx, version, connString, "-c", createDatabaseQuery(dbName, pickedParams),
+	)
+	return err
+}
+
+// dropDatabaseTimeout bounds the drop of a database, which runs even when
+// the context of the operation that created it was cancelled.
+const dropDatabaseTimeout = time.Minute
+
+// DropDatabase drops the database from the server of the connection string,
+// closing the connections that are still open. It doesn't fail if the
+// database doesn't exist. It's a cleanup step, it runs when ctx is already
+// cancelled and is only bounded by dropDatabaseTimeout.
+func (Client) DropDatabase(
+	ctx context.Context, version PGVersion, connString string, dbName string,
+) error {
+	ctx, cancel := context.WithTimeout(
+		context.WithoutCancel(ctx), dropDatabaseTimeout,
+	)
+	defer cancel()
+
+	_, err := psqlCommand(
+		ctx, version, connString,
+		"-c", "DROP DATABASE IF EXISTS "+QuoteIdentifier(dbName)+" WITH (FORCE)",
+	)
+	return err
+}
+
+// QueryValue runs the query in a read only transaction and returns the first
+// column of its result as printed by psql, for example "t" for true.
+func (Client) QueryValue(
+	ctx context.Context, version PGVersion, connString string, query string,
+) (string, error) {
+	return psqlCommand(
+		ctx, version, connString,
+		"-c", "SET default_transaction_read_only = on",
+		"-c", query,
+	)
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RunScript runs a SQL script in the database of the connection string with
+// psql in a single session, stopping at the first error, and returns what
+// psql printed. The error has the last line of the output, where psql prints
+// the error that stopped the script.
+func (Client) RunScript(
+	ctx context.Context, version PGVersion, connString string, script string,
+) (string, error) {
+	cmd := exec.CommandContext(
+		ctx, version.Value.PSQL, connString, "--no-psqlrc",
+		"-v", "ON_ERROR_STOP=1", "-f", "-",
+	)
+	cmd.Stdin = strings.NewReader(script)
+	output, err := cmd.CombinedOutput()
+	if err != nil && ctx.Err() != nil {
+		return string(output), context.Cause(ctx)
+	}
+	if err != nil {
+		message := lastLine(output)
+		if message == "" {
+			message = err.Error()
+		}
+		return string(output), fmt.Errorf(
+			"error running psql v%s: %s", version.Value.Version, message,
+		)
+	}
+	return string(output), nil
+}
+
+// lastLine returns the last non-empty line of the output of a command.
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
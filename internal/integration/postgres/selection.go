@@ -0,0 +1,291 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Limits of the object selection of a dump.
+const (
+	// MaxPatterns is the max number of patterns of each selection option.
+	MaxPatterns = 100
+	// maxPatternLength is the max length of a pattern or a role name.
+	maxPatternLength = 255
+)
+
+// ParsePatternList parses a list of patterns written one per line, the
+// surrounding spaces and the empty lines are ignored.
+func ParsePatternList(text string) []string {
+	patterns := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns
+}
+
+// selectionOption is a pg_dump option that takes a list of patterns.
+type selectionOption struct {
+	flag     string
+	patterns []string
+	// qualified reports whether the patterns can be qualified with a schema
+	qualified bool
+}
+
+// selectionOptions returns the pattern options of the dump parameters.
+func selectionOptions(params DumpParams) []selectionOption {
+	return []selectionOption{
+		{"--schema", params.Schemas, false},
+		{"--exclude-schema", params.ExcludeSchemas, false},
+		{"--table", params.Tables, true},
+		{"--exclude-table", params.ExcludeTables, true},
+		{"--exclude-table-data", params.ExcludeTableData, true},
+	}
+}
+
+// ValidateDumpSelection checks the syntax of the object selection options of
+// the dump parameters and of its role. It doesn't check that the objects
+// exist, see UnmatchedPatterns.
+func ValidateDumpSelection(params DumpParams) error {
+	for _, option := range selectionOptions(params) {
+		if len(option.patterns) > MaxPatterns {
+			return fmt.Errorf(
+				"a maximum of %d %s patterns is allowed", MaxPatterns, option.flag,
+			)
+		}
+
+		for _, pattern := range option.patterns {
+			if err := validateName(pattern); err != nil {
+				return fmt.Errorf("invalid %s pattern %q: %w", option.flag, pattern, err)
+			}
+
+			schema, _, err := splitNamePattern(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid %s pattern %q: %w", option.flag, pattern, err)
+			}
+			if !option.qualified && schema != "" {
+				return fmt.Errorf(
+					"invalid %s pattern %q: schemas can't be qualified",
+					option.flag, pattern,
+				)
+			}
+		}
+	}
+
+	if params.Role != "" {
+		if err := validateName(params.Role); err != nil {
+			return fmt.Errorf("invalid role %q: %w", params.Role, err)
+		}
+	}
+
+	return nil
+}
+
+// validateName checks the characters and length of a pattern or role name.
+func validateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("it can't be empty")
+	}
+	if len(name) > maxPatternLength {
+		return fmt.Errorf("it can't be longer than %d characters", maxPatternLength)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return fmt.Errorf("it can't contain control characters")
+	}
+	return nil
+}
+
+// splitNamePattern splits a pattern in its schema and name parts on the
+// unquoted dot, the schema is empty for unqualified patterns.
+func splitNamePattern(pattern string) (string, string, error) {
+	inQuotes := false
+	dot := -1
+
+	for i, r := range pattern {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == '.' && !inQuotes:
+			if dot >= 0 {
+				return "", "", fmt.Errorf("too many dotted names")
+			}
+			dot = i
+		}
+	}
+	if inQuotes {
+		return "", "", fmt.Errorf("unterminated quoted identifier")
+	}
+
+	if dot < 0 {
+		return "", pattern, nil
+	}
+	if dot == 0 {
+		return "", "", fmt.Errorf("the schema is empty")
+	}
+	return pattern[:dot], pattern[dot+1:], nil
+}
+
+// namePatternRegex converts one part of a pattern to the anchored regular
+// expression used by pg_dump, following the psql rules: the unquoted letters
+// are folded to lower case, * matches any text, ? matches any character and
+// the quoted text is matched literally.
+func namePatternRegex(part string) string {
+	b := strings.Builder{}
+	b.WriteString("^(")
+
+	inQuotes := false
+	runes := []rune(part)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '"':
+			// A doubled quote inside quotes is a literal quote
+			if inQuotes && i+1 < len(runes) && runes[i+1] == '"' {
+				b.WriteRune('"')
+				i++
+				continue
+			}
+			inQuotes = !inQuotes
+		case inQuotes:
+			if strings.ContainsRune(`\^$.|?*+()[]{}`, r) {
+				b.WriteRune('\\')
+			}
+			b.WriteRune(r)
+		case r == '*':
+			b.WriteString(".*")
+		case r == '?':
+			b.WriteRune('.')
+		case r == '$':
+			b.WriteString(`\$`)
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	b.WriteString(")$")
+	return b.String()
+}
+
+// quoteLiteral quotes a SQL string literal.
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// patternCondition returns the SQL condition that is true when the pattern
+// of the option matches an object of the catalog.
+func patternCondition(option selectionOption, pattern string) string {
+	schema, name, _ := splitNamePattern(pattern)
+
+	if !option.qualified {
+		return fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM pg_catalog.pg_namespace n WHERE n.nspname ~ %s)",
+			quoteLiteral(namePatternRegex(name)),
+		)
+	}
+
+	// Like pg_dump, the unqualified patterns only match the visible tables
+	schemaCondition := "pg_catalog.pg_table_is_visible(c.oid)"
+	if schema != "" {
+		schemaCondition = "n.nspname ~ " + quoteLiteral(namePatternRegex(schema))
+	}
+	return fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM pg_catalog.pg_class c "+
+			"JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace "+
+			"WHERE c.relkind IN ('r', 'p', 'v', 'm', 'S', 'f') "+
+			"AND c.relname ~ %s AND %s)",
+		quoteLiteral(namePatternRegex(name)), schemaCondition,
+	)
+}
+
+// selectionQuery returns the query that lists the position of the checks
+// that don't match anything, one per line.
+func selectionQuery(conditions []string) string {
+	selects := make([]string, len(conditions))
+	for i, condition := range conditions {
+		selects[i] = fmt.Sprintf("SELECT %d WHERE NOT %s", i, condition)
+	}
+	return strings.Join(selects, " UNION ALL ")
+}
+
+// UnmatchedPatterns checks the object selection options of the dump
+// parameters against the catalog of the database and returns the ones that
+// don't match any object, formatted as pg_dump options (for example
+// --table=public.users). A role that doesn't exist is returned as --role.
+// The parameters must be valid, see ValidateDumpSelection.
+func (c Client) UnmatchedPatterns(
+	ctx context.Context, version PGVersion, connString string, params DumpParams,
+) ([]string, error) {
+	checks := []string{}
+	conditions := []string{}
+
+	for _, option := range selectionOptions(params) {
+		for _, pattern := range option.patterns {
+			checks = append(checks, option.flag+"="+pattern)
+			conditions = append(conditions, patternCondition(option, pattern))
+		}
+	}
+	if params.Role != "" {
+		checks = append(checks, "--role="+params.Role)
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = %s)",
+			quoteLiteral(params.Role),
+		))
+	}
+
+	unmatched := []string{}
+	if len(conditions) == 0 {
+		return unmatched, nil
+	}
+
+	failed, err := c.failedConditions(ctx, version, connString, conditions)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range failed {
+		unmatched = append(unmatched, checks[i])
+	}
+
+	return unmatched, nil
+}
+
+// maxConditionsPerQuery keeps the queries of failedConditions well below the
+// length limit of a command line argument.
+const maxConditionsPerQuery = 500
+
+// failedConditions checks the conditions against the catalog of the database
+// and returns the position of the ones that are false, in order.
+func (c Client) failedConditions(
+	ctx context.Context, version PGVersion, connString string,
+	conditions []string,
+) ([]int, error) {
+	failed := []int{}
+	for start := 0; start < len(conditions); start += maxConditionsPerQuery {
+		end := min(start+maxConditionsPerQuery, len(conditions))
+
+		output, err := c.QueryValue(
+			ctx, version, connString, selectionQuery(conditions[start:end]),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range strings.Split(output, "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			i, err := strconv.Atoi(line)
+			if err != nil || i < 0 || i >= end-start {
+				return nil, fmt.Errorf("unexpected output checking the catalog: %s", line)
+			}
+			failed = append(failed, start+i)
+		}
+	}
+
+	sort.Ints(failed)
+	return failed, nil
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePatternList(t *testing.T) {
+	assert.Equal(t, []string{}, ParsePatternList(""))
+	assert.Equal(t, []string{}, ParsePatternList(" \n\n"))
+	assert.Equal(
+		t, []string{"public", "audit.*", `"My Table"`},
+		ParsePatternList("public\r\n  audit.*\n\n\"My Table\" \n"),
+	)
+}
+
+func TestValidateDumpSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  DumpParams
+		wantErr bool
+	}{
+		{"empty", DumpParams{}, false},
+		{
+			"valid",
+			DumpParams{
+				Schemas:          []string{"public", "app_*"},
+				ExcludeTables:    []string{"public.logs_*", `"Mixed.Case"`},
+				ExcludeTableData: []string{"audit.*"},
+				Role:             "backup",
+			},
+			false,
+		},
+		{"empty pattern", DumpParams{Tables: []string{" "}}, true},
+		{"control character", DumpParams{Tables: []string{"a\tb"}}, true},
+		{"qualified schema", DumpParams{Schemas: []string{"db.public"}}, true},
+		{"too many dots", DumpParams{Tables: []string{"db.public.users"}}, true},
+		{"empty schema", DumpParams{Tables: []string{".users"}}, true},
+		{"unterminated quote", DumpParams{Tables: []string{`"users`}}, true},
+		{"invalid role", DumpParams{Role: "a\nb"}, true},
+		{
+			"too many patterns",
+			DumpParams{ExcludeSchemas: make([]string, MaxPatterns+1)},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDumpSelection(tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSplitNamePattern(t *testing.T) {
+	schema, name, err := splitNamePattern("public.users")
+	assert.NoError(t, err)
+	assert.Equal(t, "public", schema)
+	assert.Equal(t, "users", name)
+
+	schema, name, err = splitNamePattern(`"a.b"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "", schema)
+	assert.Equal(t, `"a.b"`, name)
+}
+
+func TestNamePatternRegex(t *testing.T) {
+	tests := []struct {
+		part string
+		want string
+	}{
+		{"users", "^(users)$"},
+		{"Users", "^(users)$"},
+		{"log_*", "^(log_.*)$"},
+		{"t?", "^(t.)$"},
+		{"cost$", `^(cost\$)$`},
+		{"[0-9]", "^([0-9])$"},
+		{`"My*Table"`, `^(My\*Table)$`},
+		{`"say ""hi"""`, `^(say "hi")$`},
+		{`"Big"_*`, "^(Big_.*)$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.part, func(t *testing.T) {
+			assert.Equal(t, tt.want, namePatternRegex(tt.part))
+		})
+	}
+}
+
+func TestDumpArgsSelection(t *testing.T) {
+	args := dumpArgs("postgresql://localhost/db", DumpParams{
+		Schemas:          []string{"public"},
+		ExcludeTables:    []string{"public.logs", "tmp_*"},
+		ExcludeTableData: []string{"audit.*"},
+		NoOwner:          true,
+		Role:             "backup",
+	})
+	assert.Equal(t, []string{
+		"postgresql://localhost/db",
+		"--schema=public",
+		"--exclude-table=public.logs",
+		"--exclude-table=tmp_*",
+		"--exclude-table-data=audit.*",
+		"--no-owner",
+		"--role=backup",
+	}, args)
+}
@@ -0,0 +1,982 @@
+package postgres
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+)
+
+// Contents restored from a selection of a dump.
+const (
+	// RestoreContentAll restores the definitions and the data.
+	RestoreContentAll = "all"
+	// RestoreContentSchema restores only the definitions.
+	RestoreContentSchema = "schema"
+	// RestoreContentData restores only the data, into tables that still exist
+	// in the target database.
+	RestoreContentData = "data"
+)
+
+// RestoreContents maps every restore content to its human readable name.
+var RestoreContents = map[string]string{
+	RestoreContentAll:    "Definitions and data",
+	RestoreContentSchema: "Definitions only",
+	RestoreContentData:   "Data only",
+}
+
+// Types of the objects listed from a dump.
+const (
+	ArchiveObjectSchema = "schema"
+	ArchiveObjectTable  = "table"
+)
+
+// ArchiveObject is a schema or a table found in a dump.
+type ArchiveObject struct {
+	Type   string `json:"type"`
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+}
+
+// TableName is the schema qualified name of a table.
+type TableName struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+}
+
+// String returns the quoted qualified name of the table, it can be parsed
+// back with ParseTableName.
+func (t TableName) String() string {
+	return QuoteIdentifier(t.Schema) + "." + QuoteIdentifier(t.Name)
+}
+
+// ParseTableName parses a schema qualified table name, the identifiers can
+// be quoted. Unquoted identifiers are kept as written.
+func ParseTableName(value string) (TableName, error) {
+	parts, err := splitQualifiedName(value)
+	if err != nil || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return TableName{}, fmt.Errorf("invalid table name %q", value)
+	}
+	return TableName{Schema: parts[0], Name: parts[1]}, nil
+}
+
+// splitQualifiedName splits a qualified name on the unquoted dots and
+// unquotes every part.
+func splitQualifiedName(value string) ([]string, error) {
+	parts := []string{}
+	current := strings.Builder{}
+	inQuotes := false
+
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '"' && inQuotes && i+1 < len(runes) && runes[i+1] == '"':
+			current.WriteRune('"')
+			i++
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == '.' && !inQuotes:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quoted identifier in %q", value)
+	}
+
+	return append(parts, current.String()), nil
+}
+
+// RestoreSelection picks the schemas and tables restored from a dump, an
+// empty selection restores everything.
+type RestoreSelection struct {
+	Schemas []string    `json:"schemas,omitempty"`
+	Tables  []TableName `json:"tables,omitempty"`
+	// Content is one of the RestoreContent* constants, empty means all
+	Content string `json:"content,omitempty"`
+	// TargetSchema renames the schema of the selection, empty keeps it
+	TargetSchema string `json:"target_schema,omitempty"`
+}
+
+// ParseRestoreSelection decodes a selection stored in the database, no
+// content means an empty selection.
+func ParseRestoreSelection(data []byte) (RestoreSelection, error) {
+	selection := RestoreSelection{}
+	if len(data) == 0 {
+		return selection, nil
+	}
+	if err := json.Unmarshal(data, &selection); err != nil {
+		return selection, fmt.Errorf("error parsing restore selection: %w", err)
+	}
+	return selection, nil
+}
+
+// Marshal encodes the selection to be stored in the database.
+func (s RestoreSelection) Marshal() []byte {
+	data, _ := json.Marshal(s)
+	return data
+}
+
+// IsEmpty reports whether the selection restores everything.
+func (s RestoreSelection) IsEmpty() bool {
+	return len(s.Schemas) == 0 && len(s.Tables) == 0
+}
+
+// content returns the content of the selection, all by default.
+func (s RestoreSelection) content() string {
+	if s.Content == "" {
+		return RestoreContentAll
+	}
+	return s.Content
+}
+
+// Validate checks the selection before it is restored. Only the objects of a
+// single schema can be restored into a target schema.
+func (s RestoreSelection) Validate() error {
+	if s.IsEmpty() {
+		if s.content() != RestoreContentAll || s.TargetSchema != "" {
+			return fmt.Errorf(
+				"pick the schemas or tables to restore to set the content or the target schema",
+			)
+		}
+		return nil
+	}
+
+	if _, ok := RestoreContents[s.content()]; !ok {
+		return fmt.Errorf("invalid restore content %q", s.Content)
+	}
+
+	for _, schema := range s.Schemas {
+		if err := validateName(schema); err != nil {
+			return fmt.Errorf("invalid schema %q: %w", schema, err)
+		}
+	}
+	for _, table := range s.Tables {
+		if err := validateName(table.Schema); err != nil {
+			return fmt.Errorf("invalid schema of table %s: %w", table, err)
+		}
+		if err := validateName(table.Name); err != nil {
+			return fmt.Errorf("invalid table %s: %w", table, err)
+		}
+	}
+
+	if s.TargetSchema != "" {
+		if err := validateName(s.TargetSchema); err != nil {
+			return fmt.Errorf("invalid target schema %q: %w", s.TargetSchema, err)
+		}
+		sources := s.sourceSchemas()
+		if len(sources) != 1 {
+			return fmt.Errorf(
+				"the selected objects must be in a single schema to restore them into a target schema",
+			)
+		}
+		if sources[0] == s.TargetSchema {
+			return fmt.Errorf("the target schema must be different from %q", sources[0])
+		}
+	}
+
+	return nil
+}
+
+// sourceSchemas returns the sorted schemas of the selected objects.
+func (s RestoreSelection) sourceSchemas() []string {
+	set := map[string]bool{}
+	for _, schema := range s.Schemas {
+		set[schema] = true
+	}
+	for _, table := range s.Tables {
+		set[table.Schema] = true
+	}
+
+	schemas := make([]string, 0, len(set))
+	for schema := range set {
+		schemas = append(schemas, schema)
+	}
+	sort.Strings(schemas)
+	return schemas
+}
+
+// Check returns an error if a selected schema or table is not one of the
+// objects of the dump.
+func (s RestoreSelection) Check(objects []ArchiveObject) error {
+	found := map[ArchiveObject]bool{}
+	for _, object := range objects {
+		found[object] = true
+	}
+
+	for _, schema := range s.Schemas {
+		if !found[ArchiveObject{Type: ArchiveObjectSchema, Name: schema}] {
+			return fmt.Errorf("schema %s not found in the backup", QuoteIdentifier(schema))
+		}
+	}
+	for _, table := range s.Tables {
+		object := ArchiveObject{
+			Type: ArchiveObjectTable, Schema: table.Schema, Name: table.Name,
+		}
+		if !found[object] {
+			return fmt.Errorf("table %s not found in the backup", table)
+		}
+	}
+
+	return nil
+}
+
+// Describe returns a human readable summary of the selection.
+func (s RestoreSelection) Describe() string {
+	if s.IsEmpty() {
+		return "Everything"
+	}
+
+	parts := []string{}
+	if len(s.Schemas) > 0 {
+		parts = append(parts, "schemas "+strings.Join(s.Schemas, ", "))
+	}
+	if len(s.Tables) > 0 {
+		tables := make([]string, len(s.Tables))
+		for i, table := range s.Tables {
+			tables[i] = table.Schema + "." + table.Name
+		}
+		parts = append(parts, "tables "+strings.Join(tables, ", "))
+	}
+	description := strings.Join(parts, "; ") + " (" +
+		strings.ToLower(RestoreContents[s.content()]) + ")"
+	if s.TargetSchema != "" {
+		description += " into schema " + s.TargetSchema
+	}
+	return description
+}
+
+// tocEntry is an entry of the table of contents of a dump, as printed by
+// pg_restore --list or in the header comments of a SQL script.
+type tocEntry struct {
+	// desc is the type of the entry, for example TABLE or TABLE DATA
+	desc string
+	// schema is the schema of the entry, "-" or empty when it has none
+	schema string
+	// tag is the name of the entry, some types prefix it with the table
+	tag string
+}
+
+// multiWordDescs are the entry types of more than one word, longest first.
+var multiWordDescs = []string{
+	"MATERIALIZED VIEW DATA", "SEQUENCE OWNED BY", "MATERIALIZED VIEW",
+	"FK CONSTRAINT", "FOREIGN TABLE", "INDEX ATTACH", "SEQUENCE SET",
+	"TABLE ATTACH", "TABLE DATA", "DEFAULT ACL", "LARGE OBJECT",
+}
+
+// parseListLine parses a line printed by pg_restore --list, like
+// "215; 1259 16386 TABLE public users postgres".
+func parseListLine(line string) (tocEntry, bool) {
+	if strings.HasPrefix(line, ";") {
+		return tocEntry{}, false
+	}
+	idx := strings.Index(line, "; ")
+	if idx < 0 {
+		return tocEntry{}, false
+	}
+
+	// The table OID and the object OID come before the type
+	fields := strings.Fields(line[idx+2:])
+	if len(fields) < 4 {
+		return tocEntry{}, false
+	}
+	fields = fields[2:]
+
+	desc := fields[0]
+	rest := strings.Join(fields, " ")
+	for _, multiWord := range multiWordDescs {
+		if strings.HasPrefix(rest, multiWord+" ") {
+			desc = multiWord
+			break
+		}
+	}
+	fields = fields[len(strings.Fields(desc)):]
+	if len(fields) < 2 {
+		return tocEntry{}, false
+	}
+
+	// The owner is the last field, unless the entry has none
+	tagFields := fields[1:]
+	if len(tagFields) > 1 {
+		tagFields = tagFields[:len(tagFields)-1]
+	}
+	return tocEntry{
+		desc: desc, schema: fields[0], tag: strings.Join(tagFields, " "),
+	}, true
+}
+
+// parseScriptHeader parses the header comment that pg_dump writes before
+// every entry of a SQL script, like
+// "-- Name: users; Type: TABLE; Schema: public; Owner: postgres".
+func parseScriptHeader(line string) (tocEntry, bool) {
+	var rest string
+	switch {
+	case strings.HasPrefix(line, "-- Name: "):
+		rest = strings.TrimPrefix(line, "-- Name: ")
+	case strings.HasPrefix(line, "-- Data for Name: "):
+		rest = strings.TrimPrefix(line, "-- Data for Name: ")
+	default:
+		return tocEntry{}, false
+	}
+
+	tag, rest, ok := strings.Cut(rest, "; Type: ")
+	if !ok {
+		return tocEntry{}, false
+	}
+	desc, rest, ok := strings.Cut(rest, "; Schema: ")
+	if !ok {
+		return tocEntry{}, false
+	}
+	schema, _, _ := strings.Cut(rest, "; ")
+
+	return tocEntry{desc: desc, schema: schema, tag: tag}, true
+}
+
+// archiveObjects returns the schemas and tables of the entries of a dump,
+// the schemas first. The schemas without an entry of their own, like public,
+// are listed when they have tables.
+func archiveObjects(entries []tocEntry) []ArchiveObject {
+	schemas := map[string]bool{}
+	tables := []ArchiveObject{}
+
+	for _, entry := range entries {
+		switch entry.desc {
+		case "SCHEMA":
+			schemas[entry.tag] = true
+		case "TABLE":
+			schemas[entry.schema] = true
+			tables = append(tables, ArchiveObject{
+				Type: ArchiveObjectTable, Schema: entry.schema, Name: entry.tag,
+			})
+		}
+	}
+
+	objects := make([]ArchiveObject, 0, len(schemas)+len(tables))
+	for schema := range schemas {
+		objects = append(objects, ArchiveObject{
+			Type: ArchiveObjectSchema, Name: schema,
+		})
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Name < objects[j].Name
+	})
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].Schema != tables[j].Schema {
+			return tables[i].Schema < tables[j].Schema
+		}
+		return tables[i].Name < tables[j].Name
+	})
+
+	return append(objects, tables...)
+}
+
+// scriptScanner reads a SQL script written by pg_dump or pg_restore line by
+// line, keeping track of the entry every line belongs to. The lines before
+// the first entry are the preamble.
+type scriptScanner struct {
+	reader *bufio.Reader
+	inCopy bool
+	// data reports whether the current line is a row of a COPY block
+	data bool
+	// section is the index of the current entry, -1 in the preamble
+	section int
+	// newSection reports whether the current line starts an entry
+	newSection bool
+	entry      tocEntry
+	line       string
+	err        error
+}
+
+func newScriptScanner(r io.Reader) *scriptScanner {
+	return &scriptScanner{reader: bufio.NewReaderSize(r, 64<<10), section: -1}
+}
+
+// next reads the next line, it returns false at the end of the script or
+// when reading fails, see err.
+func (s *scriptScanner) next() bool {
+	line, err := s.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err != io.EOF {
+			s.err = err
+		}
+		return false
+	}
+	s.line = line
+	s.newSection = false
+	s.data = s.inCopy
+
+	// The rows of COPY blocks are data, never headers
+	trimmed := strings.TrimRight(line, "\r\n")
+	switch {
+	case s.inCopy:
+		if trimmed == `\.` {
+			s.inCopy = false
+		}
+	case strings.HasPrefix(trimmed, "COPY ") && strings.HasSuffix(trimmed, "FROM stdin;"):
+		s.inCopy = true
+	default:
+		if entry, ok := parseScriptHeader(trimmed); ok {
+			s.section++
+			s.newSection = true
+			s.entry = entry
+		}
+	}
+
+	return true
+}
+
+// scriptSection is an entry of a SQL script and the table it belongs to.
+type scriptSection struct {
+	entry tocEntry
+	table *TableName
+	// sequenceOf is the table that owns the sequence of the entry
+	sequenceOf *TableName
+}
+
+// Patterns of the statements that tie an entry to a table.
+const identPattern = `("(?:[^"]|"")*"|[^\s."(),;]+)`
+
+var (
+	indexOnRegex = regexp.MustCompile(
+		`(?is)\bINDEX\b.*?\bON\s+(?:ONLY\s+)?` + identPattern + `\.` + identPattern,
+	)
+	ownedByRegex = regexp.MustCompile(
+		`(?is)\bOWNED\s+BY\s+` + identPattern + `\.` + identPattern + `\.`,
+	)
+	identityRegex = regexp.MustCompile(
+		`(?is)^ALTER\s+TABLE\s+(?:ONLY\s+)?` + identPattern + `\.` + identPattern +
+			`\s+ALTER\s+COLUMN`,
+	)
+)
+
+// bodyTable returns the table found by the regex in a statement.
+func bodyTable(regex *regexp.Regexp, body string) *TableName {
+	match := regex.FindStringSubmatch(body)
+	if match == nil {
+		return nil
+	}
+	table, err := ParseTableName(match[1] + "." + match[2])
+	if err != nil {
+		return nil
+	}
+	return &table
+}
+
+// entryTable returns the table an entry belongs to from its type and tag.
+func entryTable(entry tocEntry) *TableName {
+	switch entry.desc {
+	case "TABLE", "TABLE DATA":
+		return &TableName{Schema: entry.schema, Name: entry.tag}
+	case "CONSTRAINT", "FK CONSTRAINT", "DEFAULT", "TRIGGER", "POLICY", "RULE":
+		// The tag is the table followed by the name of the object
+		name, _, _ := strings.Cut(entry.tag, " ")
+		return &TableName{Schema: entry.schema, Name: name}
+	case "COMMENT", "ACL":
+		if name, ok := strings.CutPrefix(entry.tag, "TABLE "); ok {
+			return &TableName{Schema: entry.schema, Name: name}
+		}
+		if column, ok := strings.CutPrefix(entry.tag, "COLUMN "); ok {
+			if idx := strings.LastIndex(column, "."); idx > 0 {
+				return &TableName{Schema: entry.schema, Name: column[:idx]}
+			}
+		}
+	}
+	return nil
+}
+
+// maxBodyLength is the max number of bytes of the statements of an entry
+// read to find its table.
+const maxBodyLength = 4 << 10
+
+// scanScript reads the entries of a SQL script, reading the statements of
+// the indexes and sequences to find the tables they belong to.
+func scanScript(r io.Reader) ([]scriptSection, error) {
+	sections := []scriptSection{}
+	body := strings.Builder{}
+
+	finish := func() {
+		if len(sections) == 0 {
+			return
+		}
+		section := &sections[len(sections)-1]
+		switch section.entry.desc {
+		case "INDEX":
+			section.table = bodyTable(indexOnRegex, body.String())
+		case "SEQUENCE OWNED BY":
+			section.sequenceOf = bodyTable(ownedByRegex, body.String())
+		case "SEQUENCE":
+			// Identity columns create their sequence with ALTER TABLE
+			section.sequenceOf = bodyTable(identityRegex, body.String())
+		}
+		body.Reset()
+	}
+
+	scanner := newScriptScanner(r)
+	for scanner.next() {
+		if scanner.newSection {
+			finish()
+			sections = append(sections, scriptSection{
+				entry: scanner.entry, table: entryTable(scanner.entry),
+			})
+			continue
+		}
+		if scanner.section < 0 || scanner.inCopy ||
+			strings.HasPrefix(scanner.line, "--") {
+			continue
+		}
+		if body.Len() < maxBodyLength {
+			body.WriteString(scanner.line)
+		}
+	}
+	if scanner.err != nil {
+		return nil, fmt.Errorf("error reading dump: %w", scanner.err)
+	}
+	finish()
+
+	return sections, nil
+}
+
+// scriptEntries returns the entries of the sections of a script.
+func scriptEntries(sections []scriptSection) []tocEntry {
+	entries := make([]tocEntry, len(sections))
+	for i, section := range sections {
+		entries[i] = section.entry
+	}
+	return entries
+}
+
+// dataDescs are the entry types restored by the data only content.
+var dataDescs = map[string]bool{
+	"TABLE DATA":             true,
+	"SEQUENCE SET":           true,
+	"MATERIALIZED VIEW DATA": true,
+}
+
+// selectSections returns which sections of a script are restored for the
+// selection: everything in the selected schemas, and the selected tables
+// with their data, indexes, constraints, defaults, triggers, policies,
+// comments, privileges and owned sequences.
+func selectSections(
+	sections []scriptSection, selection RestoreSelection,
+) []bool {
+	schemas := map[string]bool{}
+	for _, schema := range selection.Schemas {
+		schemas[schema] = true
+	}
+	tables := map[TableName]bool{}
+	for _, table := range selection.Tables {
+		tables[table] = true
+	}
+
+	// The sequences are defined before the statements that tie them to
+	// their table
+	sequenceOwners := map[TableName]TableName{}
+	for _, section := range sections {
+		if section.sequenceOf != nil {
+			sequence := TableName{Schema: section.entry.schema, Name: section.entry.tag}
+			sequenceOwners[sequence] = *section.sequenceOf
+		}
+	}
+
+	keep := make([]bool, len(sections))
+	for i, section := range sections {
+		entry := section.entry
+
+		table := section.table
+		switch entry.desc {
+		case "SEQUENCE", "SEQUENCE SET", "SEQUENCE OWNED BY":
+			sequence := TableName{Schema: entry.schema, Name: entry.tag}
+			if owner, ok := sequenceOwners[sequence]; ok {
+				table = &owner
+			}
+		}
+
+		selected := schemas[entry.schema] ||
+			(entry.desc == "SCHEMA" && schemas[entry.tag]) ||
+			((entry.desc == "COMMENT" || entry.desc == "ACL") &&
+				schemas[strings.TrimPrefix(entry.tag, "SCHEMA ")] &&
+				strings.HasPrefix(entry.tag, "SCHEMA ")) ||
+			(table != nil && tables[*table])
+		if !selected {
+			continue
+		}
+
+		switch selection.content() {
+		case RestoreContentData:
+			keep[i] = dataDescs[entry.desc]
+		case RestoreContentSchema:
+			keep[i] = !dataDescs[entry.desc]
+		default:
+			keep[i] = true
+		}
+	}
+
+	return keep
+}
+
+// isPreambleStatement reports whether a line of the preamble of a script is
+// kept when the script is filtered. Only the session settings are kept, the
+// DROP statements written by --clean and the database creation are not.
+func isPreambleStatement(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" ||
+		strings.HasPrefix(trimmed, "--") ||
+		strings.HasPrefix(trimmed, "SET ") ||
+		strings.HasPrefix(trimmed, "SELECT pg_catalog.set_config(") ||
+		strings.HasPrefix(trimmed, `\restrict `)
+}
+
+// writeSections copies the preamble and the kept sections of a script, keep
+// must come from the sections of the same script and nil keeps all of them.
+// The statement lines are passed through rewrite when it is not nil, the
+// rows of the COPY blocks never are.
+func writeSections(
+	r io.Reader, w io.Writer, keep []bool, rewrite lineRewriter,
+) error {
+	writer := bufio.NewWriterSize(w, 64<<10)
+
+	scanner := newScriptScanner(r)
+	for scanner.next() {
+		var write bool
+		switch {
+		case scanner.section < 0:
+			write = isPreambleStatement(scanner.line)
+		case keep == nil:
+			write = true
+		default:
+			write = scanner.section < len(keep) && keep[scanner.section]
+		}
+		if !write {
+			continue
+		}
+
+		line := scanner.line
+		if rewrite != nil && !scanner.data {
+			if line, write = rewrite(line); !write {
+				continue
+			}
+		}
+		if _, err := writer.WriteString(line); err != nil {
+			return fmt.Errorf("error writing selection: %w", err)
+		}
+	}
+	if scanner.err != nil {
+		return fmt.Errorf("error reading dump: %w", scanner.err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("error writing selection: %w", err)
+	}
+	return nil
+}
+
+// ListArchiveObjects reads a backup file created by DumpZip and returns the
+// schemas and tables of its dump. Plain SQL dumps are scanned,
+// the archive formats are listed with pg_restore --list.
+func (Client) ListArchiveObjects(
+	ctx context.Context, version PGVersion, file *database.BackupFile,
+	fileExtension string,
+) ([]ArchiveObject, error) {
+	workDir, err := os.MkdirTemp("", "pbw-list-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	format, dumpPath, err := fetchDump(ctx, file, fileExtension, workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := dumpEntries(ctx, version, format, dumpPath)
+	if err != nil {
+		return nil, err
+	}
+	return archiveObjects(entries), nil
+}
+
+// dumpEntries returns the entries of an extracted dump, plain dumps are
+// scanned and archives are listed with pg_restore.
+func dumpEntries(
+	ctx context.Context, version PGVersion, format string, dumpPath string,
+) ([]tocEntry, error) {
+	if format == DumpFormatPlain {
+		file, err := os.Open(dumpPath)
+		if err != nil {
+			return nil, fmt.Errorf("error opening dump: %w", err)
+		}
+		defer file.Close()
+
+		sections, err := scanScript(file)
+		if err != nil {
+			return nil, err
+		}
+		return scriptEntries(sections), nil
+	}
+
+	cmd := exec.CommandContext(ctx, version.Value.PGRestore, "--list", dumpPath)
+	output, err := cmd.Output()
+	if err != nil && ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error running pg_restore v%s command: %s",
+			version.Value.Version, commandError(err),
+		)
+	}
+
+	entries := []tocEntry{}
+	for _, line := range strings.Split(string(output), "\n") {
+		if entry, ok := parseListLine(strings.TrimSpace(line)); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// commandError returns the stderr of a failed command, or the error itself.
+func commandError(err error) string {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return strings.TrimSpace(string(exitErr.Stderr))
+	}
+	return err.Error()
+}
+
+// dumpScript returns the path of the dump as a SQL script, the archive
+// formats are converted with pg_restore into the directory of the dump.
+func dumpScript(
+	ctx context.Context, version PGVersion, format string, dumpPath string,
+	params RestoreParams,
+) (string, error) {
+	if format == DumpFormatPlain {
+		return dumpPath, nil
+	}
+
+	scriptPath := filepath.Join(filepath.Dir(dumpPath), "script.sql")
+	args := []string{"--file=" + scriptPath}
+	if params.NoOwner {
+		args = append(args, "--no-owner")
+	}
+	if params.NoPrivileges {
+		args = append(args, "--no-privileges")
+	}
+	args = append(args, dumpPath)
+
+	cmd := exec.CommandContext(ctx, version.Value.PGRestore, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil && ctx.Err() != nil {
+		return "", context.Cause(ctx)
+	}
+	if err != nil {
+		return "", fmt.Errorf(
+			"error running pg_restore v%s command: %s",
+			version.Value.Version, output,
+		)
+	}
+
+	return scriptPath, nil
+}
+
+// filterScript writes the sections of the script restored with the
+// parameters to dst, see scriptRewriter for the changes to the statements.
+// Only the objects of the selection are kept when it isn't empty, it fails
+// if a selected object is not in the script. With a target schema the schema
+// entries are left out, the source schema is created before loading the
+// selection. With SkipDatabase the entries of the database are left out.
+func filterScript(scriptPath string, dst string, params RestoreParams) error {
+	selection := params.Selection
+
+	file, err := os.Open(scriptPath)
+	if err != nil {
+		return fmt.Errorf("error opening dump: %w", err)
+	}
+	defer file.Close()
+
+	sections, err := scanScript(file)
+	if err != nil {
+		return err
+	}
+	keep := make([]bool, len(sections))
+	for i := range keep {
+		keep[i] = true
+	}
+	if !selection.IsEmpty() {
+		if err := selection.Check(archiveObjects(scriptEntries(sections))); err != nil {
+			return err
+		}
+		keep = selectSections(sections, selection)
+	}
+
+	for i, section := range sections {
+		if selection.TargetSchema != "" && section.entry.desc == "SCHEMA" {
+			keep[i] = false
+		}
+		if params.SkipDatabase && isDatabaseEntry(section.entry) {
+			keep[i] = false
+		}
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error reading dump: %w", err)
+	}
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("error creating filtered dump: %w", err)
+	}
+	defer dstFile.Close()
+
+	return writeSections(file, dstFile, keep, scriptRewriter(params))
+}
+
+// loadScript runs the SQL script in the database of the connection string in
+// a single transaction, stopping at the first error so a script is never
+// restored halfway.
+func loadScript(
+	ctx context.Context, version PGVersion, connString string, scriptPath string,
+) error {
+	cmd := exec.CommandContext(
+		ctx, version.Value.PSQL, connString, "--no-psqlrc", "--quiet",
+		"-v", "ON_ERROR_STOP=1", "--single-transaction", "-f", scriptPath,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil && ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf(
+			"error running psql v%s command: %s",
+			version.Value.Version, output,
+		)
+	}
+	return nil
+}
+
+// needsScript reports whether the dump must be restored as a filtered SQL
+// script instead of being passed as is to psql or pg_restore.
+func needsScript(format string, params RestoreParams) bool {
+	return !params.Selection.IsEmpty() || len(params.RoleMap) > 0 ||
+		(format == DumpFormatPlain && params.SkipDatabase)
+}
+
+// restoreScript restores the dump as a SQL script filtered following the
+// parameters: the dump is converted to a SQL script, the restored sections
+// are kept and rewritten, and the result is loaded with psql.
+//
+// With the target schema of a selection, the selection is first loaded into a
+// scratch database of the same server, where the schema is renamed, and then
+// dumped from there into the target database.
+func restoreScript(
+	ctx context.Context, version PGVersion, connString string, format string,
+	dumpPath string, params RestoreParams,
+) error {
+	selection := params.Selection
+	if err := selection.Validate(); err != nil {
+		return err
+	}
+
+	scriptPath, err := dumpScript(ctx, version, format, dumpPath, params)
+	if err != nil {
+		return err
+	}
+
+	workDir := filepath.Dir(dumpPath)
+	filteredPath := filepath.Join(workDir, "filtered.sql")
+
+	if selection.TargetSchema == "" {
+		if err := filterScript(scriptPath, filteredPath, params); err != nil {
+			return err
+		}
+		return loadScript(ctx, version, connString, filteredPath)
+	}
+
+	// The scratch database needs the definitions to dump the data, the roles
+	// are renamed there so the dump of the renamed schema already has them
+	scratchParams := params
+	scratchParams.Selection.Content = RestoreContentAll
+	if err := filterScript(scriptPath, filteredPath, scratchParams); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("error generating scratch database name: %w", err)
+	}
+	scratchName := "pbw_restore_" + hex.EncodeToString(suffix)
+
+	c := Client{}
+	if err := c.CreateDatabase(ctx, version, connString, scratchName); err != nil {
+		return fmt.Errorf("error creating scratch database: %w", err)
+	}
+	defer func() {
+		_ = c.DropDatabase(ctx, version, connString, scratchName)
+	}()
+
+	scratchConnString, err := WithDatabase(connString, scratchName)
+	if err != nil {
+		return err
+	}
+
+	source := QuoteIdentifier(selection.sourceSchemas()[0])
+	target := QuoteIdentifier(selection.TargetSchema)
+
+	_, err = psqlCommand(
+		ctx, version, scratchConnString, "-c", "CREATE SCHEMA IF NOT EXISTS "+source,
+	)
+	if err != nil {
+		return err
+	}
+	if err := loadScript(ctx, version, scratchConnString, filteredPath); err != nil {
+		return err
+	}
+	_, err = psqlCommand(
+		ctx, version, scratchConnString,
+		"-c", "ALTER SCHEMA "+source+" RENAME TO "+target,
+	)
+	if err != nil {
+		return err
+	}
+
+	renamedPath := filepath.Join(workDir, "renamed.sql")
+	args := []string{scratchConnString, "--schema=" + target, "--file=" + renamedPath}
+	switch selection.content() {
+	case RestoreContentData:
+		args = append(args, "--data-only")
+	case RestoreContentSchema:
+		args = append(args, "--schema-only")
+	}
+	if params.NoOwner {
+		args = append(args, "--no-owner")
+	}
+	if params.NoPrivileges {
+		args = append(args, "--no-privileges")
+	}
+
+	cmd := exec.CommandContext(ctx, version.Value.PGDump, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil && ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf(
+			"error running pg_dump v%s command: %s",
+			version.Value.Version, output,
+		)
+	}
+
+	return loadScript(ctx, version, connString, renamedPath)
+}
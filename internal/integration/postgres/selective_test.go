@@ -0,0 +1,349 @@
+package postgres
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTableName(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    TableName
+		wantErr bool
+	}{
+		{"public.users", TableName{"public", "users"}, false},
+		{`"My Schema"."Order.Items"`, TableName{"My Schema", "Order.Items"}, false},
+		{`public."say ""hi"""`, TableName{"public", `say "hi"`}, false},
+		{"users", TableName{}, true},
+		{"a.b.c", TableName{}, true},
+		{".users", TableName{}, true},
+		{`public."users`, TableName{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTableName(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			roundTrip, err := ParseTableName(got.String())
+			assert.NoError(t, err)
+			assert.Equal(t, got, roundTrip)
+		})
+	}
+}
+
+func TestRestoreSelectionValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection RestoreSelection
+		wantErr   bool
+	}{
+		{"empty", RestoreSelection{}, false},
+		{"content without objects", RestoreSelection{Content: RestoreContentData}, true},
+		{"schemas", RestoreSelection{Schemas: []string{"public", "audit"}}, false},
+		{
+			"tables data",
+			RestoreSelection{
+				Tables:  []TableName{{"public", "users"}},
+				Content: RestoreContentData,
+			},
+			false,
+		},
+		{
+			"invalid content",
+			RestoreSelection{Schemas: []string{"public"}, Content: "indexes"},
+			true,
+		},
+		{"invalid schema", RestoreSelection{Schemas: []string{"a\nb"}}, true},
+		{
+			"target schema",
+			RestoreSelection{
+				Tables:       []TableName{{"public", "users"}, {"public", "orders"}},
+				TargetSchema: "restored",
+			},
+			false,
+		},
+		{
+			"target schema from many schemas",
+			RestoreSelection{
+				Schemas:      []string{"audit"},
+				Tables:       []TableName{{"public", "users"}},
+				TargetSchema: "restored",
+			},
+			true,
+		},
+		{
+			"target schema equal to source",
+			RestoreSelection{Schemas: []string{"public"}, TargetSchema: "public"},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.selection.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRestoreSelectionMarshal(t *testing.T) {
+	selection := RestoreSelection{
+		Tables:       []TableName{{"public", "users"}},
+		Content:      RestoreContentSchema,
+		TargetSchema: "restored",
+	}
+	parsed, err := ParseRestoreSelection(selection.Marshal())
+	assert.NoError(t, err)
+	assert.Equal(t, selection, parsed)
+
+	parsed, err = ParseRestoreSelection([]byte("{}"))
+	assert.NoError(t, err)
+	assert.True(t, parsed.IsEmpty())
+
+	assert.Equal(t, "Everything", RestoreSelection{}.Describe())
+	assert.Equal(
+		t, "tables public.users (definitions only) into schema restored",
+		selection.Describe(),
+	)
+}
+
+func TestParseListLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want tocEntry
+		ok   bool
+	}{
+		{"; Archive created at 2026-10-18 10:00:00 UTC", tocEntry{}, false},
+		{"", tocEntry{}, false},
+		{
+			"215; 1259 16386 TABLE public users postgres",
+			tocEntry{"TABLE", "public", "users"}, true,
+		},
+		{
+			"3350; 0 16386 TABLE DATA public users postgres",
+			tocEntry{"TABLE DATA", "public", "users"}, true,
+		},
+		{
+			"6; 2615 16385 SCHEMA - audit postgres",
+			tocEntry{"SCHEMA", "-", "audit"}, true,
+		},
+		{
+			"3200; 2606 16400 CONSTRAINT public users users_pkey postgres",
+			tocEntry{"CONSTRAINT", "public", "users users_pkey"}, true,
+		},
+		{
+			"3360; 0 0 SEQUENCE SET public users_id_seq postgres",
+			tocEntry{"SEQUENCE SET", "public", "users_id_seq"}, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, ok := parseListLine(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseScriptHeader(t *testing.T) {
+	got, ok := parseScriptHeader(
+		"-- Name: users; Type: TABLE; Schema: public; Owner: postgres",
+	)
+	assert.True(t, ok)
+	assert.Equal(t, tocEntry{"TABLE", "public", "users"}, got)
+
+	got, ok = parseScriptHeader(
+		"-- Data for Name: users; Type: TABLE DATA; Schema: public; Owner: postgres",
+	)
+	assert.True(t, ok)
+	assert.Equal(t, tocEntry{"TABLE DATA", "public", "users"}, got)
+
+	_, ok = parseScriptHeader("-- PostgreSQL database dump")
+	assert.False(t, ok)
+}
+
+const testScript = `--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+SELECT pg_catalog.set_config('search_path', '', false);
+DROP TABLE public.users;
+
+--
+-- Name: audit; Type: SCHEMA; Schema: -; Owner: postgres
+--
+
+CREATE SCHEMA audit;
+
+--
+-- Name: events; Type: TABLE; Schema: audit; Owner: postgres
+--
+
+CREATE TABLE audit.events (id integer);
+
+--
+-- Name: users; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.users (
+    id integer NOT NULL,
+    email text
+);
+
+--
+-- Name: users_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
+--
+
+CREATE SEQUENCE public.users_id_seq;
+
+--
+-- Name: users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: postgres
+--
+
+ALTER SEQUENCE public.users_id_seq OWNED BY public.users.id;
+
+--
+-- Name: orders; Type: TABLE; Schema: public; Owner: postgres
+--
+
+CREATE TABLE public.orders (id integer);
+
+--
+-- Data for Name: events; Type: TABLE DATA; Schema: audit; Owner: postgres
+--
+
+COPY audit.events (id) FROM stdin;
+1
+\.
+
+--
+-- Data for Name: users; Type: TABLE DATA; Schema: public; Owner: postgres
+--
+
+COPY public.users (id, email) FROM stdin;
+1	-- Name: fake; Type: TABLE; Schema: public; Owner: x
+\.
+
+--
+-- Name: users_id_seq; Type: SEQUENCE SET; Schema: public; Owner: postgres
+--
+
+SELECT pg_catalog.setval('public.users_id_seq', 1, true);
+
+--
+-- Name: users users_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
+--
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+--
+-- Name: users_email_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX users_email_idx ON public.users USING btree (email);
+
+--
+-- Name: orders_id_idx; Type: INDEX; Schema: public; Owner: postgres
+--
+
+CREATE INDEX orders_id_idx ON public.orders USING btree (id);
+`
+
+func TestScanScript(t *testing.T) {
+	sections, err := scanScript(strings.NewReader(testScript))
+	require.NoError(t, err)
+	require.Len(t, sections, 12)
+
+	assert.Equal(t, []ArchiveObject{
+		{Type: ArchiveObjectSchema, Name: "audit"},
+		{Type: ArchiveObjectSchema, Name: "public"},
+		{Type: ArchiveObjectTable, Schema: "audit", Name: "events"},
+		{Type: ArchiveObjectTable, Schema: "public", Name: "orders"},
+		{Type: ArchiveObjectTable, Schema: "public", Name: "users"},
+	}, archiveObjects(scriptEntries(sections)))
+
+	assert.Equal(t, &TableName{"public", "users"}, sections[4].sequenceOf)
+	assert.Equal(t, &TableName{"public", "users"}, sections[10].table)
+	assert.Equal(t, &TableName{"public", "orders"}, sections[11].table)
+}
+
+func filterTestScript(t *testing.T, selection RestoreSelection) string {
+	sections, err := scanScript(strings.NewReader(testScript))
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	keep := selectSections(sections, selection)
+	require.NoError(t, writeSections(strings.NewReader(testScript), out, keep, nil))
+	return out.String()
+}
+
+func TestSelectSectionsTable(t *testing.T) {
+	out := filterTestScript(t, RestoreSelection{
+		Tables: []TableName{{"public", "users"}},
+	})
+
+	assert.Contains(t, out, "SET statement_timeout = 0;")
+	assert.Contains(t, out, "SELECT pg_catalog.set_config(")
+	assert.NotContains(t, out, "DROP TABLE")
+	assert.Contains(t, out, "CREATE TABLE public.users")
+	assert.Contains(t, out, "CREATE SEQUENCE public.users_id_seq;")
+	assert.Contains(t, out, "OWNED BY public.users.id")
+	assert.Contains(t, out, "COPY public.users")
+	assert.Contains(t, out, "pg_catalog.setval('public.users_id_seq'")
+	assert.Contains(t, out, "users_pkey")
+	assert.Contains(t, out, "users_email_idx")
+	assert.NotContains(t, out, "audit.events")
+	assert.NotContains(t, out, "public.orders")
+}
+
+func TestSelectSectionsContent(t *testing.T) {
+	out := filterTestScript(t, RestoreSelection{
+		Tables:  []TableName{{"public", "users"}},
+		Content: RestoreContentData,
+	})
+	assert.NotContains(t, out, "CREATE TABLE")
+	assert.NotContains(t, out, "users_pkey")
+	assert.Contains(t, out, "COPY public.users")
+	assert.Contains(t, out, "pg_catalog.setval(")
+
+	out = filterTestScript(t, RestoreSelection{
+		Schemas: []string{"audit"},
+		Content: RestoreContentSchema,
+	})
+	assert.Contains(t, out, "CREATE SCHEMA audit;")
+	assert.Contains(t, out, "CREATE TABLE audit.events")
+	assert.NotContains(t, out, "COPY audit.events")
+	assert.NotContains(t, out, "public.users")
+}
+
+func TestRestoreSelectionCheck(t *testing.T) {
+	sections, err := scanScript(strings.NewReader(testScript))
+	require.NoError(t, err)
+	objects := archiveObjects(scriptEntries(sections))
+
+	assert.NoError(t, RestoreSelection{
+		Schemas: []string{"public"},
+		Tables:  []TableName{{"audit", "events"}},
+	}.Check(objects))
+	assert.Error(t, RestoreSelection{Schemas: []string{"sales"}}.Check(objects))
+	assert.Error(t, RestoreSelection{
+		Tables: []TableName{{"public", "Users"}},
+	}.Check(objects))
+}
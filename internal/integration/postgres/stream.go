@@ -0,0 +1,296 @@
+package postgres
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+)
+
+// backupDump is the dump of a backup file created by DumpZip, read from the
+// backup file as it is consumed. The backup file is closed by its owner.
+type backupDump struct {
+	format string
+	file   *database.BackupFile
+	// codec is the codec of a backup file compressed as a stream
+	codec string
+	// zipFiles are the files of the dump stored in a ZIP backup file
+	zipFiles []*zip.File
+}
+
+// openDump opens the dump of the backup file created by DumpZip, the decoder
+// is picked from fileExtension. A ZIP backup file that can only be read in
+// order is buffered first, its index is at the end of the file.
+func openDump(
+	ctx context.Context, file *database.BackupFile, fileExtension string,
+) (*backupDump, error) {
+	codec, kind := compression.ParseExtension(fileExtension)
+	isZip := fileExtension == "" || codec == compression.CodecZip
+
+	dump := &backupDump{codec: codec, file: file}
+	if !isZip {
+		format, err := formatFromKind(kind)
+		if err != nil {
+			return nil, err
+		}
+		dump.format = format
+		return dump, nil
+	}
+
+	err := file.Buffer()
+	if err == nil {
+		err = dump.openZip()
+	}
+	if err != nil && ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return dump, nil
+}
+
+// openZip reads the index of the ZIP backup file and picks the files of the
+// dump. The format is detected from the names of the files so backups taken
+// before formats were supported keep working.
+func (d *backupDump) openZip() error {
+	zipReader, err := zip.NewReader(d.file, d.file.Size())
+	if err != nil {
+		return fmt.Errorf("error opening ZIP file: %w", err)
+	}
+
+	names := make([]string, 0, len(zipReader.File))
+	for _, file := range zipReader.File {
+		names = append(names, file.Name)
+	}
+	d.format, err = detectArchiveFormat(names)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range zipReader.File {
+		isMember := file.Name == dumpFileName(d.format)
+		if d.format == DumpFormatDirectory {
+			isMember = strings.HasPrefix(file.Name, dumpDirName+"/") &&
+				!file.FileInfo().IsDir()
+		}
+		if isMember {
+			d.zipFiles = append(d.zipFiles, file)
+		}
+	}
+	if len(d.zipFiles) == 0 {
+		return fmt.Errorf("%s not found in ZIP file", dumpFileName(d.format))
+	}
+
+	return nil
+}
+
+// reader returns the uncompressed dump of the single file formats, it can
+// only be read once.
+func (d *backupDump) reader() (io.ReadCloser, error) {
+	if d.format == DumpFormatDirectory {
+		return nil, fmt.Errorf("directory dumps can't be read as a stream")
+	}
+
+	if d.zipFiles != nil {
+		reader, err := d.zipFiles[0].Open()
+		if err != nil {
+			return nil, fmt.Errorf("error reading ZIP file: %w", err)
+		}
+		return reader, nil
+	}
+
+	reader, err := compression.NewReader(d.file, d.codec)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing backup file: %w", err)
+	}
+	return reader, nil
+}
+
+// extract writes the dump into dir and returns its path, which is a
+// directory for the directory format. Only the dump is written, the backup
+// file is never copied.
+func (d *backupDump) extract(dir string) (string, error) {
+	if d.format != DumpFormatDirectory {
+		reader, err := d.reader()
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+
+		dumpPath := filepath.Join(dir, dumpFileName(d.format))
+		return dumpPath, writeDumpFile(reader, dumpPath)
+	}
+
+	dumpPath := filepath.Join(dir, dumpDirName)
+	if d.zipFiles == nil {
+		reader, err := d.reader()
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+		return dumpPath, compression.ExtractTar(reader, dir)
+	}
+
+	for _, file := range d.zipFiles {
+		target := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return "", fmt.Errorf("invalid file path in ZIP file: %s", file.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return "", err
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return "", fmt.Errorf("error reading ZIP file: %w", err)
+		}
+		err = writeDumpFile(reader, target)
+		_ = reader.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return dumpPath, nil
+}
+
+// writeDumpFile writes the content of the reader into the file.
+func writeDumpFile(r io.Reader, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating dump file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, r); err != nil {
+		return fmt.Errorf("error extracting dump: %w", err)
+	}
+	return file.Close()
+}
+
+// fetchDump extracts the dump of a backup file created by DumpZip into
+// workDir, it returns the format of the dump and its path.
+func fetchDump(
+	ctx context.Context, file *database.BackupFile, fileExtension string,
+	workDir string,
+) (string, string, error) {
+	dump, err := openDump(ctx, file, fileExtension)
+	if err != nil {
+		return "", "", err
+	}
+
+	dumpPath, err := dump.extract(workDir)
+	if err != nil && ctx.Err() != nil {
+		return "", "", context.Cause(ctx)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return dump.format, dumpPath, nil
+}
+
+// canStream reports whether the dump can be restored from the standard
+// input of psql or pg_restore. Directory dumps, parallel restores and the
+// restores that filter the dump need it on disk.
+func canStream(format string, params RestoreParams) bool {
+	if format == DumpFormatDirectory || needsScript(format, params) {
+		return false
+	}
+	return format != DumpFormatCustom || params.Jobs <= 1
+}
+
+// errorReader keeps the first error returned by the reader other than EOF.
+type errorReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// streamDump restores the dump from the standard input of psql for plain
+// SQL dumps, or pg_restore for the archives.
+func streamDump(
+	ctx context.Context, version PGVersion, connString string,
+	dump *backupDump, params RestoreParams,
+) error {
+	reader, err := dump.reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	command, name := version.Value.PSQL, "psql"
+	args := []string{connString, "-f", "-"}
+	if dump.format != DumpFormatPlain {
+		command, name = version.Value.PGRestore, "pg_restore"
+		args = restoreArgs(connString, dump.format, params)
+	}
+
+	input := &errorReader{reader: reader}
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdin = input
+	output, err := cmd.CombinedOutput()
+	if err != nil && ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	if input.err != nil {
+		return fmt.Errorf("error reading backup file: %w", input.err)
+	}
+	if err != nil {
+		return fmt.Errorf(
+			"error running %s v%s command: %s",
+			name, version.Value.Version, output,
+		)
+	}
+
+	return nil
+}
+
+// restoreBackup restores the backup file created by DumpZip. The dump is
+// streamed from the backup file into psql or pg_restore when possible, or
+// extracted into a temp dir first.
+func restoreBackup(
+	ctx context.Context, version PGVersion, connString string,
+	file *database.BackupFile, fileExtension string, params RestoreParams,
+) error {
+	dump, err := openDump(ctx, file, fileExtension)
+	if err != nil {
+		return err
+	}
+
+	if canStream(dump.format, params) {
+		return streamDump(ctx, version, connString, dump, params)
+	}
+
+	workDir, err := os.MkdirTemp("", "pbw-restore-*")
+	if err != nil {
+		return fmt.Errorf("error creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	dumpPath, err := dump.extract(workDir)
+	if err != nil && ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	return restoreDump(ctx, version, connString, dump.format, dumpPath, params)
+}
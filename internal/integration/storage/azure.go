@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+)
+
+// azureBackend stores the files in a container of Azure Blob Storage, it
+// authenticates with the shared key of the storage account or a SAS token
+type azureBackend struct {
+	config Config
+}
+
+func newAzureBackend(config Config) *azureBackend {
+	return &azureBackend{config: config}
+}
+
+func (azureBackend) GetStorageType() string {
+	return StorageTypeAzure
+}
+
+// isSAS reports whether the secret of the destination is a SAS token instead
+// of the shared key of the storage account
+func (b *azureBackend) isSAS() bool {
+	return strings.Contains(b.config.SecretKey, "sig=")
+}
+
+// serviceURL returns the blob service URL of the storage account, the
+// endpoint of the destination is used when it's set (e.g. Azurite)
+func (b *azureBackend) serviceURL() string {
+	if b.config.Endpoint != "" {
+		return strings.TrimSuffix(b.config.Endpoint, "/") + "/"
+	}
+	return fmt.Sprintf("https://%s.blob.core.windows.net/", b.config.AccessKey)
+}
+
+// client creates a new Azure Blob client
+func (b *azureBackend) client() (*azblob.Client, error) {
+	if b.isSAS() {
+		sasToken := strings.TrimPrefix(b.config.SecretKey, "?")
+		client, err := azblob.NewClientWithNoCredential(
+			b.serviceURL()+"?"+sasToken, nil,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing Azure client: %w", err)
+		}
+		return client, nil
+	}
+
+	credential, err := azblob.NewSharedKeyCredential(
+		b.config.AccessKey, b.config.SecretKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Azure shared key: %w", err)
+	}
+
+	client, err := azblob.NewClientWithSharedKeyCredential(
+		b.serviceURL(), credential, nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing Azure client: %w", err)
+	}
+	return client, nil
+}
+
+// Test tests the connection to the container
+func (b *azureBackend) Test(ctx context.Context) error {
+	client, err := b.client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.ServiceClient().
+		NewContainerClient(b.config.BucketName).
+		GetProperties(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to test Azure container: %w", err)
+	}
+
+	return nil
+}
+
+// Upload uploads a file to the container from a reader, the content is
+// uploaded in blocks without buffering the whole file.
+//
+// Returns the file size, in bytes.
+func (b *azureBackend) Upload(
+	ctx context.Context, key string, reader io.Reader,
+) (int64, error) {
+	client, err := b.client()
+	if err != nil {
+		return 0, err
+	}
+
+	key = strutil.RemoveLeadingSlash(key)
+	contentType := strutil.GetContentTypeFromFileName(key)
+
+	counter := &countingReader{reader: reader}
+	_, err = client.UploadStream(
+		ctx, b.config.BucketName, key, counter,
+		&azblob.UploadStreamOptions{
+			HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &contentType},
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upload file to Azure: %w", err)
+	}
+
+	return counter.n, nil
+}
+
+// Download returns a reader with the content of a file stored in the
+// container, the caller must close it.
+func (b *azureBackend) Download(
+	ctx context.Context, key string,
+) (io.ReadCloser, error) {
+	client, err := b.client()
+	if err != nil {
+		return nil, err
+	}
+
+	key = strutil.RemoveLeadingSlash(key)
+
+	res, err := client.DownloadStream(ctx, b.config.BucketName, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file from Azure: %w", azureError(err))
+	}
+
+	return res.Body, nil
+}
+
+// Delete deletes a file from the container
+func (b *azureBackend) Delete(ctx context.Context, key string) error {
+	client, err := b.client()
+	if err != nil {
+		return err
+	}
+
+	key = strutil.RemoveLeadingSlash(key)
+
+	_, err = client.DeleteBlob(ctx, b.config.BucketName, key, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete file from Azure: %w", azureError(err))
+	}
+
+	return nil
+}
+
+// DownloadLink generates a SAS URL for downloading a file from the
+// container. It requires the shared key, the SAS token of the destination
+// is never shared because it may allow writing to the container
+func (b *azureBackend) DownloadLink(
+	_ context.Context, key string, expiration time.Duration,
+) (string, error) {
+	if b.isSAS() {
+		return "", errors.New(
+			"download links require the shared key of the storage account",
+		)
+	}
+
+	client, err := b.client()
+	if err != nil {
+		return "", err
+	}
+
+	key = strutil.RemoveLeadingSlash(key)
+
+	link, err := client.ServiceClient().
+		NewContainerClient(b.config.BucketName).
+		NewBlobClient(key).
+		GetSASURL(sas.BlobPermissions{Read: true}, time.Now().Add(expiration), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate SAS URL: %w", err)
+	}
+
+	return link, nil
+}
+
+// azureError wraps the errors of missing blobs with fs.ErrNotExist
+func azureError(err error) error {
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+	return err
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// gcsBackend stores the files in a bucket of Google Cloud Storage, it
+// authenticates with the JSON key of a service account
+type gcsBackend struct {
+	config Config
+}
+
+func newGCSBackend(config Config) *gcsBackend {
+	return &gcsBackend{config: config}
+}
+
+func (gcsBackend) GetStorageType() string {
+	return StorageTypeGCS
+}
+
+// client creates a new GCS client, the caller must close it
+func (b *gcsBackend) client(ctx context.Context) (*gcs.Client, error) {
+	opts := []option.ClientOption{
+		option.WithCredentialsJSON([]byte(b.config.PrivateKey)),
+	}
+	if b.config.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(b.config.Endpoint))
+	}
+
+	client, err := gcs.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing GCS client: %w", err)
+	}
+	return client, nil
+}
+
+// Test tests the connection to the bucket. It lists the objects instead of
+// reading the bucket metadata because the object roles can't read it
+func (b *gcsBackend) Test(ctx context.Context) error {
+	client, err := b.client(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	objects := client.Bucket(b.config.BucketName).Objects(ctx, nil)
+	if _, err := objects.Next(); err != nil && !errors.Is(err, iterator.Done) {
+		return fmt.Errorf("failed to test GCS bucket: %w", err)
+	}
+
+	return nil
+}
+
+// Upload uploads a file to the bucket from a reader.
+//
+// Returns the file size, in bytes.
+func (b *gcsBackend) Upload(
+	ctx context.Context, key string, reader io.Reader,
+) (int64, error) {
+	client, err := b.client(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	key = strutil.RemoveLeadingSlash(key)
+
+	// Cancelling the context aborts the upload, closing the writer would
+	// store the partial content
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := client.Bucket(b.config.BucketName).Object(key).NewWriter(ctx)
+	writer.ContentType = strutil.GetContentTypeFromFileName(key)
+
+	size, err := io.Copy(writer, reader)
+	if err != nil {
+		cancel()
+		_ = writer.Close()
+		return 0, fmt.Errorf("failed to upload file to GCS: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return 0, fmt.Errorf("failed to upload file to GCS: %w", err)
+	}
+
+	return size, nil
+}
+
+// gcsReader closes the GCS client together with the object reader
+type gcsReader struct {
+	*gcs.Reader
+	client *gcs.Client
+}
+
+func (r *gcsReader) Close() error {
+	_ = r.Reader.Close()
+	return r.client.Close()
+}
+
+// Download returns a reader with the content of a file stored in the
+// bucket, the caller must close it.
+func (b *gcsBackend) Download(
+	ctx context.Context, key string,
+) (io.ReadCloser, error) {
+	client, err := b.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key = strutil.RemoveLeadingSlash(key)
+
+	reader, err := client.Bucket(b.config.BucketName).Object(key).NewReader(ctx)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to download file from GCS: %w", gcsError(err))
+	}
+
+	return &gcsReader{Reader: reader, client: client}, nil
+}
+
+// Delete deletes a file from the bucket
+func (b *gcsBackend) Delete(ctx context.Context, key string) error {
+	client, err := b.client(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	key = strutil.RemoveLeadingSlash(key)
+
+	err = client.Bucket(b.config.BucketName).Object(key).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete file from GCS: %w", gcsError(err))
+	}
+
+	return nil
+}
+
+// DownloadLink generates a V4 signed URL for downloading a file from the
+// bucket, it's signed locally with the key of the service account
+func (b *gcsBackend) DownloadLink(
+	_ context.Context, key string, expiration time.Duration,
+) (string, error) {
+	jwtConfig, err := google.JWTConfigFromJSON([]byte(b.config.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid service account JSON: %w", err)
+	}
+
+	key = strutil.RemoveLeadingSlash(key)
+
+	link, err := gcs.SignedURL(b.config.BucketName, key, &gcs.SignedURLOptions{
+		GoogleAccessID: jwtConfig.Email,
+		PrivateKey:     jwtConfig.PrivateKey,
+		Method:         http.MethodGet,
+		Expires:        time.Now().Add(expiration),
+		Scheme:         gcs.SigningSchemeV4,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signed URL: %w", err)
+	}
+
+	return link, nil
+}
+
+// gcsError wraps the errors of missing objects with fs.ErrNotExist
+func gcsError(err error) error {
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// StorageBackend is the interface that all storage backends must implement,
+// the paths are relative to the base directory of the backend
+type StorageBackend interface {
+	// Test tests the connection to the storage
+	Test(ctx context.Context) error
+
+	// Upload creates a file with the content of the reader and returns its
+	// size, in bytes. The missing parent directories are created
+	Upload(ctx context.Context, path string, reader io.Reader) (int64, error)
+
+	// Download returns a reader with the content of a file, the caller must
+	// close it
+	Download(ctx context.Context, path string) (io.ReadCloser, error)
+
+	// Delete deletes a file
+	Delete(ctx context.Context, path string) error
+
+	// GetStorageType returns the storage type identifier (e.g., "s3", "sftp")
+	GetStorageType() string
+}
+
+// FileSystemBackend is implemented by the backends that store the files in a
+// directory of this server, so they can be read directly from the disk
+type FileSystemBackend interface {
+	StorageBackend
+
+	// FullPath returns the absolute path of a file in the server
+	FullPath(path string) string
+}
+
+// PresignBackend is implemented by the backends that can generate temporary
+// download links that don't require credentials
+type PresignBackend interface {
+	StorageBackend
+
+	// DownloadLink returns a link to download a file that expires after the
+	// given duration
+	DownloadLink(
+		ctx context.Context, path string, expiration time.Duration,
+	) (string, error)
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -9,19 +10,49 @@ import (
 	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 )
 
-const (
-	localBackupsDir string = "/backups"
-)
+// localBackend stores the files in a directory of this server, it can be a
+// mounted network filesystem
+type localBackend struct {
+	dir string
+}
+
+func newLocalBackend(dir string) *localBackend {
+	return &localBackend{dir: dir}
+}
+
+func (localBackend) GetStorageType() string {
+	return StorageTypeLocal
+}
+
+// Test checks that the directory exists and files can be created in it
+func (b *localBackend) Test(_ context.Context) error {
+	info, err := os.Stat(b.dir)
+	if err != nil {
+		return fmt.Errorf("failed to access directory %s: %w", b.dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", b.dir)
+	}
+
+	file, err := os.CreateTemp(b.dir, ".pbw-test-*")
+	if err != nil {
+		return fmt.Errorf("failed to write to directory %s: %w", b.dir, err)
+	}
+	_ = file.Close()
+	return os.Remove(file.Name())
+}
 
-// LocalUpload Creates a new file using the provided path and reader relative
-// to the local backups directory.
+// Upload creates a new file using the provided path and reader relative to
+// the directory of the backend.
 //
 // Returns the size of the file created, in bytes.
-func (Client) LocalUpload(relativeFilePath string, fileReader io.Reader) (int64, error) {
-	fullPath := strutil.CreatePath(true, localBackupsDir, relativeFilePath)
+func (b *localBackend) Upload(
+	_ context.Context, path string, reader io.Reader,
+) (int64, error) {
+	fullPath := b.FullPath(path)
 	dir := filepath.Dir(fullPath)
 
-	err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm)
+	err := os.MkdirAll(dir, os.ModePerm)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
@@ -32,7 +63,7 @@ func (Client) LocalUpload(relativeFilePath string, fileReader io.Reader) (int64,
 	}
 	defer file.Close()
 
-	_, err = io.Copy(file, fileReader)
+	_, err = io.Copy(file, reader)
 	if err != nil {
 		return 0, fmt.Errorf("failed to write file %s: %w", fullPath, err)
 	}
@@ -45,10 +76,14 @@ func (Client) LocalUpload(relativeFilePath string, fileReader io.Reader) (int64,
 	return fileInfo.Size(), nil
 }
 
-// LocalDelete Deletes a file using the provided path relative to the local
-// backups directory.
-func (Client) LocalDelete(relativeFilePath string) error {
-	fullPath := strutil.CreatePath(true, localBackupsDir, relativeFilePath)
+func (b *localBackend) Download(
+	_ context.Context, path string,
+) (io.ReadCloser, error) {
+	return os.Open(b.FullPath(path))
+}
+
+func (b *localBackend) Delete(_ context.Context, path string) error {
+	fullPath := b.FullPath(path)
 
 	err := os.Remove(fullPath)
 	if err != nil {
@@ -58,8 +93,8 @@ func (Client) LocalDelete(relativeFilePath string) error {
 	return nil
 }
 
-// LocalGetFullPath Returns the full path of a file using the provided relative
-// file path to the local backups directory.
-func (Client) LocalGetFullPath(relativeFilePath string) string {
-	return strutil.CreatePath(true, localBackupsDir, relativeFilePath)
+// FullPath returns the full path of a file using the provided path relative
+// to the directory of the backend.
+func (b *localBackend) FullPath(path string) string {
+	return strutil.CreatePath(true, b.dir, path)
 }
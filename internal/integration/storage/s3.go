@@ -4,83 +4,171 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 )
 
-// createS3Client creates a new S3 client
-func createS3Client(
-	accessKey, secretKey, region, endpoint string,
-) (*s3.Client, error) {
-	credentialsProvider := credentials.NewStaticCredentialsProvider(
-		accessKey, secretKey, "",
-	)
+// s3Backend stores the files in a bucket of AWS S3 or any S3 compatible
+// storage
+type s3Backend struct {
+	config Config
+}
 
-	//nolint:all
-	endpointResolver := aws.EndpointResolverFunc(func(
-		_ string, _ string,
-	) (aws.Endpoint, error) {
-		return aws.Endpoint{
-			HostnameImmutable: true,
-			URL:               endpoint,
-		}, nil
-	})
+func newS3Backend(config Config) *s3Backend {
+	return &s3Backend{config: config}
+}
 
-	//nolint:all
-	conf, err := config.LoadDefaultConfig(
-		context.TODO(),
-		config.WithRegion(region),
-		config.WithEndpointResolver(endpointResolver),
-		config.WithCredentialsProvider(credentialsProvider),
-	)
+func (s3Backend) GetStorageType() string {
+	return StorageTypeS3
+}
+
+// client creates a new S3 client, the endpoint is used as is and the bucket
+// is sent in the path unless virtual-hosted style addressing is enabled
+func (b *s3Backend) client(ctx context.Context) (*s3.Client, error) {
+	options := []func(*config.LoadOptions) error{
+		config.WithRegion(b.config.Region),
+	}
+
+	// The default chain of LoadDefaultConfig is used when the static
+	// credentials are not set
+	mode := b.config.S3.credentialsMode()
+	hasKeys := b.config.AccessKey != "" && b.config.SecretKey != ""
+	if mode == S3CredentialsStatic || (mode == S3CredentialsAssumeRole && hasKeys) {
+		options = append(options, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(
+				b.config.AccessKey, b.config.SecretKey, "",
+			),
+		))
+	}
+
+	conf, err := config.LoadDefaultConfig(ctx, options...)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing storage config: %w", err)
 	}
 
-	s3Client := s3.NewFromConfig(conf)
+	if mode == S3CredentialsAssumeRole {
+		provider := stscreds.NewAssumeRoleProvider(
+			sts.NewFromConfig(conf), b.config.S3.RoleARN,
+			func(o *stscreds.AssumeRoleOptions) {
+				o.RoleSessionName = "pgbackweb"
+				if b.config.S3.ExternalID != "" {
+					o.ExternalID = aws.String(b.config.S3.ExternalID)
+				}
+			},
+		)
+		conf.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	s3Client := s3.NewFromConfig(conf, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(b.config.Endpoint)
+		o.UsePathStyle = !b.config.S3.VirtualHostedStyle
+	})
 	return s3Client, nil
 }
 
-// S3Test tests the connection to S3
-func (Client) S3Test(
-	accessKey, secretKey, region, endpoint, bucketName string,
-) error {
-	s3Client, err := createS3Client(
-		accessKey, secretKey, region, endpoint,
-	)
+// Test tests the connection to S3 and that the bucket supports the
+// advanced options of the destination
+func (b *s3Backend) Test(ctx context.Context) error {
+	s3Client, err := b.client(ctx)
 	if err != nil {
 		return err
 	}
 
 	_, err = s3Client.HeadBucket(
-		context.TODO(),
+		ctx,
 		&s3.HeadBucketInput{
-			Bucket: aws.String(bucketName),
+			Bucket: aws.String(b.config.BucketName),
 		},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to test S3 bucket: %w", err)
 	}
 
+	if b.config.S3.usesObjectLock() {
+		lock, err := s3Client.GetObjectLockConfiguration(
+			ctx,
+			&s3.GetObjectLockConfigurationInput{
+				Bucket: aws.String(b.config.BucketName),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get Object Lock configuration: %w", err)
+		}
+		if lock.ObjectLockConfiguration == nil ||
+			lock.ObjectLockConfiguration.ObjectLockEnabled != types.ObjectLockEnabledEnabled {
+			return fmt.Errorf("the bucket doesn't have Object Lock enabled")
+		}
+	}
+
+	if b.config.S3.ServerSideEncryption != "" || b.config.S3.StorageClass != "" {
+		if err := b.testWrite(ctx, s3Client); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// testWrite uploads, reads and deletes a small object using the encryption
+// and storage class of the destination, so the options rejected by the
+// bucket (unknown KMS keys, unsupported storage classes, etc.) are reported
+// before the first backup. The object is not locked
+func (b *s3Backend) testWrite(ctx context.Context, s3Client *s3.Client) error {
+	key := aws.String(".pgbackweb-connection-test")
+
+	options := b.config.S3
+	options.ObjectLockMode = ""
+	options.ObjectLockLegalHold = false
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(b.config.BucketName),
+		Key:    key,
+		Body:   strings.NewReader("pgbackweb"),
+	}
+	options.applyToPut(input, time.Now())
+	if _, err := s3Client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload test object with the destination options: %w", err)
+	}
+
+	head := &s3.HeadObjectInput{
+		Bucket: aws.String(b.config.BucketName),
+		Key:    key,
+	}
+	head.SSECustomerAlgorithm, head.SSECustomerKey, head.SSECustomerKeyMD5 =
+		b.config.S3.sseCustomer()
+	_, headErr := s3Client.HeadObject(ctx, head)
+
+	_, err := s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.config.BucketName),
+		Key:    key,
+	})
+	if headErr != nil {
+		return fmt.Errorf("failed to read test object with the destination options: %w", headErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete test object: %w", err)
+	}
+
 	return nil
 }
 
-// S3Upload uploads a file to S3 from a reader.
+// Upload uploads a file to S3 from a reader.
 //
 // Returns the file size, in bytes.
-func (Client) S3Upload(
-	accessKey, secretKey, region, endpoint, bucketName, key string,
-	fileReader io.Reader,
+func (b *s3Backend) Upload(
+	ctx context.Context, key string, reader io.Reader,
 ) (int64, error) {
-	s3Client, err := createS3Client(
-		accessKey, secretKey, region, endpoint,
-	)
+	s3Client, err := b.client(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -88,27 +176,27 @@ func (Client) S3Upload(
 	key = strutil.RemoveLeadingSlash(key)
 	contentType := strutil.GetContentTypeFromFileName(key)
 
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(b.config.BucketName),
+		Key:         aws.String(key),
+		Body:        reader,
+		ContentType: aws.String(contentType),
+	}
+	b.config.S3.applyToPut(input, time.Now())
+
 	uploader := manager.NewUploader(s3Client)
-	_, err = uploader.Upload(
-		context.TODO(),
-		&s3.PutObjectInput{
-			Bucket:      aws.String(bucketName),
-			Key:         aws.String(key),
-			Body:        fileReader,
-			ContentType: aws.String(contentType),
-		},
-	)
+	_, err = uploader.Upload(ctx, input)
 	if err != nil {
 		return 0, fmt.Errorf("failed to upload file to S3: %w", err)
 	}
 
-	fileHead, err := s3Client.HeadObject(
-		context.TODO(),
-		&s3.HeadObjectInput{
-			Bucket: aws.String(bucketName),
-			Key:    aws.String(key),
-		},
-	)
+	head := &s3.HeadObjectInput{
+		Bucket: aws.String(b.config.BucketName),
+		Key:    aws.String(key),
+	}
+	head.SSECustomerAlgorithm, head.SSECustomerKey, head.SSECustomerKeyMD5 =
+		b.config.S3.sseCustomer()
+	fileHead, err := s3Client.HeadObject(ctx, head)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get uploaded file info from S3: %w", err)
 	}
@@ -121,13 +209,9 @@ func (Client) S3Upload(
 	return fileSize, nil
 }
 
-// S3Delete deletes a file from S3
-func (Client) S3Delete(
-	accessKey, secretKey, region, endpoint, bucketName, key string,
-) error {
-	s3Client, err := createS3Client(
-		accessKey, secretKey, region, endpoint,
-	)
+// Delete deletes a file from S3
+func (b *s3Backend) Delete(ctx context.Context, key string) error {
+	s3Client, err := b.client(ctx)
 	if err != nil {
 		return err
 	}
@@ -135,9 +219,9 @@ func (Client) S3Delete(
 	key = strutil.RemoveLeadingSlash(key)
 
 	_, err = s3Client.DeleteObject(
-		context.TODO(),
+		ctx,
 		&s3.DeleteObjectInput{
-			Bucket: aws.String(bucketName),
+			Bucket: aws.String(b.config.BucketName),
 			Key:    aws.String(key),
 		},
 	)
@@ -148,22 +232,51 @@ func (Client) S3Delete(
 	return nil
 }
 
-// S3GetDownloadLink generates a presigned URL for downloading a file from S3
-func (Client) S3GetDownloadLink(
-	accessKey, secretKey, region, endpoint, bucketName, key string,
-	expiration time.Duration,
+// Download returns a reader with the content of a file stored in S3, the
+// caller must close it.
+func (b *s3Backend) Download(
+	ctx context.Context, key string,
+) (io.ReadCloser, error) {
+	s3Client, err := b.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key = strutil.RemoveLeadingSlash(key)
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(b.config.BucketName),
+		Key:    aws.String(key),
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 =
+		b.config.S3.sseCustomer()
+	object, err := s3Client.GetObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file from S3: %w", err)
+	}
+
+	return object.Body, nil
+}
+
+// DownloadLink generates a presigned URL for downloading a file from S3.
+// The objects encrypted with SSE-C can't be downloaded with a link because
+// the key must be sent in the headers of the request
+func (b *s3Backend) DownloadLink(
+	ctx context.Context, key string, expiration time.Duration,
 ) (string, error) {
-	s3Client, err := createS3Client(
-		accessKey, secretKey, region, endpoint,
-	)
+	if b.config.S3.ServerSideEncryption == S3EncryptionCustomer {
+		return "", fmt.Errorf("download links are not available with SSE-C")
+	}
+
+	s3Client, err := b.client(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to create S3 client: %w", err)
 	}
 
 	presigned, err := s3.NewPresignClient(s3Client).PresignGetObject(
-		context.TODO(),
+		ctx,
 		&s3.GetObjectInput{
-			Bucket: aws.String(bucketName),
+			Bucket: aws.String(b.config.BucketName),
 			Key:    aws.String(key),
 		},
 		s3.WithPresignExpires(expiration),
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"crypto/md5" //nolint:gosec
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3 credential modes
+const (
+	S3CredentialsStatic     = "static"
+	S3CredentialsDefault    = "default"
+	S3CredentialsAssumeRole = "assume_role"
+)
+
+// S3CredentialsModes maps the credential modes to their human readable names
+var S3CredentialsModes = map[string]string{
+	S3CredentialsStatic:     "Access key and secret key",
+	S3CredentialsDefault:    "Default AWS credentials chain",
+	S3CredentialsAssumeRole: "Assume IAM role",
+}
+
+// S3 server-side encryption modes
+const (
+	S3EncryptionS3       = "AES256"
+	S3EncryptionKMS      = "aws:kms"
+	S3EncryptionCustomer = "SSE-C"
+)
+
+// S3ServerSideEncryptions maps the server-side encryption modes to their
+// human readable names, the empty mode uses the default of the bucket
+var S3ServerSideEncryptions = map[string]string{
+	"":                   "Bucket default",
+	S3EncryptionS3:       "SSE-S3 (AES-256)",
+	S3EncryptionKMS:      "SSE-KMS",
+	S3EncryptionCustomer: "SSE-C (customer key)",
+}
+
+// S3StorageClasses are the storage classes that can be chosen for the
+// uploaded objects. The archive classes (GLACIER, DEEP_ARCHIVE) are not
+// offered because their objects can't be read without restoring them first
+var S3StorageClasses = []string{
+	"STANDARD", "STANDARD_IA", "ONEZONE_IA", "INTELLIGENT_TIERING", "GLACIER_IR",
+}
+
+// S3ObjectLockModes are the Object Lock retention modes
+var S3ObjectLockModes = []string{"GOVERNANCE", "COMPLIANCE"}
+
+// S3Options holds the advanced settings of S3 destinations, the zero value
+// uses the static keys, keeps the defaults of the bucket and uses path-style
+// addressing
+type S3Options struct {
+	// CredentialsMode is one of the S3Credentials* modes, static when empty
+	CredentialsMode string
+	// RoleARN is the role assumed by the assume_role mode, with the optional
+	// ExternalID required by its trust policy
+	RoleARN    string
+	ExternalID string
+
+	// ServerSideEncryption is one of the S3Encryption* modes or empty
+	ServerSideEncryption string
+	// KMSKeyID is the KMS key used by SSE-KMS, the AWS managed key when empty
+	KMSKeyID string
+	// SSECustomerKey is the base64 encoded 256-bit key used by SSE-C
+	SSECustomerKey string
+	// StorageClass is one of S3StorageClasses or empty
+	StorageClass string
+	// VirtualHostedStyle sends the bucket in the hostname instead of the path
+	VirtualHostedStyle bool
+	// ObjectLockMode is one of S3ObjectLockModes or empty, the objects are
+	// retained for ObjectLockDays
+	ObjectLockMode      string
+	ObjectLockDays      int32
+	ObjectLockLegalHold bool
+}
+
+func (o S3Options) validate() error {
+	if _, ok := S3CredentialsModes[o.credentialsMode()]; !ok {
+		return fmt.Errorf("unsupported credentials mode: %s", o.CredentialsMode)
+	}
+	if o.credentialsMode() == S3CredentialsAssumeRole &&
+		!strings.HasPrefix(o.RoleARN, "arn:") {
+		return fmt.Errorf("the role ARN is required to assume a role")
+	}
+
+	if _, ok := S3ServerSideEncryptions[o.ServerSideEncryption]; !ok {
+		return fmt.Errorf("unsupported server-side encryption: %s", o.ServerSideEncryption)
+	}
+	if o.ServerSideEncryption == S3EncryptionCustomer {
+		key, err := base64.StdEncoding.DecodeString(o.SSECustomerKey)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("the SSE-C key must be a base64 encoded 256-bit key")
+		}
+	}
+
+	if o.StorageClass != "" && !slices.Contains(S3StorageClasses, o.StorageClass) {
+		return fmt.Errorf("unsupported storage class: %s", o.StorageClass)
+	}
+
+	if o.ObjectLockMode != "" {
+		if !slices.Contains(S3ObjectLockModes, o.ObjectLockMode) {
+			return fmt.Errorf("unsupported Object Lock mode: %s", o.ObjectLockMode)
+		}
+		if o.ObjectLockDays < 1 || o.ObjectLockDays > 36500 {
+			return fmt.Errorf("the Object Lock retention must be between 1 and 36500 days")
+		}
+	}
+
+	return nil
+}
+
+// credentialsMode returns the credentials mode, static when it's not set
+func (o S3Options) credentialsMode() string {
+	if o.CredentialsMode == "" {
+		return S3CredentialsStatic
+	}
+	return o.CredentialsMode
+}
+
+// usesObjectLock reports whether the uploaded objects are locked
+func (o S3Options) usesObjectLock() bool {
+	return o.ObjectLockMode != "" || o.ObjectLockLegalHold
+}
+
+// sseCustomer returns the algorithm, key and key MD5 that must be sent in
+// every request that writes or reads an object encrypted with SSE-C, they
+// are nil when SSE-C is not used
+func (o S3Options) sseCustomer() (algorithm, key, keyMD5 *string) {
+	if o.ServerSideEncryption != S3EncryptionCustomer {
+		return nil, nil, nil
+	}
+
+	rawKey, _ := base64.StdEncoding.DecodeString(o.SSECustomerKey)
+	sum := md5.Sum(rawKey) //nolint:gosec
+	return aws.String("AES256"),
+		aws.String(o.SSECustomerKey),
+		aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// applyToPut sets the encryption, storage class and Object Lock settings
+// of an upload, the retention starts at now
+func (o S3Options) applyToPut(input *s3.PutObjectInput, now time.Time) {
+	switch o.ServerSideEncryption {
+	case S3EncryptionS3:
+		input.ServerSideEncryption = types.ServerSideEncryptionAes256
+	case S3EncryptionKMS:
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		if o.KMSKeyID != "" {
+			input.SSEKMSKeyId = aws.String(o.KMSKeyID)
+		}
+	case S3EncryptionCustomer:
+		input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 =
+			o.sseCustomer()
+	}
+
+	if o.StorageClass != "" {
+		input.StorageClass = types.StorageClass(o.StorageClass)
+	}
+
+	if o.ObjectLockMode != "" {
+		input.ObjectLockMode = types.ObjectLockMode(o.ObjectLockMode)
+		input.ObjectLockRetainUntilDate = aws.Time(
+			now.AddDate(0, 0, int(o.ObjectLockDays)),
+		)
+	}
+	if o.ObjectLockLegalHold {
+		input.ObjectLockLegalHoldStatus = types.ObjectLockLegalHoldStatusOn
+	}
+
+	// S3 requires a checksum of the content to lock objects
+	if o.usesObjectLock() {
+		input.ChecksumAlgorithm = types.ChecksumAlgorithmCrc32
+	}
+}
@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const sftpDefaultPort = "22"
+
+// sftpBackend stores the files in a server reachable through SFTP, it opens
+// a new SSH connection for every operation
+type sftpBackend struct {
+	config Config
+}
+
+func newSFTPBackend(config Config) *sftpBackend {
+	return &sftpBackend{config: config}
+}
+
+func (sftpBackend) GetStorageType() string {
+	return StorageTypeSFTP
+}
+
+// sftpAddress adds the default port to the host of a SFTP server when it
+// doesn't have one
+func sftpAddress(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), sftpDefaultPort)
+}
+
+// sshConfig returns the SSH client config of the backend, the key of the
+// server must match the host key
+func (b *sftpBackend) sshConfig() (*ssh.ClientConfig, error) {
+	auths := []ssh.AuthMethod{}
+	if b.config.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(b.config.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+	if b.config.SecretKey != "" {
+		auths = append(auths, ssh.Password(b.config.SecretKey))
+	}
+
+	if strings.TrimSpace(b.config.HostKey) == "" {
+		return nil, fmt.Errorf("the host key of the SFTP server is required")
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(b.config.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse host key: %w", err)
+	}
+
+	return &ssh.ClientConfig{
+		User:            b.config.AccessKey,
+		Auth:            auths,
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         30 * time.Second,
+	}, nil
+}
+
+// sftpSession is an open SFTP client together with its SSH connection
+type sftpSession struct {
+	*sftp.Client
+	conn *ssh.Client
+}
+
+func (s *sftpSession) Close() error {
+	_ = s.Client.Close()
+	return s.conn.Close()
+}
+
+func (b *sftpBackend) connect(ctx context.Context) (*sftpSession, error) {
+	sshConfig, err := b.sshConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	address := sftpAddress(b.config.Endpoint)
+	dialer := net.Dialer{Timeout: sshConfig.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, address, sshConfig)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, fmt.Errorf("failed to start SSH session: %w", err)
+	}
+	conn := ssh.NewClient(sshConn, chans, reqs)
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to start SFTP session: %w", err)
+	}
+
+	return &sftpSession{Client: client, conn: conn}, nil
+}
+
+// fullPath returns the path of a file in the server, relative paths are
+// resolved by the server from the home directory of the user
+func (b *sftpBackend) fullPath(filePath string) string {
+	if b.config.BasePath == "" {
+		return strutil.RemoveLeadingSlash(filePath)
+	}
+	return path.Join(b.config.BasePath, filePath)
+}
+
+// Test tests the connection to the server and the access to the base path
+func (b *sftpBackend) Test(ctx context.Context) error {
+	session, err := b.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	basePath := b.config.BasePath
+	if basePath == "" {
+		basePath = "."
+	}
+	if _, err := session.Stat(basePath); err != nil {
+		return fmt.Errorf("failed to access directory %s: %w", basePath, err)
+	}
+
+	return nil
+}
+
+// Upload uploads a file to the server from a reader.
+//
+// Returns the file size, in bytes.
+func (b *sftpBackend) Upload(
+	ctx context.Context, filePath string, reader io.Reader,
+) (int64, error) {
+	session, err := b.connect(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer session.Close()
+
+	fullPath := b.fullPath(filePath)
+	dir := path.Dir(fullPath)
+	if err := session.MkdirAll(dir); err != nil {
+		return 0, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	file, err := session.Create(fullPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file %s: %w", fullPath, err)
+	}
+	defer file.Close()
+
+	size, err := file.ReadFrom(reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write file %s: %w", fullPath, err)
+	}
+
+	return size, nil
+}
+
+// sftpFile closes the SFTP session together with the file
+type sftpFile struct {
+	*sftp.File
+	session *sftpSession
+}
+
+func (f *sftpFile) Close() error {
+	_ = f.File.Close()
+	return f.session.Close()
+}
+
+// Download returns a reader with the content of a file stored in the
+// server, the caller must close it.
+func (b *sftpBackend) Download(
+	ctx context.Context, filePath string,
+) (io.ReadCloser, error) {
+	session, err := b.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fullPath := b.fullPath(filePath)
+	file, err := session.Open(fullPath)
+	if err != nil {
+		_ = session.Close()
+		return nil, fmt.Errorf("failed to open file %s: %w", fullPath, err)
+	}
+
+	return &sftpFile{File: file, session: session}, nil
+}
+
+// Delete deletes a file from the server
+func (b *sftpBackend) Delete(ctx context.Context, filePath string) error {
+	session, err := b.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	fullPath := b.fullPath(filePath)
+	if err := session.Remove(fullPath); err != nil {
+		return fmt.Errorf("failed to delete file %s: %w", fullPath, err)
+	}
+
+	return nil
+}
@@ -1,7 +1,52 @@
 package storage
 
+import "fmt"
+
+const (
+	localBackupsDir string = "/backups"
+)
+
 type Client struct{}
 
 func New() *Client {
 	return &Client{}
 }
+
+// Local returns the backend of the local backups directory, where the
+// backups marked as local are stored
+func (Client) Local() FileSystemBackend {
+	return newLocalBackend(localBackupsDir)
+}
+
+// Backend returns the backend of a destination
+func (c Client) Backend(config Config) (StorageBackend, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch config.Type {
+	case StorageTypeS3:
+		return newS3Backend(config), nil
+	case StorageTypeSFTP:
+		return newSFTPBackend(config), nil
+	case StorageTypeWebDAV:
+		return newWebDAVBackend(config), nil
+	case StorageTypeLocal:
+		return newLocalBackend(config.BasePath), nil
+	case StorageTypeAzure:
+		return newAzureBackend(config), nil
+	case StorageTypeGCS:
+		return newGCSBackend(config), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", config.Type)
+	}
+}
+
+// BackendOrLocal returns the backend of the local backups directory when
+// isLocal is true and the backend of the destination otherwise
+func (c Client) BackendOrLocal(isLocal bool, config Config) (StorageBackend, error) {
+	if isLocal {
+		return c.Local(), nil
+	}
+	return c.Backend(config)
+}
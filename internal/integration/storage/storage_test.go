@@ -0,0 +1,491 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/webdav"
+)
+
+// testBackend uploads, downloads and deletes a file using the backend.
+func testBackend(t *testing.T, backend StorageBackend) {
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("pg back web "), 100_000)
+	filePath := "backups/2026/10/18/dump.sql.zst"
+
+	require.NoError(t, backend.Test(ctx))
+
+	// io.MultiReader hides the length of the content like a pipe does
+	size, err := backend.Upload(ctx, filePath, io.MultiReader(bytes.NewReader(content)))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), size)
+
+	reader, err := backend.Download(ctx, filePath)
+	require.NoError(t, err)
+	downloaded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, content, downloaded)
+
+	require.NoError(t, backend.Delete(ctx, filePath))
+
+	_, err = backend.Download(ctx, filePath)
+	assert.Error(t, err)
+	err = backend.Delete(ctx, filePath)
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "unexpected error: %v", err)
+}
+
+func TestLocalBackend(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := New().Backend(Config{Type: StorageTypeLocal, BasePath: dir})
+	require.NoError(t, err)
+
+	testBackend(t, backend)
+
+	fsBackend, ok := backend.(FileSystemBackend)
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "a/b.sql"), fsBackend.FullPath("/a/b.sql"))
+
+	missing, err := New().Backend(Config{
+		Type: StorageTypeLocal, BasePath: filepath.Join(dir, "missing"),
+	})
+	require.NoError(t, err)
+	assert.Error(t, missing.Test(context.Background()))
+}
+
+func TestWebDAVBackend(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "remote.php"), 0o755))
+
+	handler := &webdav.Handler{
+		FileSystem: webdav.Dir(dir),
+		LockSystem: webdav.NewMemLS(),
+	}
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			user, password, ok := r.BasicAuth()
+			if !ok || user != "pbw" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		},
+	))
+	defer server.Close()
+
+	config := Config{
+		Type:      StorageTypeWebDAV,
+		Endpoint:  server.URL + "/remote.php",
+		AccessKey: "pbw",
+		SecretKey: "secret",
+	}
+	backend, err := New().Backend(config)
+	require.NoError(t, err)
+	testBackend(t, backend)
+
+	config.SecretKey = "wrong"
+	backend, err = New().Backend(config)
+	require.NoError(t, err)
+	assert.Error(t, backend.Test(context.Background()))
+}
+
+// startSFTPServer starts a SSH server that accepts the given password and
+// serves the SFTP subsystem. It returns its address and host public key.
+func startSFTPServer(t *testing.T, password string) (string, ssh.PublicKey) {
+	_, hostPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPrivateKey)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(_ ssh.ConnMetadata, p []byte) (*ssh.Permissions, error) {
+			if string(p) != password {
+				return nil, errors.New("invalid password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config)
+		}
+	}()
+
+	return listener.Addr().String(), hostSigner.PublicKey()
+}
+
+func serveSFTP(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+
+				server, err := sftp.NewServer(channel)
+				if err != nil {
+					_ = channel.Close()
+					return
+				}
+				_ = server.Serve()
+				_ = channel.Close()
+			}
+		}()
+	}
+}
+
+func TestSFTPBackend(t *testing.T) {
+	address, hostKey := startSFTPServer(t, "secret")
+
+	config := Config{
+		Type:      StorageTypeSFTP,
+		Endpoint:  address,
+		AccessKey: "pbw",
+		SecretKey: "secret",
+		BasePath:  t.TempDir(),
+		HostKey:   string(ssh.MarshalAuthorizedKey(hostKey)),
+	}
+	backend, err := New().Backend(config)
+	require.NoError(t, err)
+	testBackend(t, backend)
+
+	t.Run("wrong host key", func(t *testing.T) {
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		otherSigner, err := ssh.NewSignerFromKey(otherKey)
+		require.NoError(t, err)
+
+		config := config
+		config.HostKey = string(ssh.MarshalAuthorizedKey(otherSigner.PublicKey()))
+		backend, err := New().Backend(config)
+		require.NoError(t, err)
+		assert.Error(t, backend.Test(context.Background()))
+	})
+
+	t.Run("empty host key", func(t *testing.T) {
+		config := config
+		config.HostKey = ""
+		_, err := New().Backend(config)
+		assert.ErrorContains(t, err, "host key is required")
+
+		backend := newSFTPBackend(config)
+		assert.ErrorContains(t, backend.Test(context.Background()), "host key")
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		config := config
+		config.SecretKey = "wrong"
+		backend, err := New().Backend(config)
+		require.NoError(t, err)
+		assert.Error(t, backend.Test(context.Background()))
+	})
+}
+
+func TestSFTPAddress(t *testing.T) {
+	assert.Equal(t, "example.com:22", sftpAddress("example.com"))
+	assert.Equal(t, "example.com:2222", sftpAddress("example.com:2222"))
+	assert.Equal(t, "[::1]:22", sftpAddress("::1"))
+	assert.Equal(t, "[::1]:22", sftpAddress("[::1]"))
+}
+
+func TestConfigValidate(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	testHostKey := string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"s3", Config{
+			Type: StorageTypeS3, BucketName: "b", Region: "r", Endpoint: "e",
+			AccessKey: "a", SecretKey: "s",
+		}, false},
+		{"s3 default credentials", Config{
+			Type: StorageTypeS3, BucketName: "b", Region: "r", Endpoint: "e",
+			S3: S3Options{CredentialsMode: S3CredentialsDefault},
+		}, false},
+		{"s3 assume role with one key", Config{
+			Type: StorageTypeS3, BucketName: "b", Region: "r", Endpoint: "e", AccessKey: "a",
+			S3: S3Options{CredentialsMode: S3CredentialsAssumeRole, RoleARN: "arn:aws:iam::1:role/r"},
+		}, true},
+		{"s3 without bucket", Config{
+			Type: StorageTypeS3, Region: "r", Endpoint: "e", AccessKey: "a", SecretKey: "s",
+		}, true},
+		{"sftp password", Config{
+			Type: StorageTypeSFTP, Endpoint: "host", AccessKey: "u", SecretKey: "p",
+			HostKey: testHostKey,
+		}, false},
+		{"sftp private key", Config{
+			Type: StorageTypeSFTP, Endpoint: "host", AccessKey: "u", PrivateKey: "k",
+			HostKey: testHostKey,
+		}, false},
+		{"sftp without credentials", Config{
+			Type: StorageTypeSFTP, Endpoint: "host", AccessKey: "u", HostKey: testHostKey,
+		}, true},
+		{"sftp without host", Config{
+			Type: StorageTypeSFTP, AccessKey: "u", SecretKey: "p", HostKey: testHostKey,
+		}, true},
+		{"sftp without host key", Config{
+			Type: StorageTypeSFTP, Endpoint: "host", AccessKey: "u", SecretKey: "p",
+		}, true},
+		{"sftp invalid host key", Config{
+			Type: StorageTypeSFTP, Endpoint: "host", AccessKey: "u", SecretKey: "p",
+			HostKey: "not a key",
+		}, true},
+		{"webdav", Config{Type: StorageTypeWebDAV, Endpoint: "https://dav.example.com"}, false},
+		{"webdav without scheme", Config{Type: StorageTypeWebDAV, Endpoint: "dav.example.com"}, true},
+		{"local", Config{Type: StorageTypeLocal, BasePath: "/mnt/nas"}, false},
+		{"local relative", Config{Type: StorageTypeLocal, BasePath: "mnt/nas"}, true},
+		{"azure", Config{
+			Type: StorageTypeAzure, BucketName: "c", AccessKey: "account", SecretKey: "key",
+		}, false},
+		{"azure without secret", Config{
+			Type: StorageTypeAzure, BucketName: "c", AccessKey: "account",
+		}, true},
+		{"azure invalid endpoint", Config{
+			Type: StorageTypeAzure, BucketName: "c", AccessKey: "account", SecretKey: "key",
+			Endpoint: "127.0.0.1:10000",
+		}, true},
+		{"gcs", Config{Type: StorageTypeGCS, BucketName: "b", PrivateKey: `{"type":"service_account"}`}, false},
+		{"gcs invalid json", Config{Type: StorageTypeGCS, BucketName: "b", PrivateKey: "key"}, true},
+		{"gcs without bucket", Config{Type: StorageTypeGCS, PrivateKey: "{}"}, true},
+		{"unknown", Config{Type: "ftp"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAzureBackend(t *testing.T) {
+	sharedKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), 64))
+	backend := newAzureBackend(Config{
+		Type: StorageTypeAzure, BucketName: "backups", AccessKey: "pbw",
+		SecretKey: sharedKey,
+	})
+	assert.False(t, backend.isSAS())
+	assert.Equal(t, "https://pbw.blob.core.windows.net/", backend.serviceURL())
+
+	link, err := backend.DownloadLink(context.Background(), "/a/b.sql", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, "https://pbw.blob.core.windows.net/backups/a%2Fb.sql?"))
+	assert.Contains(t, link, "sp=r")
+	assert.Contains(t, link, "sig=")
+
+	sasBackend := newAzureBackend(Config{
+		Type: StorageTypeAzure, BucketName: "backups", AccessKey: "devstoreaccount1",
+		SecretKey: "?sv=2022-11-02&sp=rwdl&sig=abc",
+		Endpoint:  "http://127.0.0.1:10000/devstoreaccount1/",
+	})
+	assert.True(t, sasBackend.isSAS())
+	assert.Equal(t, "http://127.0.0.1:10000/devstoreaccount1/", sasBackend.serviceURL())
+	_, err = sasBackend.DownloadLink(context.Background(), "a/b.sql", time.Hour)
+	assert.Error(t, err)
+}
+
+func TestGCSDownloadLink(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+	serviceAccount, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "pbw@project.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    "https://oauth2.googleapis.com/token",
+	})
+	require.NoError(t, err)
+
+	backend := newGCSBackend(Config{
+		Type: StorageTypeGCS, BucketName: "backups", PrivateKey: string(serviceAccount),
+	})
+	link, err := backend.DownloadLink(context.Background(), "/a/b.sql", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, "https://storage.googleapis.com/backups/a/b.sql?"))
+	assert.Contains(t, link, "X-Goog-Signature=")
+	assert.Contains(t, link, "X-Goog-Expires=")
+
+	invalid := newGCSBackend(Config{Type: StorageTypeGCS, BucketName: "b", PrivateKey: "{}"})
+	_, err = invalid.DownloadLink(context.Background(), "a/b.sql", time.Hour)
+	assert.Error(t, err)
+}
+
+func TestS3Options(t *testing.T) {
+	sseKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), 32))
+
+	t.Run("validate", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			options S3Options
+			wantErr bool
+		}{
+			{"defaults", S3Options{}, false},
+			{"sse-kms", S3Options{ServerSideEncryption: S3EncryptionKMS, KMSKeyID: "alias/backups"}, false},
+			{"sse-c", S3Options{ServerSideEncryption: S3EncryptionCustomer, SSECustomerKey: sseKey}, false},
+			{"sse-c short key", S3Options{ServerSideEncryption: S3EncryptionCustomer, SSECustomerKey: "a2V5"}, true},
+			{"unknown sse", S3Options{ServerSideEncryption: "aws:kms:dsse"}, true},
+			{"storage class", S3Options{StorageClass: "GLACIER_IR"}, false},
+			{"archive storage class", S3Options{StorageClass: "DEEP_ARCHIVE"}, true},
+			{"object lock", S3Options{ObjectLockMode: "COMPLIANCE", ObjectLockDays: 30}, false},
+			{"object lock without days", S3Options{ObjectLockMode: "GOVERNANCE"}, true},
+			{"unknown object lock mode", S3Options{ObjectLockMode: "FOREVER", ObjectLockDays: 1}, true},
+			{"legal hold", S3Options{ObjectLockLegalHold: true}, false},
+			{"default credentials", S3Options{CredentialsMode: S3CredentialsDefault}, false},
+			{"assume role", S3Options{
+				CredentialsMode: S3CredentialsAssumeRole, RoleARN: "arn:aws:iam::111122223333:role/backups",
+			}, false},
+			{"assume role without arn", S3Options{CredentialsMode: S3CredentialsAssumeRole}, true},
+			{"unknown credentials mode", S3Options{CredentialsMode: "sso"}, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := tt.options.validate()
+				if tt.wantErr {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			})
+		}
+	})
+
+	t.Run("apply to put", func(t *testing.T) {
+		now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+		input := &s3.PutObjectInput{}
+		S3Options{}.applyToPut(input, now)
+		assert.Equal(t, &s3.PutObjectInput{}, input)
+
+		input = &s3.PutObjectInput{}
+		S3Options{
+			ServerSideEncryption: S3EncryptionKMS, KMSKeyID: "alias/backups",
+			StorageClass: "STANDARD_IA", ObjectLockMode: "GOVERNANCE",
+			ObjectLockDays: 7, ObjectLockLegalHold: true,
+		}.applyToPut(input, now)
+		assert.Equal(t, s3types.ServerSideEncryptionAwsKms, input.ServerSideEncryption)
+		assert.Equal(t, "alias/backups", *input.SSEKMSKeyId)
+		assert.Equal(t, s3types.StorageClassStandardIa, input.StorageClass)
+		assert.Equal(t, s3types.ObjectLockModeGovernance, input.ObjectLockMode)
+		assert.Equal(t, now.AddDate(0, 0, 7), *input.ObjectLockRetainUntilDate)
+		assert.Equal(t, s3types.ObjectLockLegalHoldStatusOn, input.ObjectLockLegalHoldStatus)
+		assert.Equal(t, s3types.ChecksumAlgorithmCrc32, input.ChecksumAlgorithm)
+
+		input = &s3.PutObjectInput{}
+		S3Options{
+			ServerSideEncryption: S3EncryptionCustomer, SSECustomerKey: sseKey,
+		}.applyToPut(input, now)
+		assert.Equal(t, "AES256", *input.SSECustomerAlgorithm)
+		assert.Equal(t, sseKey, *input.SSECustomerKey)
+		assert.NotEmpty(t, *input.SSECustomerKeyMD5)
+	})
+}
+
+func TestS3DownloadLink(t *testing.T) {
+	config := Config{
+		Type: StorageTypeS3, BucketName: "my-bucket", Region: "us-west-1",
+		Endpoint: "https://s3.us-west-1.amazonaws.com", AccessKey: "a", SecretKey: "s",
+	}
+
+	link, err := newS3Backend(config).DownloadLink(context.Background(), "a/b.sql", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, "https://s3.us-west-1.amazonaws.com/my-bucket/a/b.sql?"))
+
+	config.S3.VirtualHostedStyle = true
+	link, err = newS3Backend(config).DownloadLink(context.Background(), "a/b.sql", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, "https://my-bucket.s3.us-west-1.amazonaws.com/a/b.sql?"))
+
+	config.S3.ServerSideEncryption = S3EncryptionCustomer
+	_, err = newS3Backend(config).DownloadLink(context.Background(), "a/b.sql", time.Hour)
+	assert.Error(t, err)
+}
+
+func TestS3Credentials(t *testing.T) {
+	config := Config{
+		Type: StorageTypeS3, BucketName: "my-bucket", Region: "us-west-1",
+		Endpoint: "https://s3.us-west-1.amazonaws.com", AccessKey: "a", SecretKey: "s",
+	}
+
+	client, err := newS3Backend(config).client(context.Background())
+	require.NoError(t, err)
+	creds, err := client.Options().Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "a", creds.AccessKeyID)
+
+	config.S3 = S3Options{
+		CredentialsMode: S3CredentialsAssumeRole,
+		RoleARN:         "arn:aws:iam::111122223333:role/backups",
+		ExternalID:      "pgbackweb",
+	}
+	client, err = newS3Backend(config).client(context.Background())
+	require.NoError(t, err)
+	cache, ok := client.Options().Credentials.(*aws.CredentialsCache)
+	require.True(t, ok)
+	assert.True(t, cache.IsCredentialsProvider(&stscreds.AssumeRoleProvider{}))
+}
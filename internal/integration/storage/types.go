@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Storage type constants
+const (
+	StorageTypeS3     = "s3"
+	StorageTypeSFTP   = "sftp"
+	StorageTypeWebDAV = "webdav"
+	StorageTypeLocal  = "local"
+	StorageTypeAzure  = "azure"
+	StorageTypeGCS    = "gcs"
+)
+
+// StorageTypes maps the storage types to their human readable names
+var StorageTypes = map[string]string{
+	StorageTypeS3:     "S3 compatible",
+	StorageTypeSFTP:   "SFTP",
+	StorageTypeWebDAV: "WebDAV",
+	StorageTypeLocal:  "Local directory",
+	StorageTypeAzure:  "Azure Blob Storage",
+	StorageTypeGCS:    "Google Cloud Storage",
+}
+
+// SupportedStorageTypes returns the storage types in the order they are
+// offered to the user
+func SupportedStorageTypes() []string {
+	return []string{
+		StorageTypeS3, StorageTypeAzure, StorageTypeGCS, StorageTypeSFTP,
+		StorageTypeWebDAV, StorageTypeLocal,
+	}
+}
+
+// Config holds the settings of a destination, every storage type uses only
+// some of them:
+//
+//   - s3: BucketName, Region, Endpoint, AccessKey and SecretKey (optional
+//     when the credentials don't come from them) and S3
+//   - sftp: Endpoint (host[:port]), AccessKey (username), SecretKey
+//     (password), PrivateKey, HostKey and BasePath
+//   - webdav: Endpoint (URL), AccessKey (username), SecretKey (password) and
+//     BasePath
+//   - local: BasePath, an absolute directory of this server
+//   - azure: BucketName (container), AccessKey (storage account name),
+//     SecretKey (shared key or SAS token) and Endpoint (optional)
+//   - gcs: BucketName, PrivateKey (service account JSON) and Endpoint
+//     (optional)
+type Config struct {
+	Type       string
+	BucketName string
+	Region     string
+	Endpoint   string
+	AccessKey  string
+	SecretKey  string
+	BasePath   string
+	PrivateKey string
+	HostKey    string
+	S3         S3Options
+}
+
+// Validate checks that the settings required by the storage type are set
+func (c Config) Validate() error {
+	// required receives pairs of setting names and values
+	required := func(pairs ...string) error {
+		for i := 0; i < len(pairs); i += 2 {
+			if strings.TrimSpace(pairs[i+1]) == "" {
+				return fmt.Errorf(
+					"%s is required for %s destinations", pairs[i], StorageTypes[c.Type],
+				)
+			}
+		}
+		return nil
+	}
+
+	switch c.Type {
+	case StorageTypeS3:
+		if err := required(
+			"bucket name", c.BucketName,
+			"region", c.Region,
+			"endpoint", c.Endpoint,
+		); err != nil {
+			return err
+		}
+		switch c.S3.credentialsMode() {
+		case S3CredentialsStatic:
+			if err := required(
+				"access key", c.AccessKey,
+				"secret key", c.SecretKey,
+			); err != nil {
+				return err
+			}
+		case S3CredentialsAssumeRole:
+			if (c.AccessKey == "") != (c.SecretKey == "") {
+				return fmt.Errorf("set both the access key and the secret key or none of them")
+			}
+		}
+		return c.S3.validate()
+
+	case StorageTypeSFTP:
+		if err := required(
+			"host", c.Endpoint,
+			"username", c.AccessKey,
+			"host key", c.HostKey,
+		); err != nil {
+			return err
+		}
+		if c.SecretKey == "" && c.PrivateKey == "" {
+			return fmt.Errorf("a password or a private key is required for SFTP destinations")
+		}
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(c.HostKey)); err != nil {
+			return fmt.Errorf("the host key must be in authorized_keys format: %w", err)
+		}
+		return nil
+
+	case StorageTypeWebDAV:
+		if !strings.HasPrefix(c.Endpoint, "http://") &&
+			!strings.HasPrefix(c.Endpoint, "https://") {
+			return fmt.Errorf("the URL of WebDAV destinations must start with http:// or https://")
+		}
+		return nil
+
+	case StorageTypeLocal:
+		if !filepath.IsAbs(c.BasePath) {
+			return fmt.Errorf("the directory of local destinations must be an absolute path")
+		}
+		return nil
+
+	case StorageTypeAzure:
+		if err := required(
+			"container", c.BucketName,
+			"storage account name", c.AccessKey,
+			"shared key or SAS token", c.SecretKey,
+		); err != nil {
+			return err
+		}
+		return validateOptionalURL(c.Endpoint)
+
+	case StorageTypeGCS:
+		if err := required(
+			"bucket name", c.BucketName,
+			"service account JSON", c.PrivateKey,
+		); err != nil {
+			return err
+		}
+		if !json.Valid([]byte(c.PrivateKey)) {
+			return fmt.Errorf("the service account key must be a valid JSON")
+		}
+		return validateOptionalURL(c.Endpoint)
+
+	default:
+		return fmt.Errorf("unsupported storage type: %s", c.Type)
+	}
+}
+
+// validateOptionalURL checks that the endpoint, when set, is a http(s) URL
+func validateOptionalURL(endpoint string) error {
+	if endpoint == "" ||
+		strings.HasPrefix(endpoint, "http://") ||
+		strings.HasPrefix(endpoint, "https://") {
+		return nil
+	}
+	return fmt.Errorf("the endpoint must start with http:// or https://")
+}
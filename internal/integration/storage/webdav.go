@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// webdavBackend stores the files in a WebDAV server (Nextcloud, ownCloud,
+// Apache mod_dav, rclone serve webdav, etc.)
+type webdavBackend struct {
+	config Config
+	client *http.Client
+}
+
+func newWebDAVBackend(config Config) *webdavBackend {
+	return &webdavBackend{config: config, client: http.DefaultClient}
+}
+
+func (webdavBackend) GetStorageType() string {
+	return StorageTypeWebDAV
+}
+
+// url returns the URL of a file or directory, the path is relative to the
+// base path of the backend
+func (b *webdavBackend) url(filePath string) (string, error) {
+	u, err := url.Parse(b.config.Endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid WebDAV URL: %w", err)
+	}
+	u.Path = path.Join("/", u.Path, b.config.BasePath, filePath)
+	if strings.HasSuffix(filePath, "/") {
+		u.Path += "/"
+	}
+	return u.String(), nil
+}
+
+// do sends a request to the server, 404 responses are returned as
+// fs.ErrNotExist and the rest of the unexpected status codes as errors
+func (b *webdavBackend) do(
+	ctx context.Context, method, filePath string, body io.Reader,
+	expectedStatus ...int,
+) (*http.Response, error) {
+	u, err := b.url(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if b.config.AccessKey != "" || b.config.SecretKey != "" {
+		req.SetBasicAuth(b.config.AccessKey, b.config.SecretKey)
+	}
+	if method == "PROPFIND" {
+		req.Header.Set("Depth", "0")
+	}
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	for _, status := range expectedStatus {
+		if res.StatusCode == status {
+			return res, nil
+		}
+	}
+
+	_ = res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s %s: %w", method, filePath, fs.ErrNotExist)
+	}
+	return nil, fmt.Errorf("%s %s: unexpected status %s", method, filePath, res.Status)
+}
+
+// mkdirAll creates a directory and its missing parents, the directories that
+// already exist are skipped
+func (b *webdavBackend) mkdirAll(ctx context.Context, dir string) error {
+	current := ""
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if part == "" || part == "." {
+			continue
+		}
+		current += "/" + part
+
+		res, err := b.do(
+			ctx, "MKCOL", current+"/", nil,
+			// 405 Method Not Allowed is returned when the directory exists
+			http.StatusCreated, http.StatusMethodNotAllowed,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", current, err)
+		}
+		_ = res.Body.Close()
+	}
+	return nil
+}
+
+// Test tests the connection to the server and the access to the base path
+func (b *webdavBackend) Test(ctx context.Context) error {
+	res, err := b.do(ctx, "PROPFIND", "/", nil, http.StatusMultiStatus)
+	if err != nil {
+		return fmt.Errorf("failed to test WebDAV server: %w", err)
+	}
+	return res.Body.Close()
+}
+
+// Upload uploads a file to the server from a reader, the content is streamed
+// without buffering it.
+//
+// Returns the file size, in bytes.
+func (b *webdavBackend) Upload(
+	ctx context.Context, filePath string, reader io.Reader,
+) (int64, error) {
+	if err := b.mkdirAll(ctx, path.Dir(path.Join("/", filePath))); err != nil {
+		return 0, err
+	}
+
+	counter := &countingReader{reader: reader}
+	res, err := b.do(
+		ctx, http.MethodPut, filePath, counter,
+		http.StatusOK, http.StatusCreated, http.StatusNoContent,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upload file to WebDAV: %w", err)
+	}
+	_ = res.Body.Close()
+
+	return counter.n, nil
+}
+
+// Download returns a reader with the content of a file stored in the
+// server, the caller must close it.
+func (b *webdavBackend) Download(
+	ctx context.Context, filePath string,
+) (io.ReadCloser, error) {
+	res, err := b.do(ctx, http.MethodGet, filePath, nil, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file from WebDAV: %w", err)
+	}
+	return res.Body, nil
+}
+
+// Delete deletes a file from the server
+func (b *webdavBackend) Delete(ctx context.Context, filePath string) error {
+	res, err := b.do(
+		ctx, http.MethodDelete, filePath, nil,
+		http.StatusOK, http.StatusNoContent, http.StatusAccepted,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete file from WebDAV: %w", err)
+	}
+	return res.Body.Close()
+}
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
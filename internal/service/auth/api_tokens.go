@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// apiTokenPrefix makes the API tokens easy to recognize, e.g. by
+	// secret scanners.
+	apiTokenPrefix = "pbw_"
+
+	APITokenScopeReadOnly   = "read_only"
+	APITokenScopeRunBackups = "run_backups"
+	APITokenScopeAdmin      = "admin"
+)
+
+// APITokenScopes maps every API token scope to its human readable name.
+var APITokenScopes = map[string]string{
+	APITokenScopeReadOnly:   "Read only",
+	APITokenScopeRunBackups: "Run backups",
+	APITokenScopeAdmin:      "Admin",
+}
+
+// generateAPIToken returns a new token for the given token ID and the
+// secret part of it, which is the only part that gets hashed and stored.
+//
+// The token has the format pbw_<id>_<secret> so the row can be found by
+// ID before comparing the bcrypt hash.
+func generateAPIToken(tokenID uuid.UUID) (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	secret := hex.EncodeToString(b)
+
+	id := strings.ReplaceAll(tokenID.String(), "-", "")
+	return apiTokenPrefix + id + "_" + secret, secret, nil
+}
+
+// parseAPIToken splits a token created by generateAPIToken into its ID
+// and secret parts.
+func parseAPIToken(token string) (uuid.UUID, string, error) {
+	rest, ok := strings.CutPrefix(token, apiTokenPrefix)
+	if !ok {
+		return uuid.Nil, "", fmt.Errorf("invalid api token format")
+	}
+
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || secret == "" {
+		return uuid.Nil, "", fmt.Errorf("invalid api token format")
+	}
+
+	tokenID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("invalid api token format")
+	}
+
+	return tokenID, secret, nil
+}
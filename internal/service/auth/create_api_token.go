@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/util/cryptoutil"
+	"github.com/google/uuid"
+)
+
+type CreateAPITokenParams struct {
+	UserID    uuid.UUID
+	Name      string
+	Scope     string
+	ExpiresAt sql.NullTime
+}
+
+// CreateAPIToken creates a new API token for the given user.
+//
+// Returns the plain token, which is not stored anywhere and must be shown
+// to the user only once.
+func (s *Service) CreateAPIToken(
+	ctx context.Context, params CreateAPITokenParams,
+) (string, dbgen.ApiToken, error) {
+	if _, ok := APITokenScopes[params.Scope]; !ok {
+		return "", dbgen.ApiToken{}, fmt.Errorf("invalid api token scope %q", params.Scope)
+	}
+
+	tokenID := uuid.New()
+	token, secret, err := generateAPIToken(tokenID)
+	if err != nil {
+		return "", dbgen.ApiToken{}, err
+	}
+
+	hash, err := cryptoutil.CreateBcryptHash(secret)
+	if err != nil {
+		return "", dbgen.ApiToken{}, err
+	}
+
+	apiToken, err := s.dbgen.AuthServiceCreateAPIToken(
+		ctx, dbgen.AuthServiceCreateAPITokenParams{
+			ID:        tokenID,
+			UserID:    params.UserID,
+			Name:      params.Name,
+			TokenHash: hash,
+			Scope:     params.Scope,
+			ExpiresAt: params.ExpiresAt,
+		},
+	)
+	if err != nil {
+		return "", dbgen.ApiToken{}, err
+	}
+
+	return token, apiToken, nil
+}
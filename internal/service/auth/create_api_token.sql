@@ -0,0 +1,4 @@
+-- name: AuthServiceCreateAPIToken :one
+INSERT INTO api_tokens (id, user_id, name, token_hash, scope, expires_at)
+VALUES (@id, @user_id, @name, @token_hash, @scope, @expires_at)
+RETURNING *;
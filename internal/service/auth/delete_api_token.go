@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/google/uuid"
+)
+
+// DeleteAPIToken revokes an API token, only if it belongs to the given user.
+func (s *Service) DeleteAPIToken(
+	ctx context.Context, userID, tokenID uuid.UUID,
+) error {
+	return s.dbgen.AuthServiceDeleteAPIToken(
+		ctx, dbgen.AuthServiceDeleteAPITokenParams{
+			ID:     tokenID,
+			UserID: userID,
+		},
+	)
+}
@@ -0,0 +1,2 @@
+-- name: AuthServiceDeleteAPIToken :exec
+DELETE FROM api_tokens WHERE id = @id AND user_id = @user_id;
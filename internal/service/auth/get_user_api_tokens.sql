@@ -0,0 +1,2 @@
+-- name: AuthServiceGetUserAPITokens :many
+SELECT * FROM api_tokens WHERE user_id = @user_id ORDER BY created_at DESC;
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/util/cryptoutil"
+)
+
+// GetUserByAPIToken returns the owner of the given API token, it returns
+// false if the token is malformed, unknown, expired or revoked.
+func (s *Service) GetUserByAPIToken(
+	ctx context.Context, token string,
+) (bool, dbgen.AuthServiceGetUserByAPITokenRow, error) {
+	tokenID, secret, err := parseAPIToken(token)
+	if err != nil {
+		return false, dbgen.AuthServiceGetUserByAPITokenRow{}, nil
+	}
+
+	user, err := s.dbgen.AuthServiceGetUserByAPIToken(ctx, tokenID)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return false, user, nil
+	}
+	if err != nil {
+		return false, user, err
+	}
+
+	if err := cryptoutil.VerifyBcryptHash(secret, user.ApiTokenHash); err != nil {
+		return false, dbgen.AuthServiceGetUserByAPITokenRow{}, nil
+	}
+
+	if err := s.dbgen.AuthServiceSetAPITokenLastUsed(ctx, tokenID); err != nil {
+		return false, user, err
+	}
+
+	return true, user, nil
+}
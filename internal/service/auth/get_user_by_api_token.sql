@@ -0,0 +1,14 @@
+-- name: AuthServiceGetUserByAPIToken :one
+SELECT
+  api_tokens.id AS api_token_id,
+  api_tokens.token_hash AS api_token_hash,
+  api_tokens.scope AS api_token_scope,
+  users.*
+FROM api_tokens
+INNER JOIN users ON users.id = api_tokens.user_id
+WHERE api_tokens.id = @api_token_id
+AND users.is_active = true
+AND (api_tokens.expires_at IS NULL OR api_tokens.expires_at > NOW());
+
+-- name: AuthServiceSetAPITokenLastUsed :exec
+UPDATE api_tokens SET last_used_at = NOW() WHERE id = @id;
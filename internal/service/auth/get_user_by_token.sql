@@ -4,4 +4,5 @@ SELECT
   sessions.id as session_id
 FROM sessions
 JOIN users ON users.id = sessions.user_id
-WHERE pgp_sym_decrypt(sessions.token, @encryption_key) = @token::TEXT;
+WHERE pgp_sym_decrypt(sessions.token, @encryption_key) = @token::TEXT
+AND users.is_active = true;
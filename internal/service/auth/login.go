@@ -21,6 +21,10 @@ func (s *Service) Login(
 		return dbgen.AuthServiceLoginCreateSessionRow{}, fmt.Errorf("invalid password")
 	}
 
+	if !user.IsActive {
+		return dbgen.AuthServiceLoginCreateSessionRow{}, fmt.Errorf("user is disabled")
+	}
+
 	session, err := s.dbgen.AuthServiceLoginCreateSession(
 		ctx, dbgen.AuthServiceLoginCreateSessionParams{
 			UserID:        user.ID,
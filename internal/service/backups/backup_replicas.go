@@ -0,0 +1,125 @@
+package backups
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/google/uuid"
+)
+
+// ReplicaLocal is the value that selects the local backups directory as a
+// replica, the rest of the values are destination IDs.
+const ReplicaLocal = "local"
+
+// Replica is an additional storage where every execution of a backup is
+// copied, next to the destination of the backup itself.
+type Replica struct {
+	IsLocal       bool
+	DestinationID uuid.NullUUID
+}
+
+// ParseReplicas parses the replicas selected in the forms and the API,
+// either ReplicaLocal or destination IDs. Duplicated values are ignored.
+func ParseReplicas(values []string) ([]Replica, error) {
+	replicas := []Replica{}
+	seen := map[string]bool{}
+
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+
+		if value == ReplicaLocal {
+			replicas = append(replicas, Replica{IsLocal: true})
+			continue
+		}
+
+		destinationID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replica %q", value)
+		}
+		replicas = append(replicas, Replica{
+			DestinationID: uuid.NullUUID{Valid: true, UUID: destinationID},
+		})
+	}
+
+	return replicas, nil
+}
+
+// ReplicaValues returns the values that select the given replicas, the
+// inverse of ParseReplicas.
+func ReplicaValues(replicas []dbgen.BackupReplica) []string {
+	values := make([]string, 0, len(replicas))
+	for _, replica := range replicas {
+		if replica.IsLocal {
+			values = append(values, ReplicaLocal)
+			continue
+		}
+		values = append(values, replica.DestinationID.UUID.String())
+	}
+	return values
+}
+
+// ValidateReplicas checks that no replica is the storage of the backup
+// itself.
+func ValidateReplicas(
+	isLocal bool, destinationID uuid.NullUUID, replicas []Replica,
+) error {
+	for _, replica := range replicas {
+		if replica.IsLocal && isLocal {
+			return fmt.Errorf(
+				"the local directory is already the destination of the backup",
+			)
+		}
+		if !replica.IsLocal && destinationID.Valid &&
+			replica.DestinationID.UUID == destinationID.UUID {
+			return fmt.Errorf(
+				"the destination of the backup can't be a replica too",
+			)
+		}
+	}
+	return nil
+}
+
+// GetBackupReplicas returns the replicas of a backup.
+func (s *Service) GetBackupReplicas(
+	ctx context.Context, backupID uuid.UUID,
+) ([]dbgen.BackupReplica, error) {
+	return s.dbgen.BackupsServiceGetBackupReplicas(ctx, backupID)
+}
+
+// SetBackupReplicas replaces the replicas of a backup.
+func (s *Service) SetBackupReplicas(
+	ctx context.Context, backupID uuid.UUID, replicas []Replica,
+) error {
+	backup, err := s.dbgen.BackupsServiceGetBackup(ctx, backupID)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateReplicas(backup.IsLocal, backup.DestinationID, replicas)
+	if err != nil {
+		return err
+	}
+
+	if err := s.dbgen.BackupsServiceDeleteBackupReplicas(ctx, backupID); err != nil {
+		return err
+	}
+
+	for _, replica := range replicas {
+		err := s.dbgen.BackupsServiceCreateBackupReplica(
+			ctx, dbgen.BackupsServiceCreateBackupReplicaParams{
+				BackupID:      backupID,
+				DestinationID: replica.DestinationID,
+				IsLocal:       replica.IsLocal,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+-- name: BackupsServiceGetBackupReplicas :many
+SELECT * FROM backup_replicas
+WHERE backup_id = @backup_id
+ORDER BY is_local DESC, created_at ASC;
+
+-- name: BackupsServiceDeleteBackupReplicas :exec
+DELETE FROM backup_replicas
+WHERE backup_id = @backup_id;
+
+-- name: BackupsServiceCreateBackupReplica :exec
+INSERT INTO backup_replicas (backup_id, destination_id, is_local)
+VALUES (@backup_id, sqlc.narg('destination_id'), @is_local);
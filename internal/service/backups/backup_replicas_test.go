@@ -0,0 +1,52 @@
+package backups
+
+import (
+	"testing"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReplicas(t *testing.T) {
+	id := uuid.MustParse("c0d6a0f5-8a53-4ad2-a4a1-6a5f2b7e7c11")
+
+	replicas, err := ParseReplicas([]string{
+		ReplicaLocal, id.String(), "", id.String(), ReplicaLocal,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Replica{
+		{IsLocal: true},
+		{DestinationID: uuid.NullUUID{Valid: true, UUID: id}},
+	}, replicas)
+
+	replicas, err = ParseReplicas(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, replicas)
+
+	_, err = ParseReplicas([]string{"s3"})
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{ReplicaLocal, id.String()}, ReplicaValues([]dbgen.BackupReplica{
+		{IsLocal: true},
+		{DestinationID: uuid.NullUUID{Valid: true, UUID: id}},
+	}))
+}
+
+func TestValidateReplicas(t *testing.T) {
+	id := uuid.NullUUID{Valid: true, UUID: uuid.New()}
+	other := uuid.NullUUID{Valid: true, UUID: uuid.New()}
+
+	assert.NoError(t, ValidateReplicas(true, uuid.NullUUID{}, []Replica{
+		{DestinationID: id},
+	}))
+	assert.NoError(t, ValidateReplicas(false, id, []Replica{
+		{IsLocal: true}, {DestinationID: other},
+	}))
+	assert.Error(t, ValidateReplicas(true, uuid.NullUUID{}, []Replica{
+		{IsLocal: true},
+	}))
+	assert.Error(t, ValidateReplicas(false, id, []Replica{
+		{DestinationID: id},
+	}))
+}
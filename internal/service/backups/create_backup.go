@@ -5,6 +5,10 @@ import (
 	"fmt"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
 	"github.com/eduardolat/pgbackweb/internal/validate"
 )
 
@@ -15,6 +19,61 @@ func (s *Service) CreateBackup(
 		return dbgen.Backup{}, fmt.Errorf("invalid cron expression")
 	}
 
+	err := postgres.ValidateDumpFormat(params.OptFormat, int(params.OptJobs))
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	err = postgres.ValidateDumpSelection(postgres.DumpParams{
+		Schemas:          params.OptSchemas,
+		ExcludeSchemas:   params.OptExcludeSchemas,
+		Tables:           params.OptTables,
+		ExcludeTables:    params.OptExcludeTables,
+		ExcludeTableData: params.OptExcludeTableData,
+		Role:             params.OptRole,
+	})
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	err = compression.Validate(params.Compression, int(params.CompressionLevel))
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	dbType, err := s.dbgen.BackupsServiceGetDatabaseType(ctx, params.DatabaseID)
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+	err = postgres.ValidateBackupMode(params.Mode, dbType, params.Compression)
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	err = executions.RetentionPolicyFromBackup(dbgen.Backup{
+		RetentionDays:        params.RetentionDays,
+		RetentionKeepLast:    params.RetentionKeepLast,
+		RetentionKeepDaily:   params.RetentionKeepDaily,
+		RetentionKeepWeekly:  params.RetentionKeepWeekly,
+		RetentionKeepMonthly: params.RetentionKeepMonthly,
+		RetentionKeepYearly:  params.RetentionKeepYearly,
+		RetentionMinKeep:     params.RetentionMinKeep,
+	}).Validate()
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	if params.Hooks == "" {
+		params.Hooks = string(hooks.Hooks{}.Marshal())
+	}
+	backupHooks, err := hooks.Parse([]byte(params.Hooks))
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+	if err := s.executionsService.ValidateHooks(backupHooks); err != nil {
+		return dbgen.Backup{}, err
+	}
+
 	backup, err := s.dbgen.BackupsServiceCreateBackup(ctx, params)
 	if err != nil {
 		return backup, err
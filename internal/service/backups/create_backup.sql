@@ -2,11 +2,30 @@
 INSERT INTO backups (
   database_id, destination_id, is_local, name, cron_expression, time_zone,
   is_active, dest_dir, retention_days, opt_data_only, opt_schema_only,
-  opt_clean, opt_if_exists, opt_create, opt_no_comments
+  opt_clean, opt_if_exists, opt_create, opt_no_comments, opt_format, opt_jobs,
+  compression, compression_level, retention_keep_last, retention_keep_daily,
+  retention_keep_weekly, retention_keep_monthly, retention_keep_yearly,
+  retention_min_keep, mode, max_duration_minutes, priority,
+  retry_max_attempts, retry_backoff_seconds, hooks, opt_schemas,
+  opt_exclude_schemas, opt_tables, opt_exclude_tables, opt_exclude_table_data,
+  opt_no_owner, opt_no_privileges, opt_role
 )
 VALUES (
   @database_id, @destination_id, @is_local, @name, @cron_expression, @time_zone,
   @is_active, @dest_dir, @retention_days, @opt_data_only, @opt_schema_only,
-  @opt_clean, @opt_if_exists, @opt_create, @opt_no_comments
+  @opt_clean, @opt_if_exists, @opt_create, @opt_no_comments, @opt_format,
+  @opt_jobs, @compression, @compression_level, @retention_keep_last,
+  @retention_keep_daily, @retention_keep_weekly, @retention_keep_monthly,
+  @retention_keep_yearly, @retention_min_keep, @mode,
+  @max_duration_minutes, @priority,
+  NULLIF(sqlc.narg('retry_max_attempts')::SMALLINT, 0),
+  NULLIF(sqlc.narg('retry_backoff_seconds')::INTEGER, 0),
+  @hooks::TEXT::JSONB, @opt_schemas,
+  @opt_exclude_schemas, @opt_tables, @opt_exclude_tables,
+  @opt_exclude_table_data, @opt_no_owner, @opt_no_privileges, @opt_role
 )
 RETURNING *;
+
+-- name: BackupsServiceGetDatabaseType :one
+SELECT database_type FROM databases
+WHERE id = @database_id;
@@ -10,5 +10,15 @@ import (
 func (s *Service) DuplicateBackup(
 	ctx context.Context, backupID uuid.UUID,
 ) (dbgen.Backup, error) {
-	return s.dbgen.BackupsServiceDuplicateBackup(ctx, backupID)
+	backup, err := s.dbgen.BackupsServiceDuplicateBackup(ctx, backupID)
+	if err != nil {
+		return backup, err
+	}
+
+	return backup, s.dbgen.BackupsServiceDuplicateBackupReplicas(
+		ctx, dbgen.BackupsServiceDuplicateBackupReplicasParams{
+			BackupID:    backupID,
+			NewBackupID: backup.ID,
+		},
+	)
 }
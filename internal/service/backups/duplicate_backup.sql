@@ -11,3 +11,9 @@ SELECT (
 FROM backups
 WHERE backups.id = @backup_id
 RETURNING *;
+
+-- name: BackupsServiceDuplicateBackupReplicas :exec
+INSERT INTO backup_replicas (backup_id, destination_id, is_local)
+SELECT @new_backup_id, destination_id, is_local
+FROM backup_replicas
+WHERE backup_id = @backup_id;
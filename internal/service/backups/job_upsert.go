@@ -1,16 +1,13 @@
 package backups
 
-import (
-	"context"
-
-	"github.com/google/uuid"
-)
+import "github.com/google/uuid"
 
+// jobUpsert schedules the backup, every run is added to the jobs queue.
 func (s *Service) jobUpsert(
 	backupID uuid.UUID, timeZone string, cronExpression string,
 ) error {
 	return s.cr.UpsertJob(
 		backupID, timeZone, cronExpression,
-		s.executionsService.RunExecution, context.Background(), backupID,
+		s.jobsService.EnqueueScheduledJob, backupID,
 	)
 }
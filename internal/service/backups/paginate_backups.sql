@@ -5,7 +5,17 @@ SELECT COUNT(*) FROM backups;
 SELECT
   backups.*,
   databases.name AS database_name,
-  destinations.name AS destination_name
+  destinations.name AS destination_name,
+  COALESCE((
+    SELECT string_agg(
+      CASE WHEN backup_replicas.is_local THEN 'Local' ELSE replica_destinations.name END,
+      ', ' ORDER BY backup_replicas.is_local DESC, replica_destinations.name ASC
+    )
+    FROM backup_replicas
+    LEFT JOIN destinations replica_destinations
+      ON replica_destinations.id = backup_replicas.destination_id
+    WHERE backup_replicas.backup_id = backups.id
+  ), '')::TEXT AS replica_names
 FROM backups
 INNER JOIN databases ON backups.database_id = databases.id
 LEFT JOIN destinations ON backups.destination_id = destinations.id
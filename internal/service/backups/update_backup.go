@@ -5,6 +5,10 @@ import (
 	"fmt"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
 	"github.com/eduardolat/pgbackweb/internal/validate"
 )
 
@@ -15,6 +19,76 @@ func (s *Service) UpdateBackup(
 		return dbgen.Backup{}, fmt.Errorf("invalid cron expression")
 	}
 
+	if params.OptFormat.Valid && params.OptJobs.Valid {
+		err := postgres.ValidateDumpFormat(
+			params.OptFormat.String, int(params.OptJobs.Int16),
+		)
+		if err != nil {
+			return dbgen.Backup{}, err
+		}
+	}
+
+	err := postgres.ValidateDumpSelection(postgres.DumpParams{
+		Schemas:          params.OptSchemas,
+		ExcludeSchemas:   params.OptExcludeSchemas,
+		Tables:           params.OptTables,
+		ExcludeTables:    params.OptExcludeTables,
+		ExcludeTableData: params.OptExcludeTableData,
+		Role:             params.OptRole.String,
+	})
+	if err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	if params.Compression.Valid && params.CompressionLevel.Valid {
+		err := compression.Validate(
+			params.Compression.String, int(params.CompressionLevel.Int16),
+		)
+		if err != nil {
+			return dbgen.Backup{}, err
+		}
+	}
+
+	if params.Compression.Valid {
+		current, err := s.dbgen.BackupsServiceGetBackup(ctx, params.ID)
+		if err != nil {
+			return dbgen.Backup{}, err
+		}
+		dbType, err := s.dbgen.BackupsServiceGetDatabaseType(ctx, current.DatabaseID)
+		if err != nil {
+			return dbgen.Backup{}, err
+		}
+		err = postgres.ValidateBackupMode(
+			current.Mode, dbType, params.Compression.String,
+		)
+		if err != nil {
+			return dbgen.Backup{}, err
+		}
+	}
+
+	retentionPolicy := executions.RetentionPolicy{
+		Days:    int(params.RetentionDays.Int16),
+		Last:    int(params.RetentionKeepLast.Int16),
+		Daily:   int(params.RetentionKeepDaily.Int16),
+		Weekly:  int(params.RetentionKeepWeekly.Int16),
+		Monthly: int(params.RetentionKeepMonthly.Int16),
+		Yearly:  int(params.RetentionKeepYearly.Int16),
+		MinKeep: int(params.RetentionMinKeep.Int16),
+	}
+	if err := retentionPolicy.Validate(); err != nil {
+		return dbgen.Backup{}, err
+	}
+
+	if params.Hooks.Valid {
+		backupHooks, err := hooks.Parse([]byte(params.Hooks.String))
+		if err != nil {
+			return dbgen.Backup{}, err
+		}
+		if err := s.executionsService.ValidateHooks(backupHooks); err != nil {
+			return dbgen.Backup{}, err
+		}
+	}
+
 	backup, err := s.dbgen.BackupsServiceUpdateBackup(ctx, params)
 	if err != nil {
 		return backup, err
@@ -12,6 +12,30 @@ SET
   opt_clean = COALESCE(sqlc.narg('opt_clean'), opt_clean),
   opt_if_exists = COALESCE(sqlc.narg('opt_if_exists'), opt_if_exists),
   opt_create = COALESCE(sqlc.narg('opt_create'), opt_create),
-  opt_no_comments = COALESCE(sqlc.narg('opt_no_comments'), opt_no_comments)
+  opt_no_comments = COALESCE(sqlc.narg('opt_no_comments'), opt_no_comments),
+  opt_format = COALESCE(sqlc.narg('opt_format'), opt_format),
+  opt_jobs = COALESCE(sqlc.narg('opt_jobs'), opt_jobs),
+  compression = COALESCE(sqlc.narg('compression'), compression),
+  compression_level = COALESCE(sqlc.narg('compression_level'), compression_level),
+  retention_keep_last = COALESCE(sqlc.narg('retention_keep_last'), retention_keep_last),
+  retention_keep_daily = COALESCE(sqlc.narg('retention_keep_daily'), retention_keep_daily),
+  retention_keep_weekly = COALESCE(sqlc.narg('retention_keep_weekly'), retention_keep_weekly),
+  retention_keep_monthly = COALESCE(sqlc.narg('retention_keep_monthly'), retention_keep_monthly),
+  retention_keep_yearly = COALESCE(sqlc.narg('retention_keep_yearly'), retention_keep_yearly),
+  retention_min_keep = COALESCE(sqlc.narg('retention_min_keep'), retention_min_keep),
+  max_duration_minutes = COALESCE(sqlc.narg('max_duration_minutes'), max_duration_minutes),
+  priority = COALESCE(sqlc.narg('priority'), priority),
+  -- A retry setting of 0 goes back to the server default
+  retry_max_attempts = NULLIF(COALESCE(sqlc.narg('retry_max_attempts'), retry_max_attempts), 0),
+  retry_backoff_seconds = NULLIF(COALESCE(sqlc.narg('retry_backoff_seconds'), retry_backoff_seconds), 0),
+  hooks = COALESCE(sqlc.narg('hooks')::TEXT::JSONB, hooks),
+  opt_schemas = COALESCE(sqlc.narg('opt_schemas')::TEXT[], opt_schemas),
+  opt_exclude_schemas = COALESCE(sqlc.narg('opt_exclude_schemas')::TEXT[], opt_exclude_schemas),
+  opt_tables = COALESCE(sqlc.narg('opt_tables')::TEXT[], opt_tables),
+  opt_exclude_tables = COALESCE(sqlc.narg('opt_exclude_tables')::TEXT[], opt_exclude_tables),
+  opt_exclude_table_data = COALESCE(sqlc.narg('opt_exclude_table_data')::TEXT[], opt_exclude_table_data),
+  opt_no_owner = COALESCE(sqlc.narg('opt_no_owner'), opt_no_owner),
+  opt_no_privileges = COALESCE(sqlc.narg('opt_no_privileges'), opt_no_privileges),
+  opt_role = COALESCE(sqlc.narg('opt_role'), opt_role)
 WHERE id = @id
 RETURNING *;
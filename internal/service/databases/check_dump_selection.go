@@ -0,0 +1,43 @@
+package databases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/google/uuid"
+)
+
+// CheckDumpSelection validates the object selection options of a backup of
+// the database and checks them against its catalog. It returns the patterns
+// and role that don't match anything in the database, formatted as pg_dump
+// options.
+func (s *Service) CheckDumpSelection(
+	ctx context.Context, databaseID uuid.UUID, params postgres.DumpParams,
+) ([]string, error) {
+	if err := postgres.ValidateDumpSelection(params); err != nil {
+		return nil, err
+	}
+
+	db, err := s.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting database: %w", err)
+	}
+	if db.DatabaseType != database.DatabaseTypePostgreSQL {
+		return []string{}, nil
+	}
+
+	version, err := s.ints.PGClient.ParseVersionPG(db.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	unmatched, err := s.ints.PGClient.UnmatchedPatterns(
+		ctx, version, db.DecryptedConnectionString, params,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error checking the patterns: %w", err)
+	}
+	return unmatched, nil
+}
@@ -1,8 +1,10 @@
 -- name: DatabasesServiceCreateDatabase :one
 INSERT INTO databases (
-  name, connection_string, database_type, version
+  name, connection_string, database_type, version, is_production,
+  is_protected
 )
 VALUES (
-  @name, pgp_sym_encrypt(@connection_string, @encryption_key), @database_type, @version
+  @name, pgp_sym_encrypt(@connection_string, @encryption_key), @database_type, @version,
+  @is_production, @is_protected
 )
 RETURNING *;
@@ -10,6 +10,8 @@ SET
       sqlc.narg('connection_string')::TEXT, sqlc.arg('encryption_key')::TEXT
     )
     ELSE connection_string
-  END
+  END,
+  is_production = COALESCE(sqlc.narg('is_production'), is_production),
+  is_protected = COALESCE(sqlc.narg('is_protected'), is_protected)
 WHERE id = @id
 RETURNING *;
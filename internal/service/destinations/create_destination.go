@@ -4,15 +4,44 @@ import (
 	"context"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
 )
 
 func (s *Service) CreateDestination(
 	ctx context.Context, params dbgen.DestinationsServiceCreateDestinationParams,
 ) (dbgen.Destination, error) {
-	err := s.TestDestination(
-		params.AccessKey, params.SecretKey, params.Region, params.Endpoint,
-		params.BucketName,
-	)
+	if err := encryption.ValidateKey(params.BackupEncryptionKey); err != nil {
+		return dbgen.Destination{}, err
+	}
+	if params.S3CredentialsMode == "" {
+		params.S3CredentialsMode = storage.S3CredentialsStatic
+	}
+
+	err := s.TestDestination(ctx, storage.Config{
+		Type:       params.Type,
+		BucketName: params.BucketName,
+		Region:     params.Region,
+		Endpoint:   params.Endpoint,
+		AccessKey:  params.AccessKey,
+		SecretKey:  params.SecretKey,
+		BasePath:   params.BasePath,
+		PrivateKey: params.PrivateKey,
+		HostKey:    params.HostKey,
+		S3: storage.S3Options{
+			CredentialsMode:      params.S3CredentialsMode,
+			RoleARN:              params.S3RoleArn,
+			ExternalID:           params.S3ExternalID,
+			ServerSideEncryption: params.S3ServerSideEncryption,
+			KMSKeyID:             params.S3KmsKeyID,
+			SSECustomerKey:       params.S3SseCustomerKey,
+			StorageClass:         params.S3StorageClass,
+			VirtualHostedStyle:   params.S3VirtualHostedStyle,
+			ObjectLockMode:       params.S3ObjectLockMode,
+			ObjectLockDays:       params.S3ObjectLockDays,
+			ObjectLockLegalHold:  params.S3ObjectLockLegalHold,
+		},
+	})
 	if err != nil {
 		return dbgen.Destination{}, err
 	}
@@ -1,11 +1,37 @@
 -- name: DestinationsServiceCreateDestination :one
 INSERT INTO destinations (
-  name, bucket_name, region, endpoint,
-  access_key, secret_key
+  name, type, bucket_name, region, endpoint, base_path, host_key,
+  access_key, secret_key, private_key, backup_encryption_key,
+  s3_server_side_encryption, s3_kms_key_id, s3_sse_customer_key,
+  s3_storage_class, s3_virtual_hosted_style, s3_object_lock_mode,
+  s3_object_lock_days, s3_object_lock_legal_hold, s3_credentials_mode,
+  s3_role_arn, s3_external_id
 )
 VALUES (
-  @name, @bucket_name, @region, @endpoint,
+  @name, @type, @bucket_name, @region, @endpoint, @base_path, @host_key,
   pgp_sym_encrypt(@access_key, @encryption_key),
-  pgp_sym_encrypt(@secret_key, @encryption_key)
+  pgp_sym_encrypt(@secret_key, @encryption_key),
+  (
+    CASE WHEN @private_key::TEXT = ''
+    THEN NULL
+    ELSE pgp_sym_encrypt(@private_key::TEXT, @encryption_key)
+    END
+  ),
+  (
+    CASE WHEN @backup_encryption_key::TEXT = ''
+    THEN NULL
+    ELSE pgp_sym_encrypt(@backup_encryption_key::TEXT, @encryption_key)
+    END
+  ),
+  @s3_server_side_encryption, @s3_kms_key_id,
+  (
+    CASE WHEN @s3_sse_customer_key::TEXT = ''
+    THEN NULL
+    ELSE pgp_sym_encrypt(@s3_sse_customer_key::TEXT, @encryption_key)
+    END
+  ),
+  @s3_storage_class, @s3_virtual_hosted_style, @s3_object_lock_mode,
+  @s3_object_lock_days, @s3_object_lock_legal_hold, @s3_credentials_mode,
+  @s3_role_arn, @s3_external_id
 )
 RETURNING *;
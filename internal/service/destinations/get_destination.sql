@@ -2,6 +2,24 @@
 SELECT
   *,
   pgp_sym_decrypt(access_key, @encryption_key) AS decrypted_access_key,
-  pgp_sym_decrypt(secret_key, @encryption_key) AS decrypted_secret_key
+  pgp_sym_decrypt(secret_key, @encryption_key) AS decrypted_secret_key,
+  (
+    CASE WHEN private_key IS NOT NULL
+    THEN pgp_sym_decrypt(private_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_private_key,
+  (
+    CASE WHEN s3_sse_customer_key IS NOT NULL
+    THEN pgp_sym_decrypt(s3_sse_customer_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_s3_sse_customer_key,
+  (
+    CASE WHEN backup_encryption_key IS NOT NULL
+    THEN pgp_sym_decrypt(backup_encryption_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_backup_encryption_key
 FROM destinations
 WHERE id = @id;
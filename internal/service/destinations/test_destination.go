@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
 	"github.com/google/uuid"
 )
 
@@ -36,10 +37,7 @@ func (s *Service) TestDestinationAndStoreResult(
 		return storeRes(false, fmt.Errorf("error getting destination: %w", err))
 	}
 
-	err = s.TestDestination(
-		dest.DecryptedAccessKey, dest.DecryptedSecretKey, dest.Region,
-		dest.Endpoint, dest.BucketName,
-	)
+	err = s.TestDestination(ctx, StorageConfig(dest))
 	if err != nil && dest.TestOk.Valid && dest.TestOk.Bool {
 		s.webhooksService.RunDestinationUnhealthy(dest.ID)
 	}
@@ -53,11 +51,43 @@ func (s *Service) TestDestinationAndStoreResult(
 	return storeRes(true, nil)
 }
 
+// StorageConfig returns the storage settings of a destination.
+func StorageConfig(dest dbgen.DestinationsServiceGetDestinationRow) storage.Config {
+	return storage.Config{
+		Type:       dest.Type,
+		BucketName: dest.BucketName,
+		Region:     dest.Region,
+		Endpoint:   dest.Endpoint,
+		AccessKey:  dest.DecryptedAccessKey,
+		SecretKey:  dest.DecryptedSecretKey,
+		BasePath:   dest.BasePath,
+		PrivateKey: dest.DecryptedPrivateKey,
+		HostKey:    dest.HostKey,
+		S3: storage.S3Options{
+			CredentialsMode:      dest.S3CredentialsMode,
+			RoleARN:              dest.S3RoleArn,
+			ExternalID:           dest.S3ExternalID,
+			ServerSideEncryption: dest.S3ServerSideEncryption,
+			KMSKeyID:             dest.S3KmsKeyID,
+			SSECustomerKey:       dest.DecryptedS3SseCustomerKey,
+			StorageClass:         dest.S3StorageClass,
+			VirtualHostedStyle:   dest.S3VirtualHostedStyle,
+			ObjectLockMode:       dest.S3ObjectLockMode,
+			ObjectLockDays:       dest.S3ObjectLockDays,
+			ObjectLockLegalHold:  dest.S3ObjectLockLegalHold,
+		},
+	}
+}
+
 func (s *Service) TestDestination(
-	accessKey, secretKey, region, endpoint, bucketName string,
+	ctx context.Context, config storage.Config,
 ) error {
-	err := s.ints.StorageClient.S3Test(accessKey, secretKey, region, endpoint, bucketName)
+	backend, err := s.ints.StorageClient.Backend(config)
 	if err != nil {
+		return err
+	}
+
+	if err := backend.Test(ctx); err != nil {
 		return fmt.Errorf("error testing destination: %w", err)
 	}
 
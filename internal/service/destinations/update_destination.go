@@ -2,21 +2,66 @@ package destinations
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
 )
 
 func (s *Service) UpdateDestination(
 	ctx context.Context, params dbgen.DestinationsServiceUpdateDestinationParams,
 ) (dbgen.Destination, error) {
-	err := s.TestDestination(
-		params.AccessKey.String, params.SecretKey.String, params.Region.String,
-		params.Endpoint.String, params.BucketName.String,
-	)
+	if err := encryption.ValidateKey(params.BackupEncryptionKey.String); err != nil {
+		return dbgen.Destination{}, err
+	}
+	if params.S3CredentialsMode.Valid && params.S3CredentialsMode.String == "" {
+		params.S3CredentialsMode.String = storage.S3CredentialsStatic
+	}
+
+	current, err := s.GetDestination(ctx, params.ID)
 	if err != nil {
 		return dbgen.Destination{}, err
 	}
 
+	// The omitted settings keep their current value
+	config := StorageConfig(current)
+	override := func(dst *string, value sql.NullString) {
+		if value.Valid {
+			*dst = value.String
+		}
+	}
+	override(&config.Type, params.Type)
+	override(&config.BucketName, params.BucketName)
+	override(&config.Region, params.Region)
+	override(&config.Endpoint, params.Endpoint)
+	override(&config.AccessKey, params.AccessKey)
+	override(&config.SecretKey, params.SecretKey)
+	override(&config.BasePath, params.BasePath)
+	override(&config.PrivateKey, params.PrivateKey)
+	override(&config.HostKey, params.HostKey)
+	override(&config.S3.CredentialsMode, params.S3CredentialsMode)
+	override(&config.S3.RoleARN, params.S3RoleArn)
+	override(&config.S3.ExternalID, params.S3ExternalID)
+	override(&config.S3.ServerSideEncryption, params.S3ServerSideEncryption)
+	override(&config.S3.KMSKeyID, params.S3KmsKeyID)
+	override(&config.S3.SSECustomerKey, params.S3SseCustomerKey)
+	override(&config.S3.StorageClass, params.S3StorageClass)
+	override(&config.S3.ObjectLockMode, params.S3ObjectLockMode)
+	if params.S3VirtualHostedStyle.Valid {
+		config.S3.VirtualHostedStyle = params.S3VirtualHostedStyle.Bool
+	}
+	if params.S3ObjectLockDays.Valid {
+		config.S3.ObjectLockDays = params.S3ObjectLockDays.Int32
+	}
+	if params.S3ObjectLockLegalHold.Valid {
+		config.S3.ObjectLockLegalHold = params.S3ObjectLockLegalHold.Bool
+	}
+
+	if err := s.TestDestination(ctx, config); err != nil {
+		return dbgen.Destination{}, err
+	}
+
 	params.EncryptionKey = s.env.PBW_ENCRYPTION_KEY
 	dest, err := s.dbgen.DestinationsServiceUpdateDestination(ctx, params)
 
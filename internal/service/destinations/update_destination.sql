@@ -2,9 +2,22 @@
 UPDATE destinations
 SET
   name = COALESCE(sqlc.narg('name'), name),
+  type = COALESCE(sqlc.narg('type'), type),
+  base_path = COALESCE(sqlc.narg('base_path'), base_path),
+  host_key = COALESCE(sqlc.narg('host_key'), host_key),
   bucket_name = COALESCE(sqlc.narg('bucket_name'), bucket_name),
   region = COALESCE(sqlc.narg('region'), region),
   endpoint = COALESCE(sqlc.narg('endpoint'), endpoint),
+  s3_server_side_encryption = COALESCE(sqlc.narg('s3_server_side_encryption'), s3_server_side_encryption),
+  s3_kms_key_id = COALESCE(sqlc.narg('s3_kms_key_id'), s3_kms_key_id),
+  s3_storage_class = COALESCE(sqlc.narg('s3_storage_class'), s3_storage_class),
+  s3_virtual_hosted_style = COALESCE(sqlc.narg('s3_virtual_hosted_style'), s3_virtual_hosted_style),
+  s3_object_lock_mode = COALESCE(sqlc.narg('s3_object_lock_mode'), s3_object_lock_mode),
+  s3_object_lock_days = COALESCE(sqlc.narg('s3_object_lock_days'), s3_object_lock_days),
+  s3_object_lock_legal_hold = COALESCE(sqlc.narg('s3_object_lock_legal_hold'), s3_object_lock_legal_hold),
+  s3_credentials_mode = COALESCE(sqlc.narg('s3_credentials_mode'), s3_credentials_mode),
+  s3_role_arn = COALESCE(sqlc.narg('s3_role_arn'), s3_role_arn),
+  s3_external_id = COALESCE(sqlc.narg('s3_external_id'), s3_external_id),
   access_key = CASE
     WHEN sqlc.narg('access_key')::TEXT IS NOT NULL
     THEN pgp_sym_encrypt(sqlc.narg('access_key')::TEXT, sqlc.arg('encryption_key')::TEXT)
@@ -14,6 +27,27 @@ SET
     WHEN sqlc.narg('secret_key')::TEXT IS NOT NULL
     THEN pgp_sym_encrypt(sqlc.narg('secret_key')::TEXT, sqlc.arg('encryption_key')::TEXT)
     ELSE secret_key
+  END,
+  private_key = CASE
+    WHEN sqlc.narg('private_key')::TEXT IS NULL
+    THEN private_key
+    WHEN sqlc.narg('private_key')::TEXT = ''
+    THEN NULL
+    ELSE pgp_sym_encrypt(sqlc.narg('private_key')::TEXT, sqlc.arg('encryption_key')::TEXT)
+  END,
+  s3_sse_customer_key = CASE
+    WHEN sqlc.narg('s3_sse_customer_key')::TEXT IS NULL
+    THEN s3_sse_customer_key
+    WHEN sqlc.narg('s3_sse_customer_key')::TEXT = ''
+    THEN NULL
+    ELSE pgp_sym_encrypt(sqlc.narg('s3_sse_customer_key')::TEXT, sqlc.arg('encryption_key')::TEXT)
+  END,
+  backup_encryption_key = CASE
+    WHEN sqlc.narg('backup_encryption_key')::TEXT IS NULL
+    THEN backup_encryption_key
+    WHEN sqlc.narg('backup_encryption_key')::TEXT = ''
+    THEN NULL
+    ELSE pgp_sym_encrypt(sqlc.narg('backup_encryption_key')::TEXT, sqlc.arg('encryption_key')::TEXT)
   END
 WHERE id = @id
 RETURNING *;
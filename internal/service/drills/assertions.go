@@ -0,0 +1,95 @@
+package drills
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxAssertions is the maximum number of assertions of a drill.
+const MaxAssertions = 50
+
+// AssertionResult is the outcome of running one assertion of a drill.
+type AssertionResult struct {
+	Query  string `json:"query"`
+	Passed bool   `json:"passed"`
+	Output string `json:"output"`
+}
+
+// SplitAssertions splits the assertions of a drill into the SQL statements
+// that are run one by one. Statements are separated by semicolons, which are
+// ignored inside quotes and -- comments, and comments are not part of the
+// returned statements.
+func SplitAssertions(assertions string) ([]string, error) {
+	statements := []string{}
+	current := strings.Builder{}
+	var quote rune
+	inComment := false
+
+	flush := func() {
+		statement := strings.TrimSpace(current.String())
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(assertions)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case inComment:
+			if r == '\n' {
+				inComment = false
+				current.WriteRune(r)
+			}
+			continue
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			inComment = true
+			continue
+		case r == ';':
+			flush()
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	if quote != 0 {
+		return nil, errors.New("assertions contain an unterminated quote")
+	}
+	flush()
+
+	if len(statements) > MaxAssertions {
+		return nil, fmt.Errorf(
+			"a restore drill can't have more than %d assertions", MaxAssertions,
+		)
+	}
+
+	return statements, nil
+}
+
+// assertionPassed reports whether the psql output of an assertion is a
+// single true value.
+func assertionPassed(output string) bool {
+	value := strings.ToLower(strings.TrimSpace(output))
+	return value == "t" || value == "true"
+}
+
+// ParseAssertionResults decodes the assertion results stored in a drill run,
+// invalid content is returned as no results.
+func ParseAssertionResults(data []byte) []AssertionResult {
+	results := []AssertionResult{}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return []AssertionResult{}
+	}
+	return results
+}
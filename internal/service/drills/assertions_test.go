@@ -0,0 +1,64 @@
+package drills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitAssertions(t *testing.T) {
+	tests := []struct {
+		name       string
+		assertions string
+		want       []string
+	}{
+		{"empty", "  \n ", []string{}},
+		{"single without semicolon", "SELECT true", []string{"SELECT true"}},
+		{
+			"multiple",
+			"SELECT count(*) > 0 FROM users;\nSELECT true;",
+			[]string{"SELECT count(*) > 0 FROM users", "SELECT true"},
+		},
+		{
+			"semicolon in quotes",
+			`SELECT 'a;b' = 'a;b'; SELECT count(*) > 0 FROM "my;table"`,
+			[]string{`SELECT 'a;b' = 'a;b'`, `SELECT count(*) > 0 FROM "my;table"`},
+		},
+		{
+			"comments",
+			"-- users must exist; always\nSELECT count(*) > 0 FROM users; -- end",
+			[]string{"SELECT count(*) > 0 FROM users"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitAssertions(tt.assertions)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := SplitAssertions("SELECT 'unterminated")
+	assert.Error(t, err)
+}
+
+func TestAssertionPassed(t *testing.T) {
+	assert.True(t, assertionPassed("t"))
+	assert.True(t, assertionPassed(" true\n"))
+	assert.False(t, assertionPassed("f"))
+	assert.False(t, assertionPassed("t\nt"))
+	assert.False(t, assertionPassed("42"))
+	assert.False(t, assertionPassed(""))
+}
+
+func TestParseAssertionResults(t *testing.T) {
+	results := ParseAssertionResults(
+		[]byte(`[{"query":"SELECT true","passed":true,"output":"t"}]`),
+	)
+	assert.Equal(t, []AssertionResult{
+		{Query: "SELECT true", Passed: true, Output: "t"},
+	}, results)
+
+	assert.Empty(t, ParseAssertionResults([]byte("not json")))
+}
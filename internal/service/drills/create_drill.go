@@ -0,0 +1,29 @@
+package drills
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+)
+
+func (s *Service) CreateDrill(
+	ctx context.Context, params dbgen.DrillsServiceCreateDrillParams,
+) (dbgen.RestoreDrill, error) {
+	err := s.validateDrill(
+		ctx, params.CronExpression, params.Assertions, params.DatabaseID,
+	)
+	if err != nil {
+		return dbgen.RestoreDrill{}, err
+	}
+
+	drill, err := s.dbgen.DrillsServiceCreateDrill(ctx, params)
+	if err != nil {
+		return drill, err
+	}
+
+	if !drill.IsActive {
+		return drill, s.jobRemove(drill.ID)
+	}
+
+	return drill, s.jobUpsert(drill.ID, drill.TimeZone, drill.CronExpression)
+}
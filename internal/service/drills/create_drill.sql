@@ -0,0 +1,10 @@
+-- name: DrillsServiceCreateDrill :one
+INSERT INTO restore_drills (
+  backup_id, database_id, name, cron_expression, time_zone, is_active,
+  assertions
+)
+VALUES (
+  @backup_id, @database_id, @name, @cron_expression, @time_zone, @is_active,
+  @assertions
+)
+RETURNING *;
@@ -0,0 +1,15 @@
+package drills
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+func (s *Service) DeleteDrill(ctx context.Context, id uuid.UUID) error {
+	if err := s.jobRemove(id); err != nil {
+		return err
+	}
+
+	return s.dbgen.DrillsServiceDeleteDrill(ctx, id)
+}
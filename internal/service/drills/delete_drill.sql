@@ -0,0 +1,3 @@
+-- name: DrillsServiceDeleteDrill :exec
+DELETE FROM restore_drills
+WHERE id = @id;
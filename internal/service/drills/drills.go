@@ -0,0 +1,40 @@
+package drills
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/cron"
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration"
+	"github.com/eduardolat/pgbackweb/internal/service/databases"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/service/restorations"
+	"github.com/eduardolat/pgbackweb/internal/service/webhooks"
+)
+
+// Service runs restore drills: the latest successful execution of a backup
+// is restored into a scratch database where user defined SQL assertions are
+// checked, so broken backups are found before they are needed.
+type Service struct {
+	dbgen               *dbgen.Queries
+	cr                  *cron.Cron
+	ints                *integration.Integration
+	executionsService   *executions.Service
+	databasesService    *databases.Service
+	restorationsService *restorations.Service
+	webhooksService     *webhooks.Service
+}
+
+func New(
+	dbgen *dbgen.Queries, cr *cron.Cron, ints *integration.Integration,
+	executionsService *executions.Service, databasesService *databases.Service,
+	restorationsService *restorations.Service, webhooksService *webhooks.Service,
+) *Service {
+	return &Service{
+		dbgen:               dbgen,
+		cr:                  cr,
+		ints:                ints,
+		executionsService:   executionsService,
+		databasesService:    databasesService,
+		restorationsService: restorationsService,
+		webhooksService:     webhooksService,
+	}
+}
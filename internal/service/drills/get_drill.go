@@ -0,0 +1,14 @@
+package drills
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/google/uuid"
+)
+
+func (s *Service) GetDrill(
+	ctx context.Context, id uuid.UUID,
+) (dbgen.RestoreDrill, error) {
+	return s.dbgen.DrillsServiceGetDrill(ctx, id)
+}
@@ -0,0 +1,3 @@
+-- name: DrillsServiceGetDrill :one
+SELECT * FROM restore_drills
+WHERE id = @id;
@@ -0,0 +1,7 @@
+package drills
+
+import "github.com/google/uuid"
+
+func (s *Service) jobRemove(drillID uuid.UUID) error {
+	return s.cr.RemoveJob(drillID)
+}
@@ -0,0 +1,16 @@
+package drills
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+func (s *Service) jobUpsert(
+	drillID uuid.UUID, timeZone string, cronExpression string,
+) error {
+	return s.cr.UpsertJob(
+		drillID, timeZone, cronExpression,
+		s.RunDrill, context.Background(), drillID,
+	)
+}
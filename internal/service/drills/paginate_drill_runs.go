@@ -0,0 +1,49 @@
+package drills
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+	"github.com/google/uuid"
+)
+
+type PaginateDrillRunsParams struct {
+	Page        int
+	Limit       int
+	DrillFilter uuid.NullUUID
+}
+
+func (s *Service) PaginateDrillRuns(
+	ctx context.Context, params PaginateDrillRunsParams,
+) (paginateutil.PaginateResponse, []dbgen.DrillsServicePaginateDrillRunsRow, error) {
+	page := max(params.Page, 1)
+	limit := min(max(params.Limit, 1), 100)
+
+	count, err := s.dbgen.DrillsServicePaginateDrillRunsCount(
+		ctx, params.DrillFilter,
+	)
+	if err != nil {
+		return paginateutil.PaginateResponse{}, nil, err
+	}
+
+	paginateParams := paginateutil.PaginateParams{
+		Page:  page,
+		Limit: limit,
+	}
+	offset := paginateutil.CreateOffsetFromParams(paginateParams)
+	paginateResponse := paginateutil.CreatePaginateResponse(paginateParams, int(count))
+
+	runs, err := s.dbgen.DrillsServicePaginateDrillRuns(
+		ctx, dbgen.DrillsServicePaginateDrillRunsParams{
+			DrillID: params.DrillFilter,
+			Limit:   int32(limit),
+			Offset:  int32(offset),
+		},
+	)
+	if err != nil {
+		return paginateutil.PaginateResponse{}, nil, err
+	}
+
+	return paginateResponse, runs, nil
+}
@@ -0,0 +1,22 @@
+-- name: DrillsServicePaginateDrillRunsCount :one
+SELECT COUNT(*)
+FROM restore_drill_runs
+WHERE
+  sqlc.narg('drill_id')::UUID IS NULL
+  OR
+  restore_drill_runs.drill_id = sqlc.narg('drill_id')::UUID;
+
+-- name: DrillsServicePaginateDrillRuns :many
+SELECT
+  restore_drill_runs.*,
+  restore_drills.name AS drill_name,
+  backups.name AS backup_name
+FROM restore_drill_runs
+INNER JOIN restore_drills ON restore_drills.id = restore_drill_runs.drill_id
+INNER JOIN backups ON backups.id = restore_drills.backup_id
+WHERE
+  sqlc.narg('drill_id')::UUID IS NULL
+  OR
+  restore_drill_runs.drill_id = sqlc.narg('drill_id')::UUID
+ORDER BY restore_drill_runs.started_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,44 @@
+package drills
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+)
+
+type PaginateDrillsParams struct {
+	Page  int
+	Limit int
+}
+
+func (s *Service) PaginateDrills(
+	ctx context.Context, params PaginateDrillsParams,
+) (paginateutil.PaginateResponse, []dbgen.DrillsServicePaginateDrillsRow, error) {
+	page := max(params.Page, 1)
+	limit := min(max(params.Limit, 1), 100)
+
+	count, err := s.dbgen.DrillsServicePaginateDrillsCount(ctx)
+	if err != nil {
+		return paginateutil.PaginateResponse{}, nil, err
+	}
+
+	paginateParams := paginateutil.PaginateParams{
+		Page:  page,
+		Limit: limit,
+	}
+	offset := paginateutil.CreateOffsetFromParams(paginateParams)
+	paginateResponse := paginateutil.CreatePaginateResponse(paginateParams, int(count))
+
+	drills, err := s.dbgen.DrillsServicePaginateDrills(
+		ctx, dbgen.DrillsServicePaginateDrillsParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		},
+	)
+	if err != nil {
+		return paginateutil.PaginateResponse{}, nil, err
+	}
+
+	return paginateResponse, drills, nil
+}
@@ -0,0 +1,22 @@
+-- name: DrillsServicePaginateDrillsCount :one
+SELECT COUNT(*) FROM restore_drills;
+
+-- name: DrillsServicePaginateDrills :many
+SELECT
+  restore_drills.*,
+  backups.name AS backup_name,
+  databases.name AS database_name,
+  last_run.status AS last_run_status,
+  last_run.started_at AS last_run_started_at
+FROM restore_drills
+INNER JOIN backups ON backups.id = restore_drills.backup_id
+INNER JOIN databases ON databases.id = restore_drills.database_id
+LEFT JOIN LATERAL (
+  SELECT status, started_at
+  FROM restore_drill_runs
+  WHERE restore_drill_runs.drill_id = restore_drills.id
+  ORDER BY started_at DESC
+  LIMIT 1
+) last_run ON TRUE
+ORDER BY restore_drills.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,231 @@
+package drills
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
+)
+
+// scratchDatabaseName returns the name of the scratch database of a drill
+// run, it is unique per run so concurrent drills don't collide.
+func scratchDatabaseName(runID uuid.UUID) string {
+	return "pbw_drill_" + strings.ReplaceAll(runID.String(), "-", "")[:12]
+}
+
+// RunDrill runs a restore drill: it restores the latest successful execution
+// of the backup into a scratch database created on the drill server, checks
+// the assertions against it and drops it. The outcome is stored as a drill
+// run and the restore drill webhooks are sent.
+func (s *Service) RunDrill(ctx context.Context, drillID uuid.UUID) error {
+	logError := func(err error) {
+		logger.Error("error running restore drill", logger.KV{
+			"drill_id": drillID.String(),
+			"error":    err.Error(),
+		})
+	}
+
+	drill, err := s.dbgen.DrillsServiceGetDrill(ctx, drillID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The drill was deleted together with its backup or database
+		return s.jobRemove(drillID)
+	}
+	if err != nil {
+		logError(err)
+		return err
+	}
+
+	run, err := s.dbgen.DrillsServiceCreateDrillRun(ctx, drill.ID)
+	if err != nil {
+		logError(err)
+		return err
+	}
+
+	finish := func(results []AssertionResult, err error) error {
+		status, message := "success", ""
+		if err != nil {
+			logError(err)
+			status, message = "failed", err.Error()
+		} else {
+			message = fmt.Sprintf(
+				"Backup restored and %d assertions passed", len(results),
+			)
+		}
+
+		if results == nil {
+			results = []AssertionResult{}
+		}
+		resultsJSON, jsonErr := json.Marshal(results)
+		if jsonErr != nil {
+			logError(jsonErr)
+			resultsJSON = []byte("[]")
+		}
+
+		_, updateErr := s.dbgen.DrillsServiceUpdateDrillRun(
+			ctx, dbgen.DrillsServiceUpdateDrillRunParams{
+				ID:               run.ID,
+				Status:           sql.NullString{Valid: true, String: status},
+				Message:          sql.NullString{Valid: true, String: message},
+				AssertionResults: sql.NullString{Valid: true, String: string(resultsJSON)},
+				FinishedAt:       sql.NullTime{Valid: true, Time: time.Now()},
+			},
+		)
+
+		if status == "success" {
+			s.webhooksService.RunRestoreDrillSuccess(drill.BackupID)
+		} else {
+			s.webhooksService.RunRestoreDrillFailed(drill.BackupID)
+		}
+
+		return updateErr
+	}
+
+	executionID, err := s.dbgen.DrillsServiceGetLatestSuccessfulExecution(
+		ctx, drill.BackupID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return finish(nil, errors.New("the backup has no successful executions"))
+	}
+	if err != nil {
+		return finish(nil, err)
+	}
+
+	_, err = s.dbgen.DrillsServiceUpdateDrillRun(
+		ctx, dbgen.DrillsServiceUpdateDrillRunParams{
+			ID:          run.ID,
+			ExecutionID: uuid.NullUUID{Valid: true, UUID: executionID},
+		},
+	)
+	if err != nil {
+		return finish(nil, err)
+	}
+
+	execution, err := s.executionsService.GetExecution(ctx, executionID)
+	if err != nil {
+		return finish(nil, err)
+	}
+	if execution.DatabaseDatabaseType != database.DatabaseTypePostgreSQL {
+		return finish(nil, errors.New("restore drills only support PostgreSQL backups"))
+	}
+	if execution.BackupMode == postgres.BackupModePhysical {
+		return finish(nil, errors.New("restore drills don't support physical backups"))
+	}
+	if execution.BackupOptCreate {
+		// Plain dumps taken with --create connect to the original database
+		// name, so they can't be restored into a scratch database
+		return finish(nil, errors.New(
+			"restore drills don't support backups taken with --create",
+		))
+	}
+
+	server, err := s.databasesService.GetDatabase(ctx, drill.DatabaseID)
+	if err != nil {
+		return finish(nil, err)
+	}
+	pgVersion, err := s.ints.PGClient.ParseVersionPG(server.Version)
+	if err != nil {
+		return finish(nil, err)
+	}
+
+	scratchName := scratchDatabaseName(run.ID)
+	scratchConnString, err := postgres.WithDatabase(
+		server.DecryptedConnectionString, scratchName,
+	)
+	if err != nil {
+		return finish(nil, err)
+	}
+
+	err = s.ints.PGClient.CreateDatabase(
+		ctx, pgVersion, server.DecryptedConnectionString, scratchName,
+	)
+	if err != nil {
+		return finish(nil, fmt.Errorf("error creating scratch database: %w", err))
+	}
+
+	_, err = s.dbgen.DrillsServiceUpdateDrillRun(
+		ctx, dbgen.DrillsServiceUpdateDrillRunParams{
+			ID:              run.ID,
+			ScratchDatabase: sql.NullString{Valid: true, String: scratchName},
+		},
+	)
+	if err != nil {
+		logError(err)
+	}
+
+	results, err := s.checkScratchDatabase(
+		ctx, drill, execution, pgVersion, scratchConnString,
+	)
+
+	dropErr := s.ints.PGClient.DropDatabase(
+		ctx, pgVersion, server.DecryptedConnectionString, scratchName,
+	)
+	if dropErr != nil {
+		dropErr = fmt.Errorf("error dropping scratch database: %w", dropErr)
+		err = errors.Join(err, dropErr)
+	}
+
+	return finish(results, err)
+}
+
+// checkScratchDatabase restores the execution into the scratch database and
+// runs the assertions of the drill against it.
+func (s *Service) checkScratchDatabase(
+	ctx context.Context, drill dbgen.RestoreDrill,
+	execution dbgen.ExecutionsServiceGetExecutionRow,
+	pgVersion postgres.PGVersion, scratchConnString string,
+) ([]AssertionResult, error) {
+	err := s.restorationsService.RestoreExecution(
+		ctx, execution, scratchConnString, postgres.RestoreParams{
+			Jobs:         int(execution.BackupOptJobs),
+			NoOwner:      execution.BackupOptNoOwner,
+			NoPrivileges: execution.BackupOptNoPrivileges,
+		}, nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error restoring backup: %w", err)
+	}
+
+	assertions, err := SplitAssertions(drill.Assertions)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]AssertionResult, 0, len(assertions))
+	failed := 0
+	for _, assertion := range assertions {
+		output, err := s.ints.PGClient.QueryValue(
+			ctx, pgVersion, scratchConnString, assertion,
+		)
+		if err != nil {
+			output = err.Error()
+		}
+
+		passed := err == nil && assertionPassed(output)
+		if !passed {
+			failed++
+		}
+
+		results = append(results, AssertionResult{
+			Query:  assertion,
+			Passed: passed,
+			Output: output,
+		})
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf(
+			"%d of %d assertions failed", failed, len(results),
+		)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,27 @@
+-- name: DrillsServiceCreateDrillRun :one
+INSERT INTO restore_drill_runs (drill_id, status)
+VALUES (@drill_id, 'running')
+RETURNING *;
+
+-- name: DrillsServiceUpdateDrillRun :one
+UPDATE restore_drill_runs
+SET
+  execution_id = COALESCE(sqlc.narg('execution_id'), execution_id),
+  status = COALESCE(sqlc.narg('status'), status),
+  message = COALESCE(sqlc.narg('message'), message),
+  scratch_database = COALESCE(sqlc.narg('scratch_database'), scratch_database),
+  assertion_results = COALESCE(
+    sqlc.narg('assertion_results')::TEXT::JSONB, assertion_results
+  ),
+  finished_at = COALESCE(sqlc.narg('finished_at'), finished_at)
+WHERE id = @id
+RETURNING *;
+
+-- name: DrillsServiceGetLatestSuccessfulExecution :one
+SELECT id
+FROM executions
+WHERE backup_id = @backup_id
+AND status = 'success'
+AND deleted_at IS NULL
+ORDER BY finished_at DESC
+LIMIT 1;
@@ -0,0 +1,34 @@
+package drills
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/logger"
+)
+
+func (s *Service) ScheduleAll() {
+	drills, err := s.dbgen.DrillsServiceGetScheduleAllData(
+		context.Background(),
+	)
+	if err != nil {
+		logger.Error("error getting all restore drills", logger.KV{"error": err})
+	}
+
+	for _, drill := range drills {
+		if !drill.IsActive {
+			err := s.jobRemove(drill.ID)
+			if err != nil {
+				logger.Error("error removing inactive restore drill", logger.KV{"error": err})
+			}
+		}
+
+		if drill.IsActive {
+			err := s.jobUpsert(drill.ID, drill.TimeZone, drill.CronExpression)
+			if err != nil {
+				logger.Error("error scheduling restore drill", logger.KV{"error": err})
+			}
+		}
+	}
+
+	logger.Info("all active restore drills scheduled")
+}
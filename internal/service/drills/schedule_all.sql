@@ -0,0 +1,8 @@
+-- name: DrillsServiceGetScheduleAllData :many
+SELECT
+  id,
+  is_active,
+  cron_expression,
+  time_zone
+FROM restore_drills
+ORDER BY created_at DESC;
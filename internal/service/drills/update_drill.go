@@ -0,0 +1,45 @@
+package drills
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+)
+
+func (s *Service) UpdateDrill(
+	ctx context.Context, params dbgen.DrillsServiceUpdateDrillParams,
+) (dbgen.RestoreDrill, error) {
+	current, err := s.dbgen.DrillsServiceGetDrill(ctx, params.ID)
+	if err != nil {
+		return dbgen.RestoreDrill{}, err
+	}
+
+	cronExpression := current.CronExpression
+	if params.CronExpression.Valid {
+		cronExpression = params.CronExpression.String
+	}
+	assertions := current.Assertions
+	if params.Assertions.Valid {
+		assertions = params.Assertions.String
+	}
+	databaseID := current.DatabaseID
+	if params.DatabaseID.Valid {
+		databaseID = params.DatabaseID.UUID
+	}
+
+	err = s.validateDrill(ctx, cronExpression, assertions, databaseID)
+	if err != nil {
+		return dbgen.RestoreDrill{}, err
+	}
+
+	drill, err := s.dbgen.DrillsServiceUpdateDrill(ctx, params)
+	if err != nil {
+		return drill, err
+	}
+
+	if !drill.IsActive {
+		return drill, s.jobRemove(drill.ID)
+	}
+
+	return drill, s.jobUpsert(drill.ID, drill.TimeZone, drill.CronExpression)
+}
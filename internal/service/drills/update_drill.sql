@@ -0,0 +1,11 @@
+-- name: DrillsServiceUpdateDrill :one
+UPDATE restore_drills
+SET
+  database_id = COALESCE(sqlc.narg('database_id'), database_id),
+  name = COALESCE(sqlc.narg('name'), name),
+  cron_expression = COALESCE(sqlc.narg('cron_expression'), cron_expression),
+  time_zone = COALESCE(sqlc.narg('time_zone'), time_zone),
+  is_active = COALESCE(sqlc.narg('is_active'), is_active),
+  assertions = COALESCE(sqlc.narg('assertions'), assertions)
+WHERE id = @id
+RETURNING *;
@@ -0,0 +1,35 @@
+package drills
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/google/uuid"
+)
+
+// validateDrill checks the schedule and the assertions of a drill, and that
+// the server where the scratch databases are created is a PostgreSQL one.
+func (s *Service) validateDrill(
+	ctx context.Context, cronExpression string, assertions string,
+	databaseID uuid.UUID,
+) error {
+	if !validate.CronExpression(cronExpression) {
+		return fmt.Errorf("invalid cron expression")
+	}
+
+	if _, err := SplitAssertions(assertions); err != nil {
+		return err
+	}
+
+	db, err := s.databasesService.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return err
+	}
+	if db.DatabaseType != database.DatabaseTypePostgreSQL {
+		return fmt.Errorf("restore drills can only run on PostgreSQL servers")
+	}
+
+	return nil
+}
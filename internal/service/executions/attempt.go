@@ -0,0 +1,33 @@
+package executions
+
+import "github.com/google/uuid"
+
+// Attempt identifies which run of a backup an execution is when its failures
+// are retried, the retries point to the execution of the first attempt.
+type Attempt struct {
+	ParentExecutionID uuid.NullUUID
+	Number            int32
+	MaxAttempts       int32
+	// Pinned executions are never deleted by the retention policy
+	Pinned bool
+	// JobID is the job that runs the attempt, the job is linked to the
+	// execution as soon as it's created
+	JobID uuid.NullUUID
+}
+
+// normalize fills the zero values so that the zero Attempt is a single try.
+func (a Attempt) normalize() Attempt {
+	if a.Number < 1 {
+		a.Number = 1
+	}
+	if a.MaxAttempts < a.Number {
+		a.MaxAttempts = a.Number
+	}
+	return a
+}
+
+// IsFinal reports whether no more attempts follow this one if it fails.
+func (a Attempt) IsFinal() bool {
+	a = a.normalize()
+	return a.Number >= a.MaxAttempts
+}
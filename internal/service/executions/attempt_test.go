@@ -0,0 +1,29 @@
+package executions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptIsFinal(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt Attempt
+		want    bool
+	}{
+		{name: "zero value", attempt: Attempt{}, want: true},
+		{name: "single attempt", attempt: Attempt{Number: 1, MaxAttempts: 1}, want: true},
+		{name: "first of three", attempt: Attempt{Number: 1, MaxAttempts: 3}, want: false},
+		{name: "second of three", attempt: Attempt{Number: 2, MaxAttempts: 3}, want: false},
+		{name: "last of three", attempt: Attempt{Number: 3, MaxAttempts: 3}, want: true},
+		{name: "past the max", attempt: Attempt{Number: 4, MaxAttempts: 3}, want: true},
+		{name: "max not set", attempt: Attempt{Number: 2}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.attempt.IsFinal())
+		})
+	}
+}
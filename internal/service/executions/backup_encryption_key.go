@@ -0,0 +1,28 @@
+package executions
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+)
+
+// BackupEncryptionKey returns the key used to encrypt the backups stored in
+// the local backups directory or in the destination with the given key.
+//
+// Returns false if the backups stored there are not encrypted.
+func (s *Service) BackupEncryptionKey(
+	isLocal bool, destinationKey string,
+) (encryption.Key, bool, error) {
+	secret := destinationKey
+	if isLocal {
+		secret = s.env.PBW_LOCAL_BACKUPS_ENCRYPTION_KEY
+	}
+
+	if secret == "" {
+		return encryption.Key{}, false, nil
+	}
+
+	key, err := encryption.ParseKey(secret)
+	if err != nil {
+		return encryption.Key{}, false, err
+	}
+	return key, true, nil
+}
@@ -0,0 +1,95 @@
+package executions
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of the executions that were stopped before finishing.
+const (
+	StatusCancelled = "cancelled"
+	StatusTimedOut  = "timed_out"
+)
+
+var (
+	// ErrExecutionCancelled is the cause of the executions cancelled by a user.
+	ErrExecutionCancelled = errors.New("execution cancelled")
+	// ErrExecutionTimedOut is the cause of the executions that exceeded the max
+	// duration of their backup.
+	ErrExecutionTimedOut = errors.New("execution timed out")
+	// ErrExecutionNotRunning is returned when cancelling an execution that is
+	// not running in this instance.
+	ErrExecutionNotRunning = errors.New("execution is not running")
+)
+
+// runningExecutions holds the cancel functions of the executions that are
+// running in this instance.
+type runningExecutions struct {
+	mu      sync.Mutex
+	cancels map[uuid.UUID]context.CancelCauseFunc
+}
+
+// track registers the cancel function of an execution, the returned function
+// must be called once the execution finishes.
+func (r *runningExecutions) track(
+	executionID uuid.UUID, cancel context.CancelCauseFunc,
+) func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancels == nil {
+		r.cancels = map[uuid.UUID]context.CancelCauseFunc{}
+	}
+	r.cancels[executionID] = cancel
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.cancels, executionID)
+	}
+}
+
+// cancel cancels a running execution with the given cause.
+func (r *runningExecutions) cancel(executionID uuid.UUID, cause error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cancel, ok := r.cancels[executionID]
+	if !ok {
+		return ErrExecutionNotRunning
+	}
+	cancel(cause)
+
+	return nil
+}
+
+// has reports whether the execution is running in this instance.
+func (r *runningExecutions) has(executionID uuid.UUID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.cancels[executionID]
+	return ok
+}
+
+// CancelExecution stops a running execution: the dump is killed, the partial
+// files are removed and the execution is marked as cancelled.
+func (s *Service) CancelExecution(executionID uuid.UUID) error {
+	return s.running.cancel(executionID, ErrExecutionCancelled)
+}
+
+// interruptedStatus returns the status of an execution whose context was
+// done because of cause, the empty string if it was not interrupted.
+func interruptedStatus(cause error) string {
+	switch {
+	case errors.Is(cause, ErrExecutionTimedOut):
+		return StatusTimedOut
+	case cause != nil:
+		return StatusCancelled
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,48 @@
+package executions
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunningExecutions(t *testing.T) {
+	running := runningExecutions{}
+	executionID := uuid.New()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	untrack := running.track(executionID, cancel)
+	assert.True(t, running.has(executionID))
+	assert.False(t, running.has(uuid.New()))
+
+	assert.ErrorIs(t, running.cancel(uuid.New(), ErrExecutionCancelled), ErrExecutionNotRunning)
+	require.NoError(t, running.cancel(executionID, ErrExecutionCancelled))
+	assert.ErrorIs(t, context.Cause(ctx), ErrExecutionCancelled)
+
+	untrack()
+	assert.False(t, running.has(executionID))
+	assert.ErrorIs(t, running.cancel(executionID, ErrExecutionCancelled), ErrExecutionNotRunning)
+}
+
+func TestInterruptedStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		cause error
+		want  string
+	}{
+		{"not interrupted", nil, ""},
+		{"cancelled", ErrExecutionCancelled, StatusCancelled},
+		{"timed out", fmt.Errorf("%w after 1m0s", ErrExecutionTimedOut), StatusTimedOut},
+		{"parent context cancelled", context.Canceled, StatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, interruptedStatus(tt.cause))
+		})
+	}
+}
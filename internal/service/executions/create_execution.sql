@@ -1,4 +1,9 @@
 -- name: ExecutionsServiceCreateExecution :one
-INSERT INTO executions (backup_id, status, message, path)
-VALUES (@backup_id, @status, @message, @path)
+INSERT INTO executions (
+  backup_id, status, message, path, parent_execution_id, attempt, is_pinned
+)
+VALUES (
+  @backup_id, @status, @message, @path, @parent_execution_id, @attempt,
+  @is_pinned
+)
 RETURNING *;
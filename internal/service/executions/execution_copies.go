@@ -0,0 +1,105 @@
+package executions
+
+import (
+	"context"
+	"database/sql"
+	"io"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
+	"github.com/google/uuid"
+)
+
+// executionCopy is a file uploaded by an execution together with the
+// credentials of the storage where it lives.
+type executionCopy = dbgen.ExecutionsServiceGetExecutionCopiesRow
+
+// CopyLocation returns the human readable name of the storage of a copy.
+func CopyLocation(isLocal bool, destinationName sql.NullString) string {
+	if isLocal {
+		return "Local"
+	}
+	return destinationName.String
+}
+
+// ListExecutionCopies returns the copies of an execution, the copy in the
+// destination of the backup first.
+func (s *Service) ListExecutionCopies(
+	ctx context.Context, executionID uuid.UUID,
+) ([]dbgen.ExecutionsServiceListExecutionCopiesRow, error) {
+	return s.dbgen.ExecutionsServiceListExecutionCopies(ctx, executionID)
+}
+
+// availableCopies returns the successfully uploaded copies of an execution,
+// the ones that look healthy first.
+func (s *Service) availableCopies(
+	ctx context.Context, executionID uuid.UUID,
+) ([]executionCopy, error) {
+	copies, err := s.dbgen.ExecutionsServiceGetExecutionCopies(
+		ctx, dbgen.ExecutionsServiceGetExecutionCopiesParams{
+			ExecutionID:   executionID,
+			DecryptionKey: s.env.PBW_ENCRYPTION_KEY,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make([]executionCopy, 0, len(copies))
+	for _, c := range copies {
+		if c.Status == "success" && c.Path.Valid {
+			available = append(available, c)
+		}
+	}
+	return available, nil
+}
+
+// copyBackend returns the backend of the storage where a copy lives.
+func (s *Service) copyBackend(c executionCopy) (storage.StorageBackend, error) {
+	return s.ints.StorageClient.BackendOrLocal(c.IsLocal, storage.Config{
+		Type:       c.Type.String,
+		BucketName: c.BucketName.String,
+		Region:     c.Region.String,
+		Endpoint:   c.Endpoint.String,
+		AccessKey:  c.DecryptedAccessKey,
+		SecretKey:  c.DecryptedSecretKey,
+		BasePath:   c.BasePath.String,
+		PrivateKey: c.DecryptedPrivateKey,
+		HostKey:    c.HostKey.String,
+		S3: storage.S3Options{
+			CredentialsMode:      c.S3CredentialsMode.String,
+			RoleARN:              c.S3RoleArn.String,
+			ExternalID:           c.S3ExternalID.String,
+			ServerSideEncryption: c.S3ServerSideEncryption.String,
+			KMSKeyID:             c.S3KmsKeyID.String,
+			SSECustomerKey:       c.DecryptedS3SseCustomerKey,
+			StorageClass:         c.S3StorageClass.String,
+			VirtualHostedStyle:   c.S3VirtualHostedStyle.Bool,
+			ObjectLockMode:       c.S3ObjectLockMode.String,
+			ObjectLockDays:       c.S3ObjectLockDays.Int32,
+			ObjectLockLegalHold:  c.S3ObjectLockLegalHold.Bool,
+		},
+	})
+}
+
+// openStoredFile returns a reader with the content of the file of a copy as
+// it is stored in the local backups directory or in the destination.
+func (s *Service) openStoredFile(
+	ctx context.Context, c executionCopy,
+) (io.ReadCloser, error) {
+	backend, err := s.copyBackend(c)
+	if err != nil {
+		return nil, err
+	}
+	return backend.Download(ctx, c.Path.String)
+}
+
+// deleteStoredFile deletes the file of a copy from the local backups
+// directory or the destination.
+func (s *Service) deleteStoredFile(ctx context.Context, c executionCopy) error {
+	backend, err := s.copyBackend(c)
+	if err != nil {
+		return err
+	}
+	return backend.Delete(ctx, c.Path.String)
+}
@@ -0,0 +1,81 @@
+-- name: ExecutionsServiceGetExecutionCopies :many
+SELECT
+  execution_copies.*,
+  executions.backup_id AS backup_id,
+  destinations.name AS destination_name,
+  destinations.type AS type,
+  destinations.base_path AS base_path,
+  destinations.host_key AS host_key,
+  (
+    CASE WHEN destinations.private_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.private_key, sqlc.arg('decryption_key')::TEXT)
+    ELSE ''
+    END
+  ) AS decrypted_private_key,
+  destinations.s3_server_side_encryption AS s3_server_side_encryption,
+  destinations.s3_kms_key_id AS s3_kms_key_id,
+  destinations.s3_storage_class AS s3_storage_class,
+  destinations.s3_virtual_hosted_style AS s3_virtual_hosted_style,
+  destinations.s3_object_lock_mode AS s3_object_lock_mode,
+  destinations.s3_object_lock_days AS s3_object_lock_days,
+  destinations.s3_object_lock_legal_hold AS s3_object_lock_legal_hold,
+  destinations.s3_credentials_mode AS s3_credentials_mode,
+  destinations.s3_role_arn AS s3_role_arn,
+  destinations.s3_external_id AS s3_external_id,
+  (
+    CASE WHEN destinations.s3_sse_customer_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.s3_sse_customer_key, sqlc.arg('decryption_key')::TEXT)
+    ELSE ''
+    END
+  ) AS decrypted_s3_sse_customer_key,
+  destinations.bucket_name AS bucket_name,
+  destinations.region AS region,
+  destinations.endpoint AS endpoint,
+  (
+    CASE WHEN destinations.access_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.access_key, sqlc.arg('decryption_key')::TEXT)
+    ELSE ''
+    END
+  ) AS decrypted_access_key,
+  (
+    CASE WHEN destinations.secret_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.secret_key, sqlc.arg('decryption_key')::TEXT)
+    ELSE ''
+    END
+  ) AS decrypted_secret_key,
+  (
+    CASE WHEN destinations.backup_encryption_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.backup_encryption_key, sqlc.arg('decryption_key')::TEXT)
+    ELSE ''
+    END
+  ) AS decrypted_backup_encryption_key
+FROM execution_copies
+INNER JOIN executions ON executions.id = execution_copies.execution_id
+LEFT JOIN destinations ON destinations.id = execution_copies.destination_id
+WHERE execution_copies.execution_id = @execution_id
+-- Healthy copies first so reads fall back to the rest only when needed
+ORDER BY
+  (execution_copies.status = 'success') DESC,
+  (COALESCE(execution_copies.verify_status, 'ok') = 'ok') DESC,
+  (destinations.test_ok IS DISTINCT FROM FALSE) DESC,
+  execution_copies.is_primary DESC,
+  execution_copies.created_at ASC;
+
+-- name: ExecutionsServiceListExecutionCopies :many
+SELECT
+  execution_copies.*,
+  destinations.name AS destination_name
+FROM execution_copies
+LEFT JOIN destinations ON destinations.id = execution_copies.destination_id
+WHERE execution_copies.execution_id = @execution_id
+ORDER BY
+  execution_copies.is_primary DESC,
+  execution_copies.is_local DESC,
+  destinations.name ASC;
+
+-- name: ExecutionsServiceSetExecutionCopyVerifyResult :exec
+UPDATE execution_copies
+SET
+  verify_status = @verify_status,
+  verified_at = NOW()
+WHERE id = @id;
@@ -12,6 +12,7 @@ type Service struct {
 	dbgen           *dbgen.Queries
 	ints            *integration.Integration
 	webhooksService *webhooks.Service
+	running         runningExecutions
 }
 
 func New(
@@ -0,0 +1,75 @@
+package executions
+
+import (
+	"errors"
+	"io"
+)
+
+// errEveryCopyFailed is returned by fanOut when every reader was closed
+// before the source was fully copied.
+var errEveryCopyFailed = errors.New("every copy of the backup failed")
+
+// fanOut copies the source into n readers so a single dump can be uploaded
+// to several destinations at the same time.
+//
+// A consumer that fails must close its reader with CloseWithError, the rest
+// keep receiving the data. If the source fails, every reader returns its
+// error. The returned channel receives the result of the copy once the
+// source is fully read or every reader is closed.
+func fanOut(src io.Reader, n int) ([]*io.PipeReader, <-chan error) {
+	readers := make([]*io.PipeReader, n)
+	writers := make([]*io.PipeWriter, n)
+	for i := range n {
+		readers[i], writers[i] = io.Pipe()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		alive := make([]bool, n)
+		for i := range alive {
+			alive[i] = true
+		}
+
+		var copyErr error
+		buf := make([]byte, 32*1024)
+		for {
+			nr, readErr := src.Read(buf)
+			if nr > 0 {
+				anyAlive := false
+				for i, w := range writers {
+					if !alive[i] {
+						continue
+					}
+					if _, err := w.Write(buf[:nr]); err != nil {
+						alive[i] = false
+						continue
+					}
+					anyAlive = true
+				}
+				if !anyAlive {
+					copyErr = errEveryCopyFailed
+					break
+				}
+			}
+			if readErr == io.EOF {
+				break
+			}
+			if readErr != nil {
+				copyErr = readErr
+				break
+			}
+		}
+
+		// Stop the producer when nobody reads its output anymore
+		if errors.Is(copyErr, errEveryCopyFailed) {
+			stopReader(src, copyErr)
+		}
+
+		for _, w := range writers {
+			_ = w.CloseWithError(copyErr)
+		}
+		done <- copyErr
+	}()
+
+	return readers, done
+}
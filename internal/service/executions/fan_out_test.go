@@ -0,0 +1,82 @@
+package executions
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFanOut(t *testing.T) {
+	data := bytes.Repeat([]byte("pgbackweb"), 50_000)
+
+	t.Run("every reader gets the data", func(t *testing.T) {
+		readers, done := fanOut(bytes.NewReader(data), 3)
+
+		results := make([][]byte, len(readers))
+		wg := sync.WaitGroup{}
+		for i, r := range readers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], _ = io.ReadAll(r)
+			}()
+		}
+		wg.Wait()
+
+		assert.NoError(t, <-done)
+		for _, result := range results {
+			assert.Equal(t, data, result)
+		}
+	})
+
+	t.Run("a failed reader doesn't stop the rest", func(t *testing.T) {
+		readers, done := fanOut(bytes.NewReader(data), 2)
+
+		failErr := errors.New("upload failed")
+		readers[0].CloseWithError(failErr)
+
+		result, err := io.ReadAll(readers[1])
+		assert.NoError(t, err)
+		assert.Equal(t, data, result)
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("every reader failed", func(t *testing.T) {
+		src, srcWriter := io.Pipe()
+		readers, done := fanOut(src, 2)
+
+		for _, r := range readers {
+			r.CloseWithError(errors.New("upload failed"))
+		}
+
+		_, err := srcWriter.Write(data)
+		assert.ErrorIs(t, <-done, errEveryCopyFailed)
+
+		// The producer is stopped
+		_, err = srcWriter.Write(data)
+		assert.Error(t, err)
+	})
+
+	t.Run("the source error reaches every reader", func(t *testing.T) {
+		src, srcWriter := io.Pipe()
+		readers, done := fanOut(src, 2)
+
+		dumpErr := errors.New("pg_dump failed")
+		go func() {
+			_, _ = srcWriter.Write(data[:100])
+			srcWriter.CloseWithError(dumpErr)
+		}()
+
+		for _, r := range readers {
+			go func() {
+				_, err := io.ReadAll(r)
+				assert.ErrorIs(t, err, dumpErr)
+			}()
+		}
+		assert.ErrorIs(t, <-done, dumpErr)
+	})
+}
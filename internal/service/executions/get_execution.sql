@@ -3,7 +3,14 @@ SELECT
   executions.*,
   databases.id AS database_id,
   databases.database_type AS database_database_type,
-  databases.version AS database_version
+  databases.version AS database_version,
+  backups.opt_clean AS backup_opt_clean,
+  backups.opt_if_exists AS backup_opt_if_exists,
+  backups.opt_create AS backup_opt_create,
+  backups.opt_jobs AS backup_opt_jobs,
+  backups.opt_no_owner AS backup_opt_no_owner,
+  backups.opt_no_privileges AS backup_opt_no_privileges,
+  backups.mode AS backup_mode
 FROM executions
 INNER JOIN backups ON backups.id = executions.backup_id
 INNER JOIN databases ON databases.id = backups.database_id
@@ -2,46 +2,70 @@ package executions
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
-	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
 	"github.com/google/uuid"
 )
 
+// ErrNoDirectLink is returned by GetExecutionDownloadLinkOrPath when no
+// available copy of the execution can be read directly, because they are
+// encrypted or stored in backends without download links (SFTP, WebDAV).
+// The file must be read with OpenExecutionFile instead.
+var ErrNoDirectLink = errors.New("execution has no copy that can be read directly")
+
 // GetExecutionDownloadLinkOrPath returns a download link for the file associated
-// with the given execution. If the execution is stored locally, the link will
-// be a file path.
+// with the given execution. If the execution is stored in a directory of this
+// server, the link will be a file path.
+//
+// The first available copy that is not encrypted and is stored in the local
+// backups directory, a local destination or a destination that supports
+// download links (S3, Azure Blob and GCS) is used.
 //
 // Returns a boolean indicating if the file is locally stored and the download
 // link/path.
 func (s *Service) GetExecutionDownloadLinkOrPath(
 	ctx context.Context, executionID uuid.UUID,
 ) (bool, string, error) {
-	data, err := s.dbgen.ExecutionsServiceGetDownloadLinkOrPathData(
-		ctx, dbgen.ExecutionsServiceGetDownloadLinkOrPathDataParams{
-			ExecutionID:   executionID,
-			DecryptionKey: s.env.PBW_ENCRYPTION_KEY,
-		},
-	)
+	copies, err := s.availableCopies(ctx, executionID)
 	if err != nil {
 		return false, "", err
 	}
 
-	if !data.Path.Valid {
+	if len(copies) == 0 {
 		return false, "", fmt.Errorf("execution has no file associated")
 	}
 
-	if data.IsLocal {
-		return true, s.ints.StorageClient.LocalGetFullPath(data.Path.String), nil
-	}
+	for _, c := range copies {
+		if encryption.IsEncrypted(c.FileExtension) {
+			continue
+		}
 
-	link, err := s.ints.StorageClient.S3GetDownloadLink(
-		data.DecryptedAccessKey, data.DecryptedSecretKey, data.Region.String,
-		data.Endpoint.String, data.BucketName.String, data.Path.String, time.Hour*12,
-	)
-	if err != nil {
-		return false, "", err
+		backend, err := s.copyBackend(c)
+		if err != nil {
+			continue
+		}
+
+		switch backend := backend.(type) {
+		case storage.FileSystemBackend:
+			path := backend.FullPath(c.Path.String)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			return true, path, nil
+
+		case storage.PresignBackend:
+			link, err := backend.DownloadLink(ctx, c.Path.String, time.Hour*12)
+			if err != nil {
+				continue
+			}
+			return false, link, nil
+		}
 	}
-	return false, link, nil
+
+	return false, "", ErrNoDirectLink
 }
@@ -0,0 +1,63 @@
+package executions
+
+import (
+	"context"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	// HeartbeatInterval is how often the running executions and restorations
+	// refresh their heartbeat.
+	HeartbeatInterval = 30 * time.Second
+	// StaleAfter is how long a running execution or restoration can go without
+	// a heartbeat before it's considered abandoned by a dead process.
+	StaleAfter = 5 * HeartbeatInterval
+)
+
+// Heartbeat calls touch every interval until the returned function is
+// called, the errors are logged and don't stop the heartbeat.
+func Heartbeat(
+	ctx context.Context, interval time.Duration,
+	touch func(ctx context.Context) error,
+) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := touch(ctx); err != nil && ctx.Err() == nil {
+					logger.Error("error refreshing heartbeat", logger.KV{
+						"error": err.Error(),
+					})
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// heartbeat keeps the heartbeat of a running execution fresh until the
+// returned function is called.
+func (s *Service) heartbeat(ctx context.Context, executionID uuid.UUID) func() {
+	return Heartbeat(
+		ctx, HeartbeatInterval, func(ctx context.Context) error {
+			return s.dbgen.ExecutionsServiceTouchExecution(ctx, executionID)
+		},
+	)
+}
@@ -0,0 +1,32 @@
+package executions
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeartbeat(t *testing.T) {
+	touches := atomic.Int32{}
+	stop := Heartbeat(
+		context.Background(), time.Millisecond, func(ctx context.Context) error {
+			if touches.Add(1) == 1 {
+				return errors.New("database unavailable")
+			}
+			return nil
+		},
+	)
+
+	assert.Eventually(t, func() bool {
+		return touches.Load() >= 3
+	}, time.Second, time.Millisecond)
+
+	stop()
+	stopped := touches.Load()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, stopped, touches.Load())
+}
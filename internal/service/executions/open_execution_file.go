@@ -0,0 +1,101 @@
+package executions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/google/uuid"
+)
+
+// OpenExecutionFile returns a reader with the content of the file associated
+// with the given execution. Encrypted files are decrypted while they are
+// read, so the content is never stored decrypted.
+//
+// The copies of the execution are tried in order, so the file can be read as
+// long as any of them is available.
+//
+// Returns the reader, that must be closed by the caller, and the name of the
+// file once decrypted.
+func (s *Service) OpenExecutionFile(
+	ctx context.Context, executionID uuid.UUID,
+) (io.ReadCloser, string, error) {
+	copies, err := s.availableCopies(ctx, executionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(copies) == 0 {
+		return nil, "", fmt.Errorf("execution has no file associated")
+	}
+
+	errs := []error{}
+	for _, c := range copies {
+		file, fileName, err := s.openCopy(ctx, c)
+		if err == nil {
+			return file, fileName, nil
+		}
+		errs = append(errs, fmt.Errorf(
+			"%s: %w", CopyLocation(c.IsLocal, c.DestinationName), err,
+		))
+	}
+
+	return nil, "", errors.Join(errs...)
+}
+
+// openCopy returns a reader with the decrypted content of a copy and the
+// name of the file once decrypted.
+func (s *Service) openCopy(
+	ctx context.Context, c executionCopy,
+) (io.ReadCloser, string, error) {
+	var key encryption.Key
+	isEncrypted := encryption.IsEncrypted(c.FileExtension)
+	if isEncrypted {
+		var ok bool
+		var err error
+		key, ok, err = s.BackupEncryptionKey(
+			c.IsLocal, c.DecryptedBackupEncryptionKey,
+		)
+		if err != nil {
+			return nil, "", err
+		}
+		if !ok {
+			return nil, "", fmt.Errorf(
+				"execution file is encrypted but no encryption key is configured",
+			)
+		}
+		if key.Fingerprint() != c.EncryptionKeyFingerprint.String {
+			return nil, "", fmt.Errorf(
+				"execution file was encrypted with the key %s but the configured key is %s",
+				c.EncryptionKeyFingerprint.String, key.Fingerprint(),
+			)
+		}
+	}
+
+	file, err := s.openStoredFile(ctx, c)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fileName := filepath.Base(c.Path.String)
+	if !isEncrypted {
+		return file, fileName, nil
+	}
+
+	decrypted, err := encryption.NewReader(file, key)
+	if err != nil {
+		_ = file.Close()
+		return nil, "", err
+	}
+
+	return decryptedFile{Reader: decrypted, Closer: file},
+		encryption.TrimExtension(fileName), nil
+}
+
+type decryptedFile struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,121 @@
+package executions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/service/webhooks"
+	"github.com/google/uuid"
+)
+
+// staleExecutionMessage is the message of the executions interrupted by the
+// shutdown or crash of the process that was running them.
+const staleExecutionMessage = "The execution was interrupted because PG Back Web stopped " +
+	"while it was running, the partially uploaded files were removed"
+
+// RecoverStaleExecutions marks as failed the running executions whose
+// heartbeat stopped because the process running them died and deletes the
+// files their uploads left behind. The failed execution webhooks are not
+// triggered for the executions whose job is queued again.
+func (s *Service) RecoverStaleExecutions() {
+	ctx := context.Background()
+
+	stale, err := s.dbgen.ExecutionsServiceGetStaleExecutions(
+		ctx, time.Now().Add(-StaleAfter),
+	)
+	if err != nil {
+		logger.Error("error getting stale executions", logger.KV{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, execution := range stale {
+		// The heartbeat of an execution of this process may have failed to be
+		// written, it's still alive
+		if s.running.has(execution.ID) {
+			continue
+		}
+
+		if err := s.recoverStaleExecution(ctx, execution.ID); err != nil {
+			logger.Error("error recovering stale execution", logger.KV{
+				"execution_id": execution.ID.String(),
+				"error":        err.Error(),
+			})
+			continue
+		}
+		logger.Info("stale execution marked as failed", logger.KV{
+			"execution_id": execution.ID.String(),
+			"backup_id":    execution.BackupID.String(),
+		})
+		// The stale job of the execution is queued again, the backup runs again
+		if s.env.PBW_REQUEUE_INTERRUPTED_BACKUPS && execution.HasJob {
+			continue
+		}
+		s.webhooksService.RunExecutionFailed(
+			execution.BackupID, webhooks.ExecutionDetails{
+				ExecutionID: execution.ID,
+				Attempts:    execution.Attempt,
+				MaxAttempts: execution.Attempt,
+			},
+		)
+	}
+}
+
+// recoverStaleExecution deletes the files of the copies of the execution that
+// were being uploaded and marks them and the execution as failed.
+func (s *Service) recoverStaleExecution(
+	ctx context.Context, executionID uuid.UUID,
+) error {
+	copies, err := s.dbgen.ExecutionsServiceGetExecutionCopies(
+		ctx, dbgen.ExecutionsServiceGetExecutionCopiesParams{
+			ExecutionID:   executionID,
+			DecryptionKey: s.env.PBW_ENCRYPTION_KEY,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range copies {
+		if c.Status != "running" || !c.Path.Valid {
+			continue
+		}
+
+		err := s.deleteStoredFile(ctx, c)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logger.Error("error removing partial backup file", logger.KV{
+				"execution_id": executionID.String(),
+				"location":     CopyLocation(c.IsLocal, c.DestinationName),
+				"path":         c.Path.String,
+				"error":        err.Error(),
+			})
+		}
+	}
+
+	message := sql.NullString{Valid: true, String: staleExecutionMessage}
+	err = s.dbgen.ExecutionsServiceFailRunningExecutionCopies(
+		ctx, dbgen.ExecutionsServiceFailRunningExecutionCopiesParams{
+			ExecutionID: executionID,
+			Message:     message,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.dbgen.ExecutionsServiceUpdateExecution(
+		ctx, dbgen.ExecutionsServiceUpdateExecutionParams{
+			ID:         executionID,
+			Status:     sql.NullString{Valid: true, String: "failed"},
+			Message:    message,
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		},
+	)
+	return err
+}
@@ -0,0 +1,30 @@
+-- name: ExecutionsServiceTouchExecution :exec
+UPDATE executions
+SET heartbeat_at = NOW()
+WHERE id = @id AND status = 'running';
+
+-- name: ExecutionsServiceGetStaleExecutions :many
+SELECT
+  executions.id,
+  executions.backup_id,
+  executions.attempt,
+  EXISTS (
+    SELECT 1 FROM backup_jobs
+    WHERE
+      backup_jobs.execution_id = executions.id
+      AND backup_jobs.status IN ('running', 'queued')
+  )::BOOLEAN AS has_job
+FROM executions
+WHERE
+  executions.status = 'running'
+  AND COALESCE(executions.heartbeat_at, executions.started_at)
+    < sqlc.arg('stale_before')::TIMESTAMPTZ
+ORDER BY executions.started_at ASC;
+
+-- name: ExecutionsServiceFailRunningExecutionCopies :exec
+UPDATE execution_copies
+SET
+  status = 'failed',
+  message = @message,
+  finished_at = NOW()
+WHERE execution_id = @execution_id AND status = 'running';
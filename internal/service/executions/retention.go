@@ -0,0 +1,158 @@
+package executions
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxRetentionCount is the maximum value of every count of a retention
+// policy.
+const MaxRetentionCount = 1000
+
+// MaxRetentionDays is the maximum value of the days of a retention policy.
+const MaxRetentionDays = 36500
+
+// RetentionPolicy decides which executions of a backup are deleted.
+//
+// Successful executions are kept while they match any of the rules: they
+// finished in the last Days days, they are one of the Last most recent ones,
+// or they are the most recent one of one of the Daily most recent days, the
+// Weekly most recent weeks, and so on (grandfather-father-son). Executions
+// that failed are only deleted after Days days.
+//
+// The MinKeep most recent successful executions are never deleted, so a run
+// of failed executions can't leave the backup without a good file.
+type RetentionPolicy struct {
+	Days    int
+	Last    int
+	Daily   int
+	Weekly  int
+	Monthly int
+	Yearly  int
+	MinKeep int
+}
+
+// RetentionExecution is the data of an execution needed to apply a
+// retention policy.
+type RetentionExecution struct {
+	ID         uuid.UUID
+	Status     string
+	FinishedAt time.Time
+}
+
+// Validate checks that every value of the policy is in range.
+func (p RetentionPolicy) Validate() error {
+	if p.Days < 0 || p.Days > MaxRetentionDays {
+		return fmt.Errorf("retention days must be between 0 and %d", MaxRetentionDays)
+	}
+
+	counts := map[string]int{
+		"keep last":    p.Last,
+		"keep daily":   p.Daily,
+		"keep weekly":  p.Weekly,
+		"keep monthly": p.Monthly,
+		"keep yearly":  p.Yearly,
+		"minimum keep": p.MinKeep,
+	}
+	for name, count := range counts {
+		if count < 0 || count > MaxRetentionCount {
+			return fmt.Errorf(
+				"%s must be between 0 and %d", name, MaxRetentionCount,
+			)
+		}
+	}
+
+	return nil
+}
+
+// IsEnabled reports whether the policy deletes executions at all.
+func (p RetentionPolicy) IsEnabled() bool {
+	return p.Days > 0 || p.hasGFSRules()
+}
+
+func (p RetentionPolicy) hasGFSRules() bool {
+	return p.Last > 0 || p.Daily > 0 || p.Weekly > 0 || p.Monthly > 0 ||
+		p.Yearly > 0
+}
+
+// Expired returns the executions that must be deleted according to the
+// policy, sorted from the newest to the oldest. The periods of the
+// grandfather-father-son rules are calculated in the given location.
+func (p RetentionPolicy) Expired(
+	executions []RetentionExecution, now time.Time, loc *time.Location,
+) []RetentionExecution {
+	if !p.IsEnabled() {
+		return nil
+	}
+
+	sorted := make([]RetentionExecution, len(executions))
+	copy(sorted, executions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].FinishedAt.After(sorted[j].FinishedAt)
+	})
+
+	successful := []RetentionExecution{}
+	for _, execution := range sorted {
+		if execution.Status == "success" {
+			successful = append(successful, execution)
+		}
+	}
+
+	keep := map[uuid.UUID]bool{}
+	for i, execution := range successful {
+		if i < p.MinKeep || i < p.Last {
+			keep[execution.ID] = true
+		}
+	}
+
+	periods := []struct {
+		count int
+		key   func(t time.Time) string
+	}{
+		{p.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{p.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{p.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+		{p.Yearly, func(t time.Time) string { return t.Format("2006") }},
+	}
+	for _, period := range periods {
+		seen := map[string]bool{}
+		for _, execution := range successful {
+			if len(seen) >= period.count {
+				break
+			}
+			key := period.key(execution.FinishedAt.In(loc))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			keep[execution.ID] = true
+		}
+	}
+
+	expired := []RetentionExecution{}
+	for _, execution := range sorted {
+		if keep[execution.ID] {
+			continue
+		}
+
+		withinDays := p.Days > 0 &&
+			execution.FinishedAt.Add(time.Duration(p.Days)*24*time.Hour).After(now)
+		if withinDays {
+			continue
+		}
+
+		if execution.Status != "success" && p.Days == 0 {
+			continue
+		}
+
+		expired = append(expired, execution)
+	}
+
+	return expired
+}
@@ -0,0 +1,114 @@
+package executions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// dailyExecutions returns one execution per day for the given number of days
+// before now, the newest first.
+func dailyExecutions(now time.Time, days int, status string) []RetentionExecution {
+	executions := make([]RetentionExecution, 0, days)
+	for i := 0; i < days; i++ {
+		executions = append(executions, RetentionExecution{
+			ID:         uuid.New(),
+			Status:     status,
+			FinishedAt: now.Add(-time.Duration(i) * 24 * time.Hour),
+		})
+	}
+	return executions
+}
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	assert.NoError(t, RetentionPolicy{}.Validate())
+	assert.NoError(t, RetentionPolicy{Days: 30, Daily: 7, MinKeep: 1}.Validate())
+	assert.Error(t, RetentionPolicy{Days: -1}.Validate())
+	assert.Error(t, RetentionPolicy{Weekly: MaxRetentionCount + 1}.Validate())
+	assert.Error(t, RetentionPolicy{MinKeep: -1}.Validate())
+}
+
+func TestRetentionPolicyExpired(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	executions := dailyExecutions(now, 400, "success")
+
+	tests := []struct {
+		name     string
+		policy   RetentionPolicy
+		wantKept int
+	}{
+		{"disabled", RetentionPolicy{MinKeep: 1}, 400},
+		{"days", RetentionPolicy{Days: 30}, 30},
+		{"last", RetentionPolicy{Last: 5}, 5},
+		{"daily", RetentionPolicy{Daily: 7}, 7},
+		{"daily and last overlap", RetentionPolicy{Last: 3, Daily: 7}, 7},
+		{"monthly", RetentionPolicy{Monthly: 12}, 12},
+		{"yearly", RetentionPolicy{Yearly: 5}, 2},
+		{
+			"gfs",
+			RetentionPolicy{Daily: 7, Weekly: 4, Monthly: 12, Yearly: 2},
+			// 7 dailies, 3 more weeks, 10 more months (the current and the
+			// previous month are already kept) and 1 more year
+			7 + 3 + 10 + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expired := tt.policy.Expired(executions, now, time.UTC)
+			assert.Equal(t, tt.wantKept, len(executions)-len(expired))
+
+			for _, execution := range expired {
+				assert.NotEqual(t, executions[0].ID, execution.ID)
+			}
+		})
+	}
+}
+
+func TestRetentionPolicyMinKeep(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	// The last good execution is old and everything after it failed
+	executions := dailyExecutions(now, 10, "failed")
+	good := RetentionExecution{
+		ID: uuid.New(), Status: "success", FinishedAt: now.AddDate(0, 0, -60),
+	}
+	executions = append(executions, good)
+
+	expired := RetentionPolicy{Days: 5, MinKeep: 1}.Expired(executions, now, time.UTC)
+	assert.Len(t, expired, 5)
+	for _, execution := range expired {
+		assert.NotEqual(t, good.ID, execution.ID)
+		assert.Equal(t, "failed", execution.Status)
+	}
+
+	expired = RetentionPolicy{Days: 5}.Expired(executions, now, time.UTC)
+	assert.Len(t, expired, 6)
+}
+
+func TestRetentionPolicyFailedExecutions(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	executions := dailyExecutions(now, 10, "failed")
+
+	// Failed executions are only deleted by the days rule
+	expired := RetentionPolicy{Last: 1, Daily: 1}.Expired(executions, now, time.UTC)
+	assert.Empty(t, expired)
+}
+
+func TestRetentionPolicyTimeZone(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	// Both executions are on the same day in UTC but on different days in
+	// UTC-5
+	executions := []RetentionExecution{
+		{ID: uuid.New(), Status: "success", FinishedAt: time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), Status: "success", FinishedAt: time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC)},
+	}
+
+	policy := RetentionPolicy{Daily: 2}
+	assert.Len(t, policy.Expired(executions, now, time.UTC), 1)
+	assert.Empty(t, policy.Expired(executions, now, loc))
+}
@@ -4,33 +4,52 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/eduardolat/pgbackweb/internal/integration/clickhouse"
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
 	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
 	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/service/webhooks"
 	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
 	"github.com/google/uuid"
 )
 
-// RunExecution runs a backup execution
-func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
-	updateExec := func(params dbgen.ExecutionsServiceUpdateExecutionParams) error {
+// RunExecution runs a backup execution and returns it once it finishes, the
+// error is only returned when the execution can't be created or updated.
+//
+// The failed webhooks only run when the attempt is the last one, the retries
+// are left to the caller.
+func (s *Service) RunExecution(
+	ctx context.Context, backupID uuid.UUID, attempt Attempt,
+) (dbgen.Execution, error) {
+	attempt = attempt.normalize()
+
+	updateExec := func(
+		params dbgen.ExecutionsServiceUpdateExecutionParams,
+	) (dbgen.Execution, error) {
+		details := webhooks.ExecutionDetails{
+			ExecutionID: params.ID,
+			Attempts:    attempt.Number,
+			MaxAttempts: attempt.MaxAttempts,
+		}
+
 		if params.Status.String == "success" {
-			s.webhooksService.RunExecutionSuccess(backupID)
+			s.webhooksService.RunExecutionSuccess(backupID, details)
 		}
 
-		if params.Status.String == "failed" {
-			s.webhooksService.RunExecutionFailed(backupID)
+		failed := params.Status.String == "failed" ||
+			params.Status.String == StatusTimedOut
+		if failed && attempt.IsFinal() {
+			s.webhooksService.RunExecutionFailed(backupID, details)
 		}
 
-		_, err := s.dbgen.ExecutionsServiceUpdateExecution(
-			ctx, params,
-		)
-		return err
+		return s.dbgen.ExecutionsServiceUpdateExecution(ctx, params)
 	}
 
 	logError := func(err error) {
@@ -48,35 +67,63 @@ func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
 	)
 	if err != nil {
 		logError(err)
-		return err
+		return dbgen.Execution{}, err
+	}
+
+	replicas, err := s.dbgen.ExecutionsServiceGetBackupReplicas(
+		ctx, dbgen.ExecutionsServiceGetBackupReplicasParams{
+			BackupID:      backupID,
+			EncryptionKey: s.env.PBW_ENCRYPTION_KEY,
+		},
+	)
+	if err != nil {
+		logError(err)
+		return dbgen.Execution{}, err
 	}
 
 	ex, err := s.CreateExecution(ctx, dbgen.ExecutionsServiceCreateExecutionParams{
-		BackupID: backupID,
-		Status:   "running",
+		BackupID:          backupID,
+		Status:            "running",
+		ParentExecutionID: attempt.ParentExecutionID,
+		Attempt:           attempt.Number,
+		IsPinned:          attempt.Pinned,
 	})
 	if err != nil {
 		logError(err)
-		return err
+		return dbgen.Execution{}, err
 	}
 
-	if !back.BackupIsLocal {
-		err = s.ints.StorageClient.S3Test(
-			back.DecryptedDestinationAccessKey, back.DecryptedDestinationSecretKey,
-			back.DestinationRegion.String, back.DestinationEndpoint.String,
-			back.DestinationBucketName.String,
+	// The recovery of stale executions finds the job of the execution with
+	// this link if the process dies while it runs
+	if attempt.JobID.Valid {
+		err := s.dbgen.ExecutionsServiceLinkJobExecution(
+			ctx, dbgen.ExecutionsServiceLinkJobExecutionParams{
+				ExecutionID: uuid.NullUUID{Valid: true, UUID: ex.ID},
+				JobID:       attempt.JobID.UUID,
+			},
 		)
 		if err != nil {
 			logError(err)
-			return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
-				ID:         ex.ID,
-				Status:     sql.NullString{Valid: true, String: "failed"},
-				Message:    sql.NullString{Valid: true, String: err.Error()},
-				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
-			})
 		}
 	}
 
+	// The dump and the uploads run with their own context, it's cancelled by
+	// CancelExecution or when the max duration of the backup is exceeded. The
+	// execution records keep using ctx so they can be updated afterwards.
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if back.BackupMaxDurationMinutes > 0 {
+		maxDuration := time.Duration(back.BackupMaxDurationMinutes) * time.Minute
+		var cancelTimeout context.CancelFunc
+		runCtx, cancelTimeout = context.WithTimeoutCause(
+			runCtx, maxDuration,
+			fmt.Errorf("%w after %s", ErrExecutionTimedOut, maxDuration),
+		)
+		defer cancelTimeout()
+	}
+	defer s.running.track(ex.ID, cancel)()
+	defer s.heartbeat(ctx, ex.ID)()
+
 	// Get database client based on database type
 	dbClient, err := s.ints.GetDatabaseClient(back.DatabaseDatabaseType)
 	if err != nil {
@@ -101,6 +148,56 @@ func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
 		})
 	}
 
+	// The pre hooks run once the database is reachable. Once they ran, the
+	// post hooks run whatever the outcome of the dump, so they can undo what
+	// the pre hooks did
+	backupHooks, err := hooks.Parse(back.BackupHooks)
+	if err != nil {
+		logError(err)
+		return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
+			ID:         ex.ID,
+			Status:     sql.NullString{Valid: true, String: "failed"},
+			Message:    sql.NullString{Valid: true, String: err.Error()},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+	appendLog := s.appendLog(ctx, ex.ID)
+	hooksTarget := func(stage string, status string) HooksTarget {
+		return HooksTarget{
+			DatabaseType: back.DatabaseDatabaseType,
+			Version:      back.DatabaseVersion,
+			ConnString:   back.DecryptedDatabaseConnectionString,
+			Env: map[string]string{
+				"PBW_HOOK_STAGE":    stage,
+				"PBW_BACKUP_ID":     backupID.String(),
+				"PBW_EXECUTION_ID":  ex.ID.String(),
+				"PBW_DATABASE_NAME": back.DatabaseName,
+				"PBW_BACKUP_STATUS": status,
+			},
+		}
+	}
+	runPostHooks := func(status string) error {
+		return s.RunHooks(
+			ctx, backupHooks.Stage(hooks.StagePost),
+			hooksTarget(hooks.StagePost, status), appendLog,
+		)
+	}
+
+	err = s.RunHooks(
+		runCtx, backupHooks.Stage(hooks.StagePre),
+		hooksTarget(hooks.StagePre, ""), appendLog,
+	)
+	if err != nil {
+		logError(err)
+		_ = runPostHooks("failed")
+		return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
+			ID:         ex.ID,
+			Status:     sql.NullString{Valid: true, String: "failed"},
+			Message:    sql.NullString{Valid: true, String: err.Error()},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+
 	// Create dump parameters based on database type
 	var dumpParams database.DumpParams
 	switch back.DatabaseDatabaseType {
@@ -112,6 +209,17 @@ func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
 			IfExists:   back.BackupOptIfExists,
 			Create:     back.BackupOptCreate,
 			NoComments: back.BackupOptNoComments,
+			Format:     back.BackupOptFormat,
+			Jobs:       int(back.BackupOptJobs),
+
+			Schemas:          back.BackupOptSchemas,
+			ExcludeSchemas:   back.BackupOptExcludeSchemas,
+			Tables:           back.BackupOptTables,
+			ExcludeTables:    back.BackupOptExcludeTables,
+			ExcludeTableData: back.BackupOptExcludeTableData,
+			NoOwner:          back.BackupOptNoOwner,
+			NoPrivileges:     back.BackupOptNoPrivileges,
+			Role:             back.BackupOptRole,
 		}
 	case "clickhouse":
 		// ClickHouse backup parameters
@@ -127,59 +235,139 @@ func (s *Service) RunExecution(ctx context.Context, backupID uuid.UUID) error {
 		dumpParams = nil
 	}
 
-	dumpReader := dbClient.DumpZip(back.DatabaseVersion, back.DecryptedDatabaseConnectionString, dumpParams)
-
-	date := time.Now().Format(timeutil.LayoutSlashYYYYMMDD)
-	file := fmt.Sprintf(
-		"dump-%s-%s.zip",
-		time.Now().Format(timeutil.LayoutYYYYMMDDHHMMSS),
-		uuid.NewString(),
-	)
-	path := strutil.CreatePath(false, back.BackupDestDir, date, file)
-	fileSize := int64(0)
+	comp := compression.Params{
+		Codec: back.BackupCompression,
+		Level: int(back.BackupCompressionLevel),
+	}
+	filePrefix := "dump"
+	var dumpReader io.Reader
+	var fileExtension string
+	var baseBackupInfo *postgres.BaseBackupInfo
 
-	if back.BackupIsLocal {
-		fileSize, err = s.ints.StorageClient.LocalUpload(path, dumpReader)
+	if back.BackupMode == postgres.BackupModePhysical {
+		pgVersion, err := s.ints.PGClient.ParseVersionPG(back.DatabaseVersion)
 		if err != nil {
 			logError(err)
 			return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
 				ID:         ex.ID,
 				Status:     sql.NullString{Valid: true, String: "failed"},
 				Message:    sql.NullString{Valid: true, String: err.Error()},
-				Path:       sql.NullString{Valid: true, String: path},
 				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
 			})
 		}
+
+		filePrefix = "base"
+		dumpReader, fileExtension, baseBackupInfo = s.ints.PGClient.BaseBackup(
+			runCtx, pgVersion, back.DecryptedDatabaseConnectionString, comp,
+		)
+	} else {
+		dumpReader, fileExtension = dbClient.DumpZip(
+			runCtx, back.DatabaseVersion, back.DecryptedDatabaseConnectionString, dumpParams, comp,
+		)
+	}
+
+	date := time.Now().Format(timeutil.LayoutSlashYYYYMMDD)
+	file := fmt.Sprintf(
+		"%s-%s-%s",
+		filePrefix,
+		time.Now().Format(timeutil.LayoutYYYYMMDDHHMMSS),
+		uuid.NewString(),
+	)
+	basePath := strutil.CreatePath(false, back.BackupDestDir, date, file)
+
+	// The dump is taken once and uploaded to every destination of the backup
+	results := s.uploadCopies(
+		runCtx, ex.ID, uploadTargets(back, replicas), dumpReader, basePath,
+		fileExtension,
+	)
+
+	// The execution describes the copy in the destination of the backup, or
+	// the first successful copy if that upload failed
+	reference, succeeded := results[0], 0
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		if succeeded == 0 && reference.Err != nil {
+			reference = result
+		}
+		succeeded++
 	}
+	copiesErr := copyErrors(results)
 
-	if !back.BackupIsLocal {
-		fileSize, err = s.ints.StorageClient.S3Upload(
-			back.DecryptedDestinationAccessKey, back.DecryptedDestinationSecretKey,
-			back.DestinationRegion.String, back.DestinationEndpoint.String,
-			back.DestinationBucketName.String, path, dumpReader,
+	// The partial files of the interrupted uploads were already removed
+	cause := context.Cause(runCtx)
+	if status := interruptedStatus(cause); status != "" && copiesErr != nil {
+		logError(cause)
+		_ = runPostHooks(status)
+		return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
+			ID:          ex.ID,
+			Status:      sql.NullString{Valid: true, String: status},
+			Message:     sql.NullString{Valid: true, String: cause.Error()},
+			Compression: sql.NullString{Valid: true, String: comp.Codec},
+			FinishedAt:  sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+
+	if succeeded == 0 {
+		logError(copiesErr)
+		_ = runPostHooks("failed")
+		return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
+			ID:                       ex.ID,
+			Status:                   sql.NullString{Valid: true, String: "failed"},
+			Message:                  sql.NullString{Valid: true, String: copiesErr.Error()},
+			Path:                     sql.NullString{Valid: true, String: reference.Path},
+			Compression:              sql.NullString{Valid: true, String: comp.Codec},
+			FileExtension:            sql.NullString{Valid: true, String: reference.FileExtension},
+			EncryptionKeyFingerprint: reference.Fingerprint,
+			FinishedAt:               sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+
+	message := "Backup created successfully"
+	if copiesErr != nil {
+		logError(copiesErr)
+		message = fmt.Sprintf(
+			"Backup created successfully in %d of %d destinations, the rest failed: %s",
+			succeeded, len(results), copiesErr.Error(),
 		)
-		if err != nil {
-			logError(err)
-			return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
-				ID:         ex.ID,
-				Status:     sql.NullString{Valid: true, String: "failed"},
-				Message:    sql.NullString{Valid: true, String: err.Error()},
-				Path:       sql.NullString{Valid: true, String: path},
-				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
-			})
+	}
+
+	walStartLSN, walEndLSN := sql.NullString{}, sql.NullString{}
+	if baseBackupInfo != nil {
+		walStartLSN = sql.NullString{
+			Valid: baseBackupInfo.StartLSN != "", String: baseBackupInfo.StartLSN,
+		}
+		walEndLSN = sql.NullString{
+			Valid: baseBackupInfo.EndLSN != "", String: baseBackupInfo.EndLSN,
 		}
 	}
 
+	// A post hook that fails with the abort policy fails the execution, the
+	// uploaded files are kept
+	status := "success"
+	if err := runPostHooks(status); err != nil {
+		logError(err)
+		status = "failed"
+		message = fmt.Sprintf("%s, but %s", message, err.Error())
+	}
+
 	logger.Info("backup created successfully", logger.KV{
 		"backup_id":    backupID.String(),
 		"execution_id": ex.ID.String(),
 	})
 	return updateExec(dbgen.ExecutionsServiceUpdateExecutionParams{
-		ID:         ex.ID,
-		Status:     sql.NullString{Valid: true, String: "success"},
-		Message:    sql.NullString{Valid: true, String: "Backup created successfully"},
-		Path:       sql.NullString{Valid: true, String: path},
-		FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
-		FileSize:   sql.NullInt64{Valid: true, Int64: fileSize},
+		ID:                       ex.ID,
+		Status:                   sql.NullString{Valid: true, String: status},
+		Message:                  sql.NullString{Valid: true, String: message},
+		Path:                     sql.NullString{Valid: true, String: reference.Path},
+		Compression:              sql.NullString{Valid: true, String: comp.Codec},
+		FileExtension:            sql.NullString{Valid: true, String: reference.FileExtension},
+		EncryptionKeyFingerprint: reference.Fingerprint,
+		FinishedAt:               sql.NullTime{Valid: true, Time: time.Now()},
+		FileSize:                 sql.NullInt64{Valid: true, Int64: reference.FileSize},
+		Checksum:                 sql.NullString{Valid: true, String: reference.Checksum},
+		WalStartLsn:              walStartLSN,
+		WalEndLsn:                walEndLSN,
 	})
 }
@@ -2,6 +2,7 @@
 SELECT
   backups.is_active as backup_is_active,
   backups.is_local as backup_is_local,
+  backups.destination_id as backup_destination_id,
   backups.dest_dir as backup_dest_dir,
   backups.opt_data_only as backup_opt_data_only,
   backups.opt_schema_only as backup_opt_schema_only,
@@ -9,11 +10,53 @@ SELECT
   backups.opt_if_exists as backup_opt_if_exists,
   backups.opt_create as backup_opt_create,	
   backups.opt_no_comments as backup_opt_no_comments,
+  backups.opt_format as backup_opt_format,
+  backups.opt_jobs as backup_opt_jobs,
+  backups.compression as backup_compression,
+  backups.compression_level as backup_compression_level,
+  backups.mode as backup_mode,
+  backups.max_duration_minutes as backup_max_duration_minutes,
+  backups.hooks as backup_hooks,
+  backups.opt_schemas as backup_opt_schemas,
+  backups.opt_exclude_schemas as backup_opt_exclude_schemas,
+  backups.opt_tables as backup_opt_tables,
+  backups.opt_exclude_tables as backup_opt_exclude_tables,
+  backups.opt_exclude_table_data as backup_opt_exclude_table_data,
+  backups.opt_no_owner as backup_opt_no_owner,
+  backups.opt_no_privileges as backup_opt_no_privileges,
+  backups.opt_role as backup_opt_role,
 
   pgp_sym_decrypt(databases.connection_string, @encryption_key) AS decrypted_database_connection_string,
   databases.database_type as database_database_type,
   databases.version as database_version,
+  databases.name as database_name,
 
+  destinations.name as destination_name,
+  destinations.type as destination_type,
+  destinations.base_path as destination_base_path,
+  destinations.host_key as destination_host_key,
+  (
+    CASE WHEN destinations.private_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.private_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_private_key,
+  destinations.s3_server_side_encryption AS destination_s3_server_side_encryption,
+  destinations.s3_kms_key_id AS destination_s3_kms_key_id,
+  destinations.s3_storage_class AS destination_s3_storage_class,
+  destinations.s3_virtual_hosted_style AS destination_s3_virtual_hosted_style,
+  destinations.s3_object_lock_mode AS destination_s3_object_lock_mode,
+  destinations.s3_object_lock_days AS destination_s3_object_lock_days,
+  destinations.s3_object_lock_legal_hold AS destination_s3_object_lock_legal_hold,
+  destinations.s3_credentials_mode AS destination_s3_credentials_mode,
+  destinations.s3_role_arn AS destination_s3_role_arn,
+  destinations.s3_external_id AS destination_s3_external_id,
+  (
+    CASE WHEN destinations.s3_sse_customer_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.s3_sse_customer_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_s3_sse_customer_key,
   destinations.bucket_name as destination_bucket_name,
   destinations.region as destination_region,
   destinations.endpoint as destination_endpoint,
@@ -28,8 +71,97 @@ SELECT
     THEN pgp_sym_decrypt(destinations.secret_key, @encryption_key)
     ELSE ''
     END
-  ) AS decrypted_destination_secret_key
+  ) AS decrypted_destination_secret_key,
+  (
+    CASE WHEN destinations.backup_encryption_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.backup_encryption_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_backup_encryption_key
 FROM backups
 INNER JOIN databases ON backups.database_id = databases.id
 LEFT JOIN destinations ON backups.destination_id = destinations.id
 WHERE backups.id = @backup_id;
+
+-- name: ExecutionsServiceGetBackupReplicas :many
+SELECT
+  backup_replicas.destination_id,
+  backup_replicas.is_local,
+
+  destinations.name as destination_name,
+  destinations.type as destination_type,
+  destinations.base_path as destination_base_path,
+  destinations.host_key as destination_host_key,
+  (
+    CASE WHEN destinations.private_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.private_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_private_key,
+  destinations.s3_server_side_encryption AS destination_s3_server_side_encryption,
+  destinations.s3_kms_key_id AS destination_s3_kms_key_id,
+  destinations.s3_storage_class AS destination_s3_storage_class,
+  destinations.s3_virtual_hosted_style AS destination_s3_virtual_hosted_style,
+  destinations.s3_object_lock_mode AS destination_s3_object_lock_mode,
+  destinations.s3_object_lock_days AS destination_s3_object_lock_days,
+  destinations.s3_object_lock_legal_hold AS destination_s3_object_lock_legal_hold,
+  destinations.s3_credentials_mode AS destination_s3_credentials_mode,
+  destinations.s3_role_arn AS destination_s3_role_arn,
+  destinations.s3_external_id AS destination_s3_external_id,
+  (
+    CASE WHEN destinations.s3_sse_customer_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.s3_sse_customer_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_s3_sse_customer_key,
+  destinations.bucket_name as destination_bucket_name,
+  destinations.region as destination_region,
+  destinations.endpoint as destination_endpoint,
+  (
+    CASE WHEN destinations.access_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.access_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_access_key,
+  (
+    CASE WHEN destinations.secret_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.secret_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_secret_key,
+  (
+    CASE WHEN destinations.backup_encryption_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.backup_encryption_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_backup_encryption_key
+FROM backup_replicas
+LEFT JOIN destinations ON backup_replicas.destination_id = destinations.id
+WHERE backup_replicas.backup_id = @backup_id
+ORDER BY backup_replicas.is_local DESC, destinations.name ASC;
+
+-- name: ExecutionsServiceCreateExecutionCopy :one
+INSERT INTO execution_copies (
+  execution_id, destination_id, is_local, is_primary, path, file_extension,
+  encryption_key_fingerprint
+)
+VALUES (
+  @execution_id, sqlc.narg('destination_id'), @is_local, @is_primary, @path,
+  @file_extension, sqlc.narg('encryption_key_fingerprint')
+)
+RETURNING *;
+
+-- name: ExecutionsServiceUpdateExecutionCopy :exec
+UPDATE execution_copies
+SET
+  status = @status,
+  message = sqlc.narg('message'),
+  file_size = sqlc.narg('file_size'),
+  checksum = sqlc.narg('checksum'),
+  finished_at = NOW()
+WHERE id = @id;
+
+-- name: ExecutionsServiceLinkJobExecution :exec
+UPDATE backup_jobs
+SET execution_id = @execution_id
+WHERE id = @job_id;
@@ -0,0 +1,74 @@
+package executions
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
+)
+
+// ValidateHooks checks the hooks of a backup or restoration before they are
+// saved, the command hooks are only accepted when PBW_ENABLE_COMMAND_HOOKS
+// is set.
+func (s *Service) ValidateHooks(h hooks.Hooks) error {
+	return h.Validate(s.env.PBW_ENABLE_COMMAND_HOOKS)
+}
+
+// HooksTarget is the database the hooks of a backup or restoration run
+// against.
+type HooksTarget struct {
+	DatabaseType string
+	Version      string
+	ConnString   string
+	// Env are the PBW_* variables passed to the command hooks, they must not
+	// contain secrets
+	Env map[string]string
+}
+
+// RunHooks runs the hooks of the target database, the SQL hooks run with
+// psql and are only supported for PostgreSQL databases. The output of every
+// hook is passed to log.
+func (s *Service) RunHooks(
+	ctx context.Context, h hooks.Hooks, target HooksTarget, log func(string),
+) error {
+	if len(h) == 0 {
+		return nil
+	}
+
+	hooksTarget := hooks.Target{
+		CommandsEnabled: s.env.PBW_ENABLE_COMMAND_HOOKS,
+		Env:             target.Env,
+	}
+	if target.DatabaseType == database.DatabaseTypePostgreSQL {
+		hooksTarget.RunSQL = func(ctx context.Context, script string) (string, error) {
+			version, err := s.ints.PGClient.ParseVersionPG(target.Version)
+			if err != nil {
+				return "", err
+			}
+			return s.ints.PGClient.RunScript(ctx, version, target.ConnString, script)
+		}
+	}
+
+	return hooks.Run(ctx, h, hooksTarget, log)
+}
+
+// appendLog appends an entry to the log of an execution.
+func (s *Service) appendLog(ctx context.Context, executionID uuid.UUID) func(string) {
+	return func(entry string) {
+		err := s.dbgen.ExecutionsServiceAppendExecutionLog(
+			ctx, dbgen.ExecutionsServiceAppendExecutionLogParams{
+				ID:    executionID,
+				Entry: entry,
+			},
+		)
+		if err != nil {
+			logger.Error("error appending to the execution log", logger.KV{
+				"execution_id": executionID.String(),
+				"error":        err.Error(),
+			})
+		}
+	}
+}
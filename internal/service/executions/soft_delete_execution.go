@@ -2,46 +2,50 @@ package executions
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"fmt"
+	"io/fs"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/google/uuid"
 )
 
+// SoftDeleteExecution deletes the files of every copy of the execution and
+// marks it as deleted.
 func (s *Service) SoftDeleteExecution(
 	ctx context.Context, executionID uuid.UUID,
 ) error {
-	execution, err := s.dbgen.ExecutionsServiceGetExecutionForSoftDelete(
-		ctx, dbgen.ExecutionsServiceGetExecutionForSoftDeleteParams{
+	copies, err := s.dbgen.ExecutionsServiceGetExecutionCopies(
+		ctx, dbgen.ExecutionsServiceGetExecutionCopiesParams{
 			ExecutionID:   executionID,
-			EncryptionKey: s.env.PBW_ENCRYPTION_KEY,
+			DecryptionKey: s.env.PBW_ENCRYPTION_KEY,
 		},
 	)
-	if err != nil && errors.Is(err, sql.ErrNoRows) {
-		return nil
-	}
 	if err != nil {
 		return err
 	}
 
-	if execution.ExecutionPath.Valid && !execution.BackupIsLocal {
-		err := s.ints.StorageClient.S3Delete(
-			execution.DecryptedDestinationAccessKey, execution.DecryptedDestinationSecretKey,
-			execution.DestinationRegion.String, execution.DestinationEndpoint.String,
-			execution.DestinationBucketName.String, execution.ExecutionPath.String,
-		)
-		if err != nil {
-			return err
+	for _, c := range copies {
+		if !c.Path.Valid || c.Status == "deleted" {
+			continue
 		}
-	}
 
-	if execution.ExecutionPath.Valid && execution.BackupIsLocal {
-		err := s.ints.StorageClient.LocalDelete(execution.ExecutionPath.String)
+		err := s.deleteStoredFile(ctx, c)
+		// Failed uploads may not have left any file behind
+		if err != nil && (c.Status == "failed" || errors.Is(err, fs.ErrNotExist)) {
+			continue
+		}
 		if err != nil {
-			return err
+			return fmt.Errorf(
+				"error deleting copy in %s: %w",
+				CopyLocation(c.IsLocal, c.DestinationName), err,
+			)
 		}
 	}
 
+	if err := s.dbgen.ExecutionsServiceSoftDeleteExecutionCopies(ctx, executionID); err != nil {
+		return err
+	}
+
 	return s.dbgen.ExecutionsServiceSoftDeleteExecution(ctx, executionID)
 }
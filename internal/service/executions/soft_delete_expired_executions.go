@@ -2,14 +2,60 @@ package executions
 
 import (
 	"context"
+	"time"
 
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
 )
 
+// RetentionPolicyFromBackup returns the retention policy configured in the
+// backup.
+func RetentionPolicyFromBackup(backup dbgen.Backup) RetentionPolicy {
+	return RetentionPolicy{
+		Days:    int(backup.RetentionDays),
+		Last:    int(backup.RetentionKeepLast),
+		Daily:   int(backup.RetentionKeepDaily),
+		Weekly:  int(backup.RetentionKeepWeekly),
+		Monthly: int(backup.RetentionKeepMonthly),
+		Yearly:  int(backup.RetentionKeepYearly),
+		MinKeep: int(backup.RetentionMinKeep),
+	}
+}
+
+// ExpiredExecutions returns the executions of the backup that the policy
+// deletes if it is applied now, the periods of the policy are calculated in
+// the time zone of the backup.
+func (s *Service) ExpiredExecutions(
+	ctx context.Context, backupID uuid.UUID, timeZone string,
+	policy RetentionPolicy,
+) ([]RetentionExecution, error) {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	rows, err := s.dbgen.ExecutionsServiceGetRetentionExecutions(ctx, backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	executions := make([]RetentionExecution, 0, len(rows))
+	for _, row := range rows {
+		executions = append(executions, RetentionExecution{
+			ID:         row.ID,
+			Status:     row.Status,
+			FinishedAt: row.FinishedAt.Time,
+		})
+	}
+
+	return policy.Expired(executions, time.Now(), loc), nil
+}
+
 func (s *Service) SoftDeleteExpiredExecutions() {
 	ctx := context.Background()
 
-	expiredExecutions, err := s.dbgen.ExecutionsServiceGetExpiredExecutions(ctx)
+	backups, err := s.dbgen.ExecutionsServiceGetBackupsWithRetention(ctx)
 	if err != nil {
 		logger.Error(
 			"error soft deleting expired executions",
@@ -18,13 +64,26 @@ func (s *Service) SoftDeleteExpiredExecutions() {
 		return
 	}
 
-	for _, execution := range expiredExecutions {
-		if err := s.SoftDeleteExecution(ctx, execution.ID); err != nil {
+	for _, backup := range backups {
+		expired, err := s.ExpiredExecutions(
+			ctx, backup.ID, backup.TimeZone, RetentionPolicyFromBackup(backup),
+		)
+		if err != nil {
 			logger.Error(
 				"error soft deleting expired executions",
-				logger.KV{"id": execution.ID.String(), "error": err},
+				logger.KV{"backup_id": backup.ID.String(), "error": err},
 			)
-			return
+			continue
+		}
+
+		for _, execution := range expired {
+			if err := s.SoftDeleteExecution(ctx, execution.ID); err != nil {
+				logger.Error(
+					"error soft deleting expired executions",
+					logger.KV{"id": execution.ID.String(), "error": err},
+				)
+				continue
+			}
 		}
 	}
 
@@ -1,11 +1,23 @@
--- name: ExecutionsServiceGetExpiredExecutions :many
-SELECT executions.*
+-- name: ExecutionsServiceGetBackupsWithRetention :many
+SELECT *
+FROM backups
+WHERE
+  retention_days > 0
+  OR retention_keep_last > 0
+  OR retention_keep_daily > 0
+  OR retention_keep_weekly > 0
+  OR retention_keep_monthly > 0
+  OR retention_keep_yearly > 0;
+
+-- name: ExecutionsServiceGetRetentionExecutions :many
+SELECT
+  executions.id,
+  executions.status,
+  executions.finished_at
 FROM executions
-JOIN backups ON executions.backup_id = backups.id
 WHERE
-  backups.retention_days > 0
+  executions.backup_id = @backup_id
   AND executions.status != 'deleted'
   AND executions.finished_at IS NOT NULL
-  AND (
-    executions.finished_at + (backups.retention_days || ' days')::INTERVAL
-  ) < NOW();
+  AND NOT executions.is_pinned
+ORDER BY executions.finished_at DESC;
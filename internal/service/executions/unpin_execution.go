@@ -0,0 +1,16 @@
+package executions
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// UnpinExecution lets the retention policy of the backup delete the execution
+// again, it's used to release the pre-restore backups that are not needed
+// anymore.
+func (s *Service) UnpinExecution(
+	ctx context.Context, executionID uuid.UUID,
+) error {
+	return s.dbgen.ExecutionsServiceUnpinExecution(ctx, executionID)
+}
@@ -0,0 +1,4 @@
+-- name: ExecutionsServiceUnpinExecution :exec
+UPDATE executions
+SET is_pinned = FALSE
+WHERE id = @id;
@@ -6,6 +6,19 @@ SET
   path = COALESCE(sqlc.narg('path'), path),
   finished_at = COALESCE(sqlc.narg('finished_at'), finished_at),
   deleted_at = COALESCE(sqlc.narg('deleted_at'), deleted_at),
-  file_size = COALESCE(sqlc.narg('file_size'), file_size)
+  file_size = COALESCE(sqlc.narg('file_size'), file_size),
+  compression = COALESCE(sqlc.narg('compression'), compression),
+  file_extension = COALESCE(sqlc.narg('file_extension'), file_extension),
+  encryption_key_fingerprint = COALESCE(
+    sqlc.narg('encryption_key_fingerprint'), encryption_key_fingerprint
+  ),
+  checksum = COALESCE(sqlc.narg('checksum'), checksum),
+  wal_start_lsn = COALESCE(sqlc.narg('wal_start_lsn'), wal_start_lsn),
+  wal_end_lsn = COALESCE(sqlc.narg('wal_end_lsn'), wal_end_lsn)
 WHERE id = @id
 RETURNING *;
+
+-- name: ExecutionsServiceAppendExecutionLog :exec
+UPDATE executions
+SET log = COALESCE(log, '') || @entry::TEXT
+WHERE id = @id;
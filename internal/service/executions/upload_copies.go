@@ -0,0 +1,299 @@
+package executions
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
+)
+
+// uploadTarget is a storage where a copy of the executions of a backup is
+// uploaded: the destination of the backup itself or one of its replicas.
+type uploadTarget struct {
+	DestinationID       uuid.NullUUID
+	DestinationName     sql.NullString
+	IsLocal             bool
+	IsPrimary           bool
+	Storage             storage.Config
+	BackupEncryptionKey string
+}
+
+func (t uploadTarget) location() string {
+	return CopyLocation(t.IsLocal, t.DestinationName)
+}
+
+// uploadTargets returns the storages where the executions of the backup are
+// uploaded, the destination of the backup first.
+func uploadTargets(
+	back dbgen.ExecutionsServiceGetBackupDataRow,
+	replicas []dbgen.ExecutionsServiceGetBackupReplicasRow,
+) []uploadTarget {
+	targets := []uploadTarget{{
+		DestinationID:       back.BackupDestinationID,
+		DestinationName:     back.DestinationName,
+		IsLocal:             back.BackupIsLocal,
+		IsPrimary:           true,
+		BackupEncryptionKey: back.DecryptedDestinationBackupEncryptionKey,
+		Storage: storage.Config{
+			Type:       back.DestinationType.String,
+			BucketName: back.DestinationBucketName.String,
+			Region:     back.DestinationRegion.String,
+			Endpoint:   back.DestinationEndpoint.String,
+			AccessKey:  back.DecryptedDestinationAccessKey,
+			SecretKey:  back.DecryptedDestinationSecretKey,
+			BasePath:   back.DestinationBasePath.String,
+			PrivateKey: back.DecryptedDestinationPrivateKey,
+			HostKey:    back.DestinationHostKey.String,
+			S3: storage.S3Options{
+				CredentialsMode:      back.DestinationS3CredentialsMode.String,
+				RoleARN:              back.DestinationS3RoleArn.String,
+				ExternalID:           back.DestinationS3ExternalID.String,
+				ServerSideEncryption: back.DestinationS3ServerSideEncryption.String,
+				KMSKeyID:             back.DestinationS3KmsKeyID.String,
+				SSECustomerKey:       back.DecryptedDestinationS3SseCustomerKey,
+				StorageClass:         back.DestinationS3StorageClass.String,
+				VirtualHostedStyle:   back.DestinationS3VirtualHostedStyle.Bool,
+				ObjectLockMode:       back.DestinationS3ObjectLockMode.String,
+				ObjectLockDays:       back.DestinationS3ObjectLockDays.Int32,
+				ObjectLockLegalHold:  back.DestinationS3ObjectLockLegalHold.Bool,
+			},
+		},
+	}}
+
+	for _, replica := range replicas {
+		targets = append(targets, uploadTarget{
+			DestinationID:       replica.DestinationID,
+			DestinationName:     replica.DestinationName,
+			IsLocal:             replica.IsLocal,
+			BackupEncryptionKey: replica.DecryptedDestinationBackupEncryptionKey,
+			Storage: storage.Config{
+				Type:       replica.DestinationType.String,
+				BucketName: replica.DestinationBucketName.String,
+				Region:     replica.DestinationRegion.String,
+				Endpoint:   replica.DestinationEndpoint.String,
+				AccessKey:  replica.DecryptedDestinationAccessKey,
+				SecretKey:  replica.DecryptedDestinationSecretKey,
+				BasePath:   replica.DestinationBasePath.String,
+				PrivateKey: replica.DecryptedDestinationPrivateKey,
+				HostKey:    replica.DestinationHostKey.String,
+				S3: storage.S3Options{
+					CredentialsMode:      replica.DestinationS3CredentialsMode.String,
+					RoleARN:              replica.DestinationS3RoleArn.String,
+					ExternalID:           replica.DestinationS3ExternalID.String,
+					ServerSideEncryption: replica.DestinationS3ServerSideEncryption.String,
+					KMSKeyID:             replica.DestinationS3KmsKeyID.String,
+					SSECustomerKey:       replica.DecryptedDestinationS3SseCustomerKey,
+					StorageClass:         replica.DestinationS3StorageClass.String,
+					VirtualHostedStyle:   replica.DestinationS3VirtualHostedStyle.Bool,
+					ObjectLockMode:       replica.DestinationS3ObjectLockMode.String,
+					ObjectLockDays:       replica.DestinationS3ObjectLockDays.Int32,
+					ObjectLockLegalHold:  replica.DestinationS3ObjectLockLegalHold.Bool,
+				},
+			},
+		})
+	}
+
+	return targets
+}
+
+// copyResult is the outcome of the upload of a copy of an execution.
+type copyResult struct {
+	Target        uploadTarget
+	Path          string
+	FileExtension string
+	Fingerprint   sql.NullString
+	FileSize      int64
+	Checksum      string
+	Err           error
+}
+
+// copyErrors joins the errors of the failed copies, prefixed with their
+// location when there is more than one copy.
+func copyErrors(results []copyResult) error {
+	errs := []error{}
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		if len(results) == 1 {
+			errs = append(errs, result.Err)
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", result.Target.location(), result.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// stopReader makes the producer of a pipe stop when its output is not going
+// to be read.
+func stopReader(r io.Reader, err error) {
+	if closer, ok := r.(interface{ CloseWithError(error) error }); ok {
+		_ = closer.CloseWithError(err)
+	}
+}
+
+// uploadCopies uploads the compressed dump to every target at the same time,
+// encrypting it with the key of each target. The dump is read only once, a
+// target that fails doesn't stop the rest.
+//
+// basePath is the path of the file without extension, fileExtension is the
+// extension of the compressed dump. Every copy is recorded in the execution,
+// the records are updated even if ctx is cancelled.
+func (s *Service) uploadCopies(
+	ctx context.Context, executionID uuid.UUID, targets []uploadTarget,
+	src io.Reader, basePath string, fileExtension string,
+) []copyResult {
+	dbCtx := context.WithoutCancel(ctx)
+	results := make([]copyResult, len(targets))
+	backends := make([]storage.StorageBackend, len(targets))
+	copyIDs := make([]uuid.UUID, len(targets))
+	keys := make([]encryption.Key, len(targets))
+	encrypted := make([]bool, len(targets))
+	live := []int{}
+
+	for i, target := range targets {
+		results[i] = copyResult{
+			Target:        target,
+			FileExtension: fileExtension,
+		}
+
+		key, ok, err := s.BackupEncryptionKey(target.IsLocal, target.BackupEncryptionKey)
+		if err == nil && ok {
+			keys[i], encrypted[i] = key, true
+			results[i].FileExtension += encryption.Extension
+			results[i].Fingerprint = sql.NullString{
+				Valid: true, String: key.Fingerprint(),
+			}
+		}
+		results[i].Path = basePath + results[i].FileExtension
+		results[i].Err = err
+
+		execCopy, createErr := s.dbgen.ExecutionsServiceCreateExecutionCopy(
+			dbCtx, dbgen.ExecutionsServiceCreateExecutionCopyParams{
+				ExecutionID:              executionID,
+				DestinationID:            target.DestinationID,
+				IsLocal:                  target.IsLocal,
+				IsPrimary:                target.IsPrimary,
+				Path:                     sql.NullString{Valid: true, String: results[i].Path},
+				FileExtension:            results[i].FileExtension,
+				EncryptionKeyFingerprint: results[i].Fingerprint,
+			},
+		)
+		if createErr != nil {
+			results[i].Err = errors.Join(results[i].Err, createErr)
+			continue
+		}
+		copyIDs[i] = execCopy.ID
+
+		if results[i].Err == nil {
+			backends[i], results[i].Err = s.ints.StorageClient.BackendOrLocal(
+				target.IsLocal, target.Storage,
+			)
+		}
+		if results[i].Err == nil && !target.IsLocal {
+			results[i].Err = backends[i].Test(ctx)
+		}
+		if results[i].Err == nil {
+			live = append(live, i)
+		}
+	}
+
+	if len(live) == 0 {
+		stopReader(src, errEveryCopyFailed)
+	} else {
+		readers, done := fanOut(src, len(live))
+
+		wg := sync.WaitGroup{}
+		for j, i := range live {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				var reader io.Reader = readers[j]
+				if encrypted[i] {
+					reader = encryption.Encrypt(reader, keys[i])
+				}
+				stream := reader
+
+				// The checksum is computed from the same bytes that are uploaded
+				hash := sha256.New()
+				reader = io.TeeReader(reader, hash)
+
+				size, err := backends[i].Upload(ctx, results[i].Path, reader)
+				if err != nil {
+					if ctx.Err() != nil {
+						err = context.Cause(ctx)
+					}
+					stopReader(stream, err)
+					readers[j].CloseWithError(err)
+					results[i].Err = err
+					removePartialFile(dbCtx, backends[i], results[i].Path)
+					return
+				}
+				results[i].FileSize = size
+				results[i].Checksum = hex.EncodeToString(hash.Sum(nil))
+			}()
+		}
+		wg.Wait()
+		<-done
+	}
+
+	for i, result := range results {
+		if copyIDs[i] == uuid.Nil {
+			continue
+		}
+
+		params := dbgen.ExecutionsServiceUpdateExecutionCopyParams{
+			ID:       copyIDs[i],
+			Status:   "success",
+			FileSize: sql.NullInt64{Valid: true, Int64: result.FileSize},
+			Checksum: sql.NullString{Valid: true, String: result.Checksum},
+		}
+		if result.Err != nil {
+			params = dbgen.ExecutionsServiceUpdateExecutionCopyParams{
+				ID:      copyIDs[i],
+				Status:  "failed",
+				Message: sql.NullString{Valid: true, String: result.Err.Error()},
+			}
+		}
+
+		if err := s.dbgen.ExecutionsServiceUpdateExecutionCopy(dbCtx, params); err != nil {
+			logger.Error("error updating execution copy", logger.KV{
+				"execution_id": executionID.String(),
+				"error":        err.Error(),
+			})
+		}
+	}
+
+	return results
+}
+
+// removePartialFile deletes what a failed upload left in the storage, the
+// local, SFTP and WebDAV backends write the file while it's read so a failed
+// upload leaves part of it behind.
+func removePartialFile(
+	ctx context.Context, backend storage.StorageBackend, path string,
+) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	err := backend.Delete(ctx, path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Error("error removing partial backup file", logger.KV{
+			"path":  path,
+			"error": err.Error(),
+		})
+	}
+}
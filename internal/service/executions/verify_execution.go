@@ -0,0 +1,194 @@
+package executions
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
+)
+
+// Results of the integrity verification of an execution file.
+const (
+	VerifyStatusOk       = "ok"
+	VerifyStatusMismatch = "mismatch"
+	VerifyStatusError    = "error"
+)
+
+const (
+	// verifyInterval is the time after which a verified execution file is
+	// verified again.
+	verifyInterval = time.Hour * 24 * 7
+
+	// verifyBatchSize is the maximum number of execution files verified on
+	// every run of VerifyExecutions, so big installations spread the reads
+	// over time.
+	verifyBatchSize = 20
+)
+
+// VerifyExecution reads again the file of every copy of the given execution
+// from the local backups directory or the destination and compares its
+// SHA-256 with the checksum computed when it was uploaded.
+//
+// The result of every copy is stored in the copy, the worst one is stored in
+// the execution and the integrity failed webhooks are run if any file can't
+// be read or its checksum doesn't match. When the copies can't be checked at
+// all, the error is stored in the execution so it isn't verified again
+// before the next interval.
+func (s *Service) VerifyExecution(
+	ctx context.Context, executionID uuid.UUID,
+) (string, error) {
+	copies, err := s.availableCopies(ctx, executionID)
+	if err != nil {
+		return s.setVerifyError(ctx, executionID, err)
+	}
+
+	verifiable := make([]executionCopy, 0, len(copies))
+	for _, c := range copies {
+		if c.Checksum.Valid {
+			verifiable = append(verifiable, c)
+		}
+	}
+	if len(verifiable) == 0 {
+		return s.setVerifyError(
+			ctx, executionID, fmt.Errorf("execution has no checksum to verify"),
+		)
+	}
+
+	status, verifyErrs := VerifyStatusOk, []error{}
+	for _, c := range verifiable {
+		copyStatus, copyErr := s.verifyCopy(ctx, c)
+		if copyErr != nil {
+			verifyErrs = append(verifyErrs, fmt.Errorf(
+				"%s: %w", CopyLocation(c.IsLocal, c.DestinationName), copyErr,
+			))
+		}
+
+		// An unreadable file is worse than a mismatch, nothing can be restored
+		// from it
+		if copyStatus == VerifyStatusError ||
+			(copyStatus == VerifyStatusMismatch && status == VerifyStatusOk) {
+			status = copyStatus
+		}
+
+		err := s.dbgen.ExecutionsServiceSetExecutionCopyVerifyResult(
+			ctx, dbgen.ExecutionsServiceSetExecutionCopyVerifyResultParams{
+				ID:           c.ID,
+				VerifyStatus: copyStatus,
+			},
+		)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	verifyErr := errors.Join(verifyErrs...)
+	message := sql.NullString{}
+	if verifyErr != nil {
+		message = sql.NullString{Valid: true, String: verifyErr.Error()}
+		s.webhooksService.RunExecutionIntegrityFailed(verifiable[0].BackupID)
+	}
+
+	err = s.dbgen.ExecutionsServiceSetVerifyResult(
+		ctx, dbgen.ExecutionsServiceSetVerifyResultParams{
+			ID:            executionID,
+			VerifyStatus:  status,
+			VerifyMessage: message,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return status, verifyErr
+}
+
+// setVerifyError stores the error as the result of the verification of an
+// execution whose copies couldn't be verified, it returns the error.
+func (s *Service) setVerifyError(
+	ctx context.Context, executionID uuid.UUID, verifyErr error,
+) (string, error) {
+	err := s.dbgen.ExecutionsServiceSetVerifyResult(
+		ctx, dbgen.ExecutionsServiceSetVerifyResultParams{
+			ID:            executionID,
+			VerifyStatus:  VerifyStatusError,
+			VerifyMessage: sql.NullString{Valid: true, String: verifyErr.Error()},
+		},
+	)
+	if err != nil {
+		return "", errors.Join(verifyErr, err)
+	}
+
+	return VerifyStatusError, verifyErr
+}
+
+// verifyCopy compares the SHA-256 of the stored file of a copy with the
+// checksum computed when it was uploaded.
+func (s *Service) verifyCopy(ctx context.Context, c executionCopy) (string, error) {
+	checksum, err := s.storedFileChecksum(ctx, c)
+	if err != nil {
+		return VerifyStatusError, err
+	}
+	if checksum != c.Checksum.String {
+		return VerifyStatusMismatch, fmt.Errorf(
+			"checksum mismatch, expected %s but got %s",
+			c.Checksum.String, checksum,
+		)
+	}
+	return VerifyStatusOk, nil
+}
+
+// storedFileChecksum returns the SHA-256 of the stored file of a copy.
+func (s *Service) storedFileChecksum(
+	ctx context.Context, c executionCopy,
+) (string, error) {
+	file, err := s.openStoredFile(ctx, c)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("error reading execution file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyExecutions verifies the files of the successful executions that were
+// never verified or were verified a long time ago.
+func (s *Service) VerifyExecutions() {
+	ctx := context.Background()
+
+	executionIDs, err := s.dbgen.ExecutionsServiceGetExecutionsToVerify(
+		ctx, dbgen.ExecutionsServiceGetExecutionsToVerifyParams{
+			VerifiedBefore: time.Now().Add(-verifyInterval),
+			Limit:          verifyBatchSize,
+		},
+	)
+	if err != nil {
+		logger.Error(
+			"error getting executions to verify", logger.KV{"error": err},
+		)
+		return
+	}
+
+	for _, executionID := range executionIDs {
+		if _, err := s.VerifyExecution(ctx, executionID); err != nil {
+			logger.Error("error verifying execution", logger.KV{
+				"id":    executionID.String(),
+				"error": err,
+			})
+		}
+	}
+
+	logger.Info("executions verified", logger.KV{"count": len(executionIDs)})
+}
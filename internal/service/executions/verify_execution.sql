@@ -0,0 +1,25 @@
+-- name: ExecutionsServiceGetExecutionsToVerify :many
+SELECT id
+FROM executions
+WHERE status = 'success'
+AND deleted_at IS NULL
+AND checksum IS NOT NULL
+-- The executions whose copies have no checksum can't be verified
+AND EXISTS (
+  SELECT 1 FROM execution_copies
+  WHERE execution_copies.execution_id = executions.id
+  AND execution_copies.status = 'success'
+  AND execution_copies.path IS NOT NULL
+  AND execution_copies.checksum IS NOT NULL
+)
+AND (verified_at IS NULL OR verified_at < sqlc.arg('verified_before')::TIMESTAMPTZ)
+ORDER BY verified_at ASC NULLS FIRST, started_at ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ExecutionsServiceSetVerifyResult :exec
+UPDATE executions
+SET
+  verify_status = @verify_status,
+  verify_message = sqlc.narg('verify_message'),
+  verified_at = NOW()
+WHERE id = @id;
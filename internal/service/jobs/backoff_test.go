@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{"first attempt", time.Minute, 1, time.Minute},
+		{"second attempt", time.Minute, 2, 2 * time.Minute},
+		{"fourth attempt", time.Minute, 4, 8 * time.Minute},
+		{"capped", time.Minute, 20, 6 * time.Hour},
+		{"base over the cap", 10 * time.Hour, 1, 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Backoff(tt.base, tt.attempt))
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, retryable(StatusFailed))
+	assert.True(t, retryable("timed_out"))
+	assert.False(t, retryable(StatusSuccess))
+	assert.False(t, retryable(StatusCancelled))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxAttempts    sql.NullInt16
+		backoffSeconds sql.NullInt32
+		wantAttempts   int32
+		wantBackoff    time.Duration
+	}{
+		{
+			name:           "backup settings",
+			maxAttempts:    sql.NullInt16{Int16: 3, Valid: true},
+			backoffSeconds: sql.NullInt32{Int32: 30, Valid: true},
+			wantAttempts:   3,
+			wantBackoff:    30 * time.Second,
+		},
+		{
+			name:         "server defaults",
+			wantAttempts: 5,
+			wantBackoff:  2 * time.Minute,
+		},
+		{
+			name:           "default attempts",
+			backoffSeconds: sql.NullInt32{Int32: 30, Valid: true},
+			wantAttempts:   5,
+			wantBackoff:    30 * time.Second,
+		},
+		{
+			name:         "never retry",
+			maxAttempts:  sql.NullInt16{Int16: 1, Valid: true},
+			wantAttempts: 1,
+			wantBackoff:  2 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts, backoff := retryPolicy(
+				tt.maxAttempts, tt.backoffSeconds, 5, 2*time.Minute,
+			)
+			assert.Equal(t, tt.wantAttempts, attempts)
+			assert.Equal(t, tt.wantBackoff, backoff)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrJobNotPending is returned when cancelling a job that already started.
+var ErrJobNotPending = errors.New(
+	"the job is not queued or waiting, cancel its execution instead",
+)
+
+// CancelJob removes a queued or waiting job from the queue.
+func (s *Service) CancelJob(ctx context.Context, jobID uuid.UUID) error {
+	_, err := s.dbgen.JobsServiceCancelJob(ctx, jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrJobNotPending
+	}
+	return err
+}
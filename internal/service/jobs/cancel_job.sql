@@ -0,0 +1,8 @@
+-- name: JobsServiceCancelJob :one
+UPDATE backup_jobs
+SET
+  status = 'cancelled',
+  message = 'Cancelled before running',
+  finished_at = NOW()
+WHERE id = @id AND status IN ('queued', 'waiting')
+RETURNING id;
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/logger"
+)
+
+// finishedJobsRetention is how long the finished jobs are kept, their
+// executions keep the history of the backups.
+const finishedJobsRetention = 7 * 24 * time.Hour
+
+// DeleteFinishedJobs deletes the jobs that finished a while ago.
+func (s *Service) DeleteFinishedJobs() {
+	err := s.dbgen.JobsServiceDeleteFinishedJobs(
+		context.Background(), time.Now().Add(-finishedJobsRetention),
+	)
+	if err != nil {
+		logger.Error("error deleting finished backup jobs", logger.KV{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	logger.Info("finished backup jobs deleted")
+}
@@ -0,0 +1,5 @@
+-- name: JobsServiceDeleteFinishedJobs :exec
+DELETE FROM backup_jobs
+WHERE
+  status IN ('success', 'failed', 'cancelled')
+  AND finished_at < sqlc.arg('finished_before')::TIMESTAMPTZ;
@@ -0,0 +1,187 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/google/uuid"
+)
+
+// Job statuses.
+const (
+	StatusQueued    = "queued"
+	StatusWaiting   = "waiting"
+	StatusRunning   = "running"
+	StatusSuccess   = "success"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// dispatchInterval is how often the queue is checked for jobs whose retry
+// time arrived, new and finished jobs check it immediately.
+const dispatchInterval = 5 * time.Second
+
+// maxBackoff caps the wait between the retries of a job.
+const maxBackoff = 6 * time.Hour
+
+// Start starts the dispatcher of the queue in the background.
+func (s *Service) Start() {
+	s.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(dispatchInterval)
+			defer ticker.Stop()
+
+			for {
+				s.DispatchJobs()
+				select {
+				case <-ticker.C:
+				case <-s.wake:
+				}
+			}
+		}()
+	})
+}
+
+// notify makes the dispatcher check the queue now.
+func (s *Service) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// DispatchJobs starts the queued jobs while there are free slots, the jobs
+// with a higher priority first.
+func (s *Service) DispatchJobs() {
+	s.dispatchMu.Lock()
+	defer s.dispatchMu.Unlock()
+
+	for {
+		job, err := s.dbgen.JobsServiceClaimJob(
+			context.Background(), dbgen.JobsServiceClaimJobParams{
+				MaxRunning:          int32(s.env.PBW_MAX_CONCURRENT_BACKUPS),
+				MaxRunningPerServer: int32(s.env.PBW_MAX_CONCURRENT_BACKUPS_PER_SERVER),
+			},
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return
+		}
+		if err != nil {
+			logger.Error("error claiming backup job", logger.KV{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		go s.runJob(job)
+	}
+}
+
+// runJob runs the execution of a claimed job and records its outcome, the
+// failed executions are retried with an exponential backoff until the job
+// runs out of attempts.
+func (s *Service) runJob(job dbgen.BackupJob) {
+	ctx := context.Background()
+	defer s.notify()
+
+	stopHeartbeat := executions.Heartbeat(
+		ctx, executions.HeartbeatInterval, func(ctx context.Context) error {
+			return s.dbgen.JobsServiceTouchJob(ctx, job.ID)
+		},
+	)
+	execution, err := s.executionsService.RunExecution(
+		ctx, job.BackupID, executions.Attempt{
+			ParentExecutionID: s.parentExecutionID(ctx, job),
+			Number:            job.Attempts,
+			MaxAttempts:       job.MaxAttempts,
+			Pinned:            job.PinExecution,
+			JobID:             uuid.NullUUID{Valid: true, UUID: job.ID},
+		},
+	)
+	stopHeartbeat()
+
+	status, message := execution.Status, execution.Message
+	if err != nil {
+		status, message = StatusFailed, sql.NullString{Valid: true, String: err.Error()}
+	}
+	executionID := uuid.NullUUID{
+		Valid: execution.ID != uuid.Nil, UUID: execution.ID,
+	}
+
+	if retryable(status) && job.Attempts < job.MaxAttempts {
+		backoff := time.Duration(job.BackoffSeconds) * time.Second
+		wait := Backoff(backoff, int(job.Attempts))
+		err = s.dbgen.JobsServiceRetryJob(ctx, dbgen.JobsServiceRetryJobParams{
+			ID:          job.ID,
+			ExecutionID: executionID,
+			RunAfter:    time.Now().Add(wait),
+			Message: sql.NullString{Valid: true, String: fmt.Sprintf(
+				"Attempt %d of %d failed, retrying in %s: %s",
+				job.Attempts, job.MaxAttempts, wait, message.String,
+			)},
+		})
+	} else {
+		if retryable(status) {
+			status = StatusFailed
+		}
+		err = s.dbgen.JobsServiceFinishJob(ctx, dbgen.JobsServiceFinishJobParams{
+			ID:          job.ID,
+			Status:      status,
+			Message:     message,
+			ExecutionID: executionID,
+		})
+	}
+	if err != nil {
+		logger.Error("error updating backup job", logger.KV{
+			"job_id": job.ID.String(),
+			"error":  err.Error(),
+		})
+	}
+}
+
+// parentExecutionID returns the execution of the first attempt of a job that
+// is being retried, the job points to the execution of its last attempt.
+func (s *Service) parentExecutionID(
+	ctx context.Context, job dbgen.BackupJob,
+) uuid.NullUUID {
+	if !job.ExecutionID.Valid {
+		return uuid.NullUUID{}
+	}
+
+	previous, err := s.dbgen.ExecutionsServiceGetExecution(ctx, job.ExecutionID.UUID)
+	if err != nil {
+		logger.Error("error getting the previous execution of a job", logger.KV{
+			"job_id": job.ID.String(),
+			"error":  err.Error(),
+		})
+		return job.ExecutionID
+	}
+
+	if previous.ParentExecutionID.Valid {
+		return previous.ParentExecutionID
+	}
+	return uuid.NullUUID{Valid: true, UUID: previous.ID}
+}
+
+// retryable reports whether a job whose execution ended with the status
+// can be retried, the executions cancelled by a user are not.
+func retryable(executionStatus string) bool {
+	return executionStatus == StatusFailed ||
+		executionStatus == executions.StatusTimedOut
+}
+
+// Backoff returns the wait before the next attempt of a job that failed
+// attempt times, it doubles after every attempt up to 6 hours.
+func Backoff(base time.Duration, attempt int) time.Duration {
+	wait := base
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
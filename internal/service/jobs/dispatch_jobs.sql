@@ -0,0 +1,54 @@
+-- name: JobsServiceClaimJob :one
+UPDATE backup_jobs
+SET
+  status = 'running',
+  attempts = attempts + 1,
+  started_at = NOW(),
+  heartbeat_at = NOW()
+WHERE id = (
+  SELECT pending.id
+  FROM backup_jobs pending
+  WHERE
+    pending.status IN ('queued', 'waiting')
+    AND pending.run_after <= NOW()
+    AND (
+      SELECT COUNT(*) FROM backup_jobs WHERE status = 'running'
+    ) < sqlc.arg('max_running')::INTEGER
+    AND (
+      SELECT COUNT(*)
+      FROM backup_jobs running
+      WHERE running.status = 'running' AND running.server = pending.server
+    ) < sqlc.arg('max_running_per_server')::INTEGER
+    AND NOT EXISTS (
+      SELECT 1
+      FROM backup_jobs running
+      WHERE running.status = 'running' AND running.backup_id = pending.backup_id
+    )
+  ORDER BY pending.priority DESC, pending.run_after ASC, pending.created_at ASC
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: JobsServiceTouchJob :exec
+UPDATE backup_jobs
+SET heartbeat_at = NOW()
+WHERE id = @id AND status = 'running';
+
+-- name: JobsServiceFinishJob :exec
+UPDATE backup_jobs
+SET
+  status = @status,
+  message = sqlc.narg('message'),
+  execution_id = sqlc.narg('execution_id'),
+  finished_at = NOW()
+WHERE id = @id;
+
+-- name: JobsServiceRetryJob :exec
+UPDATE backup_jobs
+SET
+  status = 'waiting',
+  message = sqlc.narg('message'),
+  execution_id = sqlc.narg('execution_id'),
+  run_after = @run_after
+WHERE id = @id;
@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
+)
+
+// Sources of the jobs.
+const (
+	SourceSchedule   = "schedule"
+	SourceManual     = "manual"
+	SourceRecovery   = "recovery"
+	SourcePreRestore = "pre_restore"
+)
+
+// ErrAlreadyQueued is returned when a scheduled run is enqueued while the
+// previous run of the backup is still pending.
+var ErrAlreadyQueued = errors.New("the backup already has a pending job")
+
+// EnqueueJobParams are the settings of a job, the zero value queues a regular
+// run of the backup.
+type EnqueueJobParams struct {
+	// PinExecution pins the executions of the job so the retention policy
+	// never deletes them
+	PinExecution bool
+}
+
+// EnqueueJob adds a run of the backup to the queue. The scheduled runs are
+// skipped while a previous run of the same backup is queued or running.
+func (s *Service) EnqueueJob(
+	ctx context.Context, backupID uuid.UUID, source string,
+	params EnqueueJobParams,
+) (dbgen.BackupJob, error) {
+	logError := func(err error) {
+		logger.Error("error enqueuing backup job", logger.KV{
+			"backup_id": backupID.String(),
+			"source":    source,
+			"error":     err.Error(),
+		})
+	}
+
+	if source == SourceSchedule {
+		pending, err := s.dbgen.JobsServiceHasPendingJob(ctx, backupID)
+		if err != nil {
+			logError(err)
+			return dbgen.BackupJob{}, err
+		}
+		if pending {
+			logger.Info("scheduled backup skipped, the previous run is pending", logger.KV{
+				"backup_id": backupID.String(),
+			})
+			return dbgen.BackupJob{}, ErrAlreadyQueued
+		}
+	}
+
+	data, err := s.dbgen.JobsServiceGetBackupJobData(
+		ctx, dbgen.JobsServiceGetBackupJobDataParams{
+			BackupID:      backupID,
+			EncryptionKey: s.env.PBW_ENCRYPTION_KEY,
+		},
+	)
+	if err != nil {
+		logError(err)
+		return dbgen.BackupJob{}, err
+	}
+
+	maxAttempts, backoff := retryPolicy(
+		data.BackupRetryMaxAttempts, data.BackupRetryBackoffSeconds,
+		s.env.PBW_BACKUP_MAX_ATTEMPTS, s.env.PBW_BACKUP_RETRY_BACKOFF,
+	)
+	job, err := s.dbgen.JobsServiceCreateJob(ctx, dbgen.JobsServiceCreateJobParams{
+		BackupID: backupID,
+		Server: ServerKey(
+			data.DecryptedDatabaseConnectionString, data.DatabaseID.String(),
+		),
+		Source:         source,
+		Priority:       data.BackupPriority,
+		MaxAttempts:    maxAttempts,
+		BackoffSeconds: int32(backoff / time.Second),
+		PinExecution:   params.PinExecution,
+	})
+	if err != nil {
+		logError(err)
+		return dbgen.BackupJob{}, err
+	}
+
+	s.notify()
+	return job, nil
+}
+
+// retryPolicy returns the max attempts and the backoff of the jobs of a
+// backup, the retry settings that the backup doesn't set use the defaults of
+// the server.
+func retryPolicy(
+	maxAttempts sql.NullInt16, backoffSeconds sql.NullInt32,
+	defaultMaxAttempts int, defaultBackoff time.Duration,
+) (int32, time.Duration) {
+	attempts := int32(defaultMaxAttempts)
+	if maxAttempts.Valid {
+		attempts = int32(maxAttempts.Int16)
+	}
+
+	backoff := defaultBackoff
+	if backoffSeconds.Valid {
+		backoff = time.Duration(backoffSeconds.Int32) * time.Second
+	}
+
+	return attempts, backoff
+}
+
+// EnqueueScheduledJob is the task of the backup schedules.
+func (s *Service) EnqueueScheduledJob(backupID uuid.UUID) {
+	_, _ = s.EnqueueJob(
+		context.Background(), backupID, SourceSchedule, EnqueueJobParams{},
+	)
+}
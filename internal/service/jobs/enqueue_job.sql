@@ -0,0 +1,33 @@
+-- name: JobsServiceGetBackupJobData :one
+SELECT
+  backups.id AS backup_id,
+  backups.priority AS backup_priority,
+  backups.retry_max_attempts AS backup_retry_max_attempts,
+  backups.retry_backoff_seconds AS backup_retry_backoff_seconds,
+  databases.id AS database_id,
+  pgp_sym_decrypt(
+    databases.connection_string, @encryption_key
+  ) AS decrypted_database_connection_string
+FROM backups
+INNER JOIN databases ON databases.id = backups.database_id
+WHERE backups.id = @backup_id;
+
+-- name: JobsServiceHasPendingJob :one
+SELECT EXISTS (
+  SELECT 1
+  FROM backup_jobs
+  WHERE
+    backup_id = @backup_id
+    AND status IN ('queued', 'waiting', 'running')
+)::BOOLEAN;
+
+-- name: JobsServiceCreateJob :one
+INSERT INTO backup_jobs (
+  backup_id, server, source, priority, max_attempts, backoff_seconds,
+  pin_execution
+)
+VALUES (
+  @backup_id, @server, @source, @priority, @max_attempts, @backoff_seconds,
+  @pin_execution
+)
+RETURNING *;
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"sync"
+
+	"github.com/eduardolat/pgbackweb/internal/config"
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+)
+
+// Service is the persistent queue of the backup executions: the scheduled
+// and manual runs are stored as jobs in the metadata database and started
+// when the global and per database server concurrency limits allow it.
+type Service struct {
+	env               config.Env
+	dbgen             *dbgen.Queries
+	executionsService *executions.Service
+
+	// dispatchMu serializes the claims so the limits are respected
+	dispatchMu sync.Mutex
+	wake       chan struct{}
+	startOnce  sync.Once
+}
+
+func New(
+	env config.Env, dbgen *dbgen.Queries, executionsService *executions.Service,
+) *Service {
+	return &Service{
+		env:               env,
+		dbgen:             dbgen,
+		executionsService: executionsService,
+		wake:              make(chan struct{}, 1),
+	}
+}
+
+// Limits returns the max number of jobs that run at the same time, in total
+// and per database server.
+func (s *Service) Limits() (int, int) {
+	return s.env.PBW_MAX_CONCURRENT_BACKUPS,
+		s.env.PBW_MAX_CONCURRENT_BACKUPS_PER_SERVER
+}
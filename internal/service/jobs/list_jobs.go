@@ -0,0 +1,15 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+)
+
+// ListPendingJobs returns the running, queued and waiting jobs in the order
+// they are going to run.
+func (s *Service) ListPendingJobs(
+	ctx context.Context,
+) ([]dbgen.JobsServiceListPendingJobsRow, error) {
+	return s.dbgen.JobsServiceListPendingJobs(ctx)
+}
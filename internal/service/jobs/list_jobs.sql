@@ -0,0 +1,14 @@
+-- name: JobsServiceListPendingJobs :many
+SELECT
+  backup_jobs.*,
+  backups.name AS backup_name,
+  databases.name AS database_name
+FROM backup_jobs
+INNER JOIN backups ON backups.id = backup_jobs.backup_id
+INNER JOIN databases ON databases.id = backups.database_id
+WHERE backup_jobs.status IN ('queued', 'waiting', 'running')
+ORDER BY
+  (backup_jobs.status = 'running') DESC,
+  backup_jobs.priority DESC,
+  backup_jobs.run_after ASC,
+  backup_jobs.created_at ASC;
@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+)
+
+// RecoverStaleJobs handles the running jobs whose heartbeat stopped because
+// the process running them died: they are queued again when
+// PBW_REQUEUE_INTERRUPTED_BACKUPS is enabled and marked as failed otherwise.
+func (s *Service) RecoverStaleJobs() {
+	message := "The job was interrupted because PG Back Web stopped while it was running"
+	if s.env.PBW_REQUEUE_INTERRUPTED_BACKUPS {
+		message += ", it was queued again"
+	}
+
+	recovered, err := s.dbgen.JobsServiceRecoverStaleJobs(
+		context.Background(), dbgen.JobsServiceRecoverStaleJobsParams{
+			Requeue:     s.env.PBW_REQUEUE_INTERRUPTED_BACKUPS,
+			Message:     sql.NullString{Valid: true, String: message},
+			StaleBefore: time.Now().Add(-executions.StaleAfter),
+		},
+	)
+	if err != nil {
+		logger.Error("error recovering stale backup jobs", logger.KV{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, job := range recovered {
+		logger.Info("stale backup job recovered", logger.KV{
+			"job_id":    job.ID.String(),
+			"backup_id": job.BackupID.String(),
+			"status":    job.Status,
+		})
+	}
+	if len(recovered) > 0 {
+		s.notify()
+	}
+}
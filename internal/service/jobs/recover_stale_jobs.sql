@@ -0,0 +1,11 @@
+-- name: JobsServiceRecoverStaleJobs :many
+UPDATE backup_jobs
+SET
+  status = CASE WHEN @requeue::BOOLEAN THEN 'queued' ELSE 'failed' END,
+  message = @message,
+  run_after = NOW(),
+  finished_at = CASE WHEN @requeue::BOOLEAN THEN NULL ELSE NOW() END
+WHERE
+  status = 'running'
+  AND COALESCE(heartbeat_at, started_at) < sqlc.arg('stale_before')::TIMESTAMPTZ
+RETURNING id, backup_id, status;
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ServerKey returns the host and port of the server of a connection string,
+// the per server concurrency limit is applied to the jobs with the same key.
+// Connection strings in URL and keyword/value form are supported, fallback
+// is returned when the host can't be found.
+func ServerKey(connString string, fallback string) string {
+	connString = strings.TrimSpace(connString)
+
+	if strings.Contains(connString, "://") {
+		u, err := url.Parse(connString)
+		if err != nil {
+			return fallback
+		}
+		if u.Hostname() != "" {
+			return joinHostPort(u.Hostname(), u.Port())
+		}
+		// Unix sockets are given in the host parameter
+		if host := u.Query().Get("host"); host != "" {
+			return joinHostPort(host, u.Query().Get("port"))
+		}
+		return fallback
+	}
+
+	host, port := "", ""
+	for _, field := range strings.Fields(connString) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, "'")
+		switch strings.ToLower(key) {
+		case "host", "hostaddr":
+			if host == "" {
+				host = value
+			}
+		case "port":
+			port = value
+		}
+	}
+	if host == "" {
+		return fallback
+	}
+	return joinHostPort(host, port)
+}
+
+func joinHostPort(host string, port string) string {
+	host = strings.ToLower(host)
+	if port == "" {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/google/uuid"
+)
+
+// waitInterval is how often WaitJob checks the status of the job.
+const waitInterval = 2 * time.Second
+
+// WaitJob waits until the job finishes, after its last attempt when it's
+// retried, and returns it. When ctx is cancelled before the job started it
+// is removed from the queue.
+func (s *Service) WaitJob(
+	ctx context.Context, jobID uuid.UUID,
+) (dbgen.BackupJob, error) {
+	ticker := time.NewTicker(waitInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := s.dbgen.JobsServiceGetJob(ctx, jobID)
+		if err != nil && ctx.Err() == nil {
+			return dbgen.BackupJob{}, err
+		}
+		if err == nil && finished(job.Status) {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			_ = s.CancelJob(context.WithoutCancel(ctx), jobID)
+			return dbgen.BackupJob{}, context.Cause(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// finished reports whether a job with the status won't run again.
+func finished(status string) bool {
+	return status == StatusSuccess || status == StatusFailed ||
+		status == StatusCancelled
+}
@@ -0,0 +1,2 @@
+-- name: JobsServiceGetJob :one
+SELECT * FROM backup_jobs WHERE id = @id;
@@ -0,0 +1,161 @@
+package pitr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+)
+
+// walKind is the kind of the archived WAL files used in their file
+// extension, see compression.Extension.
+const walKind = "wal"
+
+// archiveSegments uploads the completed WAL segments and history files found
+// in the spool directory of the backup, compressed and encrypted like its
+// base backups. Every file is removed from the spool directory once it is
+// recorded.
+func (s *Service) archiveSegments(ctx context.Context, a archive) error {
+	entries, err := os.ReadDir(spoolDir(a.BackupID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	key, encrypted, err := s.executionsService.BackupEncryptionKey(
+		a.IsLocal, a.EncryptionKey,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !postgres.IsWALFileName(entry.Name()) {
+			continue
+		}
+		err := s.archiveSegment(ctx, a, entry.Name(), key, encrypted)
+		if err != nil {
+			return fmt.Errorf("error archiving %s: %w", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) archiveSegment(
+	ctx context.Context, a archive, fileName string, key encryption.Key,
+	encrypted bool,
+) error {
+	localPath := filepath.Join(spoolDir(a.BackupID), fileName)
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	codec := a.Compression
+	if codec == compression.CodecZip {
+		codec = compression.CodecNone
+	}
+	ext := compression.Extension(walKind, codec)
+	var reader io.Reader = compression.Compress(
+		file, codec, int(a.CompressionLevel),
+	)
+
+	fingerprint := sql.NullString{}
+	if encrypted {
+		reader = encryption.Encrypt(reader, key)
+		ext += encryption.Extension
+		fingerprint = sql.NullString{Valid: true, String: key.Fingerprint()}
+	}
+
+	path := a.walPath(fileName + ext)
+	fileSize, err := s.upload(ctx, a, path, reader)
+	if err != nil {
+		return err
+	}
+
+	err = s.dbgen.PITRServiceCreateWALSegment(
+		ctx, dbgen.PITRServiceCreateWALSegmentParams{
+			BackupID:                 a.BackupID,
+			FileName:                 fileName,
+			Path:                     path,
+			FileSize:                 fileSize,
+			Compression:              codec,
+			FileExtension:            ext,
+			EncryptionKeyFingerprint: fingerprint,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(localPath)
+}
+
+// openSegment returns a reader with the decrypted and decompressed content of
+// an archived WAL file, the caller must close it.
+func (s *Service) openSegment(
+	ctx context.Context, a archive, segment dbgen.WalSegment,
+) (io.ReadCloser, error) {
+	file, err := s.open(ctx, a, segment.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader = file
+	ext := segment.FileExtension
+	if encryption.IsEncrypted(ext) {
+		key, ok, err := s.executionsService.BackupEncryptionKey(
+			a.IsLocal, a.EncryptionKey,
+		)
+		if err == nil && !ok {
+			err = fmt.Errorf("WAL file is encrypted but no encryption key is configured")
+		}
+		if err == nil && key.Fingerprint() != segment.EncryptionKeyFingerprint.String {
+			err = fmt.Errorf(
+				"WAL file was encrypted with the key %s but the configured key is %s",
+				segment.EncryptionKeyFingerprint.String, key.Fingerprint(),
+			)
+		}
+		if err == nil {
+			reader, err = encryption.NewReader(file, key)
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		ext = encryption.TrimExtension(ext)
+	}
+
+	codec, _ := compression.ParseExtension(ext)
+	decompressed, err := compression.NewReader(reader, codec)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return segmentFile{ReadCloser: decompressed, file: file}, nil
+}
+
+// segmentFile closes both the decompressor and the stored file.
+type segmentFile struct {
+	io.ReadCloser
+	file io.Closer
+}
+
+func (f segmentFile) Close() error {
+	_ = f.ReadCloser.Close()
+	return f.file.Close()
+}
@@ -0,0 +1,10 @@
+-- name: PITRServiceCreateWALSegment :exec
+INSERT INTO wal_segments (
+  backup_id, file_name, path, file_size, compression, file_extension,
+  encryption_key_fingerprint
+)
+VALUES (
+  @backup_id, @file_name, @path, @file_size, @compression, @file_extension,
+  @encryption_key_fingerprint
+)
+ON CONFLICT (backup_id, file_name) DO NOTHING;
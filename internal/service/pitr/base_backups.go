@@ -0,0 +1,107 @@
+package pitr
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/google/uuid"
+)
+
+// baseBackup is a successful base backup of a physical backup.
+type baseBackup struct {
+	ExecutionID uuid.UUID
+	FinishedAt  time.Time
+	StartLSN    uint64
+	EndLSN      uint64
+}
+
+// baseBackupsFromRows converts the rows into base backups, the rows with
+// invalid positions are skipped.
+func baseBackupsFromRows(rows []dbgen.PITRServiceGetBaseBackupsRow) []baseBackup {
+	bases := make([]baseBackup, 0, len(rows))
+	for _, row := range rows {
+		startLSN, err := postgres.ParseLSN(row.WalStartLsn.String)
+		if err != nil {
+			continue
+		}
+		endLSN, err := postgres.ParseLSN(row.WalEndLsn.String)
+		if err != nil {
+			continue
+		}
+
+		bases = append(bases, baseBackup{
+			ExecutionID: row.ID,
+			FinishedAt:  row.FinishedAt.Time,
+			StartLSN:    startLSN,
+			EndLSN:      endLSN,
+		})
+	}
+	return bases
+}
+
+// baseBackups returns the successful base backups of a physical backup.
+func (s *Service) baseBackups(
+	ctx context.Context, backupID uuid.UUID,
+) ([]baseBackup, error) {
+	rows, err := s.dbgen.PITRServiceGetBaseBackups(ctx, backupID)
+	if err != nil {
+		return nil, err
+	}
+	return baseBackupsFromRows(rows), nil
+}
+
+// pickBaseBackup returns the newest base backup the target can be reached
+// from: the cluster is only consistent once the WAL is replayed up to the
+// end of the base backup, so it must have finished before the target.
+func pickBaseBackup(
+	bases []baseBackup, target postgres.RecoveryTarget,
+) (baseBackup, error) {
+	targetLSN := uint64(0)
+	if target.LSN != "" {
+		lsn, err := postgres.ParseLSN(target.LSN)
+		if err != nil {
+			return baseBackup{}, err
+		}
+		targetLSN = lsn
+	}
+
+	picked, found := baseBackup{}, false
+	for _, base := range bases {
+		if !target.Time.IsZero() && base.FinishedAt.After(target.Time) {
+			continue
+		}
+		if target.LSN != "" && base.EndLSN > targetLSN {
+			continue
+		}
+		if !found || base.EndLSN > picked.EndLSN {
+			picked, found = base, true
+		}
+	}
+
+	if !found {
+		if len(bases) == 0 {
+			return baseBackup{}, errors.New("the backup has no successful base backups")
+		}
+		return baseBackup{}, errors.New(
+			"there is no base backup that finished before the recovery target",
+		)
+	}
+	return picked, nil
+}
+
+// oldestStartLSN returns the position the oldest base backup starts at,
+// false is returned when there are no base backups.
+func oldestStartLSN(bases []baseBackup) (uint64, bool) {
+	if len(bases) == 0 {
+		return 0, false
+	}
+
+	oldest := bases[0].StartLSN
+	for _, base := range bases[1:] {
+		oldest = min(oldest, base.StartLSN)
+	}
+	return oldest, true
+}
@@ -0,0 +1,92 @@
+package pitr
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseBackupsFromRows(t *testing.T) {
+	id := uuid.New()
+	finishedAt := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	bases := baseBackupsFromRows([]dbgen.PITRServiceGetBaseBackupsRow{
+		{
+			ID:          id,
+			FinishedAt:  sql.NullTime{Valid: true, Time: finishedAt},
+			WalStartLsn: sql.NullString{Valid: true, String: "0/2000028"},
+			WalEndLsn:   sql.NullString{Valid: true, String: "0/2000138"},
+		},
+		{
+			ID:          uuid.New(),
+			WalStartLsn: sql.NullString{Valid: true, String: "invalid"},
+			WalEndLsn:   sql.NullString{Valid: true, String: "0/2000138"},
+		},
+	})
+
+	assert.Equal(t, []baseBackup{{
+		ExecutionID: id,
+		FinishedAt:  finishedAt,
+		StartLSN:    0x2000028,
+		EndLSN:      0x2000138,
+	}}, bases)
+}
+
+func TestPickBaseBackup(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)
+	}
+	bases := []baseBackup{
+		{ExecutionID: uuid.New(), FinishedAt: day(3), StartLSN: 0x3000000, EndLSN: 0x3000100},
+		{ExecutionID: uuid.New(), FinishedAt: day(1), StartLSN: 0x1000000, EndLSN: 0x1000100},
+		{ExecutionID: uuid.New(), FinishedAt: day(2), StartLSN: 0x2000000, EndLSN: 0x2000100},
+	}
+
+	tests := []struct {
+		name    string
+		target  postgres.RecoveryTarget
+		want    int
+		wantErr bool
+	}{
+		{"latest", postgres.RecoveryTarget{}, 0, false},
+		{"time after all", postgres.RecoveryTarget{Time: day(10)}, 0, false},
+		{"time between", postgres.RecoveryTarget{Time: day(2).Add(time.Hour)}, 2, false},
+		{"time equal to finish", postgres.RecoveryTarget{Time: day(1)}, 1, false},
+		{"time before all", postgres.RecoveryTarget{Time: day(1).Add(-time.Hour)}, 0, true},
+		{"lsn between", postgres.RecoveryTarget{LSN: "0/2500000"}, 2, false},
+		{"lsn equal to end", postgres.RecoveryTarget{LSN: "0/1000100"}, 1, false},
+		{"lsn inside first", postgres.RecoveryTarget{LSN: "0/1000050"}, 0, true},
+		{"invalid lsn", postgres.RecoveryTarget{LSN: "invalid"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickBaseBackup(bases, tt.target)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, bases[tt.want], got)
+		})
+	}
+
+	_, err := pickBaseBackup(nil, postgres.RecoveryTarget{})
+	assert.Error(t, err)
+}
+
+func TestOldestStartLSN(t *testing.T) {
+	_, ok := oldestStartLSN(nil)
+	assert.False(t, ok)
+
+	lsn, ok := oldestStartLSN([]baseBackup{
+		{StartLSN: 0x3000000}, {StartLSN: 0x1000000}, {StartLSN: 0x2000000},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0x1000000), lsn)
+}
@@ -0,0 +1,62 @@
+package pitr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchiveStatus summarizes the WAL archive of a physical backup.
+type ArchiveStatus struct {
+	Receiver  ReceiverStatus
+	Segments  int64
+	TotalSize int64
+	// LastSegment is the name of the last archived WAL file, empty when
+	// nothing is archived yet
+	LastSegment    string
+	LastArchivedAt time.Time
+	BaseBackups    int
+	// RecoverableFrom is when the oldest base backup finished, the cluster
+	// can be recovered to any point between it and the last archived WAL
+	RecoverableFrom time.Time
+}
+
+// GetArchiveStatus returns the status of the WAL archive of a physical
+// backup.
+func (s *Service) GetArchiveStatus(
+	ctx context.Context, backupID uuid.UUID,
+) (ArchiveStatus, error) {
+	status := ArchiveStatus{Receiver: s.ReceiverStatus(backupID)}
+
+	summary, err := s.dbgen.PITRServiceGetWALSegmentsSummary(ctx, backupID)
+	if err != nil {
+		return ArchiveStatus{}, err
+	}
+	status.Segments = summary.Segments
+	status.TotalSize = summary.TotalSize
+
+	last, err := s.dbgen.PITRServiceGetLastWALSegment(ctx, backupID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return ArchiveStatus{}, err
+	}
+	if err == nil {
+		status.LastSegment = last.FileName
+		status.LastArchivedAt = last.CreatedAt
+	}
+
+	bases, err := s.baseBackups(ctx, backupID)
+	if err != nil {
+		return ArchiveStatus{}, err
+	}
+	status.BaseBackups = len(bases)
+	for _, base := range bases {
+		if status.RecoverableFrom.IsZero() || base.FinishedAt.Before(status.RecoverableFrom) {
+			status.RecoverableFrom = base.FinishedAt
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,12 @@
+-- name: PITRServiceGetWALSegmentsSummary :one
+SELECT
+  COUNT(*) AS segments,
+  COALESCE(SUM(file_size), 0)::BIGINT AS total_size
+FROM wal_segments
+WHERE backup_id = @backup_id;
+
+-- name: PITRServiceGetLastWALSegment :one
+SELECT * FROM wal_segments
+WHERE backup_id = @backup_id
+ORDER BY created_at DESC, file_name DESC
+LIMIT 1;
@@ -0,0 +1,10 @@
+-- name: PITRServiceGetBaseBackups :many
+SELECT id, finished_at, wal_start_lsn, wal_end_lsn
+FROM executions
+WHERE backup_id = @backup_id
+AND status = 'success'
+AND deleted_at IS NULL
+AND path IS NOT NULL
+AND wal_start_lsn IS NOT NULL
+AND wal_end_lsn IS NOT NULL
+ORDER BY finished_at DESC;
@@ -0,0 +1,125 @@
+-- name: PITRServiceGetPhysicalBackups :many
+SELECT
+  backups.id,
+  backups.is_active,
+  backups.is_local,
+  backups.dest_dir,
+  backups.compression,
+  backups.compression_level,
+
+  pgp_sym_decrypt(databases.connection_string, @encryption_key) AS decrypted_database_connection_string,
+  databases.version AS database_version,
+
+  destinations.type AS destination_type,
+  destinations.base_path AS destination_base_path,
+  destinations.host_key AS destination_host_key,
+  (
+    CASE WHEN destinations.private_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.private_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_private_key,
+  destinations.s3_server_side_encryption AS destination_s3_server_side_encryption,
+  destinations.s3_kms_key_id AS destination_s3_kms_key_id,
+  destinations.s3_storage_class AS destination_s3_storage_class,
+  destinations.s3_virtual_hosted_style AS destination_s3_virtual_hosted_style,
+  destinations.s3_object_lock_mode AS destination_s3_object_lock_mode,
+  destinations.s3_object_lock_days AS destination_s3_object_lock_days,
+  destinations.s3_object_lock_legal_hold AS destination_s3_object_lock_legal_hold,
+  destinations.s3_credentials_mode AS destination_s3_credentials_mode,
+  destinations.s3_role_arn AS destination_s3_role_arn,
+  destinations.s3_external_id AS destination_s3_external_id,
+  (
+    CASE WHEN destinations.s3_sse_customer_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.s3_sse_customer_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_s3_sse_customer_key,
+  destinations.bucket_name AS destination_bucket_name,
+  destinations.region AS destination_region,
+  destinations.endpoint AS destination_endpoint,
+  (
+    CASE WHEN destinations.access_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.access_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_access_key,
+  (
+    CASE WHEN destinations.secret_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.secret_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_secret_key,
+  (
+    CASE WHEN destinations.backup_encryption_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.backup_encryption_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_backup_encryption_key
+FROM backups
+INNER JOIN databases ON backups.database_id = databases.id
+LEFT JOIN destinations ON backups.destination_id = destinations.id
+WHERE backups.mode = 'physical';
+
+-- name: PITRServiceGetPhysicalBackup :one
+SELECT
+  backups.id,
+  backups.is_active,
+  backups.is_local,
+  backups.dest_dir,
+  backups.compression,
+  backups.compression_level,
+
+  pgp_sym_decrypt(databases.connection_string, @encryption_key) AS decrypted_database_connection_string,
+  databases.version AS database_version,
+
+  destinations.type AS destination_type,
+  destinations.base_path AS destination_base_path,
+  destinations.host_key AS destination_host_key,
+  (
+    CASE WHEN destinations.private_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.private_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_private_key,
+  destinations.s3_server_side_encryption AS destination_s3_server_side_encryption,
+  destinations.s3_kms_key_id AS destination_s3_kms_key_id,
+  destinations.s3_storage_class AS destination_s3_storage_class,
+  destinations.s3_virtual_hosted_style AS destination_s3_virtual_hosted_style,
+  destinations.s3_object_lock_mode AS destination_s3_object_lock_mode,
+  destinations.s3_object_lock_days AS destination_s3_object_lock_days,
+  destinations.s3_object_lock_legal_hold AS destination_s3_object_lock_legal_hold,
+  destinations.s3_credentials_mode AS destination_s3_credentials_mode,
+  destinations.s3_role_arn AS destination_s3_role_arn,
+  destinations.s3_external_id AS destination_s3_external_id,
+  (
+    CASE WHEN destinations.s3_sse_customer_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.s3_sse_customer_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_s3_sse_customer_key,
+  destinations.bucket_name AS destination_bucket_name,
+  destinations.region AS destination_region,
+  destinations.endpoint AS destination_endpoint,
+  (
+    CASE WHEN destinations.access_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.access_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_access_key,
+  (
+    CASE WHEN destinations.secret_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.secret_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_secret_key,
+  (
+    CASE WHEN destinations.backup_encryption_key IS NOT NULL
+    THEN pgp_sym_decrypt(destinations.backup_encryption_key, @encryption_key)
+    ELSE ''
+    END
+  ) AS decrypted_destination_backup_encryption_key
+FROM backups
+INNER JOIN databases ON backups.database_id = databases.id
+LEFT JOIN destinations ON backups.destination_id = destinations.id
+WHERE backups.mode = 'physical' AND backups.id = @backup_id;
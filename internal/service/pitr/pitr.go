@@ -0,0 +1,45 @@
+package pitr
+
+import (
+	"sync"
+
+	"github.com/eduardolat/pgbackweb/internal/config"
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/service/restorations"
+	"github.com/google/uuid"
+)
+
+// Service handles the point-in-time recovery of the physical backups: it
+// streams the WAL of every active physical backup with pg_receivewal,
+// archives the completed segments next to the base backups and prepares data
+// directories recovered up to a timestamp or an LSN.
+type Service struct {
+	env                 config.Env
+	dbgen               *dbgen.Queries
+	ints                *integration.Integration
+	executionsService   *executions.Service
+	restorationsService *restorations.Service
+
+	// syncMu prevents overlapping runs of SyncReceivers
+	syncMu sync.Mutex
+	// mu protects receivers
+	mu        sync.Mutex
+	receivers map[uuid.UUID]*receiver
+}
+
+func New(
+	env config.Env, dbgen *dbgen.Queries, ints *integration.Integration,
+	executionsService *executions.Service,
+	restorationsService *restorations.Service,
+) *Service {
+	return &Service{
+		env:                 env,
+		dbgen:               dbgen,
+		ints:                ints,
+		executionsService:   executionsService,
+		restorationsService: restorationsService,
+		receivers:           map[uuid.UUID]*receiver{},
+	}
+}
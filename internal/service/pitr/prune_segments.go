@@ -0,0 +1,80 @@
+package pitr
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+)
+
+// PruneSegments deletes the archived WAL files that are older than the
+// oldest base backup of each physical backup, they can't be used by any
+// point-in-time restore once the retention policy removed the base backups
+// that needed them. It is called every hour.
+func (s *Service) PruneSegments() {
+	ctx := context.Background()
+	rows, err := s.dbgen.PITRServiceGetPhysicalBackups(
+		ctx, s.env.PBW_ENCRYPTION_KEY,
+	)
+	if err != nil {
+		logger.Error("error getting physical backups", logger.KV{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, row := range rows {
+		a := archiveFromRow(row)
+		if err := s.pruneSegments(ctx, a); err != nil {
+			logger.Error("error pruning WAL segments", logger.KV{
+				"backup_id": a.BackupID.String(),
+				"error":     err.Error(),
+			})
+		}
+	}
+}
+
+func (s *Service) pruneSegments(ctx context.Context, a archive) error {
+	bases, err := s.baseBackups(ctx, a.BackupID)
+	if err != nil {
+		return err
+	}
+
+	// Without base backups there is nothing to restore yet, the WAL is kept
+	// until the first one finishes
+	oldest, ok := oldestStartLSN(bases)
+	if !ok {
+		return nil
+	}
+
+	segments, err := s.dbgen.PITRServiceGetWALSegments(ctx, a.BackupID)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		names = append(names, segment.FileName)
+	}
+	prunable := map[string]bool{}
+	for _, name := range postgres.WALFilesBefore(names, oldest) {
+		prunable[name] = true
+	}
+
+	for _, segment := range segments {
+		if !prunable[segment.FileName] {
+			continue
+		}
+
+		err := s.remove(ctx, a, segment.Path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := s.dbgen.PITRServiceDeleteWALSegment(ctx, segment.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+-- name: PITRServiceGetWALSegments :many
+SELECT * FROM wal_segments
+WHERE backup_id = @backup_id
+ORDER BY file_name ASC;
+
+-- name: PITRServiceDeleteWALSegment :exec
+DELETE FROM wal_segments
+WHERE id = @id;
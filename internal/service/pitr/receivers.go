@@ -0,0 +1,225 @@
+package pitr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
+)
+
+// receiver is a pg_receivewal process streaming the WAL of a physical backup
+// into its spool directory.
+type receiver struct {
+	cancel     context.CancelFunc
+	done       chan struct{}
+	connString string
+	version    string
+	startedAt  time.Time
+	err        error
+	errAt      time.Time
+}
+
+// ReceiverStatus is the state of the WAL streaming of a physical backup.
+type ReceiverStatus struct {
+	Running     bool
+	StartedAt   time.Time
+	LastError   string
+	LastErrorAt time.Time
+}
+
+// slotName returns the name of the replication slot of a physical backup.
+func slotName(backupID uuid.UUID) string {
+	return "pbw_" + strings.ReplaceAll(backupID.String(), "-", "")
+}
+
+// spoolDir returns the local directory where pg_receivewal writes the WAL of
+// a physical backup before it is archived.
+func spoolDir(backupID uuid.UUID) string {
+	return filepath.Join(os.TempDir(), "pbw-wal", backupID.String())
+}
+
+// ReceiverStatus returns the state of the WAL streaming of a backup.
+func (s *Service) ReceiverStatus(backupID uuid.UUID) ReceiverStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.receivers[backupID]
+	if !ok {
+		return ReceiverStatus{}
+	}
+
+	status := ReceiverStatus{
+		StartedAt:   r.startedAt,
+		LastErrorAt: r.errAt,
+	}
+	if r.err != nil {
+		status.LastError = r.err.Error()
+	}
+	select {
+	case <-r.done:
+	default:
+		status.Running = true
+	}
+	return status
+}
+
+// SyncReceivers starts the WAL streaming of the active physical backups that
+// are not streaming yet, restarting the ones that stopped, stops the
+// streaming of the rest and archives the completed WAL segments. It is
+// called every minute.
+func (s *Service) SyncReceivers() {
+	if !s.syncMu.TryLock() {
+		return
+	}
+	defer s.syncMu.Unlock()
+
+	ctx := context.Background()
+	rows, err := s.dbgen.PITRServiceGetPhysicalBackups(
+		ctx, s.env.PBW_ENCRYPTION_KEY,
+	)
+	if err != nil {
+		logger.Error("error getting physical backups", logger.KV{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	active := map[uuid.UUID]archive{}
+	for _, row := range rows {
+		if row.IsActive {
+			active[row.ID] = archiveFromRow(row)
+		}
+	}
+
+	s.mu.Lock()
+	stale := []uuid.UUID{}
+	for backupID, r := range s.receivers {
+		a, ok := active[backupID]
+		if !ok || a.ConnString != r.connString || a.DatabaseVersion != r.version {
+			stale = append(stale, backupID)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, backupID := range stale {
+		_, keepSlot := active[backupID]
+		s.stopReceiver(backupID, !keepSlot)
+	}
+
+	for _, a := range active {
+		s.startReceiver(a)
+
+		if err := s.archiveSegments(ctx, a); err != nil {
+			logger.Error("error archiving WAL segments", logger.KV{
+				"backup_id": a.BackupID.String(),
+				"error":     err.Error(),
+			})
+		}
+	}
+}
+
+// startReceiver starts pg_receivewal for the physical backup unless it is
+// already running. The replication slot of the backup is created first so
+// the server keeps the WAL that is not archived yet.
+func (s *Service) startReceiver(a archive) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.receivers[a.BackupID]; ok {
+		select {
+		case <-r.done:
+		default:
+			return
+		}
+	}
+
+	r := &receiver{
+		done:       make(chan struct{}),
+		connString: a.ConnString,
+		version:    a.DatabaseVersion,
+		startedAt:  time.Now(),
+	}
+	s.receivers[a.BackupID] = r
+
+	setError := func(err error) {
+		logger.Error("error streaming WAL", logger.KV{
+			"backup_id": a.BackupID.String(),
+			"error":     err.Error(),
+		})
+		s.mu.Lock()
+		r.err, r.errAt = err, time.Now()
+		s.mu.Unlock()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	go func() {
+		defer close(r.done)
+		defer cancel()
+
+		pgVersion, err := s.ints.PGClient.ParseVersionPG(a.DatabaseVersion)
+		if err != nil {
+			setError(err)
+			return
+		}
+
+		dir := spoolDir(a.BackupID)
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			setError(err)
+			return
+		}
+
+		slot := slotName(a.BackupID)
+		err = s.ints.PGClient.CreateReplicationSlot(pgVersion, a.ConnString, slot)
+		if err != nil {
+			setError(err)
+			return
+		}
+
+		err = s.ints.PGClient.ReceiveWAL(ctx, pgVersion, a.ConnString, slot, dir)
+		if err != nil {
+			setError(err)
+		}
+	}()
+}
+
+// stopReceiver stops the WAL streaming of a backup. When dropSlot is true
+// the replication slot is dropped so the server doesn't keep WAL that will
+// never be archived, and the spool directory is removed.
+func (s *Service) stopReceiver(backupID uuid.UUID, dropSlot bool) {
+	s.mu.Lock()
+	r, ok := s.receivers[backupID]
+	delete(s.receivers, backupID)
+	s.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	r.cancel()
+	<-r.done
+
+	if !dropSlot {
+		return
+	}
+
+	pgVersion, err := s.ints.PGClient.ParseVersionPG(r.version)
+	if err == nil {
+		err = s.ints.PGClient.DropReplicationSlot(
+			pgVersion, r.connString, slotName(backupID),
+		)
+	}
+	if err != nil {
+		logger.Error("error dropping replication slot", logger.KV{
+			"backup_id": backupID.String(),
+			"error":     err.Error(),
+		})
+	}
+
+	_ = os.RemoveAll(spoolDir(backupID))
+}
@@ -0,0 +1,302 @@
+package pitr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
+)
+
+// walDirName is the directory inside the restored data directory where the
+// archived WAL files are downloaded for the recovery.
+const walDirName = "pbw_wal"
+
+type RestorePointInTimeParams struct {
+	BackupID uuid.UUID
+	Target   postgres.RecoveryTarget
+	// DataDirectory is the absolute path, in the server where PG Back Web is
+	// running, of the empty directory where the cluster is restored.
+	DataDirectory string
+}
+
+// validateDataDirectory checks that the data directory is an absolute path
+// to an empty or missing directory.
+func validateDataDirectory(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return errors.New("the data directory must be an absolute path")
+	}
+	if strings.ContainsAny(dir, `"'`) {
+		return errors.New("the data directory can't contain quotes")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading the data directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return errors.New("the data directory must be empty or not exist")
+	}
+
+	return nil
+}
+
+// ValidateRestorePointInTime checks the parameters of a point-in-time
+// restore and returns the ID of the base backup execution it would start
+// from. It is used to report mistakes before starting the restore in the
+// background.
+func (s *Service) ValidateRestorePointInTime(
+	ctx context.Context, params RestorePointInTimeParams,
+) (uuid.UUID, error) {
+	if err := params.Target.Validate(); err != nil {
+		return uuid.Nil, err
+	}
+	if err := validateDataDirectory(params.DataDirectory); err != nil {
+		return uuid.Nil, err
+	}
+
+	bases, err := s.baseBackups(ctx, params.BackupID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	base, err := pickBaseBackup(bases, params.Target)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return base.ExecutionID, nil
+}
+
+// targetDescription describes the point the cluster is recovered to.
+func targetDescription(target postgres.RecoveryTarget) string {
+	switch {
+	case !target.Time.IsZero():
+		return "up to " + target.Time.UTC().Format(time.RFC3339)
+	case target.LSN != "":
+		return "up to the LSN " + target.LSN
+	default:
+		return "up to the last archived WAL segment"
+	}
+}
+
+// RestorePointInTime restores the newest base backup that finished before
+// the target into the data directory, downloads the archived WAL needed to
+// replay it and configures the recovery. Starting PostgreSQL on the data
+// directory recovers the cluster up to the target.
+//
+// The restore is recorded as a restoration of the base backup execution.
+func (s *Service) RestorePointInTime(
+	ctx context.Context, params RestorePointInTimeParams,
+) error {
+	logError := func(err error) {
+		logger.Error("error running point-in-time restore", logger.KV{
+			"backup_id": params.BackupID.String(),
+			"error":     err.Error(),
+		})
+	}
+
+	if err := params.Target.Validate(); err != nil {
+		logError(err)
+		return err
+	}
+	if err := validateDataDirectory(params.DataDirectory); err != nil {
+		logError(err)
+		return err
+	}
+
+	bases, err := s.baseBackups(ctx, params.BackupID)
+	if err != nil {
+		logError(err)
+		return err
+	}
+	base, err := pickBaseBackup(bases, params.Target)
+	if err != nil {
+		logError(err)
+		return err
+	}
+
+	res, err := s.restorationsService.CreateRestoration(
+		ctx, dbgen.RestorationsServiceCreateRestorationParams{
+			ExecutionID: base.ExecutionID,
+			Status:      "running",
+			DataDirectory: sql.NullString{
+				Valid: true, String: params.DataDirectory,
+			},
+			RecoveryTargetTime: sql.NullTime{
+				Valid: !params.Target.Time.IsZero(), Time: params.Target.Time,
+			},
+			RecoveryTargetLsn: sql.NullString{
+				Valid: params.Target.LSN != "", String: params.Target.LSN,
+			},
+		},
+	)
+	if err != nil {
+		logError(err)
+		return err
+	}
+	defer s.restorationsService.StartHeartbeat(ctx, res.ID)()
+
+	version, err := s.prepareDataDirectory(ctx, params, base)
+	if err != nil {
+		logError(err)
+		clearDirectory(params.DataDirectory)
+		_, updateErr := s.restorationsService.UpdateRestoration(
+			ctx, dbgen.RestorationsServiceUpdateRestorationParams{
+				ID:         res.ID,
+				Status:     sql.NullString{Valid: true, String: "failed"},
+				Message:    sql.NullString{Valid: true, String: err.Error()},
+				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+			},
+		)
+		return errors.Join(err, updateErr)
+	}
+
+	logger.Info("point-in-time restore prepared successfully", logger.KV{
+		"restoration_id": res.ID.String(),
+		"backup_id":      params.BackupID.String(),
+	})
+	_, err = s.restorationsService.UpdateRestoration(
+		ctx, dbgen.RestorationsServiceUpdateRestorationParams{
+			ID:     res.ID,
+			Status: sql.NullString{Valid: true, String: "success"},
+			Message: sql.NullString{Valid: true, String: fmt.Sprintf(
+				"The data directory is ready, start PostgreSQL %s on it to recover the cluster %s. "+
+					"The archived WAL is downloaded in %s and can be removed once the recovery finishes",
+				version, targetDescription(params.Target),
+				filepath.Join(params.DataDirectory, walDirName),
+			)},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		},
+	)
+	return err
+}
+
+// prepareDataDirectory extracts the base backup into the data directory,
+// downloads the WAL files needed to replay it and writes the recovery
+// configuration. It returns the PostgreSQL version of the cluster.
+func (s *Service) prepareDataDirectory(
+	ctx context.Context, params RestorePointInTimeParams, base baseBackup,
+) (string, error) {
+	row, err := s.dbgen.PITRServiceGetPhysicalBackup(
+		ctx, dbgen.PITRServiceGetPhysicalBackupParams{
+			EncryptionKey: s.env.PBW_ENCRYPTION_KEY,
+			BackupID:      params.BackupID,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	a := archiveFromRow(dbgen.PITRServiceGetPhysicalBackupsRow(row))
+
+	execution, err := s.executionsService.GetExecution(ctx, base.ExecutionID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(params.DataDirectory, 0o700); err != nil {
+		return "", fmt.Errorf("error creating the data directory: %w", err)
+	}
+
+	file, _, err := s.executionsService.OpenExecutionFile(ctx, base.ExecutionID)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	codec, _ := compression.ParseExtension(
+		encryption.TrimExtension(execution.FileExtension),
+	)
+	reader, err := compression.NewReader(file, codec)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	if err := compression.ExtractTar(reader, params.DataDirectory); err != nil {
+		return "", fmt.Errorf("error extracting the base backup: %w", err)
+	}
+
+	walDir := filepath.Join(params.DataDirectory, walDirName)
+	if err := os.MkdirAll(walDir, 0o700); err != nil {
+		return "", err
+	}
+
+	segments, err := s.dbgen.PITRServiceGetWALSegments(ctx, params.BackupID)
+	if err != nil {
+		return "", err
+	}
+	byName := make(map[string]dbgen.WalSegment, len(segments))
+	names := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		byName[segment.FileName] = segment
+		names = append(names, segment.FileName)
+	}
+
+	for _, name := range postgres.WALFilesFrom(names, base.StartLSN) {
+		err := s.downloadSegment(ctx, a, byName[name], filepath.Join(walDir, name))
+		if err != nil {
+			return "", fmt.Errorf("error downloading WAL file %s: %w", name, err)
+		}
+	}
+
+	err = postgres.WriteRecoveryConfig(params.DataDirectory, walDir, params.Target)
+	if err != nil {
+		return "", err
+	}
+
+	// PostgreSQL refuses to start if the data directory is accessible by
+	// other users
+	if err := os.Chmod(params.DataDirectory, 0o700); err != nil {
+		return "", err
+	}
+
+	return execution.DatabaseVersion, nil
+}
+
+// downloadSegment writes the decrypted and decompressed content of an
+// archived WAL file to dst.
+func (s *Service) downloadSegment(
+	ctx context.Context, a archive, segment dbgen.WalSegment, dst string,
+) error {
+	reader, err := s.openSegment(ctx, a, segment)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+// clearDirectory removes the content of the directory, the directory itself
+// is kept because it may be a mount point.
+func clearDirectory(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		_ = os.RemoveAll(filepath.Join(dir, entry.Name()))
+	}
+}
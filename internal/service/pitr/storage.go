@@ -0,0 +1,109 @@
+package pitr
+
+import (
+	"context"
+	"io"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/google/uuid"
+)
+
+// archive is a physical backup together with the storage where its WAL
+// files are archived.
+type archive struct {
+	BackupID         uuid.UUID
+	IsActive         bool
+	IsLocal          bool
+	DestDir          string
+	Compression      string
+	CompressionLevel int16
+	ConnString       string
+	DatabaseVersion  string
+	Storage          storage.Config
+	EncryptionKey    string
+}
+
+func archiveFromRow(row dbgen.PITRServiceGetPhysicalBackupsRow) archive {
+	return archive{
+		BackupID:         row.ID,
+		IsActive:         row.IsActive,
+		IsLocal:          row.IsLocal,
+		DestDir:          row.DestDir,
+		Compression:      row.Compression,
+		CompressionLevel: row.CompressionLevel,
+		ConnString:       row.DecryptedDatabaseConnectionString,
+		DatabaseVersion:  row.DatabaseVersion,
+		Storage: storage.Config{
+			Type:       row.DestinationType.String,
+			BucketName: row.DestinationBucketName.String,
+			Region:     row.DestinationRegion.String,
+			Endpoint:   row.DestinationEndpoint.String,
+			AccessKey:  row.DecryptedDestinationAccessKey,
+			SecretKey:  row.DecryptedDestinationSecretKey,
+			BasePath:   row.DestinationBasePath.String,
+			PrivateKey: row.DecryptedDestinationPrivateKey,
+			HostKey:    row.DestinationHostKey.String,
+			S3: storage.S3Options{
+				CredentialsMode:      row.DestinationS3CredentialsMode.String,
+				RoleARN:              row.DestinationS3RoleArn.String,
+				ExternalID:           row.DestinationS3ExternalID.String,
+				ServerSideEncryption: row.DestinationS3ServerSideEncryption.String,
+				KMSKeyID:             row.DestinationS3KmsKeyID.String,
+				SSECustomerKey:       row.DecryptedDestinationS3SseCustomerKey,
+				StorageClass:         row.DestinationS3StorageClass.String,
+				VirtualHostedStyle:   row.DestinationS3VirtualHostedStyle.Bool,
+				ObjectLockMode:       row.DestinationS3ObjectLockMode.String,
+				ObjectLockDays:       row.DestinationS3ObjectLockDays.Int32,
+				ObjectLockLegalHold:  row.DestinationS3ObjectLockLegalHold.Bool,
+			},
+		},
+		EncryptionKey: row.DecryptedDestinationBackupEncryptionKey,
+	}
+}
+
+// walPath returns the path of an archived WAL file relative to the base
+// directory of the destination.
+func (a archive) walPath(fileName string) string {
+	return strutil.CreatePath(false, a.DestDir, "wal", fileName)
+}
+
+// backend returns the storage backend of the archive, the local backups
+// directory or its destination.
+func (s *Service) backend(a archive) (storage.StorageBackend, error) {
+	return s.ints.StorageClient.BackendOrLocal(a.IsLocal, a.Storage)
+}
+
+// upload stores the file in the storage of the archive and returns its
+// size, in bytes.
+func (s *Service) upload(
+	ctx context.Context, a archive, path string, reader io.Reader,
+) (int64, error) {
+	backend, err := s.backend(a)
+	if err != nil {
+		return 0, err
+	}
+	return backend.Upload(ctx, path, reader)
+}
+
+// open returns a reader with the content of an archived file, the caller
+// must close it.
+func (s *Service) open(
+	ctx context.Context, a archive, path string,
+) (io.ReadCloser, error) {
+	backend, err := s.backend(a)
+	if err != nil {
+		return nil, err
+	}
+	return backend.Download(ctx, path)
+}
+
+// remove deletes an archived file.
+func (s *Service) remove(ctx context.Context, a archive, path string) error {
+	backend, err := s.backend(a)
+	if err != nil {
+		return err
+	}
+	return backend.Delete(ctx, path)
+}
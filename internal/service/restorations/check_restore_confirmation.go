@@ -0,0 +1,34 @@
+package restorations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CheckRestoreConfirmation checks that the name of a protected target
+// database was typed as the confirmation of a restoration. Dry runs and
+// restores into a new database don't write to the target database, they
+// need no confirmation.
+func (s *Service) CheckRestoreConfirmation(
+	ctx context.Context, params RunRestorationParams, confirmation string,
+) error {
+	if !params.DatabaseID.Valid || params.DryRun ||
+		!params.NewDatabase.IsEmpty() {
+		return nil
+	}
+
+	db, err := s.databasesService.GetDatabase(ctx, params.DatabaseID.UUID)
+	if err != nil {
+		return err
+	}
+
+	if db.IsProtected && strings.TrimSpace(confirmation) != db.Name {
+		return fmt.Errorf(
+			"the database %q is protected, type its name to confirm the restoration",
+			db.Name,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package restorations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+)
+
+// createNewDatabase creates the database of a restoration in the server of
+// the connection string. It returns the connection string of the new
+// database and a function that drops it, which still runs once ctx is
+// cancelled.
+func (s *Service) createNewDatabase(
+	ctx context.Context, version string, connString string, newDatabase postgres.NewDatabase,
+) (string, func() error, error) {
+	pgVersion, err := s.ints.PGClient.ParseVersionPG(version)
+	if err != nil {
+		return "", nil, err
+	}
+
+	newConnString, err := postgres.WithDatabase(connString, newDatabase.Name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = s.ints.PGClient.CreateDatabase(
+		ctx, pgVersion, connString, newDatabase.Name, postgres.CreateDatabaseParams{
+			Template: newDatabase.Template,
+			Owner:    newDatabase.Owner,
+		},
+	)
+	if err != nil {
+		return "", nil, fmt.Errorf("error creating new database: %w", err)
+	}
+
+	drop := func() error {
+		err := s.ints.PGClient.DropDatabase(
+			ctx, pgVersion, connString, newDatabase.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("error dropping new database: %w", err)
+		}
+		return nil
+	}
+
+	return newConnString, drop, nil
+}
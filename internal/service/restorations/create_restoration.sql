@@ -1,4 +1,15 @@
 -- name: RestorationsServiceCreateRestoration :one
-INSERT INTO restorations (execution_id, database_id, status, message)
-VALUES (@execution_id, @database_id, @status, @message)
+INSERT INTO restorations (
+  execution_id, database_id, status, message, data_directory,
+  recovery_target_time, recovery_target_lsn, hooks, selection, new_database,
+  dry_run
+)
+VALUES (
+  @execution_id, @database_id, @status, @message, @data_directory,
+  @recovery_target_time, @recovery_target_lsn,
+  COALESCE(sqlc.narg('hooks')::TEXT::JSONB, '[]'::JSONB),
+  COALESCE(sqlc.narg('selection')::TEXT::JSONB, '{}'::JSONB),
+  COALESCE(sqlc.narg('new_database')::TEXT::JSONB, '{}'::JSONB),
+  @dry_run
+)
 RETURNING *;
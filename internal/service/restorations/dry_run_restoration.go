@@ -0,0 +1,39 @@
+package restorations
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+)
+
+// dryRunRestoration checks that the file of the execution can be restored
+// into the target with the parameters without writing anything, it returns
+// a summary of the check. Only PostgreSQL backups are supported.
+func (s *Service) dryRunRestoration(
+	ctx context.Context, execution dbgen.ExecutionsServiceGetExecutionRow,
+	connString string, params postgres.RestoreParams,
+	newDatabase postgres.NewDatabase,
+) (string, error) {
+	version, err := s.ints.PGClient.ParseVersionPG(execution.DatabaseVersion)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := s.openExecutionFile(ctx, execution, nil)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	check, err := s.ints.PGClient.CheckRestore(
+		ctx, version, connString, file,
+		encryption.TrimExtension(execution.FileExtension), params, newDatabase,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return "Dry run passed, nothing was restored: " + check.Describe(), nil
+}
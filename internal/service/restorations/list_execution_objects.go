@@ -0,0 +1,48 @@
+package restorations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/google/uuid"
+)
+
+// ListExecutionObjects returns the schemas and tables found in the file of a
+// successful execution, they can be picked for a selective restore. Only the
+// logical backups of PostgreSQL databases are supported.
+func (s *Service) ListExecutionObjects(
+	ctx context.Context, executionID uuid.UUID,
+) ([]postgres.ArchiveObject, error) {
+	execution, err := s.executionsService.GetExecution(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if execution.Status != "success" || !execution.Path.Valid {
+		return nil, fmt.Errorf("backup execution must be successful")
+	}
+	if execution.DatabaseDatabaseType != database.DatabaseTypePostgreSQL ||
+		execution.BackupMode == postgres.BackupModePhysical {
+		return nil, fmt.Errorf(
+			"only the logical backups of PostgreSQL databases can be restored selectively",
+		)
+	}
+
+	version, err := s.ints.PGClient.ParseVersionPG(execution.DatabaseVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.openExecutionFile(ctx, execution, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return s.ints.PGClient.ListArchiveObjects(
+		ctx, version, file, encryption.TrimExtension(execution.FileExtension),
+	)
+}
@@ -0,0 +1,50 @@
+package restorations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/service/jobs"
+	"github.com/google/uuid"
+)
+
+// runPreRestoreBackup backs up the target database right before a restore so
+// the restore can be rolled back by restoring the returned execution. It
+// queues a run of a logical backup task of the database, the active ones
+// first, and waits for it. The execution is pinned so the retention policy
+// of the backup task never deletes it.
+func (s *Service) runPreRestoreBackup(
+	ctx context.Context, databaseID uuid.UUID,
+) (uuid.NullUUID, error) {
+	backup, err := s.dbgen.RestorationsServiceGetPreRestoreBackup(ctx, databaseID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.NullUUID{}, fmt.Errorf(
+			"the target database has no logical backup task to take the pre-restore backup with",
+		)
+	}
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+
+	job, err := s.jobsService.EnqueueJob(
+		ctx, backup.ID, jobs.SourcePreRestore,
+		jobs.EnqueueJobParams{PinExecution: true},
+	)
+	if err != nil {
+		return uuid.NullUUID{}, fmt.Errorf("error queuing pre-restore backup: %w", err)
+	}
+
+	job, err = s.jobsService.WaitJob(ctx, job.ID)
+	if err != nil {
+		return uuid.NullUUID{}, fmt.Errorf("pre-restore backup failed: %w", err)
+	}
+	if job.Status != jobs.StatusSuccess {
+		return job.ExecutionID, fmt.Errorf(
+			"pre-restore backup with %q failed: %s", backup.Name, job.Message.String,
+		)
+	}
+
+	return job.ExecutionID, nil
+}
@@ -0,0 +1,5 @@
+-- name: RestorationsServiceGetPreRestoreBackup :one
+SELECT * FROM backups
+WHERE database_id = @database_id AND mode = 'logical'
+ORDER BY is_active DESC, created_at ASC
+LIMIT 1;
@@ -0,0 +1,51 @@
+package restorations
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/google/uuid"
+)
+
+// StartHeartbeat keeps the heartbeat of a running restoration fresh until
+// the returned function is called.
+func (s *Service) StartHeartbeat(
+	ctx context.Context, restorationID uuid.UUID,
+) func() {
+	return executions.Heartbeat(
+		ctx, executions.HeartbeatInterval, func(ctx context.Context) error {
+			return s.dbgen.RestorationsServiceTouchRestoration(ctx, restorationID)
+		},
+	)
+}
+
+// RecoverStaleRestorations marks as failed the running restorations whose
+// heartbeat stopped because the process running them died.
+func (s *Service) RecoverStaleRestorations() {
+	ids, err := s.dbgen.RestorationsServiceFailStaleRestorations(
+		context.Background(), dbgen.RestorationsServiceFailStaleRestorationsParams{
+			StaleBefore: time.Now().Add(-executions.StaleAfter),
+			Message: sql.NullString{
+				Valid: true,
+				String: "The restoration was interrupted because PG Back Web stopped " +
+					"while it was running, the target may be partially restored",
+			},
+		},
+	)
+	if err != nil {
+		logger.Error("error recovering stale restorations", logger.KV{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, id := range ids {
+		logger.Info("stale restoration marked as failed", logger.KV{
+			"restoration_id": id.String(),
+		})
+	}
+}
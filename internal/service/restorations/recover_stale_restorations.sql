@@ -0,0 +1,15 @@
+-- name: RestorationsServiceTouchRestoration :exec
+UPDATE restorations
+SET heartbeat_at = NOW()
+WHERE id = @id AND status = 'running';
+
+-- name: RestorationsServiceFailStaleRestorations :many
+UPDATE restorations
+SET
+  status = 'failed',
+  message = @message,
+  finished_at = NOW()
+WHERE
+  status = 'running'
+  AND COALESCE(heartbeat_at, started_at) < sqlc.arg('stale_before')::TIMESTAMPTZ
+RETURNING id;
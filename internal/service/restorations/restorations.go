@@ -6,6 +6,7 @@ import (
 	"github.com/eduardolat/pgbackweb/internal/service/databases"
 	"github.com/eduardolat/pgbackweb/internal/service/destinations"
 	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/service/jobs"
 )
 
 type Service struct {
@@ -14,12 +15,13 @@ type Service struct {
 	executionsService   *executions.Service
 	databasesService    *databases.Service
 	destinationsService *destinations.Service
+	jobsService         *jobs.Service
 }
 
 func New(
 	dbgen *dbgen.Queries, ints *integration.Integration,
 	executionsService *executions.Service, databasesService *databases.Service,
-	destinationsService *destinations.Service,
+	destinationsService *destinations.Service, jobsService *jobs.Service,
 ) *Service {
 	return &Service{
 		dbgen:               dbgen,
@@ -27,5 +29,6 @@ func New(
 		executionsService:   executionsService,
 		databasesService:    databasesService,
 		destinationsService: destinationsService,
+		jobsService:         jobsService,
 	}
 }
@@ -0,0 +1,71 @@
+package restorations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+)
+
+// RestoreExecution restores the file of a successful execution into the
+// database of the connection string, decrypting it as it is read if needed.
+// It doesn't create a restoration record. The progress function is called
+// while the file is read and can be nil.
+func (s *Service) RestoreExecution(
+	ctx context.Context, execution dbgen.ExecutionsServiceGetExecutionRow,
+	connString string, restoreParams database.RestoreParams,
+	progress database.ProgressFunc,
+) error {
+	dbClient, err := s.ints.GetDatabaseClient(execution.DatabaseDatabaseType)
+	if err != nil {
+		return err
+	}
+
+	// The extension of every copy only differs in the encryption suffix
+	fileExtension := encryption.TrimExtension(execution.FileExtension)
+
+	file, err := s.openExecutionFile(ctx, execution, progress)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return dbClient.RestoreZip(
+		ctx, execution.DatabaseVersion, connString, file, fileExtension,
+		restoreParams,
+	)
+}
+
+// openExecutionFile opens the file of the execution from its path or
+// download link. Encrypted files and the files of destinations without
+// download links are read from the storage and decrypted as they are read,
+// they are never copied to disk.
+func (s *Service) openExecutionFile(
+	ctx context.Context, execution dbgen.ExecutionsServiceGetExecutionRow,
+	progress database.ProgressFunc,
+) (*database.BackupFile, error) {
+	isLocal, urlOrPath, err := s.executionsService.GetExecutionDownloadLinkOrPath(
+		ctx, execution.ID,
+	)
+	if errors.Is(err, executions.ErrNoDirectLink) {
+		reader, _, err := s.executionsService.OpenExecutionFile(ctx, execution.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		// The size of the stored file is close to the decrypted one
+		size := int64(-1)
+		if execution.FileSize.Valid {
+			size = execution.FileSize.Int64
+		}
+		return database.NewStreamBackupFile(reader, size, progress), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return database.OpenBackupFile(ctx, isLocal, urlOrPath, progress)
+}
@@ -7,17 +7,45 @@ import (
 	"time"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
 	"github.com/google/uuid"
 )
 
-// RunRestoration runs a backup restoration
+type RunRestorationParams struct {
+	ExecutionID uuid.UUID
+	// DatabaseID or ConnString is the target of the restore
+	DatabaseID uuid.NullUUID
+	ConnString string
+	Hooks      hooks.Hooks
+	// Selection restores only some schemas and tables of PostgreSQL backups,
+	// an empty selection restores everything
+	Selection postgres.RestoreSelection
+	// NewDatabase restores PostgreSQL backups into a database created in the
+	// server of the target, an empty name restores into the target itself
+	NewDatabase postgres.NewDatabase
+	// DryRun only checks that the PostgreSQL backup can be restored into the
+	// target, nothing is written and the hooks don't run
+	DryRun bool
+	// PreRestoreBackup backs up the registered target database right before
+	// the restore, so a bad restore can be rolled back
+	PreRestoreBackup bool
+}
+
+// RunRestoration runs a backup restoration, the hooks run before and after
+// the restore in the target database. A new database is dropped again if
+// the restore fails, and a dry run only checks the backup and the target
 func (s *Service) RunRestoration(
-	ctx context.Context,
-	executionID uuid.UUID,
-	databaseID uuid.NullUUID,
-	connString string,
+	ctx context.Context, params RunRestorationParams,
 ) error {
+	executionID := params.ExecutionID
+	databaseID := params.DatabaseID
+	connString := params.ConnString
+	restoreHooks := params.Hooks
+
 	updateRes := func(params dbgen.RestorationsServiceUpdateRestorationParams) error {
 		_, err := s.dbgen.RestorationsServiceUpdateRestoration(
 			ctx, params,
@@ -41,11 +69,20 @@ func (s *Service) RunRestoration(
 		ExecutionID: executionID,
 		DatabaseID:  databaseID,
 		Status:      "running",
+		Hooks:       sql.NullString{Valid: true, String: string(restoreHooks.Marshal())},
+		Selection: sql.NullString{
+			Valid: true, String: string(params.Selection.Marshal()),
+		},
+		NewDatabase: sql.NullString{
+			Valid: true, String: string(params.NewDatabase.Marshal()),
+		},
+		DryRun: params.DryRun,
 	})
 	if err != nil {
 		logError(err)
 		return err
 	}
+	defer s.StartHeartbeat(ctx, res.ID)()
 
 	if !databaseID.Valid && connString == "" {
 		err := fmt.Errorf("database_id or connection_string must be provided")
@@ -80,6 +117,56 @@ func (s *Service) RunRestoration(
 		})
 	}
 
+	if execution.BackupMode == postgres.BackupModePhysical {
+		err := fmt.Errorf(
+			"base backups can't be restored into a database, use a point-in-time restore of the backup instead",
+		)
+		logError(err)
+		return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+			ID:         res.ID,
+			Status:     sql.NullString{Valid: true, String: "failed"},
+			Message:    sql.NullString{Valid: true, String: err.Error()},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+
+	if !params.Selection.IsEmpty() || !params.NewDatabase.IsEmpty() ||
+		params.DryRun {
+		err := params.Selection.Validate()
+		if err == nil && !params.NewDatabase.IsEmpty() {
+			err = params.NewDatabase.Validate()
+		}
+		if err == nil &&
+			execution.DatabaseDatabaseType != database.DatabaseTypePostgreSQL {
+			err = fmt.Errorf(
+				"only PostgreSQL backups can be restored selectively, into a new database or as a dry run",
+			)
+		}
+		if err != nil {
+			logError(err)
+			return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+				ID:         res.ID,
+				Status:     sql.NullString{Valid: true, String: "failed"},
+				Message:    sql.NullString{Valid: true, String: err.Error()},
+				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+			})
+		}
+	}
+
+	if params.PreRestoreBackup &&
+		(!databaseID.Valid || !params.NewDatabase.IsEmpty()) {
+		err := fmt.Errorf(
+			"the pre-restore backup is only taken of registered databases restored in place",
+		)
+		logError(err)
+		return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+			ID:         res.ID,
+			Status:     sql.NullString{Valid: true, String: "failed"},
+			Message:    sql.NullString{Valid: true, String: err.Error()},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+
 	if databaseID.Valid {
 		db, err := s.databasesService.GetDatabase(ctx, databaseID.UUID)
 		if err != nil {
@@ -118,11 +205,136 @@ func (s *Service) RunRestoration(
 		})
 	}
 
-	isLocal, zipURLOrPath, err := s.executionsService.GetExecutionDownloadLinkOrPath(
-		ctx, executionID,
+	// The progress of the restore is written to the log of the restoration
+	logProgress := func(read int64, size int64) {
+		s.appendLog(ctx, res.ID)("[restore] " + database.FormatProgress(read, size) + "\n")
+	}
+
+	// Create restore parameters based on database type
+	var restoreParams database.RestoreParams
+	if execution.DatabaseDatabaseType == database.DatabaseTypePostgreSQL {
+		pgParams := postgres.RestoreParams{
+			Jobs:     int(execution.BackupOptJobs),
+			Clean:    execution.BackupOptClean,
+			IfExists: execution.BackupOptIfExists,
+			Create:   execution.BackupOptCreate,
+
+			NoOwner:      execution.BackupOptNoOwner,
+			NoPrivileges: execution.BackupOptNoPrivileges,
+
+			Selection: params.Selection,
+		}
+		// The new database is empty and must not be replaced by the one of
+		// the dump
+		if !params.NewDatabase.IsEmpty() {
+			pgParams.Clean = false
+			pgParams.IfExists = false
+			pgParams.Create = false
+			pgParams.SkipDatabase = true
+			pgParams.NoOwner = pgParams.NoOwner || params.NewDatabase.NoOwner
+			pgParams.RoleMap = params.NewDatabase.RoleMap
+		}
+		restoreParams = pgParams
+	}
+
+	if params.DryRun {
+		pgParams, _ := restoreParams.(postgres.RestoreParams)
+		message, err := s.dryRunRestoration(
+			ctx, execution, connString, pgParams, params.NewDatabase,
+		)
+		if err != nil {
+			logError(err)
+			return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+				ID:         res.ID,
+				Status:     sql.NullString{Valid: true, String: "failed"},
+				Message:    sql.NullString{Valid: true, String: "Dry run failed: " + err.Error()},
+				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+			})
+		}
+		return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+			ID:         res.ID,
+			Status:     sql.NullString{Valid: true, String: "success"},
+			Message:    sql.NullString{Valid: true, String: message},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+
+	// The pre-restore backup is taken before the hooks run, so it has the
+	// database as it was before the restoration touched it
+	if params.PreRestoreBackup {
+		preRestoreID, err := s.runPreRestoreBackup(ctx, databaseID.UUID)
+		if preRestoreID.Valid {
+			_ = updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+				ID:                    res.ID,
+				PreRestoreExecutionID: preRestoreID,
+			})
+		}
+		if err != nil {
+			logError(err)
+			return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+				ID:         res.ID,
+				Status:     sql.NullString{Valid: true, String: "failed"},
+				Message:    sql.NullString{Valid: true, String: err.Error()},
+				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+			})
+		}
+	}
+
+	// The connection of the target is only used to reach its server, the
+	// hooks and the restore run in the new database
+	dropNewDatabase := func() {}
+	if !params.NewDatabase.IsEmpty() {
+		newConnString, drop, err := s.createNewDatabase(
+			ctx, execution.DatabaseVersion, connString, params.NewDatabase,
+		)
+		if err != nil {
+			logError(err)
+			return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+				ID:         res.ID,
+				Status:     sql.NullString{Valid: true, String: "failed"},
+				Message:    sql.NullString{Valid: true, String: err.Error()},
+				FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+			})
+		}
+		connString = newConnString
+		dropNewDatabase = func() {
+			if err := drop(); err != nil {
+				logError(err)
+			}
+		}
+	}
+
+	// Once the pre hooks ran, the post hooks run whatever the outcome of the
+	// restore, so they can undo what the pre hooks did
+	appendLog := s.appendLog(ctx, res.ID)
+	hooksTarget := func(stage string, status string) executions.HooksTarget {
+		return executions.HooksTarget{
+			DatabaseType: execution.DatabaseDatabaseType,
+			Version:      execution.DatabaseVersion,
+			ConnString:   connString,
+			Env: map[string]string{
+				"PBW_HOOK_STAGE":         stage,
+				"PBW_RESTORATION_ID":     res.ID.String(),
+				"PBW_EXECUTION_ID":       executionID.String(),
+				"PBW_RESTORATION_STATUS": status,
+			},
+		}
+	}
+	runPostHooks := func(status string) error {
+		return s.executionsService.RunHooks(
+			ctx, restoreHooks.Stage(hooks.StagePost),
+			hooksTarget(hooks.StagePost, status), appendLog,
+		)
+	}
+
+	err = s.executionsService.RunHooks(
+		ctx, restoreHooks.Stage(hooks.StagePre),
+		hooksTarget(hooks.StagePre, ""), appendLog,
 	)
 	if err != nil {
 		logError(err)
+		_ = runPostHooks("failed")
+		dropNewDatabase()
 		return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
 			ID:         res.ID,
 			Status:     sql.NullString{Valid: true, String: "failed"},
@@ -131,9 +343,13 @@ func (s *Service) RunRestoration(
 		})
 	}
 
-	err = dbClient.RestoreZip(execution.DatabaseVersion, connString, isLocal, zipURLOrPath)
+	err = s.RestoreExecution(
+		ctx, execution, connString, restoreParams, logProgress,
+	)
 	if err != nil {
 		logError(err)
+		_ = runPostHooks("failed")
+		dropNewDatabase()
 		return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
 			ID:         res.ID,
 			Status:     sql.NullString{Valid: true, String: "failed"},
@@ -142,6 +358,19 @@ func (s *Service) RunRestoration(
 		})
 	}
 
+	// A post hook that fails with the abort policy fails the restoration
+	if err := runPostHooks("success"); err != nil {
+		logError(err)
+		return updateRes(dbgen.RestorationsServiceUpdateRestorationParams{
+			ID:     res.ID,
+			Status: sql.NullString{Valid: true, String: "failed"},
+			Message: sql.NullString{
+				Valid: true, String: "Backup restored successfully, but " + err.Error(),
+			},
+			FinishedAt: sql.NullTime{Valid: true, Time: time.Now()},
+		})
+	}
+
 	logger.Info("backup restored successfully", logger.KV{
 		"restoration_id": res.ID.String(),
 		"execution_id":   executionID.String(),
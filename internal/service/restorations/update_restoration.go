@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/google/uuid"
 )
 
 func (s *Service) UpdateRestoration(
@@ -11,3 +13,21 @@ func (s *Service) UpdateRestoration(
 ) (dbgen.Restoration, error) {
 	return s.dbgen.RestorationsServiceUpdateRestoration(ctx, params)
 }
+
+// appendLog appends an entry to the log of a restoration.
+func (s *Service) appendLog(ctx context.Context, restorationID uuid.UUID) func(string) {
+	return func(entry string) {
+		err := s.dbgen.RestorationsServiceAppendRestorationLog(
+			ctx, dbgen.RestorationsServiceAppendRestorationLogParams{
+				ID:    restorationID,
+				Entry: entry,
+			},
+		)
+		if err != nil {
+			logger.Error("error appending to the restoration log", logger.KV{
+				"restoration_id": restorationID.String(),
+				"error":          err.Error(),
+			})
+		}
+	}
+}
@@ -3,6 +3,14 @@ UPDATE restorations
 SET
   status = COALESCE(sqlc.narg('status'), status),
   message = COALESCE(sqlc.narg('message'), message),
+  pre_restore_execution_id = COALESCE(
+    sqlc.narg('pre_restore_execution_id'), pre_restore_execution_id
+  ),
   finished_at = COALESCE(sqlc.narg('finished_at'), finished_at)
 WHERE id = @id
 RETURNING *;
+
+-- name: RestorationsServiceAppendRestorationLog :exec
+UPDATE restorations
+SET log = COALESCE(log, '') || @entry::TEXT
+WHERE id = @id;
@@ -0,0 +1,25 @@
+package restorations
+
+import (
+	"errors"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
+)
+
+// ErrRestoreHooksNotAllowed is returned when a user other than an admin
+// attaches hooks to a restoration.
+var ErrRestoreHooksNotAllowed = errors.New(
+	"only admins can attach hooks to a restoration",
+)
+
+// ValidateRestoreHooks checks that a user with the given role is allowed to
+// attach the hooks to a restoration. The SQL and command hooks run with the
+// privileges of the server, only admins can attach them.
+func (s *Service) ValidateRestoreHooks(role string, h hooks.Hooks) error {
+	if len(h) > 0 && role != users.RoleAdmin {
+		return ErrRestoreHooksNotAllowed
+	}
+
+	return s.executionsService.ValidateHooks(h)
+}
@@ -0,0 +1,57 @@
+package restorations
+
+import (
+	"testing"
+
+	"github.com/eduardolat/pgbackweb/internal/config"
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRestoreHooks(t *testing.T) {
+	s := &Service{
+		executionsService: executions.New(
+			config.Env{PBW_ENABLE_COMMAND_HOOKS: true}, nil, nil, nil,
+		),
+	}
+
+	commandHook := hooks.Hooks{{
+		Stage:     hooks.StagePre,
+		Type:      hooks.TypeCommand,
+		Script:    "echo pre",
+		OnFailure: hooks.OnFailureAbort,
+	}}
+	sqlHook := hooks.Hooks{{
+		Stage:     hooks.StagePost,
+		Type:      hooks.TypeSQL,
+		Script:    "SELECT 1;",
+		OnFailure: hooks.OnFailureAbort,
+	}}
+
+	tests := []struct {
+		name    string
+		role    string
+		hooks   hooks.Hooks
+		wantErr error
+	}{
+		{"admin with command hook", users.RoleAdmin, commandHook, nil},
+		{"admin with SQL hook", users.RoleAdmin, sqlHook, nil},
+		{"operator with command hook", users.RoleOperator, commandHook, ErrRestoreHooksNotAllowed},
+		{"operator with SQL hook", users.RoleOperator, sqlHook, ErrRestoreHooksNotAllowed},
+		{"operator without hooks", users.RoleOperator, nil, nil},
+		{"viewer with command hook", users.RoleViewer, commandHook, ErrRestoreHooksNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateRestoreHooks(tt.role, tt.hooks)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package restorations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/service/users"
+	"github.com/google/uuid"
+)
+
+// ValidateRestoreTarget checks that a user with the given role is allowed
+// to restore into the given target. Admins can restore anywhere, operators
+// can only restore into registered databases that are not flagged as
+// production, and viewers can't restore at all.
+func (s *Service) ValidateRestoreTarget(
+	ctx context.Context, role string, databaseID uuid.NullUUID,
+) error {
+	switch role {
+	case users.RoleAdmin:
+		return nil
+	case users.RoleOperator:
+	default:
+		return fmt.Errorf("your role is not allowed to restore backups")
+	}
+
+	if !databaseID.Valid {
+		return fmt.Errorf("operators can only restore into registered databases")
+	}
+
+	db, err := s.databasesService.GetDatabase(ctx, databaseID.UUID)
+	if err != nil {
+		return err
+	}
+
+	if db.IsProduction {
+		return fmt.Errorf("operators can't restore into production databases")
+	}
+
+	return nil
+}
@@ -9,7 +9,10 @@ import (
 	"github.com/eduardolat/pgbackweb/internal/service/backups"
 	"github.com/eduardolat/pgbackweb/internal/service/databases"
 	"github.com/eduardolat/pgbackweb/internal/service/destinations"
+	"github.com/eduardolat/pgbackweb/internal/service/drills"
 	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/service/jobs"
+	"github.com/eduardolat/pgbackweb/internal/service/pitr"
 	"github.com/eduardolat/pgbackweb/internal/service/restorations"
 	"github.com/eduardolat/pgbackweb/internal/service/users"
 	"github.com/eduardolat/pgbackweb/internal/service/webhooks"
@@ -20,7 +23,10 @@ type Service struct {
 	BackupsService      *backups.Service
 	DatabasesService    *databases.Service
 	DestinationsService *destinations.Service
+	DrillsService       *drills.Service
 	ExecutionsService   *executions.Service
+	JobsService         *jobs.Service
+	PITRService         *pitr.Service
 	UsersService        *users.Service
 	RestorationsService *restorations.Service
 	WebhooksService     *webhooks.Service
@@ -36,9 +42,18 @@ func New(
 	destinationsService := destinations.New(env, dbgen, ints, webhooksService)
 	executionsService := executions.New(env, dbgen, ints, webhooksService)
 	usersService := users.New(dbgen)
-	backupsService := backups.New(dbgen, cr, executionsService)
+	jobsService := jobs.New(env, dbgen, executionsService)
+	backupsService := backups.New(dbgen, cr, executionsService, jobsService)
 	restorationsService := restorations.New(
 		dbgen, ints, executionsService, databasesService, destinationsService,
+		jobsService,
+	)
+	drillsService := drills.New(
+		dbgen, cr, ints, executionsService, databasesService, restorationsService,
+		webhooksService,
+	)
+	pitrService := pitr.New(
+		env, dbgen, ints, executionsService, restorationsService,
 	)
 
 	return &Service{
@@ -46,7 +61,10 @@ func New(
 		BackupsService:      backupsService,
 		DatabasesService:    databasesService,
 		DestinationsService: destinationsService,
+		DrillsService:       drillsService,
 		ExecutionsService:   executionsService,
+		JobsService:         jobsService,
+		PITRService:         pitrService,
 		UsersService:        usersService,
 		RestorationsService: restorationsService,
 		WebhooksService:     webhooksService,
@@ -2,6 +2,7 @@ package users
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/eduardolat/pgbackweb/internal/util/cryptoutil"
@@ -10,6 +11,10 @@ import (
 func (s *Service) CreateUser(
 	ctx context.Context, params dbgen.UsersServiceCreateUserParams,
 ) (dbgen.User, error) {
+	if _, ok := Roles[params.Role]; !ok {
+		return dbgen.User{}, fmt.Errorf("invalid role %q", params.Role)
+	}
+
 	hash, err := cryptoutil.CreateBcryptHash(params.Password)
 	if err != nil {
 		return dbgen.User{}, err
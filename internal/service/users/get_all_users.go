@@ -0,0 +1,11 @@
+package users
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+)
+
+func (s *Service) GetAllUsers(ctx context.Context) ([]dbgen.User, error) {
+	return s.dbgen.UsersServiceGetAllUsers(ctx)
+}
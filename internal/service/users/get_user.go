@@ -0,0 +1,12 @@
+package users
+
+import (
+	"context"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/google/uuid"
+)
+
+func (s *Service) GetUser(ctx context.Context, id uuid.UUID) (dbgen.User, error) {
+	return s.dbgen.UsersServiceGetUser(ctx, id)
+}
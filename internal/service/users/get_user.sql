@@ -0,0 +1,2 @@
+-- name: UsersServiceGetUser :one
+SELECT * FROM users WHERE id = @id;
@@ -0,0 +1,19 @@
+package users
+
+const (
+	// RoleAdmin can do everything, including managing databases,
+	// destinations, backups, webhooks and users.
+	RoleAdmin = "admin"
+	// RoleOperator can run backups, download them and restore them into
+	// databases that are not flagged as production.
+	RoleOperator = "operator"
+	// RoleViewer can only look at the dashboard.
+	RoleViewer = "viewer"
+)
+
+// Roles maps every role to its human readable name.
+var Roles = map[string]string{
+	RoleAdmin:    "Admin",
+	RoleOperator: "Operator",
+	RoleViewer:   "Viewer",
+}
@@ -2,6 +2,7 @@ package users
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/eduardolat/pgbackweb/internal/util/cryptoutil"
@@ -10,6 +11,10 @@ import (
 func (s *Service) UpdateUser(
 	ctx context.Context, params dbgen.UsersServiceUpdateUserParams,
 ) (dbgen.User, error) {
+	if _, ok := Roles[params.Role.String]; params.Role.Valid && !ok {
+		return dbgen.User{}, fmt.Errorf("invalid role %q", params.Role.String)
+	}
+
 	if params.Password.Valid {
 		hashedPassword, err := cryptoutil.CreateBcryptHash(params.Password.String)
 		if err != nil {
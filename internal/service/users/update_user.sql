@@ -3,6 +3,8 @@ UPDATE users
 SET
   name = COALESCE(sqlc.narg('name'), name),
   email = lower(COALESCE(sqlc.narg('email'), email)),
-  password = COALESCE(sqlc.narg('password'), password)
+  password = COALESCE(sqlc.narg('password'), password),
+  role = COALESCE(sqlc.narg('role'), role),
+  is_active = COALESCE(sqlc.narg('is_active'), is_active)
 WHERE id = @id
 RETURNING *;
@@ -2,6 +2,9 @@ package webhooks
 
 import (
 	"context"
+	"database/sql"
+	"strconv"
+	"strings"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/eduardolat/pgbackweb/internal/logger"
@@ -13,7 +16,7 @@ import (
 func (s *Service) RunDatabaseHealthy(databaseID uuid.UUID) {
 	go func() {
 		ctx := context.Background()
-		runWebhook(s, ctx, EventTypeDatabaseHealthy, databaseID)
+		runWebhook(s, ctx, EventTypeDatabaseHealthy, databaseID, nil)
 	}()
 }
 
@@ -21,7 +24,7 @@ func (s *Service) RunDatabaseHealthy(databaseID uuid.UUID) {
 func (s *Service) RunDatabaseUnhealthy(databaseID uuid.UUID) {
 	go func() {
 		ctx := context.Background()
-		runWebhook(s, ctx, EventTypeDatabaseUnhealthy, databaseID)
+		runWebhook(s, ctx, EventTypeDatabaseUnhealthy, databaseID, nil)
 	}()
 }
 
@@ -29,7 +32,7 @@ func (s *Service) RunDatabaseUnhealthy(databaseID uuid.UUID) {
 func (s *Service) RunDestinationHealthy(destinationID uuid.UUID) {
 	go func() {
 		ctx := context.Background()
-		runWebhook(s, ctx, EventTypeDestinationHealthy, destinationID)
+		runWebhook(s, ctx, EventTypeDestinationHealthy, destinationID, nil)
 	}()
 }
 
@@ -38,29 +41,75 @@ func (s *Service) RunDestinationHealthy(destinationID uuid.UUID) {
 func (s *Service) RunDestinationUnhealthy(destinationID uuid.UUID) {
 	go func() {
 		ctx := context.Background()
-		runWebhook(s, ctx, EventTypeDestinationUnhealthy, destinationID)
+		runWebhook(s, ctx, EventTypeDestinationUnhealthy, destinationID, nil)
 	}()
 }
 
-// RunExecutionSuccess runs the success webhooks for the given execution ID.
-func (s *Service) RunExecutionSuccess(backupID uuid.UUID) {
+// ExecutionDetails are the values of an execution that can be used in the
+// body of the execution webhooks through the {{name}} placeholders.
+type ExecutionDetails struct {
+	ExecutionID uuid.UUID
+	Attempts    int32
+	MaxAttempts int32
+}
+
+func (d ExecutionDetails) vars() map[string]string {
+	return map[string]string{
+		"execution_id": d.ExecutionID.String(),
+		"attempts":     strconv.Itoa(int(d.Attempts)),
+		"max_attempts": strconv.Itoa(int(d.MaxAttempts)),
+	}
+}
+
+// RunExecutionSuccess runs the success webhooks for the given backup ID.
+func (s *Service) RunExecutionSuccess(backupID uuid.UUID, details ExecutionDetails) {
+	go func() {
+		ctx := context.Background()
+		runWebhook(s, ctx, EventTypeExecutionSuccess, backupID, details.vars())
+	}()
+}
+
+// RunExecutionFailed runs the failed webhooks for the given backup ID, it's
+// called once the last attempt of the execution fails.
+func (s *Service) RunExecutionFailed(backupID uuid.UUID, details ExecutionDetails) {
+	go func() {
+		ctx := context.Background()
+		runWebhook(s, ctx, EventTypeExecutionFailed, backupID, details.vars())
+	}()
+}
+
+// RunExecutionIntegrityFailed runs the integrity failed webhooks for the given
+// backup ID.
+func (s *Service) RunExecutionIntegrityFailed(backupID uuid.UUID) {
+	go func() {
+		ctx := context.Background()
+		runWebhook(s, ctx, EventTypeExecutionIntegrityFailed, backupID, nil)
+	}()
+}
+
+// RunRestoreDrillSuccess runs the restore drill success webhooks for the
+// given backup ID.
+func (s *Service) RunRestoreDrillSuccess(backupID uuid.UUID) {
 	go func() {
 		ctx := context.Background()
-		runWebhook(s, ctx, EventTypeExecutionSuccess, backupID)
+		runWebhook(s, ctx, EventTypeRestoreDrillSuccess, backupID, nil)
 	}()
 }
 
-// RunExecutionFailed runs the failed webhooks for the given execution ID.
-func (s *Service) RunExecutionFailed(backupID uuid.UUID) {
+// RunRestoreDrillFailed runs the restore drill failed webhooks for the given
+// backup ID.
+func (s *Service) RunRestoreDrillFailed(backupID uuid.UUID) {
 	go func() {
 		ctx := context.Background()
-		runWebhook(s, ctx, EventTypeExecutionFailed, backupID)
+		runWebhook(s, ctx, EventTypeRestoreDrillFailed, backupID, nil)
 	}()
 }
 
-// runWebhook runs the webhooks for the given event type and target ID.
+// runWebhook runs the webhooks for the given event type and target ID, the
+// vars replace their placeholders in the body of the webhooks.
 func runWebhook(
 	s *Service, ctx context.Context, eventType eventType, targetID uuid.UUID,
+	vars map[string]string,
 ) {
 	webhooks, err := s.dbgen.WebhooksServiceGetWebhooksToRun(
 		ctx, dbgen.WebhooksServiceGetWebhooksToRunParams{
@@ -81,6 +130,11 @@ func runWebhook(
 
 	for _, webhook := range webhooks {
 		eg.Go(func() error {
+			if webhook.Body.Valid {
+				webhook.Body = sql.NullString{
+					Valid: true, String: renderBody(webhook.Body.String, vars),
+				}
+			}
 			err := s.SendWebhookRequest(ctx, webhook)
 			if err != nil {
 				logger.Error("error sending webhook request", logger.KV{
@@ -94,3 +148,17 @@ func runWebhook(
 
 	_ = eg.Wait()
 }
+
+// renderBody replaces the {{name}} placeholders of the body with the vars,
+// the placeholders without a value are left as they are.
+func renderBody(body string, vars map[string]string) string {
+	if len(vars) == 0 {
+		return body
+	}
+
+	oldnew := make([]string, 0, len(vars)*2)
+	for name, value := range vars {
+		oldnew = append(oldnew, "{{"+name+"}}", value)
+	}
+	return strings.NewReplacer(oldnew...).Replace(body)
+}
@@ -0,0 +1,47 @@
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderBody(t *testing.T) {
+	executionID := uuid.MustParse("6a1f2d9e-8c3b-4e5f-9a7d-1b2c3d4e5f60")
+	vars := ExecutionDetails{
+		ExecutionID: executionID, Attempts: 3, MaxAttempts: 3,
+	}.vars()
+
+	tests := []struct {
+		name string
+		body string
+		vars map[string]string
+		want string
+	}{
+		{
+			name: "without vars",
+			body: `{"text": "failed after {{attempts}} attempts"}`,
+			vars: nil,
+			want: `{"text": "failed after {{attempts}} attempts"}`,
+		},
+		{
+			name: "replaces every placeholder",
+			body: `{"text": "{{attempts}}/{{max_attempts}}", "id": "{{execution_id}}"}`,
+			vars: vars,
+			want: `{"text": "3/3", "id": "` + executionID.String() + `"}`,
+		},
+		{
+			name: "keeps unknown placeholders",
+			body: `{"text": "{{unknown}} {{attempts}}"}`,
+			vars: vars,
+			want: `{"text": "{{unknown}} 3"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderBody(tt.body, tt.vars))
+		})
+	}
+}
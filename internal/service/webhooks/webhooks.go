@@ -34,15 +34,30 @@ var (
 	EventTypeExecutionFailed = eventType{
 		Value: eventTypeData{Key: "execution_failed", Name: "Execution failed"},
 	}
+	EventTypeExecutionIntegrityFailed = eventType{
+		Value: eventTypeData{
+			Key: "execution_integrity_failed", Name: "Execution integrity check failed",
+		},
+	}
+
+	EventTypeRestoreDrillSuccess = eventType{
+		Value: eventTypeData{Key: "restore_drill_success", Name: "Restore drill success"},
+	}
+	EventTypeRestoreDrillFailed = eventType{
+		Value: eventTypeData{Key: "restore_drill_failed", Name: "Restore drill failed"},
+	}
 )
 
 var FullEventTypes = map[string]string{
-	EventTypeDatabaseHealthy.Value.Key:      EventTypeDatabaseHealthy.Value.Name,
-	EventTypeDatabaseUnhealthy.Value.Key:    EventTypeDatabaseUnhealthy.Value.Name,
-	EventTypeDestinationHealthy.Value.Key:   EventTypeDestinationHealthy.Value.Name,
-	EventTypeDestinationUnhealthy.Value.Key: EventTypeDestinationUnhealthy.Value.Name,
-	EventTypeExecutionSuccess.Value.Key:     EventTypeExecutionSuccess.Value.Name,
-	EventTypeExecutionFailed.Value.Key:      EventTypeExecutionFailed.Value.Name,
+	EventTypeDatabaseHealthy.Value.Key:          EventTypeDatabaseHealthy.Value.Name,
+	EventTypeDatabaseUnhealthy.Value.Key:        EventTypeDatabaseUnhealthy.Value.Name,
+	EventTypeDestinationHealthy.Value.Key:       EventTypeDestinationHealthy.Value.Name,
+	EventTypeDestinationUnhealthy.Value.Key:     EventTypeDestinationUnhealthy.Value.Name,
+	EventTypeExecutionSuccess.Value.Key:         EventTypeExecutionSuccess.Value.Name,
+	EventTypeExecutionFailed.Value.Key:          EventTypeExecutionFailed.Value.Name,
+	EventTypeExecutionIntegrityFailed.Value.Key: EventTypeExecutionIntegrityFailed.Value.Name,
+	EventTypeRestoreDrillSuccess.Value.Key:      EventTypeRestoreDrillSuccess.Value.Name,
+	EventTypeRestoreDrillFailed.Value.Key:       EventTypeRestoreDrillFailed.Value.Name,
 }
 
 type Service struct {
@@ -24,6 +24,9 @@ func TestGetContentTypeFromFileName(t *testing.T) {
 		{"pagina.html", "text/html"},
 		{"archivo.zip", "application/zip"},
 		{"archivo.sql", "application/sql"},
+		{"archivo.sql.zst", "application/zstd"},
+		{"archivo.dump.gz", "application/gzip"},
+		{"archivo.dir.tar", "application/x-tar"},
 		{"archivo.desconocido", "application/octet-stream"}, // unknown extension
 		{"MAYUSCULAS.JPG", "image/jpeg"},                    // upper case
 		{"MezclaDeMayusculasYMinusculas.PnG", "image/png"},  // mixed case
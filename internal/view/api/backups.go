@@ -0,0 +1,615 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/backups"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/service/jobs"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type backupResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	DatabaseID     uuid.UUID       `json:"database_id"`
+	DestinationID  *uuid.UUID      `json:"destination_id"`
+	IsLocal        bool            `json:"is_local"`
+	Mode           string          `json:"mode"`
+	Name           string          `json:"name"`
+	CronExpression string          `json:"cron_expression"`
+	TimeZone       string          `json:"time_zone"`
+	IsActive       bool            `json:"is_active"`
+	DestDir        string          `json:"dest_dir"`
+	RetentionDays  int16           `json:"retention_days"`
+	KeepLast       int16           `json:"retention_keep_last"`
+	KeepDaily      int16           `json:"retention_keep_daily"`
+	KeepWeekly     int16           `json:"retention_keep_weekly"`
+	KeepMonthly    int16           `json:"retention_keep_monthly"`
+	KeepYearly     int16           `json:"retention_keep_yearly"`
+	MinKeep        int16           `json:"retention_min_keep"`
+	OptDataOnly    bool            `json:"opt_data_only"`
+	OptSchemaOnly  bool            `json:"opt_schema_only"`
+	OptClean       bool            `json:"opt_clean"`
+	OptIfExists    bool            `json:"opt_if_exists"`
+	OptCreate      bool            `json:"opt_create"`
+	OptNoComments  bool            `json:"opt_no_comments"`
+	OptFormat      string          `json:"opt_format"`
+	OptJobs        int16           `json:"opt_jobs"`
+	OptSchemas     []string        `json:"opt_schemas"`
+	OptExclSchemas []string        `json:"opt_exclude_schemas"`
+	OptTables      []string        `json:"opt_tables"`
+	OptExclTables  []string        `json:"opt_exclude_tables"`
+	OptExclData    []string        `json:"opt_exclude_table_data"`
+	OptNoOwner     bool            `json:"opt_no_owner"`
+	OptNoPrivs     bool            `json:"opt_no_privileges"`
+	OptRole        string          `json:"opt_role"`
+	Compression    string          `json:"compression"`
+	CompLevel      int16           `json:"compression_level"`
+	MaxDuration    int32           `json:"max_duration_minutes"`
+	Priority       int16           `json:"priority"`
+	RetryAttempts  *int16          `json:"retry_max_attempts"`
+	RetryBackoff   *int32          `json:"retry_backoff_seconds"`
+	Hooks          json.RawMessage `json:"hooks"`
+	Replicas       []string        `json:"replicas"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      *time.Time      `json:"updated_at"`
+}
+
+// backupUpdateRequest holds the fields that can be changed on an existing
+// backup, the database and destination are fixed once the backup exists.
+// The retention_keep_*, retention_min_keep, max_duration_minutes, priority,
+// retry_*, hooks and the object selection opt_* fields and the replicas are
+// left unchanged when they are omitted. A retry_* field of 0 uses the server
+// default, PBW_BACKUP_MAX_ATTEMPTS or PBW_BACKUP_RETRY_BACKOFF.
+type backupUpdateRequest struct {
+	Name           string       `json:"name" validate:"required"`
+	CronExpression string       `json:"cron_expression" validate:"required"`
+	TimeZone       string       `json:"time_zone" validate:"required"`
+	IsActive       bool         `json:"is_active"`
+	DestDir        string       `json:"dest_dir" validate:"required"`
+	RetentionDays  int16        `json:"retention_days" validate:"min=0"`
+	KeepLast       *int16       `json:"retention_keep_last" validate:"omitempty,min=0"`
+	KeepDaily      *int16       `json:"retention_keep_daily" validate:"omitempty,min=0"`
+	KeepWeekly     *int16       `json:"retention_keep_weekly" validate:"omitempty,min=0"`
+	KeepMonthly    *int16       `json:"retention_keep_monthly" validate:"omitempty,min=0"`
+	KeepYearly     *int16       `json:"retention_keep_yearly" validate:"omitempty,min=0"`
+	MinKeep        *int16       `json:"retention_min_keep" validate:"omitempty,min=0"`
+	OptDataOnly    bool         `json:"opt_data_only"`
+	OptSchemaOnly  bool         `json:"opt_schema_only"`
+	OptClean       bool         `json:"opt_clean"`
+	OptIfExists    bool         `json:"opt_if_exists"`
+	OptCreate      bool         `json:"opt_create"`
+	OptNoComments  bool         `json:"opt_no_comments"`
+	OptFormat      string       `json:"opt_format"`
+	OptJobs        int16        `json:"opt_jobs" validate:"min=0"`
+	OptSchemas     *[]string    `json:"opt_schemas"`
+	OptExclSchemas *[]string    `json:"opt_exclude_schemas"`
+	OptTables      *[]string    `json:"opt_tables"`
+	OptExclTables  *[]string    `json:"opt_exclude_tables"`
+	OptExclData    *[]string    `json:"opt_exclude_table_data"`
+	OptNoOwner     *bool        `json:"opt_no_owner"`
+	OptNoPrivs     *bool        `json:"opt_no_privileges"`
+	OptRole        *string      `json:"opt_role"`
+	Compression    string       `json:"compression"`
+	CompLevel      int16        `json:"compression_level" validate:"min=0"`
+	MaxDuration    *int32       `json:"max_duration_minutes" validate:"omitempty,min=0"`
+	Priority       *int16       `json:"priority" validate:"omitempty,min=0,max=100"`
+	RetryAttempts  *int16       `json:"retry_max_attempts" validate:"omitempty,min=0,max=10"`
+	RetryBackoff   *int32       `json:"retry_backoff_seconds" validate:"omitempty,min=0,max=86400"`
+	Hooks          *hooks.Hooks `json:"hooks"`
+	Replicas       *[]string    `json:"replicas"`
+}
+
+// setDefaults fills the options that older clients don't send when creating
+// a backup, so they keep creating plain SQL dumps inside ZIP files.
+func (r *backupUpdateRequest) setDefaults() {
+	if r.OptFormat == "" {
+		r.OptFormat = postgres.DumpFormatPlain
+	}
+	if r.OptJobs == 0 {
+		r.OptJobs = 1
+	}
+	if r.Compression == "" {
+		r.Compression = compression.CodecZip
+	}
+	if r.MinKeep == nil {
+		minKeep := int16(1)
+		r.MinKeep = &minKeep
+	}
+}
+
+// backupCreateRequest holds the fields of a new backup. The mode can't be
+// changed once the backup exists, it defaults to logical.
+type backupCreateRequest struct {
+	DatabaseID    uuid.UUID `json:"database_id" validate:"required"`
+	DestinationID uuid.UUID `json:"destination_id"`
+	IsLocal       bool      `json:"is_local"`
+	Mode          string    `json:"mode"`
+	backupUpdateRequest
+}
+
+// hooksValue returns the hooks of a request, no hooks when they are omitted.
+func (r *backupUpdateRequest) hooksValue() hooks.Hooks {
+	if r.Hooks == nil {
+		return hooks.Hooks{}
+	}
+	return *r.Hooks
+}
+
+// nullHooks returns the hooks of a request to be stored, not valid when they
+// are omitted.
+func (r *backupUpdateRequest) nullHooks() sql.NullString {
+	if r.Hooks == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{Valid: true, String: string(r.Hooks.Marshal())}
+}
+
+// parseReplicas parses the replicas of a request, nil when they are omitted.
+func (r *backupUpdateRequest) parseReplicas() ([]backups.Replica, error) {
+	if r.Replicas == nil {
+		return nil, nil
+	}
+	return backups.ParseReplicas(*r.Replicas)
+}
+
+func newBackupResponse(
+	backup dbgen.Backup, replicas []dbgen.BackupReplica,
+) backupResponse {
+	return backupResponse{
+		ID:             backup.ID,
+		DatabaseID:     backup.DatabaseID,
+		DestinationID:  nullUUID(backup.DestinationID),
+		IsLocal:        backup.IsLocal,
+		Mode:           backup.Mode,
+		Name:           backup.Name,
+		CronExpression: backup.CronExpression,
+		TimeZone:       backup.TimeZone,
+		IsActive:       backup.IsActive,
+		DestDir:        backup.DestDir,
+		RetentionDays:  backup.RetentionDays,
+		KeepLast:       backup.RetentionKeepLast,
+		KeepDaily:      backup.RetentionKeepDaily,
+		KeepWeekly:     backup.RetentionKeepWeekly,
+		KeepMonthly:    backup.RetentionKeepMonthly,
+		KeepYearly:     backup.RetentionKeepYearly,
+		MinKeep:        backup.RetentionMinKeep,
+		OptDataOnly:    backup.OptDataOnly,
+		OptSchemaOnly:  backup.OptSchemaOnly,
+		OptClean:       backup.OptClean,
+		OptIfExists:    backup.OptIfExists,
+		OptCreate:      backup.OptCreate,
+		OptNoComments:  backup.OptNoComments,
+		OptFormat:      backup.OptFormat,
+		OptJobs:        backup.OptJobs,
+		OptSchemas:     backup.OptSchemas,
+		OptExclSchemas: backup.OptExcludeSchemas,
+		OptTables:      backup.OptTables,
+		OptExclTables:  backup.OptExcludeTables,
+		OptExclData:    backup.OptExcludeTableData,
+		OptNoOwner:     backup.OptNoOwner,
+		OptNoPrivs:     backup.OptNoPrivileges,
+		OptRole:        backup.OptRole,
+		Compression:    backup.Compression,
+		CompLevel:      backup.CompressionLevel,
+		MaxDuration:    backup.MaxDurationMinutes,
+		Priority:       backup.Priority,
+		RetryAttempts:  nullInt16(backup.RetryMaxAttempts),
+		RetryBackoff:   nullInt32(backup.RetryBackoffSeconds),
+		Hooks:          backup.Hooks,
+		Replicas:       backups.ReplicaValues(replicas),
+		CreatedAt:      backup.CreatedAt,
+		UpdatedAt:      nullTime(backup.UpdatedAt),
+	}
+}
+
+func (h *handlers) listBackupsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryData paginationQuery
+	if err := c.Bind(&queryData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	pagination, backs, err := h.servs.BackupsService.PaginateBackups(
+		ctx, backups.PaginateBackupsParams{
+			Page:  queryData.Page,
+			Limit: queryData.Limit,
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	type item struct {
+		backupResponse
+		DatabaseName    string  `json:"database_name"`
+		DestinationName *string `json:"destination_name"`
+	}
+
+	items := make([]item, 0, len(backs))
+	for _, back := range backs {
+		replicas, err := h.servs.BackupsService.GetBackupReplicas(ctx, back.ID)
+		if err != nil {
+			return respondError(c, http.StatusInternalServerError, err)
+		}
+
+		items = append(items, item{
+			backupResponse: newBackupResponse(dbgen.Backup{
+				ID:                   back.ID,
+				DatabaseID:           back.DatabaseID,
+				DestinationID:        back.DestinationID,
+				Name:                 back.Name,
+				CronExpression:       back.CronExpression,
+				TimeZone:             back.TimeZone,
+				IsActive:             back.IsActive,
+				DestDir:              back.DestDir,
+				RetentionDays:        back.RetentionDays,
+				RetentionKeepLast:    back.RetentionKeepLast,
+				RetentionKeepDaily:   back.RetentionKeepDaily,
+				RetentionKeepWeekly:  back.RetentionKeepWeekly,
+				RetentionKeepMonthly: back.RetentionKeepMonthly,
+				RetentionKeepYearly:  back.RetentionKeepYearly,
+				RetentionMinKeep:     back.RetentionMinKeep,
+				OptDataOnly:          back.OptDataOnly,
+				OptSchemaOnly:        back.OptSchemaOnly,
+				OptClean:             back.OptClean,
+				OptIfExists:          back.OptIfExists,
+				OptCreate:            back.OptCreate,
+				OptNoComments:        back.OptNoComments,
+				OptFormat:            back.OptFormat,
+				OptJobs:              back.OptJobs,
+				Compression:          back.Compression,
+				CompressionLevel:     back.CompressionLevel,
+				CreatedAt:            back.CreatedAt,
+				UpdatedAt:            back.UpdatedAt,
+				IsLocal:              back.IsLocal,
+				Mode:                 back.Mode,
+				MaxDurationMinutes:   back.MaxDurationMinutes,
+				Priority:             back.Priority,
+				RetryMaxAttempts:     back.RetryMaxAttempts,
+				RetryBackoffSeconds:  back.RetryBackoffSeconds,
+				Hooks:                back.Hooks,
+				OptSchemas:           back.OptSchemas,
+				OptExcludeSchemas:    back.OptExcludeSchemas,
+				OptTables:            back.OptTables,
+				OptExcludeTables:     back.OptExcludeTables,
+				OptExcludeTableData:  back.OptExcludeTableData,
+				OptNoOwner:           back.OptNoOwner,
+				OptNoPrivileges:      back.OptNoPrivileges,
+				OptRole:              back.OptRole,
+			}, replicas),
+			DatabaseName:    back.DatabaseName,
+			DestinationName: nullString(back.DestinationName),
+		})
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Pagination paginateutil.PaginateResponse `json:"pagination"`
+		Items      []item                        `json:"items"`
+	}{pagination, items})
+}
+
+func (h *handlers) getBackupHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	backup, err := h.servs.BackupsService.GetBackup(ctx, backupID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	replicas, err := h.servs.BackupsService.GetBackupReplicas(ctx, backupID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, newBackupResponse(backup, replicas))
+}
+
+func (h *handlers) createBackupHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData backupCreateRequest
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if err := validate.Struct(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	reqData.setDefaults()
+	if reqData.Mode == "" {
+		reqData.Mode = postgres.BackupModeLogical
+	}
+
+	if !reqData.IsLocal && reqData.DestinationID == uuid.Nil {
+		return respondMessage(
+			c, http.StatusBadRequest, "destination_id is required when is_local is false",
+		)
+	}
+
+	destinationID := uuid.NullUUID{
+		Valid: !reqData.IsLocal, UUID: reqData.DestinationID,
+	}
+	replicas, err := reqData.parseReplicas()
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	err = backups.ValidateReplicas(reqData.IsLocal, destinationID, replicas)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	backup, err := h.servs.BackupsService.CreateBackup(
+		ctx, dbgen.BackupsServiceCreateBackupParams{
+			DatabaseID:           reqData.DatabaseID,
+			DestinationID:        destinationID,
+			IsLocal:              reqData.IsLocal,
+			Name:                 reqData.Name,
+			CronExpression:       reqData.CronExpression,
+			TimeZone:             reqData.TimeZone,
+			IsActive:             reqData.IsActive,
+			DestDir:              reqData.DestDir,
+			RetentionDays:        reqData.RetentionDays,
+			RetentionKeepLast:    int16Value(reqData.KeepLast),
+			RetentionKeepDaily:   int16Value(reqData.KeepDaily),
+			RetentionKeepWeekly:  int16Value(reqData.KeepWeekly),
+			RetentionKeepMonthly: int16Value(reqData.KeepMonthly),
+			RetentionKeepYearly:  int16Value(reqData.KeepYearly),
+			RetentionMinKeep:     int16Value(reqData.MinKeep),
+			OptDataOnly:          reqData.OptDataOnly,
+			OptSchemaOnly:        reqData.OptSchemaOnly,
+			OptClean:             reqData.OptClean,
+			OptIfExists:          reqData.OptIfExists,
+			OptCreate:            reqData.OptCreate,
+			OptNoComments:        reqData.OptNoComments,
+			OptFormat:            reqData.OptFormat,
+			OptJobs:              reqData.OptJobs,
+			Compression:          reqData.Compression,
+			CompressionLevel:     reqData.CompLevel,
+			Mode:                 reqData.Mode,
+			MaxDurationMinutes:   int32Value(reqData.MaxDuration),
+			Priority:             int16Value(reqData.Priority),
+			RetryMaxAttempts:     sqlNullInt16(reqData.RetryAttempts),
+			RetryBackoffSeconds:  sqlNullInt32(reqData.RetryBackoff),
+			Hooks:                string(reqData.hooksValue().Marshal()),
+			OptSchemas:           stringsValue(reqData.OptSchemas),
+			OptExcludeSchemas:    stringsValue(reqData.OptExclSchemas),
+			OptTables:            stringsValue(reqData.OptTables),
+			OptExcludeTables:     stringsValue(reqData.OptExclTables),
+			OptExcludeTableData:  stringsValue(reqData.OptExclData),
+			OptNoOwner:           boolValue(reqData.OptNoOwner),
+			OptNoPrivileges:      boolValue(reqData.OptNoPrivs),
+			OptRole:              stringValue(reqData.OptRole),
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	if replicas != nil {
+		err = h.servs.BackupsService.SetBackupReplicas(ctx, backup.ID, replicas)
+		if err != nil {
+			return respondError(c, http.StatusUnprocessableEntity, err)
+		}
+	}
+
+	backupReplicas, err := h.servs.BackupsService.GetBackupReplicas(ctx, backup.ID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, newBackupResponse(backup, backupReplicas))
+}
+
+func (h *handlers) updateBackupHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	var reqData backupUpdateRequest
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if err := validate.Struct(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	replicas, err := reqData.parseReplicas()
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	backup, err := h.servs.BackupsService.UpdateBackup(
+		ctx, dbgen.BackupsServiceUpdateBackupParams{
+			ID:                   backupID,
+			Name:                 sql.NullString{String: reqData.Name, Valid: true},
+			CronExpression:       sql.NullString{String: reqData.CronExpression, Valid: true},
+			TimeZone:             sql.NullString{String: reqData.TimeZone, Valid: true},
+			IsActive:             sql.NullBool{Bool: reqData.IsActive, Valid: true},
+			DestDir:              sql.NullString{String: reqData.DestDir, Valid: true},
+			RetentionDays:        sql.NullInt16{Int16: reqData.RetentionDays, Valid: true},
+			RetentionKeepLast:    sqlNullInt16(reqData.KeepLast),
+			RetentionKeepDaily:   sqlNullInt16(reqData.KeepDaily),
+			RetentionKeepWeekly:  sqlNullInt16(reqData.KeepWeekly),
+			RetentionKeepMonthly: sqlNullInt16(reqData.KeepMonthly),
+			RetentionKeepYearly:  sqlNullInt16(reqData.KeepYearly),
+			RetentionMinKeep:     sqlNullInt16(reqData.MinKeep),
+			OptDataOnly:          sql.NullBool{Bool: reqData.OptDataOnly, Valid: true},
+			OptSchemaOnly:        sql.NullBool{Bool: reqData.OptSchemaOnly, Valid: true},
+			OptClean:             sql.NullBool{Bool: reqData.OptClean, Valid: true},
+			OptIfExists:          sql.NullBool{Bool: reqData.OptIfExists, Valid: true},
+			OptCreate:            sql.NullBool{Bool: reqData.OptCreate, Valid: true},
+			OptNoComments:        sql.NullBool{Bool: reqData.OptNoComments, Valid: true},
+			OptFormat:            sql.NullString{String: reqData.OptFormat, Valid: reqData.OptFormat != ""},
+			OptJobs:              sql.NullInt16{Int16: reqData.OptJobs, Valid: reqData.OptJobs != 0},
+			Compression:          sql.NullString{String: reqData.Compression, Valid: reqData.Compression != ""},
+			CompressionLevel: sql.NullInt16{
+				Int16: reqData.CompLevel, Valid: reqData.Compression != "",
+			},
+			MaxDurationMinutes:  sqlNullInt32(reqData.MaxDuration),
+			Priority:            sqlNullInt16(reqData.Priority),
+			RetryMaxAttempts:    sqlNullInt16(reqData.RetryAttempts),
+			RetryBackoffSeconds: sqlNullInt32(reqData.RetryBackoff),
+			Hooks:               reqData.nullHooks(),
+			OptSchemas:          sqlNullStrings(reqData.OptSchemas),
+			OptExcludeSchemas:   sqlNullStrings(reqData.OptExclSchemas),
+			OptTables:           sqlNullStrings(reqData.OptTables),
+			OptExcludeTables:    sqlNullStrings(reqData.OptExclTables),
+			OptExcludeTableData: sqlNullStrings(reqData.OptExclData),
+			OptNoOwner:          sqlNullBool(reqData.OptNoOwner),
+			OptNoPrivileges:     sqlNullBool(reqData.OptNoPrivs),
+			OptRole:             sqlNullString(reqData.OptRole),
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	if replicas != nil {
+		err = h.servs.BackupsService.SetBackupReplicas(ctx, backupID, replicas)
+		if err != nil {
+			return respondError(c, http.StatusUnprocessableEntity, err)
+		}
+	}
+
+	backupReplicas, err := h.servs.BackupsService.GetBackupReplicas(ctx, backupID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, newBackupResponse(backup, backupReplicas))
+}
+
+func (h *handlers) deleteBackupHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.servs.BackupsService.DeleteBackup(ctx, backupID); err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// runBackupHandler adds a run of the backup to the jobs queue, the caller
+// can follow it through the jobs and executions endpoints.
+func (h *handlers) runBackupHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if _, err := h.servs.BackupsService.GetBackup(ctx, backupID); err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	job, err := h.servs.JobsService.EnqueueJob(
+		ctx, backupID, jobs.SourceManual, jobs.EnqueueJobParams{},
+	)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "Backup queued, check the jobs queue and the backup executions for more details",
+		"job_id":  job.ID.String(),
+	})
+}
+
+// retentionPreviewHandler returns the executions that the retention policy
+// in the body would delete right now, without deleting anything.
+func (h *handlers) retentionPreviewHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	backup, err := h.servs.BackupsService.GetBackup(ctx, backupID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	// The body overrides the rules of the current policy of the backup
+	var reqData struct {
+		RetentionDays *int16 `json:"retention_days"`
+		KeepLast      *int16 `json:"retention_keep_last"`
+		KeepDaily     *int16 `json:"retention_keep_daily"`
+		KeepWeekly    *int16 `json:"retention_keep_weekly"`
+		KeepMonthly   *int16 `json:"retention_keep_monthly"`
+		KeepYearly    *int16 `json:"retention_keep_yearly"`
+		MinKeep       *int16 `json:"retention_min_keep"`
+	}
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	override := func(dst *int16, v *int16) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	override(&backup.RetentionDays, reqData.RetentionDays)
+	override(&backup.RetentionKeepLast, reqData.KeepLast)
+	override(&backup.RetentionKeepDaily, reqData.KeepDaily)
+	override(&backup.RetentionKeepWeekly, reqData.KeepWeekly)
+	override(&backup.RetentionKeepMonthly, reqData.KeepMonthly)
+	override(&backup.RetentionKeepYearly, reqData.KeepYearly)
+	override(&backup.RetentionMinKeep, reqData.MinKeep)
+
+	policy := executions.RetentionPolicyFromBackup(backup)
+	if err := policy.Validate(); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	expired, err := h.servs.ExecutionsService.ExpiredExecutions(
+		ctx, backup.ID, backup.TimeZone, policy,
+	)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	type item struct {
+		ID         uuid.UUID `json:"id"`
+		Status     string    `json:"status"`
+		FinishedAt time.Time `json:"finished_at"`
+	}
+
+	items := make([]item, 0, len(expired))
+	for _, execution := range expired {
+		items = append(items, item{
+			ID:         execution.ID,
+			Status:     execution.Status,
+			FinishedAt: execution.FinishedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Count int    `json:"count"`
+		Items []item `json:"items"`
+	}{len(items), items})
+}
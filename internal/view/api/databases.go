@@ -0,0 +1,212 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/databases"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// databaseResponse is the public representation of a database, the
+// connection string is never exposed.
+type databaseResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	DatabaseType string     `json:"database_type"`
+	Version      string     `json:"version"`
+	IsProduction bool       `json:"is_production"`
+	IsProtected  bool       `json:"is_protected"`
+	TestOk       *bool      `json:"test_ok"`
+	TestError    *string    `json:"test_error"`
+	LastTestAt   *time.Time `json:"last_test_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at"`
+}
+
+type databaseRequest struct {
+	Name             string `json:"name" validate:"required"`
+	DatabaseType     string `json:"database_type" validate:"required"`
+	Version          string `json:"version" validate:"required"`
+	ConnectionString string `json:"connection_string" validate:"required"`
+	IsProduction     bool   `json:"is_production"`
+	IsProtected      bool   `json:"is_protected"`
+}
+
+func newDatabaseResponse(db dbgen.DatabasesServiceGetDatabaseRow) databaseResponse {
+	return databaseResponse{
+		ID:           db.ID,
+		Name:         db.Name,
+		DatabaseType: db.DatabaseType,
+		Version:      db.Version,
+		IsProduction: db.IsProduction,
+		IsProtected:  db.IsProtected,
+		TestOk:       nullBool(db.TestOk),
+		TestError:    nullString(db.TestError),
+		LastTestAt:   nullTime(db.LastTestAt),
+		CreatedAt:    db.CreatedAt,
+		UpdatedAt:    nullTime(db.UpdatedAt),
+	}
+}
+
+func (h *handlers) listDatabasesHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryData paginationQuery
+	if err := c.Bind(&queryData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	pagination, dbs, err := h.servs.DatabasesService.PaginateDatabases(
+		ctx, databases.PaginateDatabasesParams{
+			Page:  queryData.Page,
+			Limit: queryData.Limit,
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	items := make([]databaseResponse, 0, len(dbs))
+	for _, db := range dbs {
+		items = append(items, newDatabaseResponse(
+			dbgen.DatabasesServiceGetDatabaseRow(db),
+		))
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Pagination paginateutil.PaginateResponse `json:"pagination"`
+		Items      []databaseResponse            `json:"items"`
+	}{pagination, items})
+}
+
+func (h *handlers) getDatabaseHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	databaseID, err := uuid.Parse(c.Param("databaseID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	db, err := h.servs.DatabasesService.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, newDatabaseResponse(db))
+}
+
+func (h *handlers) createDatabaseHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData databaseRequest
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if err := validate.Struct(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	created, err := h.servs.DatabasesService.CreateDatabase(
+		ctx, dbgen.DatabasesServiceCreateDatabaseParams{
+			Name:             reqData.Name,
+			DatabaseType:     reqData.DatabaseType,
+			Version:          reqData.Version,
+			ConnectionString: reqData.ConnectionString,
+			IsProduction:     reqData.IsProduction,
+			IsProtected:      reqData.IsProtected,
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	db, err := h.servs.DatabasesService.GetDatabase(ctx, created.ID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, newDatabaseResponse(db))
+}
+
+func (h *handlers) updateDatabaseHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	databaseID, err := uuid.Parse(c.Param("databaseID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	var reqData databaseRequest
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if err := validate.Struct(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	_, err = h.servs.DatabasesService.UpdateDatabase(
+		ctx, dbgen.DatabasesServiceUpdateDatabaseParams{
+			ID:               databaseID,
+			Name:             sql.NullString{String: reqData.Name, Valid: true},
+			DatabaseType:     sql.NullString{String: reqData.DatabaseType, Valid: true},
+			Version:          sql.NullString{String: reqData.Version, Valid: true},
+			ConnectionString: sql.NullString{String: reqData.ConnectionString, Valid: true},
+			IsProduction:     sql.NullBool{Bool: reqData.IsProduction, Valid: true},
+			IsProtected:      sql.NullBool{Bool: reqData.IsProtected, Valid: true},
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	db, err := h.servs.DatabasesService.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, newDatabaseResponse(db))
+}
+
+func (h *handlers) deleteDatabaseHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	databaseID, err := uuid.Parse(c.Param("databaseID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.servs.DatabasesService.DeleteDatabase(ctx, databaseID); err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handlers) testDatabaseHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	databaseID, err := uuid.Parse(c.Param("databaseID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	testErr := h.servs.DatabasesService.TestDatabaseAndStoreResult(ctx, databaseID)
+
+	db, err := h.servs.DatabasesService.GetDatabase(ctx, databaseID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	status := http.StatusOK
+	if testErr != nil {
+		status = http.StatusUnprocessableEntity
+	}
+
+	return c.JSON(status, newDatabaseResponse(db))
+}
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
+	"github.com/eduardolat/pgbackweb/internal/service/destinations"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// destinationResponse is the public representation of a destination, the
+// access, secret, private and backup encryption keys are never exposed.
+type destinationResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	BucketName string     `json:"bucket_name"`
+	Region     string     `json:"region"`
+	Endpoint   string     `json:"endpoint"`
+	BasePath   string     `json:"base_path"`
+	HostKey    string     `json:"host_key"`
+	TestOk     *bool      `json:"test_ok"`
+	TestError  *string    `json:"test_error"`
+	LastTestAt *time.Time `json:"last_test_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+
+	S3CredentialsMode      string `json:"s3_credentials_mode"`
+	S3RoleArn              string `json:"s3_role_arn"`
+	S3ExternalID           string `json:"s3_external_id"`
+	S3ServerSideEncryption string `json:"s3_server_side_encryption"`
+	S3KmsKeyID             string `json:"s3_kms_key_id"`
+	S3StorageClass         string `json:"s3_storage_class"`
+	S3VirtualHostedStyle   bool   `json:"s3_virtual_hosted_style"`
+	S3ObjectLockMode       string `json:"s3_object_lock_mode"`
+	S3ObjectLockDays       int32  `json:"s3_object_lock_days"`
+	S3ObjectLockLegalHold  bool   `json:"s3_object_lock_legal_hold"`
+
+	// BackupEncryptionKeyFingerprint identifies the key used to encrypt the
+	// backups stored in the destination, null if they are not encrypted.
+	BackupEncryptionKeyFingerprint *string `json:"backup_encryption_key_fingerprint"`
+}
+
+// destinationRequest holds the settings of a destination, the settings
+// required by every type are checked by the destinations service.
+type destinationRequest struct {
+	Name       string `json:"name" validate:"required"`
+	Type       string `json:"type"`
+	BucketName string `json:"bucket_name"`
+	AccessKey  string `json:"access_key"`
+	SecretKey  string `json:"secret_key"`
+	Region     string `json:"region"`
+	Endpoint   string `json:"endpoint"`
+	BasePath   string `json:"base_path"`
+	HostKey    string `json:"host_key"`
+
+	S3CredentialsMode      string `json:"s3_credentials_mode"`
+	S3RoleArn              string `json:"s3_role_arn"`
+	S3ExternalID           string `json:"s3_external_id"`
+	S3ServerSideEncryption string `json:"s3_server_side_encryption"`
+	S3KmsKeyID             string `json:"s3_kms_key_id"`
+	S3StorageClass         string `json:"s3_storage_class"`
+	S3VirtualHostedStyle   bool   `json:"s3_virtual_hosted_style"`
+	S3ObjectLockMode       string `json:"s3_object_lock_mode"`
+	S3ObjectLockDays       int32  `json:"s3_object_lock_days"`
+	S3ObjectLockLegalHold  bool   `json:"s3_object_lock_legal_hold"`
+
+	// S3SseCustomerKey is the key used by SSE-C, it's kept unchanged on
+	// updates when omitted.
+	S3SseCustomerKey *string `json:"s3_sse_customer_key"`
+
+	// PrivateKey is the SSH key of SFTP destinations or the service account
+	// JSON of GCS destinations, it's kept unchanged on updates when omitted.
+	PrivateKey *string `json:"private_key"`
+
+	// BackupEncryptionKey is kept unchanged on updates when omitted, an empty
+	// string disables the encryption of new backups.
+	BackupEncryptionKey *string `json:"backup_encryption_key"`
+}
+
+// setDefaults keeps the requests made before the destination types were
+// added working, they always describe S3 destinations.
+func (r *destinationRequest) setDefaults() {
+	if r.Type == "" {
+		r.Type = storage.StorageTypeS3
+	}
+}
+
+func newDestinationResponse(
+	dest dbgen.DestinationsServiceGetDestinationRow,
+) destinationResponse {
+	var fingerprint *string
+	if key, err := encryption.ParseKey(dest.DecryptedBackupEncryptionKey); err == nil {
+		f := key.Fingerprint()
+		fingerprint = &f
+	}
+
+	return destinationResponse{
+		BackupEncryptionKeyFingerprint: fingerprint,
+
+		ID:         dest.ID,
+		Name:       dest.Name,
+		Type:       dest.Type,
+		BucketName: dest.BucketName,
+		Region:     dest.Region,
+		Endpoint:   dest.Endpoint,
+		BasePath:   dest.BasePath,
+		HostKey:    dest.HostKey,
+
+		S3CredentialsMode:      dest.S3CredentialsMode,
+		S3RoleArn:              dest.S3RoleArn,
+		S3ExternalID:           dest.S3ExternalID,
+		S3ServerSideEncryption: dest.S3ServerSideEncryption,
+		S3KmsKeyID:             dest.S3KmsKeyID,
+		S3StorageClass:         dest.S3StorageClass,
+		S3VirtualHostedStyle:   dest.S3VirtualHostedStyle,
+		S3ObjectLockMode:       dest.S3ObjectLockMode,
+		S3ObjectLockDays:       dest.S3ObjectLockDays,
+		S3ObjectLockLegalHold:  dest.S3ObjectLockLegalHold,
+		TestOk:                 nullBool(dest.TestOk),
+		TestError:              nullString(dest.TestError),
+		LastTestAt:             nullTime(dest.LastTestAt),
+		CreatedAt:              dest.CreatedAt,
+		UpdatedAt:              nullTime(dest.UpdatedAt),
+	}
+}
+
+func (h *handlers) listDestinationsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryData paginationQuery
+	if err := c.Bind(&queryData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	pagination, dests, err := h.servs.DestinationsService.PaginateDestinations(
+		ctx, destinations.PaginateDestinationsParams{
+			Page:  queryData.Page,
+			Limit: queryData.Limit,
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	items := make([]destinationResponse, 0, len(dests))
+	for _, dest := range dests {
+		items = append(items, newDestinationResponse(
+			dbgen.DestinationsServiceGetDestinationRow(dest),
+		))
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Pagination paginateutil.PaginateResponse `json:"pagination"`
+		Items      []destinationResponse         `json:"items"`
+	}{pagination, items})
+}
+
+func (h *handlers) getDestinationHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	destinationID, err := uuid.Parse(c.Param("destinationID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	dest, err := h.servs.DestinationsService.GetDestination(ctx, destinationID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, newDestinationResponse(dest))
+}
+
+func (h *handlers) createDestinationHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData destinationRequest
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if err := validate.Struct(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	reqData.setDefaults()
+
+	created, err := h.servs.DestinationsService.CreateDestination(
+		ctx, dbgen.DestinationsServiceCreateDestinationParams{
+			Name:       reqData.Name,
+			Type:       reqData.Type,
+			BucketName: reqData.BucketName,
+			AccessKey:  reqData.AccessKey,
+			SecretKey:  reqData.SecretKey,
+			Region:     reqData.Region,
+			Endpoint:   reqData.Endpoint,
+			BasePath:   reqData.BasePath,
+			HostKey:    reqData.HostKey,
+			PrivateKey: sqlNullString(reqData.PrivateKey).String,
+
+			S3CredentialsMode:      reqData.S3CredentialsMode,
+			S3RoleArn:              reqData.S3RoleArn,
+			S3ExternalID:           reqData.S3ExternalID,
+			S3ServerSideEncryption: reqData.S3ServerSideEncryption,
+			S3KmsKeyID:             reqData.S3KmsKeyID,
+			S3SseCustomerKey:       sqlNullString(reqData.S3SseCustomerKey).String,
+			S3StorageClass:         reqData.S3StorageClass,
+			S3VirtualHostedStyle:   reqData.S3VirtualHostedStyle,
+			S3ObjectLockMode:       reqData.S3ObjectLockMode,
+			S3ObjectLockDays:       reqData.S3ObjectLockDays,
+			S3ObjectLockLegalHold:  reqData.S3ObjectLockLegalHold,
+
+			BackupEncryptionKey: sqlNullString(reqData.BackupEncryptionKey).String,
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	dest, err := h.servs.DestinationsService.GetDestination(ctx, created.ID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, newDestinationResponse(dest))
+}
+
+func (h *handlers) updateDestinationHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	destinationID, err := uuid.Parse(c.Param("destinationID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	var reqData destinationRequest
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if err := validate.Struct(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	reqData.setDefaults()
+
+	_, err = h.servs.DestinationsService.UpdateDestination(
+		ctx, dbgen.DestinationsServiceUpdateDestinationParams{
+			ID:         destinationID,
+			Name:       sql.NullString{String: reqData.Name, Valid: true},
+			Type:       sql.NullString{String: reqData.Type, Valid: true},
+			BucketName: sql.NullString{String: reqData.BucketName, Valid: true},
+			AccessKey:  sql.NullString{String: reqData.AccessKey, Valid: true},
+			SecretKey:  sql.NullString{String: reqData.SecretKey, Valid: true},
+			Region:     sql.NullString{String: reqData.Region, Valid: true},
+			Endpoint:   sql.NullString{String: reqData.Endpoint, Valid: true},
+			BasePath:   sql.NullString{String: reqData.BasePath, Valid: true},
+			HostKey:    sql.NullString{String: reqData.HostKey, Valid: true},
+			PrivateKey: sqlNullString(reqData.PrivateKey),
+
+			S3CredentialsMode:      sql.NullString{String: reqData.S3CredentialsMode, Valid: true},
+			S3RoleArn:              sql.NullString{String: reqData.S3RoleArn, Valid: true},
+			S3ExternalID:           sql.NullString{String: reqData.S3ExternalID, Valid: true},
+			S3ServerSideEncryption: sql.NullString{String: reqData.S3ServerSideEncryption, Valid: true},
+			S3KmsKeyID:             sql.NullString{String: reqData.S3KmsKeyID, Valid: true},
+			S3SseCustomerKey:       sqlNullString(reqData.S3SseCustomerKey),
+			S3StorageClass:         sql.NullString{String: reqData.S3StorageClass, Valid: true},
+			S3VirtualHostedStyle:   sql.NullBool{Bool: reqData.S3VirtualHostedStyle, Valid: true},
+			S3ObjectLockMode:       sql.NullString{String: reqData.S3ObjectLockMode, Valid: true},
+			S3ObjectLockDays:       sql.NullInt32{Int32: reqData.S3ObjectLockDays, Valid: true},
+			S3ObjectLockLegalHold:  sql.NullBool{Bool: reqData.S3ObjectLockLegalHold, Valid: true},
+
+			BackupEncryptionKey: sqlNullString(reqData.BackupEncryptionKey),
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	dest, err := h.servs.DestinationsService.GetDestination(ctx, destinationID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, newDestinationResponse(dest))
+}
+
+func (h *handlers) deleteDestinationHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	destinationID, err := uuid.Parse(c.Param("destinationID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	err = h.servs.DestinationsService.DeleteDestination(ctx, destinationID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handlers) testDestinationHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	destinationID, err := uuid.Parse(c.Param("destinationID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	testErr := h.servs.DestinationsService.TestDestinationAndStoreResult(
+		ctx, destinationID,
+	)
+
+	dest, err := h.servs.DestinationsService.GetDestination(ctx, destinationID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	status := http.StatusOK
+	if testErr != nil {
+		status = http.StatusUnprocessableEntity
+	}
+
+	return c.JSON(status, newDestinationResponse(dest))
+}
@@ -0,0 +1,498 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/service/restorations"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type executionResponse struct {
+	ID                       uuid.UUID  `json:"id"`
+	BackupID                 uuid.UUID  `json:"backup_id"`
+	Status                   string     `json:"status"`
+	Attempt                  int32      `json:"attempt"`
+	ParentExecutionID        *uuid.UUID `json:"parent_execution_id"`
+	IsPinned                 bool       `json:"is_pinned"`
+	Log                      *string    `json:"log"`
+	Compression              string     `json:"compression"`
+	FileExtension            string     `json:"file_extension"`
+	EncryptionKeyFingerprint *string    `json:"encryption_key_fingerprint"`
+	Checksum                 *string    `json:"checksum"`
+	VerifyStatus             *string    `json:"verify_status"`
+	VerifyMessage            *string    `json:"verify_message"`
+	VerifiedAt               *time.Time `json:"verified_at"`
+	Message                  *string    `json:"message"`
+	Path                     *string    `json:"path"`
+	FileSize                 *int64     `json:"file_size"`
+	StartedAt                time.Time  `json:"started_at"`
+	UpdatedAt                *time.Time `json:"updated_at"`
+	FinishedAt               *time.Time `json:"finished_at"`
+	DeletedAt                *time.Time `json:"deleted_at"`
+}
+
+// executionCopyResponse is a file uploaded by an execution to one of the
+// destinations of its backup.
+type executionCopyResponse struct {
+	ID                       uuid.UUID  `json:"id"`
+	DestinationID            *uuid.UUID `json:"destination_id"`
+	DestinationName          *string    `json:"destination_name"`
+	IsLocal                  bool       `json:"is_local"`
+	IsPrimary                bool       `json:"is_primary"`
+	Status                   string     `json:"status"`
+	Message                  *string    `json:"message"`
+	Path                     *string    `json:"path"`
+	FileSize                 *int64     `json:"file_size"`
+	FileExtension            string     `json:"file_extension"`
+	EncryptionKeyFingerprint *string    `json:"encryption_key_fingerprint"`
+	Checksum                 *string    `json:"checksum"`
+	VerifyStatus             *string    `json:"verify_status"`
+	VerifiedAt               *time.Time `json:"verified_at"`
+	CreatedAt                time.Time  `json:"created_at"`
+	FinishedAt               *time.Time `json:"finished_at"`
+}
+
+func newExecutionCopyResponse(
+	cp dbgen.ExecutionsServiceListExecutionCopiesRow,
+) executionCopyResponse {
+	return executionCopyResponse{
+		ID:                       cp.ID,
+		DestinationID:            nullUUID(cp.DestinationID),
+		DestinationName:          nullString(cp.DestinationName),
+		IsLocal:                  cp.IsLocal,
+		IsPrimary:                cp.IsPrimary,
+		Status:                   cp.Status,
+		Message:                  nullString(cp.Message),
+		Path:                     nullString(cp.Path),
+		FileSize:                 nullInt64(cp.FileSize),
+		FileExtension:            cp.FileExtension,
+		EncryptionKeyFingerprint: nullString(cp.EncryptionKeyFingerprint),
+		Checksum:                 nullString(cp.Checksum),
+		VerifyStatus:             nullString(cp.VerifyStatus),
+		VerifiedAt:               nullTime(cp.VerifiedAt),
+		CreatedAt:                cp.CreatedAt,
+		FinishedAt:               nullTime(cp.FinishedAt),
+	}
+}
+
+func newExecutionResponse(execution dbgen.Execution) executionResponse {
+	return executionResponse{
+		ID:                       execution.ID,
+		BackupID:                 execution.BackupID,
+		Status:                   execution.Status,
+		Attempt:                  execution.Attempt,
+		ParentExecutionID:        nullUUID(execution.ParentExecutionID),
+		IsPinned:                 execution.IsPinned,
+		Log:                      nullString(execution.Log),
+		Compression:              execution.Compression,
+		FileExtension:            execution.FileExtension,
+		EncryptionKeyFingerprint: nullString(execution.EncryptionKeyFingerprint),
+		Checksum:                 nullString(execution.Checksum),
+		VerifyStatus:             nullString(execution.VerifyStatus),
+		VerifyMessage:            nullString(execution.VerifyMessage),
+		VerifiedAt:               nullTime(execution.VerifiedAt),
+		Message:                  nullString(execution.Message),
+		Path:                     nullString(execution.Path),
+		FileSize:                 nullInt64(execution.FileSize),
+		StartedAt:                execution.StartedAt,
+		UpdatedAt:                nullTime(execution.UpdatedAt),
+		FinishedAt:               nullTime(execution.FinishedAt),
+		DeletedAt:                nullTime(execution.DeletedAt),
+	}
+}
+
+func (h *handlers) listExecutionsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryData struct {
+		Page          int       `query:"page"`
+		Limit         int       `query:"limit"`
+		BackupID      uuid.UUID `query:"backup_id"`
+		DatabaseID    uuid.UUID `query:"database_id"`
+		DestinationID uuid.UUID `query:"destination_id"`
+	}
+	if err := c.Bind(&queryData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	pagination, execs, err := h.servs.ExecutionsService.PaginateExecutions(
+		ctx, executions.PaginateExecutionsParams{
+			BackupFilter: uuid.NullUUID{
+				UUID: queryData.BackupID, Valid: queryData.BackupID != uuid.Nil,
+			},
+			DatabaseFilter: uuid.NullUUID{
+				UUID: queryData.DatabaseID, Valid: queryData.DatabaseID != uuid.Nil,
+			},
+			DestinationFilter: uuid.NullUUID{
+				UUID: queryData.DestinationID, Valid: queryData.DestinationID != uuid.Nil,
+			},
+			Page:  queryData.Page,
+			Limit: queryData.Limit,
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	type item struct {
+		executionResponse
+		BackupName      string  `json:"backup_name"`
+		DatabaseName    string  `json:"database_name"`
+		DestinationName *string `json:"destination_name"`
+		BackupIsLocal   bool    `json:"backup_is_local"`
+	}
+
+	items := make([]item, 0, len(execs))
+	for _, exec := range execs {
+		items = append(items, item{
+			executionResponse: newExecutionResponse(dbgen.Execution{
+				ID:                       exec.ID,
+				BackupID:                 exec.BackupID,
+				Status:                   exec.Status,
+				Message:                  exec.Message,
+				Path:                     exec.Path,
+				StartedAt:                exec.StartedAt,
+				UpdatedAt:                exec.UpdatedAt,
+				FinishedAt:               exec.FinishedAt,
+				DeletedAt:                exec.DeletedAt,
+				FileSize:                 exec.FileSize,
+				Compression:              exec.Compression,
+				FileExtension:            exec.FileExtension,
+				EncryptionKeyFingerprint: exec.EncryptionKeyFingerprint,
+				Checksum:                 exec.Checksum,
+				VerifyStatus:             exec.VerifyStatus,
+				VerifyMessage:            exec.VerifyMessage,
+				VerifiedAt:               exec.VerifiedAt,
+			}),
+			BackupName:      exec.BackupName,
+			DatabaseName:    exec.DatabaseName,
+			DestinationName: nullString(exec.DestinationName),
+			BackupIsLocal:   exec.BackupIsLocal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Pagination paginateutil.PaginateResponse `json:"pagination"`
+		Items      []item                        `json:"items"`
+	}{pagination, items})
+}
+
+func (h *handlers) getExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	exec, err := h.servs.ExecutionsService.GetExecution(ctx, executionID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	copies, err := h.servs.ExecutionsService.ListExecutionCopies(ctx, executionID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+	copiesResponse := make([]executionCopyResponse, 0, len(copies))
+	for _, cp := range copies {
+		copiesResponse = append(copiesResponse, newExecutionCopyResponse(cp))
+	}
+
+	type response struct {
+		executionResponse
+		Copies []executionCopyResponse `json:"copies"`
+	}
+
+	return c.JSON(http.StatusOK, response{newExecutionResponse(dbgen.Execution{
+		ID:                       exec.ID,
+		BackupID:                 exec.BackupID,
+		Status:                   exec.Status,
+		Message:                  exec.Message,
+		Path:                     exec.Path,
+		StartedAt:                exec.StartedAt,
+		UpdatedAt:                exec.UpdatedAt,
+		FinishedAt:               exec.FinishedAt,
+		DeletedAt:                exec.DeletedAt,
+		FileSize:                 exec.FileSize,
+		Compression:              exec.Compression,
+		FileExtension:            exec.FileExtension,
+		EncryptionKeyFingerprint: exec.EncryptionKeyFingerprint,
+		Checksum:                 exec.Checksum,
+		VerifyStatus:             exec.VerifyStatus,
+		VerifyMessage:            exec.VerifyMessage,
+		VerifiedAt:               exec.VerifiedAt,
+	}), copiesResponse})
+}
+
+func (h *handlers) downloadExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	isLocal, link, err := h.servs.ExecutionsService.GetExecutionDownloadLinkOrPath(
+		ctx, executionID,
+	)
+	if err == nil && isLocal {
+		return c.Attachment(link, filepath.Base(link))
+	}
+	if err == nil {
+		return c.Redirect(http.StatusFound, link)
+	}
+	if !errors.Is(err, executions.ErrNoDirectLink) {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	// Encrypted files are decrypted on the fly, they can't be served with a
+	// direct link to the storage
+	file, fileName, err := h.servs.ExecutionsService.OpenExecutionFile(
+		ctx, executionID,
+	)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+	defer file.Close()
+
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=%q", fileName),
+	)
+	return c.Stream(
+		http.StatusOK, strutil.GetContentTypeFromFileName(fileName), file,
+	)
+}
+
+// verifyExecutionHandler reads the execution file again and compares it with
+// the checksum computed when it was uploaded, it responds with the execution
+// including the result of the verification.
+func (h *handlers) verifyExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	status, err := h.servs.ExecutionsService.VerifyExecution(ctx, executionID)
+	if err != nil && status == "" {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return h.getExecutionHandler(c)
+}
+
+// unpinExecutionHandler lets the retention policy of the backup delete the
+// execution again, it responds with the updated execution.
+func (h *handlers) unpinExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	err = h.servs.ExecutionsService.UnpinExecution(ctx, executionID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return h.getExecutionHandler(c)
+}
+
+// cancelExecutionHandler stops a running execution, the execution is marked
+// as cancelled once its partial files are removed.
+func (h *handlers) cancelExecutionHandler(c echo.Context) error {
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	err = h.servs.ExecutionsService.CancelExecution(executionID)
+	if errors.Is(err, executions.ErrExecutionNotRunning) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "Cancelling execution, check the execution for more details",
+	})
+}
+
+func (h *handlers) deleteExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	err = h.servs.ExecutionsService.SoftDeleteExecution(ctx, executionID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// restoreExecutionHandler starts a restoration of the execution in the
+// background, either into a registered database or into an arbitrary
+// connection string.
+func (h *handlers) restoreExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	var reqData struct {
+		DatabaseID       uuid.UUID   `json:"database_id"`
+		ConnectionString string      `json:"connection_string"`
+		Hooks            hooks.Hooks `json:"hooks"`
+		// Selection restores only some schemas and tables, see
+		// postgres.RestoreSelection
+		Selection postgres.RestoreSelection `json:"selection"`
+		// NewDatabase restores into a database created in the server of the
+		// target, see postgres.NewDatabase
+		NewDatabase postgres.NewDatabase `json:"new_database"`
+		// DryRun only checks that the backup can be restored into the target
+		DryRun bool `json:"dry_run"`
+		// PreRestoreBackup backs up the registered target database first
+		PreRestoreBackup bool `json:"pre_restore_backup"`
+		// ConfirmDatabaseName must be the name of a protected target database
+		ConfirmDatabaseName string `json:"confirm_database_name"`
+	}
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if err := validate.Struct(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if reqData.DatabaseID == uuid.Nil && reqData.ConnectionString == "" {
+		return respondMessage(
+			c, http.StatusBadRequest, "database_id or connection_string is required",
+		)
+	}
+
+	if reqData.DatabaseID != uuid.Nil && reqData.ConnectionString != "" {
+		return respondMessage(
+			c, http.StatusBadRequest, "database_id and connection_string cannot be both set",
+		)
+	}
+
+	err = h.servs.RestorationsService.ValidateRestoreTarget(
+		ctx, reqctx.GetCtx(c).User.Role, uuid.NullUUID{
+			Valid: reqData.DatabaseID != uuid.Nil,
+			UUID:  reqData.DatabaseID,
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusForbidden, err)
+	}
+
+	err = h.servs.RestorationsService.ValidateRestoreHooks(
+		reqctx.GetCtx(c).User.Role, reqData.Hooks,
+	)
+	if errors.Is(err, restorations.ErrRestoreHooksNotAllowed) {
+		return respondError(c, http.StatusForbidden, err)
+	}
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := reqData.Selection.Validate(); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if !reqData.NewDatabase.IsEmpty() {
+		if err := reqData.NewDatabase.Validate(); err != nil {
+			return respondError(c, http.StatusBadRequest, err)
+		}
+	}
+
+	execution, err := h.servs.ExecutionsService.GetExecution(ctx, executionID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	if reqData.ConnectionString != "" {
+		err := h.servs.DatabasesService.TestDatabase(
+			ctx, execution.DatabaseDatabaseType, execution.DatabaseVersion,
+			reqData.ConnectionString,
+		)
+		if err != nil {
+			return respondError(c, http.StatusUnprocessableEntity, err)
+		}
+	}
+
+	params := restorations.RunRestorationParams{
+		ExecutionID: executionID,
+		DatabaseID: uuid.NullUUID{
+			Valid: reqData.DatabaseID != uuid.Nil,
+			UUID:  reqData.DatabaseID,
+		},
+		ConnString:       reqData.ConnectionString,
+		Hooks:            reqData.Hooks,
+		Selection:        reqData.Selection,
+		NewDatabase:      reqData.NewDatabase,
+		DryRun:           reqData.DryRun,
+		PreRestoreBackup: reqData.PreRestoreBackup,
+	}
+
+	err = h.servs.RestorationsService.CheckRestoreConfirmation(
+		ctx, params, reqData.ConfirmDatabaseName,
+	)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	go func() {
+		_ = h.servs.RestorationsService.RunRestoration(
+			context.Background(), params,
+		)
+	}()
+
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "Process started, check the restorations for more details",
+	})
+}
+
+func (h *handlers) listExecutionObjectsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	objects, err := h.servs.RestorationsService.ListExecutionObjects(
+		ctx, executionID,
+	)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Objects []postgres.ArchiveObject `json:"objects"`
+	}{objects})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/service/jobs"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type jobResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	BackupID     uuid.UUID  `json:"backup_id"`
+	BackupName   string     `json:"backup_name"`
+	DatabaseName string     `json:"database_name"`
+	ExecutionID  *uuid.UUID `json:"execution_id"`
+	Server       string     `json:"server"`
+	Source       string     `json:"source"`
+	Priority     int16      `json:"priority"`
+	Status       string     `json:"status"`
+	Message      *string    `json:"message"`
+	Attempts     int32      `json:"attempts"`
+	MaxAttempts  int32      `json:"max_attempts"`
+	RunAfter     time.Time  `json:"run_after"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at"`
+}
+
+// listJobsHandler returns the running, queued and waiting jobs in the order
+// they are going to run.
+func (h *handlers) listJobsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	pending, err := h.servs.JobsService.ListPendingJobs(ctx)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	items := make([]jobResponse, 0, len(pending))
+	for _, job := range pending {
+		items = append(items, jobResponse{
+			ID:           job.ID,
+			BackupID:     job.BackupID,
+			BackupName:   job.BackupName,
+			DatabaseName: job.DatabaseName,
+			ExecutionID:  nullUUID(job.ExecutionID),
+			Server:       job.Server,
+			Source:       job.Source,
+			Priority:     job.Priority,
+			Status:       job.Status,
+			Message:      nullString(job.Message),
+			Attempts:     job.Attempts,
+			MaxAttempts:  job.MaxAttempts,
+			RunAfter:     job.RunAfter,
+			CreatedAt:    job.CreatedAt,
+			StartedAt:    nullTime(job.StartedAt),
+		})
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Count int           `json:"count"`
+		Items []jobResponse `json:"items"`
+	}{len(items), items})
+}
+
+// cancelJobHandler removes a queued or waiting job from the queue.
+func (h *handlers) cancelJobHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	jobID, err := uuid.Parse(c.Param("jobID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	err = h.servs.JobsService.CancelJob(ctx, jobID)
+	if errors.Is(err, jobs.ErrJobNotPending) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
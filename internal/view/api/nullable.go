@@ -0,0 +1,150 @@
+package api
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The helpers below turn nullable database values into pointers so
+// they are serialized as null instead of {"Valid": false, ...}.
+
+func nullString(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
+
+func nullBool(v sql.NullBool) *bool {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Bool
+}
+
+func nullTime(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Time
+}
+
+func nullInt64(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
+
+func nullInt16(v sql.NullInt16) *int16 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int16
+}
+
+func nullInt32(v sql.NullInt32) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
+
+func nullUUID(v uuid.NullUUID) *uuid.UUID {
+	if !v.Valid {
+		return nil
+	}
+	return &v.UUID
+}
+
+// sqlNullString is the inverse of nullString, it turns an optional request
+// field into a nullable database value.
+func sqlNullString(v *string) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *v, Valid: true}
+}
+
+// sqlNullInt16 turns an optional request field into a nullable database
+// value.
+func sqlNullInt16(v *int16) sql.NullInt16 {
+	if v == nil {
+		return sql.NullInt16{}
+	}
+	return sql.NullInt16{Int16: *v, Valid: true}
+}
+
+// sqlNullInt32 turns an optional request field into a nullable database
+// value.
+func sqlNullInt32(v *int32) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *v, Valid: true}
+}
+
+// int16Value returns the value of an optional request field, or 0 when it
+// is omitted.
+func int16Value(v *int16) int16 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// int32Value returns the value of an optional request field, or 0 when it
+// is omitted.
+func int32Value(v *int32) int32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// sqlNullBool turns an optional request field into a nullable database
+// value.
+func sqlNullBool(v *bool) sql.NullBool {
+	if v == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *v, Valid: true}
+}
+
+// boolValue returns the value of an optional request field, or false when
+// it is omitted.
+func boolValue(v *bool) bool {
+	if v == nil {
+		return false
+	}
+	return *v
+}
+
+// stringValue returns the value of an optional request field, or the empty
+// string when it is omitted.
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+// stringsValue returns the value of an optional list request field, or an
+// empty list when it is omitted.
+func stringsValue(v *[]string) []string {
+	if v == nil || *v == nil {
+		return []string{}
+	}
+	return *v
+}
+
+// sqlNullStrings turns an optional list request field into a nullable
+// database array, nil when it is omitted.
+func sqlNullStrings(v *[]string) []string {
+	if v == nil {
+		return nil
+	}
+	return stringsValue(v)
+}
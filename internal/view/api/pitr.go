@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/pitr"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type walArchiveResponse struct {
+	Streaming          bool       `json:"streaming"`
+	StreamingStartedAt *time.Time `json:"streaming_started_at"`
+	LastError          *string    `json:"last_error"`
+	LastErrorAt        *time.Time `json:"last_error_at"`
+	Segments           int64      `json:"segments"`
+	TotalSize          int64      `json:"total_size"`
+	LastSegment        *string    `json:"last_segment"`
+	LastArchivedAt     *time.Time `json:"last_archived_at"`
+	BaseBackups        int        `json:"base_backups"`
+	RecoverableFrom    *time.Time `json:"recoverable_from"`
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// walArchiveHandler returns the status of the WAL archive of a physical
+// backup.
+func (h *handlers) walArchiveHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	backup, err := h.servs.BackupsService.GetBackup(ctx, backupID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+	if backup.Mode != postgres.BackupModePhysical {
+		return respondMessage(
+			c, http.StatusBadRequest, "the backup doesn't take physical backups",
+		)
+	}
+
+	status, err := h.servs.PITRService.GetArchiveStatus(ctx, backupID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, walArchiveResponse{
+		Streaming:          status.Receiver.Running,
+		StreamingStartedAt: optionalTime(status.Receiver.StartedAt),
+		LastError:          optionalString(status.Receiver.LastError),
+		LastErrorAt:        optionalTime(status.Receiver.LastErrorAt),
+		Segments:           status.Segments,
+		TotalSize:          status.TotalSize,
+		LastSegment:        optionalString(status.LastSegment),
+		LastArchivedAt:     optionalTime(status.LastArchivedAt),
+		BaseBackups:        status.BaseBackups,
+		RecoverableFrom:    optionalTime(status.RecoverableFrom),
+	})
+}
+
+// pitrRestoreHandler starts a point-in-time restore of a physical backup
+// into a data directory in the background. Without target_time and
+// target_lsn the cluster is recovered up to the last archived WAL file.
+func (h *handlers) pitrRestoreHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	var reqData struct {
+		TargetTime    *time.Time `json:"target_time"`
+		TargetLSN     string     `json:"target_lsn"`
+		DataDirectory string     `json:"data_directory" validate:"required"`
+	}
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if err := validate.Struct(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	backup, err := h.servs.BackupsService.GetBackup(ctx, backupID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+	if backup.Mode != postgres.BackupModePhysical {
+		return respondMessage(
+			c, http.StatusBadRequest, "the backup doesn't take physical backups",
+		)
+	}
+
+	params := pitr.RestorePointInTimeParams{
+		BackupID:      backupID,
+		Target:        postgres.RecoveryTarget{LSN: reqData.TargetLSN},
+		DataDirectory: reqData.DataDirectory,
+	}
+	if reqData.TargetTime != nil {
+		params.Target.Time = *reqData.TargetTime
+	}
+
+	baseID, err := h.servs.PITRService.ValidateRestorePointInTime(ctx, params)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	go func() {
+		_ = h.servs.PITRService.RestorePointInTime(context.Background(), params)
+	}()
+
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message":      "Process started, check the restorations for more details",
+		"execution_id": baseID.String(),
+	})
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// errorResponse is the body returned by every failed API request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// paginationQuery holds the query params accepted by the paginated
+// list endpoints.
+type paginationQuery struct {
+	Page  int `query:"page"`
+	Limit int `query:"limit"`
+}
+
+// respondError writes err as a JSON error, using 404 for sql.ErrNoRows.
+func respondError(c echo.Context, status int, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		status = http.StatusNotFound
+	}
+	return c.JSON(status, errorResponse{Error: err.Error()})
+}
+
+// respondMessage writes a JSON error with a plain message.
+func respondMessage(c echo.Context, status int, msg string) error {
+	return c.JSON(status, errorResponse{Error: msg})
+}
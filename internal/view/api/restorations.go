@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/service/restorations"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type restorationResponse struct {
+	ID                    uuid.UUID       `json:"id"`
+	ExecutionID           uuid.UUID       `json:"execution_id"`
+	DatabaseID            *uuid.UUID      `json:"database_id"`
+	DatabaseName          *string         `json:"database_name"`
+	BackupName            string          `json:"backup_name"`
+	Status                string          `json:"status"`
+	Message               *string         `json:"message"`
+	DataDirectory         *string         `json:"data_directory"`
+	RecoveryTargetTime    *time.Time      `json:"recovery_target_time"`
+	RecoveryTargetLSN     *string         `json:"recovery_target_lsn"`
+	Hooks                 json.RawMessage `json:"hooks"`
+	Selection             json.RawMessage `json:"selection"`
+	NewDatabase           json.RawMessage `json:"new_database"`
+	DryRun                bool            `json:"dry_run"`
+	PreRestoreExecutionID *uuid.UUID      `json:"pre_restore_execution_id"`
+	Log                   *string         `json:"log"`
+	StartedAt             time.Time       `json:"started_at"`
+	UpdatedAt             *time.Time      `json:"updated_at"`
+	FinishedAt            *time.Time      `json:"finished_at"`
+}
+
+func (h *handlers) listRestorationsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryData struct {
+		Page        int       `query:"page"`
+		Limit       int       `query:"limit"`
+		ExecutionID uuid.UUID `query:"execution_id"`
+		DatabaseID  uuid.UUID `query:"database_id"`
+	}
+	if err := c.Bind(&queryData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	pagination, ress, err := h.servs.RestorationsService.PaginateRestorations(
+		ctx, restorations.PaginateRestorationsParams{
+			ExecutionFilter: uuid.NullUUID{
+				UUID: queryData.ExecutionID, Valid: queryData.ExecutionID != uuid.Nil,
+			},
+			DatabaseFilter: uuid.NullUUID{
+				UUID: queryData.DatabaseID, Valid: queryData.DatabaseID != uuid.Nil,
+			},
+			Page:  queryData.Page,
+			Limit: queryData.Limit,
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	items := make([]restorationResponse, 0, len(ress))
+	for _, res := range ress {
+		items = append(items, restorationResponse{
+			ID:                    res.ID,
+			ExecutionID:           res.ExecutionID,
+			DatabaseID:            nullUUID(res.DatabaseID),
+			DatabaseName:          nullString(res.DatabaseName),
+			BackupName:            res.BackupName,
+			Status:                res.Status,
+			Message:               nullString(res.Message),
+			DataDirectory:         nullString(res.DataDirectory),
+			RecoveryTargetTime:    nullTime(res.RecoveryTargetTime),
+			RecoveryTargetLSN:     nullString(res.RecoveryTargetLsn),
+			Hooks:                 res.Hooks,
+			Selection:             res.Selection,
+			NewDatabase:           res.NewDatabase,
+			DryRun:                res.DryRun,
+			PreRestoreExecutionID: nullUUID(res.PreRestoreExecutionID),
+			Log:                   nullString(res.Log),
+			StartedAt:             res.StartedAt,
+			UpdatedAt:             nullTime(res.UpdatedAt),
+			FinishedAt:            nullTime(res.FinishedAt),
+		})
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Pagination paginateutil.PaginateResponse `json:"pagination"`
+		Items      []restorationResponse         `json:"items"`
+	}{pagination, items})
+}
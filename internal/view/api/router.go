@@ -2,6 +2,8 @@ package api
 
 import (
 	"github.com/eduardolat/pgbackweb/internal/service"
+	"github.com/eduardolat/pgbackweb/internal/service/auth"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
 	"github.com/eduardolat/pgbackweb/internal/view/middleware"
 	"github.com/labstack/echo/v4"
 )
@@ -19,4 +21,77 @@ func MountRouter(
 		servs: servs,
 	}
 	v1.GET("/health", h.healthHandler)
+
+	authed := v1.Group("", mids.RequireAPIAuth)
+
+	// Every API token can read, only some scopes can change things. On top
+	// of that the role of the token owner is always enforced.
+	download := mids.RequireRole(users.RoleAdmin, users.RoleOperator)
+	runBackups := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return mids.RequireAPIScope(
+			auth.APITokenScopeRunBackups, auth.APITokenScopeAdmin,
+		)(mids.RequireRole(users.RoleAdmin, users.RoleOperator)(next))
+	}
+	restore := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return mids.RequireAPIScope(auth.APITokenScopeAdmin)(
+			mids.RequireRole(users.RoleAdmin, users.RoleOperator)(next),
+		)
+	}
+	admin := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return mids.RequireAPIScope(auth.APITokenScopeAdmin)(
+			mids.RequireRole(users.RoleAdmin)(next),
+		)
+	}
+
+	databases := authed.Group("/databases")
+	databases.GET("", h.listDatabasesHandler)
+	databases.POST("", h.createDatabaseHandler, admin)
+	databases.GET("/:databaseID", h.getDatabaseHandler)
+	databases.PUT("/:databaseID", h.updateDatabaseHandler, admin)
+	databases.DELETE("/:databaseID", h.deleteDatabaseHandler, admin)
+	databases.POST("/:databaseID/test", h.testDatabaseHandler, admin)
+
+	destinations := authed.Group("/destinations")
+	destinations.GET("", h.listDestinationsHandler)
+	destinations.POST("", h.createDestinationHandler, admin)
+	destinations.GET("/:destinationID", h.getDestinationHandler)
+	destinations.PUT("/:destinationID", h.updateDestinationHandler, admin)
+	destinations.DELETE("/:destinationID", h.deleteDestinationHandler, admin)
+	destinations.POST("/:destinationID/test", h.testDestinationHandler, admin)
+
+	backups := authed.Group("/backups")
+	backups.GET("", h.listBackupsHandler)
+	backups.POST("", h.createBackupHandler, admin)
+	backups.GET("/:backupID", h.getBackupHandler)
+	backups.PUT("/:backupID", h.updateBackupHandler, admin)
+	backups.DELETE("/:backupID", h.deleteBackupHandler, admin)
+	backups.POST("/:backupID/run", h.runBackupHandler, runBackups)
+	backups.POST("/:backupID/retention-preview", h.retentionPreviewHandler, admin)
+	backups.GET("/:backupID/wal-archive", h.walArchiveHandler)
+	backups.POST("/:backupID/pitr-restore", h.pitrRestoreHandler, admin)
+
+	executions := authed.Group("/executions")
+	executions.GET("", h.listExecutionsHandler)
+	executions.GET("/:executionID", h.getExecutionHandler)
+	executions.DELETE("/:executionID", h.deleteExecutionHandler, admin)
+	executions.POST("/:executionID/verify", h.verifyExecutionHandler, runBackups)
+	executions.POST("/:executionID/cancel", h.cancelExecutionHandler, runBackups)
+	executions.POST("/:executionID/unpin", h.unpinExecutionHandler, admin)
+	executions.GET("/:executionID/download", h.downloadExecutionHandler, download)
+	executions.GET("/:executionID/objects", h.listExecutionObjectsHandler, restore)
+	executions.POST("/:executionID/restore", h.restoreExecutionHandler, restore)
+
+	jobs := authed.Group("/jobs")
+	jobs.GET("", h.listJobsHandler)
+	jobs.DELETE("/:jobID", h.cancelJobHandler, runBackups)
+
+	restorations := authed.Group("/restorations")
+	restorations.GET("", h.listRestorationsHandler)
+
+	webhooks := authed.Group("/webhooks")
+	webhooks.GET("", h.listWebhooksHandler)
+	webhooks.POST("", h.createWebhookHandler, admin)
+	webhooks.GET("/:webhookID", h.getWebhookHandler)
+	webhooks.PUT("/:webhookID", h.updateWebhookHandler, admin)
+	webhooks.DELETE("/:webhookID", h.deleteWebhookHandler, admin)
 }
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/webhooks"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type webhookResponse struct {
+	ID        uuid.UUID   `json:"id"`
+	Name      string      `json:"name"`
+	IsActive  bool        `json:"is_active"`
+	EventType string      `json:"event_type"`
+	TargetIDs []uuid.UUID `json:"target_ids"`
+	Url       string      `json:"url"`
+	Method    string      `json:"method"`
+	Headers   *string     `json:"headers"`
+	Body      *string     `json:"body"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt *time.Time  `json:"updated_at"`
+}
+
+type webhookRequest struct {
+	Name      string      `json:"name" validate:"required"`
+	EventType string      `json:"event_type" validate:"required"`
+	TargetIDs []uuid.UUID `json:"target_ids" validate:"required,gt=0"`
+	IsActive  bool        `json:"is_active"`
+	Url       string      `json:"url" validate:"required,url"`
+	Method    string      `json:"method" validate:"required,oneof=GET POST"`
+	Headers   string      `json:"headers" validate:"omitempty,json"`
+	Body      string      `json:"body" validate:"omitempty,json"`
+}
+
+func (r webhookRequest) validate() error {
+	if err := validate.Struct(&r); err != nil {
+		return err
+	}
+	if _, ok := webhooks.FullEventTypes[r.EventType]; !ok {
+		return fmt.Errorf("invalid event type %q", r.EventType)
+	}
+	return nil
+}
+
+func newWebhookResponse(webhook dbgen.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:        webhook.ID,
+		Name:      webhook.Name,
+		IsActive:  webhook.IsActive,
+		EventType: webhook.EventType,
+		TargetIDs: webhook.TargetIds,
+		Url:       webhook.Url,
+		Method:    webhook.Method,
+		Headers:   nullString(webhook.Headers),
+		Body:      nullString(webhook.Body),
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: nullTime(webhook.UpdatedAt),
+	}
+}
+
+func (h *handlers) listWebhooksHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryData paginationQuery
+	if err := c.Bind(&queryData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	pagination, whs, err := h.servs.WebhooksService.PaginateWebhooks(
+		ctx, webhooks.PaginateWebhooksParams{
+			Page:  queryData.Page,
+			Limit: queryData.Limit,
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	items := make([]webhookResponse, 0, len(whs))
+	for _, wh := range whs {
+		items = append(items, newWebhookResponse(wh))
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Pagination paginateutil.PaginateResponse `json:"pagination"`
+		Items      []webhookResponse             `json:"items"`
+	}{pagination, items})
+}
+
+func (h *handlers) getWebhookHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	webhook, err := h.servs.WebhooksService.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, newWebhookResponse(webhook))
+}
+
+func (h *handlers) createWebhookHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData webhookRequest
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if err := reqData.validate(); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	webhook, err := h.servs.WebhooksService.CreateWebhook(
+		ctx, dbgen.WebhooksServiceCreateWebhookParams{
+			Name:      reqData.Name,
+			EventType: reqData.EventType,
+			TargetIds: reqData.TargetIDs,
+			IsActive:  reqData.IsActive,
+			Url:       reqData.Url,
+			Method:    reqData.Method,
+			Headers:   sql.NullString{String: reqData.Headers, Valid: true},
+			Body:      sql.NullString{String: reqData.Body, Valid: true},
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return c.JSON(http.StatusCreated, newWebhookResponse(webhook))
+}
+
+func (h *handlers) updateWebhookHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	var reqData webhookRequest
+	if err := c.Bind(&reqData); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if err := reqData.validate(); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	webhook, err := h.servs.WebhooksService.UpdateWebhook(
+		ctx, dbgen.WebhooksServiceUpdateWebhookParams{
+			WebhookID: webhookID,
+			Name:      sql.NullString{String: reqData.Name, Valid: true},
+			EventType: sql.NullString{String: reqData.EventType, Valid: true},
+			TargetIds: reqData.TargetIDs,
+			IsActive:  sql.NullBool{Bool: reqData.IsActive, Valid: true},
+			Url:       sql.NullString{String: reqData.Url, Valid: true},
+			Method:    sql.NullString{String: reqData.Method, Valid: true},
+			Headers:   sql.NullString{String: reqData.Headers, Valid: true},
+			Body:      sql.NullString{String: reqData.Body, Valid: true},
+		},
+	)
+	if err != nil {
+		return respondError(c, http.StatusUnprocessableEntity, err)
+	}
+
+	return c.JSON(http.StatusOK, newWebhookResponse(webhook))
+}
+
+func (h *handlers) deleteWebhookHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	webhookID, err := uuid.Parse(c.Param("webhookID"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.servs.WebhooksService.DeleteWebhook(ctx, webhookID); err != nil {
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
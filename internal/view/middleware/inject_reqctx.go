@@ -35,6 +35,8 @@ func (m *Middleware) InjectReqctx(next echo.HandlerFunc) echo.HandlerFunc {
 				Email:     user.Email,
 				CreatedAt: user.CreatedAt,
 				UpdatedAt: user.UpdatedAt,
+				Role:      user.Role,
+				IsActive:  user.IsActive,
 			}
 		}
 
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/labstack/echo/v4"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+)
+
+// RequireRole only lets through users that have one of the given roles.
+//
+// It must be used after RequireAuth or RequireAPIAuth.
+func (m *Middleware) RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			reqCtx := reqctx.GetCtx(c)
+
+			if slices.Contains(roles, reqCtx.User.Role) {
+				return next(c)
+			}
+
+			msg := "You don't have permission to perform this action"
+
+			if strings.HasPrefix(c.Path(), pathutil.BuildPath("/api/")) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": msg})
+			}
+
+			if htmx.ServerGetIsHtmxRequest(c.Request().Header) {
+				return respondhtmx.ToastError(c, msg)
+			}
+
+			return c.String(http.StatusForbidden, msg)
+		}
+	}
+}
@@ -16,6 +16,10 @@ type Ctx struct {
 	IsAuthed      bool
 	SessionID     uuid.UUID
 	User          dbgen.User
+
+	// APITokenScope is set when the request was authenticated with an API
+	// token instead of a session cookie.
+	APITokenScope string
 }
 
 // SetCtx inserts values into the Echo request context.
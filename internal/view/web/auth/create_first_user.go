@@ -5,6 +5,7 @@ import (
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
 	"github.com/eduardolat/pgbackweb/internal/logger"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
 	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
 	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
 	"github.com/eduardolat/pgbackweb/internal/validate"
@@ -133,6 +134,7 @@ func (h *handlers) createFirstUserHandler(c echo.Context) error {
 		Name:     formData.Name,
 		Email:    formData.Email,
 		Password: formData.Password,
+		Role:     users.RoleAdmin,
 	})
 	if err != nil {
 		return respondhtmx.ToastError(c, err.Error())
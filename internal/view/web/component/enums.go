@@ -29,12 +29,13 @@ var (
 	DropdownPositionLeft   = dropdownPosition{"left"}
 	DropdownPositionRight  = dropdownPosition{"right"}
 
-	InputTypeText     = inputType{"text"}
-	InputTypePassword = inputType{"password"}
-	InputTypeEmail    = inputType{"email"}
-	InputTypeNumber   = inputType{"number"}
-	InputTypeTel      = inputType{"tel"}
-	InputTypeUrl      = inputType{"url"}
+	InputTypeText          = inputType{"text"}
+	InputTypePassword      = inputType{"password"}
+	InputTypeEmail         = inputType{"email"}
+	InputTypeNumber        = inputType{"number"}
+	InputTypeTel           = inputType{"tel"}
+	InputTypeUrl           = inputType{"url"}
+	InputTypeDatetimeLocal = inputType{"datetime-local"}
 
 	bgBase100 = bgBase{"bg-base-100"}
 	bgBase200 = bgBase{"bg-base-200"}
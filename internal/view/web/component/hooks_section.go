@@ -0,0 +1,129 @@
+package component
+
+import (
+	"strings"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
+	nodx "github.com/nodxdev/nodxgo"
+)
+
+// HooksFormData binds the fields of HooksSection, one hook of each stage and
+// type.
+type HooksFormData struct {
+	PreSQL               string `form:"hook_pre_sql"`
+	PreSQLOnFailure      string `form:"hook_pre_sql_on_failure"`
+	PreCommand           string `form:"hook_pre_command"`
+	PreCommandOnFailure  string `form:"hook_pre_command_on_failure"`
+	PostSQL              string `form:"hook_post_sql"`
+	PostSQLOnFailure     string `form:"hook_post_sql_on_failure"`
+	PostCommand          string `form:"hook_post_command"`
+	PostCommandOnFailure string `form:"hook_post_command_on_failure"`
+}
+
+// Hooks returns the hooks of the form, the SQL hook of a stage runs before
+// its command and the empty fields are skipped.
+func (f HooksFormData) Hooks() hooks.Hooks {
+	h := hooks.Hooks{}
+	add := func(stage, hookType, script, onFailure string) {
+		if strings.TrimSpace(script) == "" {
+			return
+		}
+		if onFailure == "" {
+			onFailure = hooks.OnFailureAbort
+		}
+		h = append(h, hooks.Hook{
+			Stage: stage, Type: hookType, Script: script, OnFailure: onFailure,
+		})
+	}
+
+	add(hooks.StagePre, hooks.TypeSQL, f.PreSQL, f.PreSQLOnFailure)
+	add(hooks.StagePre, hooks.TypeCommand, f.PreCommand, f.PreCommandOnFailure)
+	add(hooks.StagePost, hooks.TypeSQL, f.PostSQL, f.PostSQLOnFailure)
+	add(hooks.StagePost, hooks.TypeCommand, f.PostCommand, f.PostCommandOnFailure)
+
+	return h
+}
+
+// HooksSection renders the fields of the pre and post hooks of a backup or
+// restoration, prefilled with the current hooks. The action is the dump or
+// the restore the hooks run around.
+func HooksSection(action string, current hooks.Hooks) nodx.Node {
+	field := func(stage, hookType, label, placeholder string) nodx.Node {
+		name := "hook_" + stage + "_" + hookType
+		hook, ok := current.Find(stage, hookType)
+
+		return nodx.Div(
+			TextareaControl(TextareaControlParams{
+				Name:        name,
+				Label:       label,
+				Placeholder: placeholder,
+				Children: []nodx.Node{
+					nodx.Class("font-mono"),
+					nodx.If(ok, nodx.Text(hook.Script)),
+				},
+			}),
+			SelectControl(SelectControlParams{
+				Name:  name + "_on_failure",
+				Label: "If it fails",
+				Children: []nodx.Node{
+					nodx.Option(
+						nodx.Value(hooks.OnFailureAbort),
+						nodx.Text("Abort"),
+						nodx.If(hook.OnFailure != hooks.OnFailureContinue, nodx.Selected("")),
+					),
+					nodx.Option(
+						nodx.Value(hooks.OnFailureContinue),
+						nodx.Text("Continue"),
+						nodx.If(hook.OnFailure == hooks.OnFailureContinue, nodx.Selected("")),
+					),
+				},
+			}),
+		)
+	}
+
+	return nodx.Div(
+		nodx.Class("pt-4"),
+		nodx.Div(
+			nodx.Class("flex justify-start items-center space-x-1"),
+			H2Text("Hooks"),
+			HelpButtonModal(HelpButtonModalParams{
+				ModalTitle: "Hooks",
+				Children:   hooksHelp(action),
+			}),
+		),
+
+		nodx.Div(
+			nodx.Class("mt-2 grid grid-cols-2 gap-2"),
+			field(hooks.StagePre, hooks.TypeSQL, "Pre SQL", "CHECKPOINT;"),
+			field(hooks.StagePre, hooks.TypeCommand, "Pre command", "curl -fsS https://example.com/maintenance/on"),
+			field(hooks.StagePost, hooks.TypeSQL, "Post SQL", "ANALYZE;"),
+			field(hooks.StagePost, hooks.TypeCommand, "Post command", "curl -fsS https://example.com/maintenance/off"),
+		),
+	)
+}
+
+func hooksHelp(action string) []nodx.Node {
+	return []nodx.Node{
+		PText(`
+			The pre hooks run before the ` + action + ` and the post hooks after it,
+			the SQL hook of a stage runs before its command. Their output is added
+			to the log of the run.
+		`),
+		PText(`
+			The SQL hooks run with psql in the database, each one in its own
+			session, and are only supported for PostgreSQL databases.
+		`),
+		PText(`
+			The commands run with sh on the PG Back Web server in an empty
+			temporary directory, with only the PATH and the PBW_HOOK_STAGE,
+			PBW_EXECUTION_ID, PBW_BACKUP_ID, PBW_DATABASE_NAME, PBW_RESTORATION_ID
+			and PBW_*_STATUS variables that apply. They are disabled unless the
+			PBW_ENABLE_COMMAND_HOOKS environment variable is set.
+		`),
+		PText(`
+			A failed hook set to abort fails the run. Once the pre hooks ran, the
+			post hooks always run so they can undo what the pre hooks did. Every
+			hook is stopped after 10 minutes.
+		`),
+	}
+}
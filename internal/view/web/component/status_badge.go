@@ -15,6 +15,14 @@ func StatusBadge(status string) nodx.Node {
 		class = "badge-error"
 	case "deleted":
 		class = "badge-warning"
+	case "timed_out":
+		class = "badge-error badge-outline"
+	case "cancelled":
+		class = "badge-neutral badge-outline"
+	case "queued":
+		class = "badge-info badge-outline"
+	case "waiting":
+		class = "badge-warning badge-outline"
 	default:
 		class = "badge-neutral"
 	}
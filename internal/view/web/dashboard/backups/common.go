@@ -1,8 +1,15 @@
 package backups
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/backups"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
 	nodx "github.com/nodxdev/nodxgo"
 	lucide "github.com/nodxdev/nodxgo-lucide"
@@ -29,6 +36,48 @@ func localBackupsHelp() []nodx.Node {
 	}
 }
 
+func backupModeHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				Logical backups dump one database with pg_dump on every scheduled run,
+				so the most data you can lose is everything since the last run.
+			`),
+
+			component.PText(`
+				Physical backups are only available for PostgreSQL. Every scheduled
+				run takes a base backup of the whole cluster with pg_basebackup, and
+				in between PG Back Web streams the write-ahead log (WAL) with
+				pg_receivewal and archives every completed segment in the same
+				destination. The cluster can then be recovered to any point in time
+				between the oldest base backup and the last archived segment.
+			`),
+
+			component.PText(`
+				The database user needs the REPLICATION attribute, the server must
+				allow replication connections from PG Back Web in pg_hba.conf and a
+				replication slot named after the backup task is created so no WAL is
+				lost while PG Back Web is down. Physical backups need the Zstandard,
+				Gzip or None compression, the pg_dump options are not used, and
+				clusters with additional tablespaces are not supported.
+			`),
+
+			nodx.Div(
+				nodx.Class("flex justify-end"),
+				nodx.A(
+					nodx.Class("btn btn-ghost"),
+					nodx.Href("https://www.postgresql.org/docs/current/continuous-archiving.html"),
+					nodx.Target("_blank"),
+					component.SpanText("Learn more about point-in-time recovery"),
+					lucide.ExternalLink(nodx.Class("ml-1")),
+				),
+			),
+		),
+	}
+}
+
 func cronExpressionHelp() []nodx.Node {
 	return []nodx.Node{
 		component.PText(`
@@ -109,7 +158,7 @@ func destinationDirectoryHelp() []nodx.Node {
 					"font-mono":             true,
 				},
 				component.BText(
-					"/backups/<destination-directory>/<YYYY>/<MM>/<DD>/dump-<random-suffix>.zip",
+					"/backups/<destination-directory>/<YYYY>/<MM>/<DD>/dump-<random-suffix>.<extension>",
 				),
 			),
 		),
@@ -128,31 +177,180 @@ func destinationDirectoryHelp() []nodx.Node {
 					"font-mono":             true,
 				},
 				component.BText(
-					"s3://<bucket>/<destination-directory>/<YYYY>/<MM>/<DD>/dump-<random-suffix>.zip",
+					"s3://<bucket>/<destination-directory>/<YYYY>/<MM>/<DD>/dump-<random-suffix>.<extension>",
 				),
 			),
 		),
 	}
 }
 
-func retentionDaysHelp() []nodx.Node {
+func replicasHelp() []nodx.Node {
 	return []nodx.Node{
 		nodx.Div(
 			nodx.Class("space-y-2"),
 
 			component.PText(`
-				Retention days specifies the number of days to keep backup files before
-				they are automatically deleted. This ensures that old backups are removed
-				to save storage space. The retention period is evaluated by execution.
+				Replicas are additional destinations where every execution of the
+				backup is copied, next to the destination of the backup itself. The
+				dump is taken only once and uploaded to every destination in
+				parallel, encrypted with the key of each destination.
 			`),
 
 			component.PText(`
-				If you set the retention days to 0, the backups will never be deleted.
+				Keeping copies in different providers (or a local copy and a remote
+				one) lets you follow the 3-2-1 rule. An execution succeeds if at
+				least one copy is uploaded, and downloads, restorations and
+				verifications fall back to the next healthy copy when one is missing.
+			`),
+
+			component.PText(`
+				The WAL archive of physical backups is only stored in the
+				destination of the backup itself.
 			`),
 		),
 	}
 }
 
+// replicasSelect renders the select of the replicas of a backup, the
+// selected values are either "local" or destination IDs.
+func replicasSelect(
+	destinations []dbgen.DestinationsServiceGetAllDestinationsRow,
+	selected []string,
+) nodx.Node {
+	isSelected := func(value string) nodx.Node {
+		return nodx.If(slices.Contains(selected, value), nodx.Selected(""))
+	}
+
+	return component.SelectControl(component.SelectControlParams{
+		Name:               "replicas",
+		Label:              "Replicas",
+		HelpText:           "Additional destinations where every execution is copied",
+		HelpButtonChildren: replicasHelp(),
+		Children: []nodx.Node{
+			nodx.Multiple(""),
+			nodx.Option(
+				nodx.Value(backups.ReplicaLocal),
+				nodx.Text("Local"),
+				isSelected(backups.ReplicaLocal),
+			),
+			nodx.Map(
+				destinations,
+				func(dest dbgen.DestinationsServiceGetAllDestinationsRow) nodx.Node {
+					return nodx.Option(
+						nodx.Value(dest.ID.String()),
+						nodx.Text(dest.Name),
+						isSelected(dest.ID.String()),
+					)
+				},
+			),
+		},
+	})
+}
+
+func retentionHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				The retention policy decides which backup files are automatically
+				deleted to save storage space. It is evaluated every hour and a
+				successful execution is kept while it matches any of the rules.
+			`),
+
+			component.PText(`
+				Retention days keeps every execution that finished in the last N days.
+				Keep last keeps the N most recent executions.
+			`),
+
+			component.PText(`
+				Keep daily, weekly, monthly and yearly keep the most recent execution
+				of each of the last N days, weeks, months and years that have one
+				(grandfather-father-son). The periods are calculated in the time zone
+				of the backup.
+			`),
+
+			component.PText(`
+				Failed executions are only deleted by the retention days rule, and the
+				minimum keep most recent successful executions are never deleted, so a
+				run of failed executions can't leave you without a good backup.
+			`),
+
+			component.PText(`
+				If every rule is set to 0, the backups will never be deleted.
+			`),
+		),
+	}
+}
+
+// retentionSection renders the inputs of the retention policy of a backup,
+// the children are added after the inputs.
+func retentionSection(
+	policy executions.RetentionPolicy, children ...nodx.Node,
+) nodx.Node {
+	input := func(name, label string, value, max int, helpText string) nodx.Node {
+		return component.InputControl(component.InputControlParams{
+			Name:     name,
+			Label:    label,
+			Required: true,
+			Type:     component.InputTypeNumber,
+			Pattern:  "[0-9]+",
+			HelpText: helpText,
+			Children: []nodx.Node{
+				nodx.Min("0"),
+				nodx.Max(fmt.Sprintf("%d", max)),
+				nodx.Value(fmt.Sprintf("%d", value)),
+			},
+		})
+	}
+
+	return nodx.Div(
+		nodx.Class("pt-4"),
+		nodx.Div(
+			nodx.Class("flex justify-start items-center space-x-1"),
+			component.H2Text("Retention"),
+			component.HelpButtonModal(component.HelpButtonModalParams{
+				ModalTitle: "Retention policy",
+				Children:   retentionHelp(),
+			}),
+		),
+
+		nodx.Div(
+			nodx.Class("mt-2 grid grid-cols-2 gap-2"),
+			input(
+				"retention_days", "Retention days", policy.Days,
+				executions.MaxRetentionDays, "Keep everything newer than N days",
+			),
+			input(
+				"retention_keep_last", "Keep last", policy.Last,
+				executions.MaxRetentionCount, "Keep the N most recent executions",
+			),
+			input(
+				"retention_keep_daily", "Keep daily", policy.Daily,
+				executions.MaxRetentionCount, "One per day for N days",
+			),
+			input(
+				"retention_keep_weekly", "Keep weekly", policy.Weekly,
+				executions.MaxRetentionCount, "One per week for N weeks",
+			),
+			input(
+				"retention_keep_monthly", "Keep monthly", policy.Monthly,
+				executions.MaxRetentionCount, "One per month for N months",
+			),
+			input(
+				"retention_keep_yearly", "Keep yearly", policy.Yearly,
+				executions.MaxRetentionCount, "One per year for N years",
+			),
+			input(
+				"retention_min_keep", "Minimum keep", policy.MinKeep,
+				executions.MaxRetentionCount, "Successful executions never deleted",
+			),
+		),
+
+		nodx.Group(children...),
+	)
+}
+
 func pgDumpOptionsHelp() []nodx.Node {
 	return []nodx.Node{
 		nodx.Div(
@@ -168,6 +366,15 @@ func pgDumpOptionsHelp() []nodx.Node {
 				PG Back Web does not pass any options so the backups are full backups.
 			`),
 
+			component.PText(`
+				The plain format creates a SQL script restored with psql. The custom,
+				directory and tar formats create archives restored with pg_restore,
+				which are faster to restore. The custom and directory formats can be
+				restored using several parallel jobs, and the directory format is also
+				dumped in parallel. For the archive formats --clean, --if-exists and
+				--create are applied when restoring.
+			`),
+
 			nodx.Div(
 				nodx.Class("flex justify-end"),
 				nodx.A(
@@ -181,3 +388,63 @@ func pgDumpOptionsHelp() []nodx.Node {
 		),
 	}
 }
+
+func dumpFormatSelectOptions(selected string) nodx.Node {
+	return nodx.Map(
+		[]string{
+			postgres.DumpFormatPlain, postgres.DumpFormatCustom,
+			postgres.DumpFormatDirectory, postgres.DumpFormatTar,
+		},
+		func(format string) nodx.Node {
+			return nodx.Option(
+				nodx.Value(format),
+				nodx.Text(postgres.DumpFormats[format]),
+				nodx.If(format == selected, nodx.Selected("")),
+			)
+		},
+	)
+}
+
+func compressionSelectOptions(selected string) nodx.Node {
+	return nodx.Map(
+		[]string{
+			compression.CodecZstd, compression.CodecGzip,
+			compression.CodecZip, compression.CodecNone,
+		},
+		func(codec string) nodx.Node {
+			return nodx.Option(
+				nodx.Value(codec),
+				nodx.Text(compression.Codecs[codec]),
+				nodx.If(codec == selected, nodx.Selected("")),
+			)
+		},
+	)
+}
+
+func compressionHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				Zstandard is the recommended compression, it is faster and produces
+				smaller files than the rest. Gzip is available everywhere, and ZIP is
+				the format used by older versions of PG Back Web. None stores the
+				dump without compression, which is useful when the dump is already
+				compressed, like with the custom and directory formats.
+			`),
+
+			component.PText(`
+				The level is only used by Zstandard (1 to 19) and Gzip (1 to 9),
+				higher levels produce smaller files but take longer. Use 0 for the
+				default level of the codec.
+			`),
+
+			component.PText(`
+				The file extension of every backup depends on the compression, so
+				restorations always use the right decoder even if the compression of
+				the backup task is changed later.
+			`),
+		),
+	}
+}
@@ -1,10 +1,16 @@
 package backups
 
 import (
+	"database/sql"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/backups"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
 	"github.com/eduardolat/pgbackweb/internal/staticdata"
 	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
 	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
@@ -26,18 +32,36 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 		DatabaseID     uuid.UUID `form:"database_id" validate:"required,uuid"`
 		DestinationID  uuid.UUID `form:"destination_id" validate:"omitempty,uuid"`
 		IsLocal        string    `form:"is_local" validate:"required,oneof=true false"`
+		Mode           string    `form:"mode" validate:"required"`
 		Name           string    `form:"name" validate:"required"`
 		CronExpression string    `form:"cron_expression" validate:"required"`
 		TimeZone       string    `form:"time_zone" validate:"required"`
 		IsActive       string    `form:"is_active" validate:"required,oneof=true false"`
 		DestDir        string    `form:"dest_dir" validate:"required"`
-		RetentionDays  int16     `form:"retention_days"`
+		RetentionDays  int16     `form:"retention_days" validate:"min=0"`
+		KeepLast       int16     `form:"retention_keep_last" validate:"min=0"`
+		KeepDaily      int16     `form:"retention_keep_daily" validate:"min=0"`
+		KeepWeekly     int16     `form:"retention_keep_weekly" validate:"min=0"`
+		KeepMonthly    int16     `form:"retention_keep_monthly" validate:"min=0"`
+		KeepYearly     int16     `form:"retention_keep_yearly" validate:"min=0"`
+		MinKeep        int16     `form:"retention_min_keep" validate:"min=0"`
 		OptDataOnly    string    `form:"opt_data_only" validate:"required,oneof=true false"`
 		OptSchemaOnly  string    `form:"opt_schema_only" validate:"required,oneof=true false"`
 		OptClean       string    `form:"opt_clean" validate:"required,oneof=true false"`
 		OptIfExists    string    `form:"opt_if_exists" validate:"required,oneof=true false"`
 		OptCreate      string    `form:"opt_create" validate:"required,oneof=true false"`
 		OptNoComments  string    `form:"opt_no_comments" validate:"required,oneof=true false"`
+		OptFormat      string    `form:"opt_format" validate:"required"`
+		OptJobs        int16     `form:"opt_jobs" validate:"required,min=1"`
+		Compression    string    `form:"compression" validate:"required"`
+		CompLevel      int16     `form:"compression_level" validate:"min=0"`
+		MaxDuration    int32     `form:"max_duration_minutes" validate:"min=0"`
+		Priority       int16     `form:"priority" validate:"min=0,max=100"`
+		RetryAttempts  int16     `form:"retry_max_attempts" validate:"min=0,max=10"`
+		RetryBackoff   int32     `form:"retry_backoff_seconds" validate:"min=0,max=86400"`
+		Replicas       []string  `form:"replicas"`
+		Hooks          component.HooksFormData
+		Selection      dumpSelectionFormData
 	}
 	if err := c.Bind(&formData); err != nil {
 		return respondhtmx.ToastError(c, err.Error())
@@ -46,31 +70,78 @@ func (h *handlers) createBackupHandler(c echo.Context) error {
 		return respondhtmx.ToastError(c, err.Error())
 	}
 
-	_, err := h.servs.BackupsService.CreateBackup(
+	destinationID := uuid.NullUUID{
+		Valid: formData.IsLocal == "false", UUID: formData.DestinationID,
+	}
+	replicas, err := backups.ParseReplicas(formData.Replicas)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	err = backups.ValidateReplicas(
+		formData.IsLocal == "true", destinationID, replicas,
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	selection := formData.Selection.params()
+	err = h.checkDumpSelection(ctx, formData.DatabaseID, selection)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	backup, err := h.servs.BackupsService.CreateBackup(
 		ctx, dbgen.BackupsServiceCreateBackupParams{
-			DatabaseID: formData.DatabaseID,
-			DestinationID: uuid.NullUUID{
-				Valid: formData.IsLocal == "false", UUID: formData.DestinationID,
-			},
-			IsLocal:        formData.IsLocal == "true",
-			Name:           formData.Name,
-			CronExpression: formData.CronExpression,
-			TimeZone:       formData.TimeZone,
-			IsActive:       formData.IsActive == "true",
-			DestDir:        formData.DestDir,
-			RetentionDays:  formData.RetentionDays,
-			OptDataOnly:    formData.OptDataOnly == "true",
-			OptSchemaOnly:  formData.OptSchemaOnly == "true",
-			OptClean:       formData.OptClean == "true",
-			OptIfExists:    formData.OptIfExists == "true",
-			OptCreate:      formData.OptCreate == "true",
-			OptNoComments:  formData.OptNoComments == "true",
+			DatabaseID:           formData.DatabaseID,
+			DestinationID:        destinationID,
+			IsLocal:              formData.IsLocal == "true",
+			Name:                 formData.Name,
+			CronExpression:       formData.CronExpression,
+			TimeZone:             formData.TimeZone,
+			IsActive:             formData.IsActive == "true",
+			DestDir:              formData.DestDir,
+			RetentionDays:        formData.RetentionDays,
+			RetentionKeepLast:    formData.KeepLast,
+			RetentionKeepDaily:   formData.KeepDaily,
+			RetentionKeepWeekly:  formData.KeepWeekly,
+			RetentionKeepMonthly: formData.KeepMonthly,
+			RetentionKeepYearly:  formData.KeepYearly,
+			RetentionMinKeep:     formData.MinKeep,
+			OptDataOnly:          formData.OptDataOnly == "true",
+			OptSchemaOnly:        formData.OptSchemaOnly == "true",
+			OptClean:             formData.OptClean == "true",
+			OptIfExists:          formData.OptIfExists == "true",
+			OptCreate:            formData.OptCreate == "true",
+			OptNoComments:        formData.OptNoComments == "true",
+			OptFormat:            formData.OptFormat,
+			OptJobs:              formData.OptJobs,
+			Compression:          formData.Compression,
+			CompressionLevel:     formData.CompLevel,
+			Mode:                 formData.Mode,
+			MaxDurationMinutes:   formData.MaxDuration,
+			Priority:             formData.Priority,
+			RetryMaxAttempts:     sql.NullInt16{Int16: formData.RetryAttempts, Valid: true},
+			RetryBackoffSeconds:  sql.NullInt32{Int32: formData.RetryBackoff, Valid: true},
+			Hooks:                string(formData.Hooks.Hooks().Marshal()),
+			OptSchemas:           selection.Schemas,
+			OptExcludeSchemas:    selection.ExcludeSchemas,
+			OptTables:            selection.Tables,
+			OptExcludeTables:     selection.ExcludeTables,
+			OptExcludeTableData:  selection.ExcludeTableData,
+			OptNoOwner:           selection.NoOwner,
+			OptNoPrivileges:      selection.NoPrivileges,
+			OptRole:              selection.Role,
 		},
 	)
 	if err != nil {
 		return respondhtmx.ToastError(c, err.Error())
 	}
 
+	err = h.servs.BackupsService.SetBackupReplicas(ctx, backup.ID, replicas)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
 	return respondhtmx.Redirect(c, pathutil.BuildPath("/dashboard/backups"))
 }
 
@@ -137,6 +208,26 @@ func createBackupForm(
 			},
 		}),
 
+		component.SelectControl(component.SelectControlParams{
+			Name:               "mode",
+			Label:              "Mode",
+			Required:           true,
+			HelpText:           "It can't be changed once the backup task is created",
+			HelpButtonChildren: backupModeHelp(),
+			Children: []nodx.Node{
+				nodx.Map(
+					[]string{postgres.BackupModeLogical, postgres.BackupModePhysical},
+					func(mode string) nodx.Node {
+						return nodx.Option(
+							nodx.Value(mode),
+							nodx.Text(postgres.BackupModes[mode]),
+							nodx.If(mode == postgres.BackupModeLogical, nodx.Selected("")),
+						)
+					},
+				),
+			},
+		}),
+
 		component.SelectControl(component.SelectControlParams{
 			Name:     "is_local",
 			Label:    "Local backup",
@@ -167,6 +258,8 @@ func createBackupForm(
 			}),
 		),
 
+		replicasSelect(destinations, nil),
+
 		component.InputControl(component.InputControlParams{
 			Name:               "cron_expression",
 			Label:              "Cron expression",
@@ -210,17 +303,75 @@ func createBackupForm(
 			HelpButtonChildren: destinationDirectoryHelp(),
 		}),
 
-		component.InputControl(component.InputControlParams{
-			Name:               "retention_days",
-			Label:              "Retention days",
-			Placeholder:        "30",
+		component.SelectControl(component.SelectControlParams{
+			Name:               "compression",
+			Label:              "Compression",
 			Required:           true,
-			Type:               component.InputTypeNumber,
-			Pattern:            "[0-9]+",
-			HelpButtonChildren: retentionDaysHelp(),
+			HelpButtonChildren: compressionHelp(),
+			Children: []nodx.Node{
+				compressionSelectOptions(compression.CodecZstd),
+			},
+		}),
+
+		component.InputControl(component.InputControlParams{
+			Name:     "compression_level",
+			Label:    "Compression level",
+			Required: true,
+			Type:     component.InputTypeNumber,
+			HelpText: "Use 0 for the default level of the compression",
+			Children: []nodx.Node{
+				nodx.Min("0"),
+				nodx.Max(fmt.Sprintf("%d", compression.MaxLevelZstd)),
+				nodx.Value("0"),
+			},
+		}),
+
+		component.InputControl(component.InputControlParams{
+			Name:     "priority",
+			Label:    "Priority",
+			Required: true,
+			Type:     component.InputTypeNumber,
+			HelpText: "From 0 to 100, queued runs with a higher priority start first",
 			Children: []nodx.Node{
 				nodx.Min("0"),
-				nodx.Max("36500"),
+				nodx.Max("100"),
+				nodx.Value("0"),
+			},
+		}),
+
+		component.InputControl(component.InputControlParams{
+			Name:        "retry_max_attempts",
+			Label:       "Max attempts",
+			Placeholder: "Server default",
+			Type:        component.InputTypeNumber,
+			HelpText:    "From 1 to 10, failed or timed out executions are retried until this many attempts were made, use 1 to never retry or leave it empty for the server default",
+			Children: []nodx.Node{
+				nodx.Min("1"),
+				nodx.Max("10"),
+			},
+		}),
+
+		component.InputControl(component.InputControlParams{
+			Name:        "retry_backoff_seconds",
+			Label:       "Retry backoff (seconds)",
+			Placeholder: "Server default",
+			Type:        component.InputTypeNumber,
+			HelpText:    "Wait before the first retry, it doubles after every failed attempt up to 6 hours, leave it empty for the server default",
+			Children: []nodx.Node{
+				nodx.Min("1"),
+				nodx.Max("86400"),
+			},
+		}),
+
+		component.InputControl(component.InputControlParams{
+			Name:     "max_duration_minutes",
+			Label:    "Max duration (minutes)",
+			Required: true,
+			Type:     component.InputTypeNumber,
+			HelpText: "Executions running for longer are stopped and marked as timed out, use 0 for no limit",
+			Children: []nodx.Node{
+				nodx.Min("0"),
+				nodx.Value("0"),
 			},
 		}),
 
@@ -234,6 +385,10 @@ func createBackupForm(
 			},
 		}),
 
+		retentionSection(executions.RetentionPolicy{MinKeep: 1}),
+
+		component.HooksSection("dump", nil),
+
 		nodx.Div(
 			nodx.Class("pt-4"),
 			nodx.Div(
@@ -301,9 +456,33 @@ func createBackupForm(
 						yesNoOptions(),
 					},
 				}),
+
+				component.SelectControl(component.SelectControlParams{
+					Name:     "opt_format",
+					Label:    "--format",
+					Required: true,
+					Children: []nodx.Node{
+						dumpFormatSelectOptions(postgres.DumpFormatPlain),
+					},
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:     "opt_jobs",
+					Label:    "--jobs",
+					Required: true,
+					Type:     component.InputTypeNumber,
+					HelpText: "Only for the custom and directory formats",
+					Children: []nodx.Node{
+						nodx.Min("1"),
+						nodx.Max(fmt.Sprintf("%d", postgres.MaxJobs)),
+						nodx.Value("1"),
+					},
+				}),
 			),
 		),
 
+		dumpSelectionSection("create", postgres.DumpParams{}),
+
 		nodx.Div(
 			nodx.Class("flex justify-end items-center space-x-2 pt-2"),
 			component.HxLoadingMd(),
@@ -0,0 +1,238 @@
+package backups
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+// dumpSelectionFormData binds the fields of dumpSelectionSection, the
+// patterns are written one per line.
+type dumpSelectionFormData struct {
+	Schemas          string `form:"opt_schemas"`
+	ExcludeSchemas   string `form:"opt_exclude_schemas"`
+	Tables           string `form:"opt_tables"`
+	ExcludeTables    string `form:"opt_exclude_tables"`
+	ExcludeTableData string `form:"opt_exclude_table_data"`
+	NoOwner          string `form:"opt_no_owner" validate:"required,oneof=true false"`
+	NoPrivileges     string `form:"opt_no_privileges" validate:"required,oneof=true false"`
+	Role             string `form:"opt_role"`
+}
+
+// params returns the selection options of the form as dump parameters.
+func (f dumpSelectionFormData) params() postgres.DumpParams {
+	return postgres.DumpParams{
+		Schemas:          postgres.ParsePatternList(f.Schemas),
+		ExcludeSchemas:   postgres.ParsePatternList(f.ExcludeSchemas),
+		Tables:           postgres.ParsePatternList(f.Tables),
+		ExcludeTables:    postgres.ParsePatternList(f.ExcludeTables),
+		ExcludeTableData: postgres.ParsePatternList(f.ExcludeTableData),
+		NoOwner:          f.NoOwner == "true",
+		NoPrivileges:     f.NoPrivileges == "true",
+		Role:             strings.TrimSpace(f.Role),
+	}
+}
+
+// checkDumpSelection checks the selection options of the form against the
+// catalog of the database before the backup is saved, so a typo doesn't go
+// unnoticed until the first dump.
+func (h *handlers) checkDumpSelection(
+	ctx context.Context, databaseID uuid.UUID, params postgres.DumpParams,
+) error {
+	unmatched, err := h.servs.DatabasesService.CheckDumpSelection(
+		ctx, databaseID, params,
+	)
+	if err != nil {
+		return err
+	}
+	if len(unmatched) > 0 {
+		return fmt.Errorf(
+			"nothing in the database matches %s", strings.Join(unmatched, ", "),
+		)
+	}
+	return nil
+}
+
+func (h *handlers) checkDumpSelectionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var formData struct {
+		DatabaseID uuid.UUID `form:"database_id" validate:"required,uuid"`
+		Selection  dumpSelectionFormData
+	}
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	unmatched, err := h.servs.DatabasesService.CheckDumpSelection(
+		ctx, formData.DatabaseID, formData.Selection.params(),
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, dumpSelectionResult(unmatched))
+}
+
+func dumpSelectionResult(unmatched []string) nodx.Node {
+	if len(unmatched) == 0 {
+		return component.PText("Every pattern matches something in the database.")
+	}
+
+	return nodx.Div(
+		nodx.Class("space-y-1"),
+		component.PText("Nothing in the database matches:"),
+		nodx.Ul(
+			nodx.Class("list-disc list-inside font-mono text-sm"),
+			nodx.Map(unmatched, func(option string) nodx.Node {
+				return nodx.Li(nodx.Text(option))
+			}),
+		),
+	)
+}
+
+// dumpSelectionSection renders the fields of the object selection options of
+// a backup, prefilled with the current options. The key makes the IDs of the
+// section unique in the page.
+func dumpSelectionSection(key string, current postgres.DumpParams) nodx.Node {
+	patterns := func(name, flag, placeholder string, values []string) nodx.Node {
+		return component.TextareaControl(component.TextareaControlParams{
+			Name:        name,
+			Label:       flag,
+			Placeholder: placeholder,
+			HelpText:    "One pattern per line",
+			Children: []nodx.Node{
+				nodx.Class("font-mono"),
+				nodx.Text(strings.Join(values, "\n")),
+			},
+		})
+	}
+
+	yesNo := func(name, label string, value bool) nodx.Node {
+		return component.SelectControl(component.SelectControlParams{
+			Name:     name,
+			Label:    label,
+			Required: true,
+			Children: []nodx.Node{
+				nodx.Option(
+					nodx.Value("true"), nodx.Text("Yes"),
+					nodx.If(value, nodx.Selected("")),
+				),
+				nodx.Option(
+					nodx.Value("false"), nodx.Text("No"),
+					nodx.If(!value, nodx.Selected("")),
+				),
+			},
+		})
+	}
+
+	targetID := "dump-selection-check-" + key
+
+	return nodx.Div(
+		nodx.Class("pt-4"),
+		nodx.Div(
+			nodx.Class("flex justify-start items-center space-x-1"),
+			component.H2Text("Objects"),
+			component.HelpButtonModal(component.HelpButtonModalParams{
+				ModalTitle: "Object selection",
+				Children:   dumpSelectionHelp(),
+			}),
+		),
+
+		nodx.Div(
+			nodx.Class("mt-2 grid grid-cols-2 gap-2"),
+			patterns("opt_schemas", "--schema", "public", current.Schemas),
+			patterns(
+				"opt_exclude_schemas", "--exclude-schema", "audit",
+				current.ExcludeSchemas,
+			),
+			patterns("opt_tables", "--table", "public.orders", current.Tables),
+			patterns(
+				"opt_exclude_tables", "--exclude-table", "public.tmp_*",
+				current.ExcludeTables,
+			),
+			patterns(
+				"opt_exclude_table_data", "--exclude-table-data", "public.logs",
+				current.ExcludeTableData,
+			),
+			component.InputControl(component.InputControlParams{
+				Name:        "opt_role",
+				Label:       "--role",
+				Placeholder: "backup_role",
+				Type:        component.InputTypeText,
+				HelpText:    "Empty to use the role of the connection",
+				Children: []nodx.Node{
+					nodx.Value(current.Role),
+				},
+			}),
+			yesNo("opt_no_owner", "--no-owner", current.NoOwner),
+			yesNo("opt_no_privileges", "--no-privileges", current.NoPrivileges),
+		),
+
+		nodx.Div(
+			nodx.Class("mt-2 space-y-2"),
+			nodx.Button(
+				htmx.HxPost(pathutil.BuildPath("/dashboard/backups/check-selection")),
+				htmx.HxInclude("closest form"),
+				htmx.HxTarget("#"+targetID),
+				htmx.HxDisabledELT("this"),
+				nodx.Type("button"),
+				nodx.Class("btn btn-neutral btn-outline btn-sm"),
+				component.SpanText("Check against the database"),
+				lucide.DatabaseZap(),
+			),
+			nodx.Div(nodx.Id(targetID)),
+		),
+	)
+}
+
+func dumpSelectionHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				By default every object of the database is dumped. Use these options
+				to dump only some schemas or tables, or to leave some of them out.
+				Every option takes one pattern per line.
+			`),
+
+			component.PText(`
+				Patterns follow the psql rules: * matches any text, ? matches any
+				character, unquoted names are folded to lower case and double quotes
+				keep the case and special characters, like "My Table". Table patterns
+				can be qualified with a schema, like public.orders or audit.*.
+			`),
+
+			component.PText(`
+				--exclude-table-data keeps the definition of the matching tables but
+				not their rows, useful for big log tables. --no-owner and
+				--no-privileges leave out the ownership and grants, so the backup can
+				be restored with other roles; for the archive formats they are
+				applied when restoring. --role runs the dump as another role.
+			`),
+
+			component.PText(`
+				The patterns and the role are checked against the database when the
+				backup task is saved, and you can check them at any time with the
+				button below the options.
+			`),
+		),
+	}
+}
@@ -3,9 +3,16 @@ package backups
 import (
 	"database/sql"
 	"fmt"
+	"net/http"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/compression"
+	"github.com/eduardolat/pgbackweb/internal/integration/hooks"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/backups"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
 	"github.com/eduardolat/pgbackweb/internal/staticdata"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
 	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
 	"github.com/eduardolat/pgbackweb/internal/validate"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
@@ -31,13 +38,32 @@ func (h *handlers) editBackupHandler(c echo.Context) error {
 		TimeZone       string `form:"time_zone" validate:"required"`
 		IsActive       string `form:"is_active" validate:"required,oneof=true false"`
 		DestDir        string `form:"dest_dir" validate:"required"`
-		RetentionDays  int16  `form:"retention_days"`
+		RetentionDays  int16  `form:"retention_days" validate:"min=0"`
+		KeepLast       int16  `form:"retention_keep_last" validate:"min=0"`
+		KeepDaily      int16  `form:"retention_keep_daily" validate:"min=0"`
+		KeepWeekly     int16  `form:"retention_keep_weekly" validate:"min=0"`
+		KeepMonthly    int16  `form:"retention_keep_monthly" validate:"min=0"`
+		KeepYearly     int16  `form:"retention_keep_yearly" validate:"min=0"`
+		MinKeep        int16  `form:"retention_min_keep" validate:"min=0"`
 		OptDataOnly    string `form:"opt_data_only" validate:"required,oneof=true false"`
 		OptSchemaOnly  string `form:"opt_schema_only" validate:"required,oneof=true false"`
 		OptClean       string `form:"opt_clean" validate:"required,oneof=true false"`
 		OptIfExists    string `form:"opt_if_exists" validate:"required,oneof=true false"`
 		OptCreate      string `form:"opt_create" validate:"required,oneof=true false"`
 		OptNoComments  string `form:"opt_no_comments" validate:"required,oneof=true false"`
+		OptFormat      string `form:"opt_format" validate:"required"`
+		OptJobs        int16  `form:"opt_jobs" validate:"required,min=1"`
+		Compression    string `form:"compression" validate:"required"`
+		CompLevel      int16  `form:"compression_level" validate:"min=0"`
+		MaxDuration    int32  `form:"max_duration_minutes" validate:"min=0"`
+		Priority       int16  `form:"priority" validate:"min=0,max=100"`
+		RetryAttempts  int16  `form:"retry_max_attempts" validate:"min=0,max=10"`
+		RetryBackoff   int32  `form:"retry_backoff_seconds" validate:"min=0,max=86400"`
+		// The replicas are lazy loaded, they are only replaced when loaded
+		ReplicasLoaded string   `form:"replicas_loaded"`
+		Replicas       []string `form:"replicas"`
+		Hooks          component.HooksFormData
+		Selection      dumpSelectionFormData
 	}
 	if err := c.Bind(&formData); err != nil {
 		return respondhtmx.ToastError(c, err.Error())
@@ -46,31 +72,108 @@ func (h *handlers) editBackupHandler(c echo.Context) error {
 		return respondhtmx.ToastError(c, err.Error())
 	}
 
+	backup, err := h.servs.BackupsService.GetBackup(ctx, backupID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	selection := formData.Selection.params()
+	err = h.checkDumpSelection(ctx, backup.DatabaseID, selection)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
 	_, err = h.servs.BackupsService.UpdateBackup(
 		ctx, dbgen.BackupsServiceUpdateBackupParams{
-			ID:             backupID,
-			Name:           sql.NullString{String: formData.Name, Valid: true},
-			CronExpression: sql.NullString{String: formData.CronExpression, Valid: true},
-			TimeZone:       sql.NullString{String: formData.TimeZone, Valid: true},
-			IsActive:       sql.NullBool{Bool: formData.IsActive == "true", Valid: true},
-			DestDir:        sql.NullString{String: formData.DestDir, Valid: true},
-			RetentionDays:  sql.NullInt16{Int16: formData.RetentionDays, Valid: true},
-			OptDataOnly:    sql.NullBool{Bool: formData.OptDataOnly == "true", Valid: true},
-			OptSchemaOnly:  sql.NullBool{Bool: formData.OptSchemaOnly == "true", Valid: true},
-			OptClean:       sql.NullBool{Bool: formData.OptClean == "true", Valid: true},
-			OptIfExists:    sql.NullBool{Bool: formData.OptIfExists == "true", Valid: true},
-			OptCreate:      sql.NullBool{Bool: formData.OptCreate == "true", Valid: true},
-			OptNoComments:  sql.NullBool{Bool: formData.OptNoComments == "true", Valid: true},
+			ID:                   backupID,
+			Name:                 sql.NullString{String: formData.Name, Valid: true},
+			CronExpression:       sql.NullString{String: formData.CronExpression, Valid: true},
+			TimeZone:             sql.NullString{String: formData.TimeZone, Valid: true},
+			IsActive:             sql.NullBool{Bool: formData.IsActive == "true", Valid: true},
+			DestDir:              sql.NullString{String: formData.DestDir, Valid: true},
+			RetentionDays:        sql.NullInt16{Int16: formData.RetentionDays, Valid: true},
+			RetentionKeepLast:    sql.NullInt16{Int16: formData.KeepLast, Valid: true},
+			RetentionKeepDaily:   sql.NullInt16{Int16: formData.KeepDaily, Valid: true},
+			RetentionKeepWeekly:  sql.NullInt16{Int16: formData.KeepWeekly, Valid: true},
+			RetentionKeepMonthly: sql.NullInt16{Int16: formData.KeepMonthly, Valid: true},
+			RetentionKeepYearly:  sql.NullInt16{Int16: formData.KeepYearly, Valid: true},
+			RetentionMinKeep:     sql.NullInt16{Int16: formData.MinKeep, Valid: true},
+			OptDataOnly:          sql.NullBool{Bool: formData.OptDataOnly == "true", Valid: true},
+			OptSchemaOnly:        sql.NullBool{Bool: formData.OptSchemaOnly == "true", Valid: true},
+			OptClean:             sql.NullBool{Bool: formData.OptClean == "true", Valid: true},
+			OptIfExists:          sql.NullBool{Bool: formData.OptIfExists == "true", Valid: true},
+			OptCreate:            sql.NullBool{Bool: formData.OptCreate == "true", Valid: true},
+			OptNoComments:        sql.NullBool{Bool: formData.OptNoComments == "true", Valid: true},
+			OptFormat:            sql.NullString{String: formData.OptFormat, Valid: true},
+			OptJobs:              sql.NullInt16{Int16: formData.OptJobs, Valid: true},
+			Compression:          sql.NullString{String: formData.Compression, Valid: true},
+			CompressionLevel:     sql.NullInt16{Int16: formData.CompLevel, Valid: true},
+			MaxDurationMinutes:   sql.NullInt32{Int32: formData.MaxDuration, Valid: true},
+			Priority:             sql.NullInt16{Int16: formData.Priority, Valid: true},
+			RetryMaxAttempts:     sql.NullInt16{Int16: formData.RetryAttempts, Valid: true},
+			RetryBackoffSeconds:  sql.NullInt32{Int32: formData.RetryBackoff, Valid: true},
+			Hooks: sql.NullString{
+				String: string(formData.Hooks.Hooks().Marshal()), Valid: true,
+			},
+			OptSchemas:          selection.Schemas,
+			OptExcludeSchemas:   selection.ExcludeSchemas,
+			OptTables:           selection.Tables,
+			OptExcludeTables:    selection.ExcludeTables,
+			OptExcludeTableData: selection.ExcludeTableData,
+			OptNoOwner:          sql.NullBool{Bool: selection.NoOwner, Valid: true},
+			OptNoPrivileges:     sql.NullBool{Bool: selection.NoPrivileges, Valid: true},
+			OptRole:             sql.NullString{String: selection.Role, Valid: true},
 		},
 	)
 	if err != nil {
 		return respondhtmx.ToastError(c, err.Error())
 	}
 
+	if formData.ReplicasLoaded == "true" {
+		replicas, err := backups.ParseReplicas(formData.Replicas)
+		if err != nil {
+			return respondhtmx.ToastError(c, err.Error())
+		}
+		err = h.servs.BackupsService.SetBackupReplicas(ctx, backupID, replicas)
+		if err != nil {
+			return respondhtmx.ToastError(c, err.Error())
+		}
+	}
+
 	return respondhtmx.AlertWithRefresh(c, "Backup task updated")
 }
 
+func (h *handlers) editReplicasFormHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	replicas, err := h.servs.BackupsService.GetBackupReplicas(ctx, backupID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	destinations, err := h.servs.DestinationsService.GetAllDestinations(ctx)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, nodx.Group(
+		nodx.Input(
+			nodx.Type("hidden"),
+			nodx.Name("replicas_loaded"),
+			nodx.Value("true"),
+		),
+		replicasSelect(destinations, backups.ReplicaValues(replicas)),
+	))
+}
+
 func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
+	// Invalid hooks are shown as no hooks, saving the form replaces them
+	backupHooks, _ := hooks.Parse(backup.Hooks)
+
 	yesNoOptions := func(value bool) nodx.Node {
 		return nodx.Group(
 			nodx.Option(
@@ -95,6 +198,12 @@ func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
 				htmx.HxDisabledELT("find button"),
 				nodx.Class("space-y-2 text-base"),
 
+				nodx.Input(
+					nodx.Type("hidden"),
+					nodx.Name("database_id"),
+					nodx.Value(backup.DatabaseID.String()),
+				),
+
 				component.InputControl(component.InputControlParams{
 					Name:        "name",
 					Label:       "Name",
@@ -157,18 +266,89 @@ func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
 					},
 				}),
 
-				component.InputControl(component.InputControlParams{
-					Name:               "retention_days",
-					Label:              "Retention days",
-					Placeholder:        "30",
+				nodx.Div(
+					htmx.HxGet(pathutil.BuildPath(fmt.Sprintf("/dashboard/backups/%s/replicas", backup.ID))),
+					htmx.HxSwap("outerHTML"),
+					htmx.HxTrigger("intersect once"),
+					nodx.Class("p-4 flex justify-center"),
+					component.HxLoadingMd(),
+				),
+
+				component.SelectControl(component.SelectControlParams{
+					Name:               "compression",
+					Label:              "Compression",
 					Required:           true,
-					Type:               component.InputTypeNumber,
-					Pattern:            "[0-9]+",
-					HelpButtonChildren: retentionDaysHelp(),
+					HelpButtonChildren: compressionHelp(),
+					Children: []nodx.Node{
+						compressionSelectOptions(backup.Compression),
+					},
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:     "compression_level",
+					Label:    "Compression level",
+					Required: true,
+					Type:     component.InputTypeNumber,
+					HelpText: "Use 0 for the default level of the compression",
 					Children: []nodx.Node{
 						nodx.Min("0"),
-						nodx.Max("36500"),
-						nodx.Value(fmt.Sprintf("%d", backup.RetentionDays)),
+						nodx.Max(fmt.Sprintf("%d", compression.MaxLevelZstd)),
+						nodx.Value(fmt.Sprintf("%d", backup.CompressionLevel)),
+					},
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:     "priority",
+					Label:    "Priority",
+					Required: true,
+					Type:     component.InputTypeNumber,
+					HelpText: "From 0 to 100, queued runs with a higher priority start first",
+					Children: []nodx.Node{
+						nodx.Min("0"),
+						nodx.Max("100"),
+						nodx.Value(fmt.Sprintf("%d", backup.Priority)),
+					},
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:        "retry_max_attempts",
+					Label:       "Max attempts",
+					Placeholder: "Server default",
+					Type:        component.InputTypeNumber,
+					HelpText:    "From 1 to 10, failed or timed out executions are retried until this many attempts were made, use 1 to never retry or leave it empty for the server default",
+					Children: []nodx.Node{
+						nodx.Min("1"),
+						nodx.Max("10"),
+						nodx.If(backup.RetryMaxAttempts.Valid, nodx.Value(
+							fmt.Sprintf("%d", backup.RetryMaxAttempts.Int16),
+						)),
+					},
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:        "retry_backoff_seconds",
+					Label:       "Retry backoff (seconds)",
+					Placeholder: "Server default",
+					Type:        component.InputTypeNumber,
+					HelpText:    "Wait before the first retry, it doubles after every failed attempt up to 6 hours, leave it empty for the server default",
+					Children: []nodx.Node{
+						nodx.Min("1"),
+						nodx.Max("86400"),
+						nodx.If(backup.RetryBackoffSeconds.Valid, nodx.Value(
+							fmt.Sprintf("%d", backup.RetryBackoffSeconds.Int32),
+						)),
+					},
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:     "max_duration_minutes",
+					Label:    "Max duration (minutes)",
+					Required: true,
+					Type:     component.InputTypeNumber,
+					HelpText: "Executions running for longer are stopped and marked as timed out, use 0 for no limit",
+					Children: []nodx.Node{
+						nodx.Min("0"),
+						nodx.Value(fmt.Sprintf("%d", backup.MaxDurationMinutes)),
 					},
 				}),
 
@@ -181,6 +361,21 @@ func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
 					},
 				}),
 
+				retentionSection(
+					executions.RetentionPolicy{
+						Days:    int(backup.RetentionDays),
+						Last:    int(backup.RetentionKeepLast),
+						Daily:   int(backup.RetentionKeepDaily),
+						Weekly:  int(backup.RetentionKeepWeekly),
+						Monthly: int(backup.RetentionKeepMonthly),
+						Yearly:  int(backup.RetentionKeepYearly),
+						MinKeep: int(backup.RetentionMinKeep),
+					},
+					retentionPreviewButton(backup.ID),
+				),
+
+				component.HooksSection("dump", backupHooks),
+
 				nodx.Div(
 					nodx.Class("pt-4"),
 					nodx.Div(
@@ -247,9 +442,42 @@ func editBackupButton(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
 								yesNoOptions(backup.OptNoComments),
 							},
 						}),
+
+						component.SelectControl(component.SelectControlParams{
+							Name:     "opt_format",
+							Label:    "--format",
+							Required: true,
+							Children: []nodx.Node{
+								dumpFormatSelectOptions(backup.OptFormat),
+							},
+						}),
+
+						component.InputControl(component.InputControlParams{
+							Name:     "opt_jobs",
+							Label:    "--jobs",
+							Required: true,
+							Type:     component.InputTypeNumber,
+							HelpText: "Only for the custom and directory formats",
+							Children: []nodx.Node{
+								nodx.Min("1"),
+								nodx.Max(fmt.Sprintf("%d", postgres.MaxJobs)),
+								nodx.Value(fmt.Sprintf("%d", backup.OptJobs)),
+							},
+						}),
 					),
 				),
 
+				dumpSelectionSection(backup.ID.String(), postgres.DumpParams{
+					Schemas:          backup.OptSchemas,
+					ExcludeSchemas:   backup.OptExcludeSchemas,
+					Tables:           backup.OptTables,
+					ExcludeTables:    backup.OptExcludeTables,
+					ExcludeTableData: backup.OptExcludeTableData,
+					NoOwner:          backup.OptNoOwner,
+					NoPrivileges:     backup.OptNoPrivileges,
+					Role:             backup.OptRole,
+				}),
+
 				nodx.Div(
 					nodx.Class("flex justify-end items-center space-x-2 pt-2"),
 					component.HxLoadingMd(),
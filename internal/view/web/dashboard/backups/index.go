@@ -37,6 +37,7 @@ func indexPage(reqCtx reqctx.Ctx) nodx.Node {
 								nodx.Th(nodx.Class("w-1")),
 								nodx.Th(component.SpanText("Name")),
 								nodx.Th(component.SpanText("Database")),
+								nodx.Th(component.SpanText("Mode")),
 								nodx.Th(component.SpanText("Destination")),
 								nodx.Th(component.SpanText("Schedule")),
 								nodx.Th(component.SpanText("Retention")),
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/service/backups"
 	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
 	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
@@ -79,6 +80,7 @@ func listBackups(
 					component.SpanText("Show executions"),
 				),
 				manualRunbutton(backup.ID),
+				pitrButton(backup.ID, backup.Mode),
 				editBackupButton(backup),
 				duplicateBackupButton(backup.ID),
 				deleteBackupButton(backup.ID),
@@ -91,9 +93,19 @@ func listBackups(
 				),
 			),
 			nodx.Td(component.SpanText(backup.DatabaseName)),
-			nodx.Td(component.PrettyDestinationName(
-				backup.IsLocal, backup.DestinationName,
-			)),
+			nodx.Td(backupModeBadge(backup.Mode)),
+			nodx.Td(
+				component.PrettyDestinationName(
+					backup.IsLocal, backup.DestinationName,
+				),
+				nodx.If(
+					backup.ReplicaNames != "",
+					nodx.Div(
+						nodx.Class("text-xs opacity-70"),
+						component.SpanText("Replicas: "+backup.ReplicaNames),
+					),
+				),
+			),
 			nodx.Td(
 				nodx.Class("font-mono"),
 				nodx.Div(
@@ -102,16 +114,7 @@ func listBackups(
 					component.SpanText(backup.TimeZone),
 				),
 			),
-			nodx.Td(
-				nodx.If(
-					backup.RetentionDays == 0,
-					lucide.Infinity(),
-				),
-				nodx.If(
-					backup.RetentionDays > 0,
-					component.SpanText(fmt.Sprintf("%d days", backup.RetentionDays)),
-				),
-			),
+			nodx.Td(retentionSummary(backup)),
 			nodx.Td(yesNoSpan(backup.OptDataOnly)),
 			nodx.Td(yesNoSpan(backup.OptSchemaOnly)),
 			nodx.Td(yesNoSpan(backup.OptClean)),
@@ -136,3 +139,48 @@ func listBackups(
 
 	return component.RenderableGroup(trs)
 }
+
+func retentionSummary(backup dbgen.BackupsServicePaginateBackupsRow) nodx.Node {
+	rules := []struct {
+		value int16
+		unit  string
+	}{
+		{backup.RetentionDays, "days"},
+		{backup.RetentionKeepLast, "last"},
+		{backup.RetentionKeepDaily, "daily"},
+		{backup.RetentionKeepWeekly, "weekly"},
+		{backup.RetentionKeepMonthly, "monthly"},
+		{backup.RetentionKeepYearly, "yearly"},
+	}
+
+	parts := []nodx.Node{}
+	for _, rule := range rules {
+		if rule.value > 0 {
+			parts = append(parts, component.SpanText(
+				fmt.Sprintf("%d %s", rule.value, rule.unit),
+			))
+		}
+	}
+
+	if len(parts) == 0 {
+		return lucide.Infinity()
+	}
+
+	return nodx.Div(
+		nodx.Class("flex flex-col items-start text-xs"),
+		nodx.Group(parts...),
+	)
+}
+
+func backupModeBadge(mode string) nodx.Node {
+	if mode == postgres.BackupModePhysical {
+		return nodx.SpanEl(
+			nodx.Class("badge badge-outline badge-primary"),
+			component.SpanText("Physical"),
+		)
+	}
+	return nodx.SpanEl(
+		nodx.Class("badge badge-outline"),
+		component.SpanText("Logical"),
+	)
+}
@@ -1,9 +1,9 @@
 package backups
 
 import (
-	"context"
 	"fmt"
 
+	"github.com/eduardolat/pgbackweb/internal/service/jobs"
 	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
 	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
@@ -20,11 +20,14 @@ func (h *handlers) manualRunHandler(c echo.Context) error {
 		return respondhtmx.ToastError(c, err.Error())
 	}
 
-	go func() {
-		_ = h.servs.ExecutionsService.RunExecution(context.Background(), backupID)
-	}()
+	_, err = h.servs.JobsService.EnqueueJob(
+		c.Request().Context(), backupID, jobs.SourceManual, jobs.EnqueueJobParams{},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
 
-	return respondhtmx.ToastSuccess(c, "Backup started, check the backup executions for more details")
+	return respondhtmx.ToastSuccess(c, "Backup queued, check the jobs queue and the backup executions for more details")
 }
 
 func manualRunbutton(backupID uuid.UUID) nodx.Node {
@@ -0,0 +1,310 @@
+package backups
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/pitr"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	alpine "github.com/nodxdev/nodxgo-alpine"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+// parseTargetTime parses the value of a datetime-local input in the time
+// zone of the backup, with or without seconds.
+func parseTargetTime(value string, timeZone string) (time.Time, error) {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, layout := range []string{
+		timeutil.LayoutInputDateTimeLocal, timeutil.LayoutInputDateTimeLocal + ":05",
+	} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid target time %q", value)
+}
+
+func (h *handlers) pitrRestoreHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	var formData struct {
+		TargetType    string `form:"target_type" validate:"required,oneof=latest time lsn"`
+		TargetTime    string `form:"target_time" validate:"omitempty"`
+		TargetLSN     string `form:"target_lsn" validate:"omitempty"`
+		DataDirectory string `form:"data_directory" validate:"required"`
+	}
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	backup, err := h.servs.BackupsService.GetBackup(ctx, backupID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	target := postgres.RecoveryTarget{}
+	switch formData.TargetType {
+	case "time":
+		target.Time, err = parseTargetTime(formData.TargetTime, backup.TimeZone)
+		if err != nil {
+			return respondhtmx.ToastError(c, err.Error())
+		}
+	case "lsn":
+		target.LSN = formData.TargetLSN
+	}
+
+	params := pitr.RestorePointInTimeParams{
+		BackupID:      backupID,
+		Target:        target,
+		DataDirectory: formData.DataDirectory,
+	}
+	_, err = h.servs.PITRService.ValidateRestorePointInTime(ctx, params)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	go func() {
+		_ = h.servs.PITRService.RestorePointInTime(context.Background(), params)
+	}()
+
+	return respondhtmx.ToastSuccess(
+		c, "Process started, check the restorations page for more details",
+	)
+}
+
+func (h *handlers) pitrFormHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	backup, err := h.servs.BackupsService.GetBackup(ctx, backupID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	status, err := h.servs.PITRService.GetArchiveStatus(ctx, backupID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, pitrForm(
+		backupID, backup.TimeZone, status,
+	))
+}
+
+func pitrArchiveStatus(status pitr.ArchiveStatus) nodx.Node {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty)
+	}
+
+	receiver := "Stopped"
+	if status.Receiver.Running {
+		receiver = "Streaming since " + formatTime(status.Receiver.StartedAt)
+	}
+
+	lastSegment := "-"
+	if status.LastSegment != "" {
+		lastSegment = fmt.Sprintf(
+			"%s (%s)", status.LastSegment, formatTime(status.LastArchivedAt),
+		)
+	}
+
+	row := func(label string, value nodx.Node) nodx.Node {
+		return nodx.Tr(
+			nodx.Th(component.SpanText(label)),
+			nodx.Td(value),
+		)
+	}
+
+	return nodx.Table(
+		nodx.Class("table table-xs"),
+		nodx.Tbody(
+			row("WAL streaming", component.SpanText(receiver)),
+			nodx.If(
+				status.Receiver.LastError != "",
+				row("Last streaming error", nodx.SpanEl(
+					nodx.Class("text-error"),
+					component.SpanText(fmt.Sprintf(
+						"%s (%s)", status.Receiver.LastError,
+						formatTime(status.Receiver.LastErrorAt),
+					)),
+				)),
+			),
+			row("Archived WAL files", component.SpanText(fmt.Sprintf(
+				"%d (%s)", status.Segments, strutil.FormatFileSize(status.TotalSize),
+			))),
+			row("Last archived WAL file", nodx.SpanEl(
+				nodx.Class("font-mono"), component.SpanText(lastSegment),
+			)),
+			row("Base backups", component.SpanText(
+				fmt.Sprintf("%d", status.BaseBackups),
+			)),
+			row("Recoverable from", component.SpanText(
+				formatTime(status.RecoverableFrom),
+			)),
+		),
+	)
+}
+
+func pitrForm(
+	backupID uuid.UUID, timeZone string, status pitr.ArchiveStatus,
+) nodx.Node {
+	return nodx.Div(
+		nodx.Class("space-y-4"),
+		pitrArchiveStatus(status),
+
+		nodx.FormEl(
+			htmx.HxPost(pathutil.BuildPath(
+				fmt.Sprintf("/dashboard/backups/%s/pitr-restore", backupID),
+			)),
+			htmx.HxConfirm("Are you sure you want to start the point-in-time restore?"),
+			htmx.HxDisabledELT("find button"),
+
+			alpine.XData(`{ target_type: "latest" }`),
+
+			nodx.Div(
+				nodx.Class("space-y-2 text-base"),
+
+				component.SelectControl(component.SelectControlParams{
+					Name:     "target_type",
+					Label:    "Recover up to",
+					Required: true,
+					Children: []nodx.Node{
+						alpine.XModel("target_type"),
+						nodx.Option(
+							nodx.Value("latest"),
+							nodx.Text("The last archived WAL file"),
+							nodx.Selected(""),
+						),
+						nodx.Option(nodx.Value("time"), nodx.Text("A point in time")),
+						nodx.Option(nodx.Value("lsn"), nodx.Text("A WAL position (LSN)")),
+					},
+				}),
+
+				alpine.Template(
+					alpine.XIf("target_type === 'time'"),
+					component.InputControl(component.InputControlParams{
+						Name:     "target_time",
+						Label:    "Target time",
+						Type:     component.InputTypeDatetimeLocal,
+						Required: true,
+						HelpText: fmt.Sprintf(
+							"In the time zone of the backup (%s), transactions committed after it are not recovered",
+							timeZone,
+						),
+						Children: []nodx.Node{nodx.Step("1")},
+					}),
+				),
+
+				alpine.Template(
+					alpine.XIf("target_type === 'lsn'"),
+					component.InputControl(component.InputControlParams{
+						Name:        "target_lsn",
+						Label:       "Target LSN",
+						Placeholder: "16/B374D848",
+						Type:        component.InputTypeText,
+						Required:    true,
+					}),
+				),
+
+				component.InputControl(component.InputControlParams{
+					Name:        "data_directory",
+					Label:       "Data directory",
+					Placeholder: "/restores/pgdata",
+					Type:        component.InputTypeText,
+					Required:    true,
+					HelpText:    "Absolute path of an empty directory in the server where PG Back Web is running",
+				}),
+
+				nodx.Div(
+					nodx.Class("pt-2"),
+					nodx.Div(
+						nodx.Role("alert"),
+						nodx.Class("alert alert-info"),
+						lucide.Info(),
+						component.PText(`
+							The newest base backup that finished before the target is
+							extracted into the data directory together with the WAL
+							needed to reach it. Start the same major version of PostgreSQL
+							on the directory to replay the WAL, the server is promoted
+							once the target is reached.
+						`),
+					),
+				),
+
+				nodx.Div(
+					nodx.Class("flex justify-end items-center space-x-2 pt-2"),
+					component.HxLoadingMd(),
+					nodx.Button(
+						nodx.Class("btn btn-primary"),
+						nodx.Type("submit"),
+						component.SpanText("Start restore"),
+						lucide.Zap(),
+					),
+				),
+			),
+		),
+	)
+}
+
+func pitrButton(backupID uuid.UUID, mode string) nodx.Node {
+	if mode != postgres.BackupModePhysical {
+		return nil
+	}
+
+	mo := component.Modal(component.ModalParams{
+		Size:  component.SizeMd,
+		Title: "Point-in-time recovery",
+		Content: []nodx.Node{
+			nodx.Div(
+				htmx.HxGet(pathutil.BuildPath(
+					fmt.Sprintf("/dashboard/backups/%s/pitr", backupID),
+				)),
+				htmx.HxSwap("outerHTML"),
+				htmx.HxTrigger("intersect once"),
+				nodx.Class("p-10 flex justify-center"),
+				component.HxLoadingMd(),
+			),
+		},
+	})
+
+	return nodx.Div(
+		mo.HTML,
+		component.OptionsDropdownButton(
+			mo.OpenerAttr,
+			lucide.History(),
+			component.SpanText("Point-in-time recovery"),
+		),
+	)
+}
@@ -0,0 +1,140 @@
+package backups
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+// retentionPreviewMaxRows is the maximum number of executions listed in the
+// retention preview.
+const retentionPreviewMaxRows = 50
+
+func (h *handlers) retentionPreviewHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backupID, err := uuid.Parse(c.Param("backupID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	var formData struct {
+		TimeZone      string `form:"time_zone" validate:"required"`
+		RetentionDays int16  `form:"retention_days" validate:"min=0"`
+		KeepLast      int16  `form:"retention_keep_last" validate:"min=0"`
+		KeepDaily     int16  `form:"retention_keep_daily" validate:"min=0"`
+		KeepWeekly    int16  `form:"retention_keep_weekly" validate:"min=0"`
+		KeepMonthly   int16  `form:"retention_keep_monthly" validate:"min=0"`
+		KeepYearly    int16  `form:"retention_keep_yearly" validate:"min=0"`
+		MinKeep       int16  `form:"retention_min_keep" validate:"min=0"`
+	}
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	policy := executions.RetentionPolicy{
+		Days:    int(formData.RetentionDays),
+		Last:    int(formData.KeepLast),
+		Daily:   int(formData.KeepDaily),
+		Weekly:  int(formData.KeepWeekly),
+		Monthly: int(formData.KeepMonthly),
+		Yearly:  int(formData.KeepYearly),
+		MinKeep: int(formData.MinKeep),
+	}
+	if err := policy.Validate(); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	expired, err := h.servs.ExecutionsService.ExpiredExecutions(
+		ctx, backupID, formData.TimeZone, policy,
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, retentionPreview(expired))
+}
+
+func retentionPreview(expired []executions.RetentionExecution) nodx.Node {
+	if len(expired) == 0 {
+		return component.PText("No executions would be deleted by this policy.")
+	}
+
+	rows := expired
+	if len(rows) > retentionPreviewMaxRows {
+		rows = rows[:retentionPreviewMaxRows]
+	}
+
+	return nodx.Div(
+		nodx.Class("space-y-2"),
+		component.PText(fmt.Sprintf(
+			"%d executions would be deleted by this policy:", len(expired),
+		)),
+		nodx.Div(
+			nodx.Class("max-h-64 overflow-y-auto"),
+			nodx.Table(
+				nodx.Class("table table-xs"),
+				nodx.Thead(
+					nodx.Tr(
+						nodx.Th(component.SpanText("Status")),
+						nodx.Th(component.SpanText("Finished at")),
+					),
+				),
+				nodx.Tbody(
+					nodx.Map(rows, func(execution executions.RetentionExecution) nodx.Node {
+						return nodx.Tr(
+							nodx.Td(component.StatusBadge(execution.Status)),
+							nodx.Td(component.SpanText(
+								execution.FinishedAt.Local().Format(
+									timeutil.LayoutYYYYMMDDHHMMSSPretty,
+								),
+							)),
+						)
+					}),
+				),
+			),
+		),
+		nodx.If(
+			len(expired) > retentionPreviewMaxRows,
+			component.PText(fmt.Sprintf(
+				"And %d more.", len(expired)-retentionPreviewMaxRows,
+			)),
+		),
+	)
+}
+
+func retentionPreviewButton(backupID uuid.UUID) nodx.Node {
+	targetID := "retention-preview-" + backupID.String()
+
+	return nodx.Div(
+		nodx.Class("mt-2 space-y-2"),
+		nodx.Button(
+			htmx.HxPost(pathutil.BuildPath(
+				fmt.Sprintf("/dashboard/backups/%s/retention-preview", backupID),
+			)),
+			htmx.HxInclude("closest form"),
+			htmx.HxTarget("#"+targetID),
+			htmx.HxDisabledELT("this"),
+			nodx.Type("button"),
+			nodx.Class("btn btn-neutral btn-outline btn-sm"),
+			component.SpanText("Preview deletions"),
+			lucide.Eye(),
+		),
+		nodx.Div(nodx.Id(targetID)),
+	)
+}
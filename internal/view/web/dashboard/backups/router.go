@@ -2,6 +2,7 @@ package backups
 
 import (
 	"github.com/eduardolat/pgbackweb/internal/service"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
 	"github.com/eduardolat/pgbackweb/internal/view/middleware"
 	"github.com/labstack/echo/v4"
 )
@@ -18,13 +19,20 @@ func MountRouter(
 	parent *echo.Group, mids *middleware.Middleware, servs *service.Service,
 ) {
 	h := newHandlers(servs)
+	admin := mids.RequireRole(users.RoleAdmin)
+	operator := mids.RequireRole(users.RoleAdmin, users.RoleOperator)
 
 	parent.GET("", h.indexPageHandler)
 	parent.GET("/list", h.listBackupsHandler)
-	parent.GET("/create-form", h.createBackupFormHandler)
-	parent.POST("", h.createBackupHandler)
-	parent.DELETE("/:backupID", h.deleteBackupHandler)
-	parent.POST("/:backupID/edit", h.editBackupHandler)
-	parent.POST("/:backupID/run", h.manualRunHandler)
-	parent.POST("/:backupID/duplicate", h.duplicateBackupHandler)
+	parent.GET("/create-form", h.createBackupFormHandler, admin)
+	parent.POST("", h.createBackupHandler, admin)
+	parent.POST("/check-selection", h.checkDumpSelectionHandler, admin)
+	parent.DELETE("/:backupID", h.deleteBackupHandler, admin)
+	parent.POST("/:backupID/edit", h.editBackupHandler, admin)
+	parent.GET("/:backupID/replicas", h.editReplicasFormHandler, admin)
+	parent.POST("/:backupID/retention-preview", h.retentionPreviewHandler, admin)
+	parent.POST("/:backupID/run", h.manualRunHandler, operator)
+	parent.POST("/:backupID/duplicate", h.duplicateBackupHandler, admin)
+	parent.GET("/:backupID/pitr", h.pitrFormHandler, admin)
+	parent.POST("/:backupID/pitr-restore", h.pitrRestoreHandler, admin)
 }
@@ -39,6 +39,8 @@ func (h *handlers) editDatabaseHandler(c echo.Context) error {
 			DatabaseType:     sql.NullString{String: formData.DatabaseType, Valid: true},
 			Version:          sql.NullString{String: formData.Version, Valid: true},
 			ConnectionString: sql.NullString{String: formData.ConnectionString, Valid: true},
+			IsProduction:     sql.NullBool{Bool: formData.IsProduction == "true", Valid: true},
+			IsProtected:      sql.NullBool{Bool: formData.IsProtected == "true", Valid: true},
 		},
 	)
 	if err != nil {
@@ -128,6 +130,44 @@ func editDatabaseButton(
 						nodx.Value(database.DecryptedConnectionString),
 					},
 				}),
+
+				component.SelectControl(component.SelectControlParams{
+					Name:     "is_production",
+					Label:    "Production database",
+					Required: true,
+					HelpText: "Operators are not allowed to restore backups into production databases",
+					Children: []nodx.Node{
+						nodx.Option(
+							nodx.Value("true"),
+							nodx.Text("Yes"),
+							nodx.If(database.IsProduction, nodx.Selected("")),
+						),
+						nodx.Option(
+							nodx.Value("false"),
+							nodx.Text("No"),
+							nodx.If(!database.IsProduction, nodx.Selected("")),
+						),
+					},
+				}),
+
+				component.SelectControl(component.SelectControlParams{
+					Name:     "is_protected",
+					Label:    "Protected database",
+					Required: true,
+					HelpText: "The name of a protected database must be typed to confirm a restore into it",
+					Children: []nodx.Node{
+						nodx.Option(
+							nodx.Value("true"),
+							nodx.Text("Yes"),
+							nodx.If(database.IsProtected, nodx.Selected("")),
+						),
+						nodx.Option(
+							nodx.Value("false"),
+							nodx.Text("No"),
+							nodx.If(!database.IsProtected, nodx.Selected("")),
+						),
+					},
+				}),
 				),
 			),
 
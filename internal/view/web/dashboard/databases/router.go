@@ -2,6 +2,7 @@ package databases
 
 import (
 	"github.com/eduardolat/pgbackweb/internal/service"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
 	"github.com/eduardolat/pgbackweb/internal/view/middleware"
 	"github.com/labstack/echo/v4"
 )
@@ -18,12 +19,13 @@ func MountRouter(
 	parent *echo.Group, mids *middleware.Middleware, servs *service.Service,
 ) {
 	h := newHandlers(servs)
+	admin := mids.RequireRole(users.RoleAdmin)
 
 	parent.GET("", h.indexPageHandler)
 	parent.GET("/list", h.listDatabasesHandler)
-	parent.POST("", h.createDatabaseHandler)
-	parent.POST("/test", h.testDatabaseHandler)
-	parent.DELETE("/:databaseID", h.deleteDatabaseHandler)
-	parent.POST("/:databaseID/edit", h.editDatabaseHandler)
-	parent.POST("/:databaseID/test", h.testExistingDatabaseHandler)
+	parent.POST("", h.createDatabaseHandler, admin)
+	parent.POST("/test", h.testDatabaseHandler, admin)
+	parent.DELETE("/:databaseID", h.deleteDatabaseHandler, admin)
+	parent.POST("/:databaseID/edit", h.editDatabaseHandler, admin)
+	parent.POST("/:databaseID/test", h.testExistingDatabaseHandler, admin)
 }
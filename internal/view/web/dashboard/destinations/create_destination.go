@@ -1,7 +1,11 @@
 package destinations
 
 import (
+	"fmt"
+
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
 	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
 	"github.com/eduardolat/pgbackweb/internal/validate"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
@@ -14,11 +18,29 @@ import (
 
 type createDestinationDTO struct {
 	Name       string `form:"name" validate:"required"`
-	BucketName string `form:"bucket_name" validate:"required"`
-	AccessKey  string `form:"access_key" validate:"required"`
-	SecretKey  string `form:"secret_key" validate:"required"`
-	Region     string `form:"region" validate:"required"`
-	Endpoint   string `form:"endpoint" validate:"required"`
+	Type       string `form:"type" validate:"required"`
+	BucketName string `form:"bucket_name"`
+	AccessKey  string `form:"access_key"`
+	SecretKey  string `form:"secret_key"`
+	Region     string `form:"region"`
+	Endpoint   string `form:"endpoint"`
+	BasePath   string `form:"base_path"`
+	PrivateKey string `form:"private_key"`
+	HostKey    string `form:"host_key"`
+
+	S3CredentialsMode      string `form:"s3_credentials_mode"`
+	S3RoleArn              string `form:"s3_role_arn"`
+	S3ExternalID           string `form:"s3_external_id"`
+	S3ServerSideEncryption string `form:"s3_server_side_encryption"`
+	S3KmsKeyID             string `form:"s3_kms_key_id"`
+	S3SseCustomerKey       string `form:"s3_sse_customer_key"`
+	S3StorageClass         string `form:"s3_storage_class"`
+	S3VirtualHostedStyle   bool   `form:"s3_virtual_hosted_style"`
+	S3ObjectLockMode       string `form:"s3_object_lock_mode"`
+	S3ObjectLockDays       int32  `form:"s3_object_lock_days"`
+	S3ObjectLockLegalHold  bool   `form:"s3_object_lock_legal_hold"`
+
+	BackupEncryptionKey string `form:"backup_encryption_key"`
 }
 
 func (h *handlers) createDestinationHandler(c echo.Context) error {
@@ -35,11 +57,29 @@ func (h *handlers) createDestinationHandler(c echo.Context) error {
 	_, err := h.servs.DestinationsService.CreateDestination(
 		ctx, dbgen.DestinationsServiceCreateDestinationParams{
 			Name:       formData.Name,
+			Type:       formData.Type,
 			AccessKey:  formData.AccessKey,
 			SecretKey:  formData.SecretKey,
 			Region:     formData.Region,
 			Endpoint:   formData.Endpoint,
 			BucketName: formData.BucketName,
+			BasePath:   formData.BasePath,
+			PrivateKey: formData.PrivateKey,
+			HostKey:    formData.HostKey,
+
+			S3CredentialsMode:      formData.S3CredentialsMode,
+			S3RoleArn:              formData.S3RoleArn,
+			S3ExternalID:           formData.S3ExternalID,
+			S3ServerSideEncryption: formData.S3ServerSideEncryption,
+			S3KmsKeyID:             formData.S3KmsKeyID,
+			S3SseCustomerKey:       formData.S3SseCustomerKey,
+			S3StorageClass:         formData.S3StorageClass,
+			S3VirtualHostedStyle:   formData.S3VirtualHostedStyle,
+			S3ObjectLockMode:       formData.S3ObjectLockMode,
+			S3ObjectLockDays:       formData.S3ObjectLockDays,
+			S3ObjectLockLegalHold:  formData.S3ObjectLockLegalHold,
+
+			BackupEncryptionKey: formData.BackupEncryptionKey,
 		},
 	)
 	if err != nil {
@@ -77,46 +117,17 @@ func createDestinationButton() nodx.Node {
 					HelpText:    "A name to easily identify the destination",
 				}),
 
-				component.InputControl(component.InputControlParams{
-					Name:        "bucket_name",
-					Label:       "Bucket name",
-					Placeholder: "my-bucket",
-					Required:    true,
-					Type:        component.InputTypeText,
-				}),
+				storageFields(storage.Config{}),
 
 				component.InputControl(component.InputControlParams{
-					Name:        "endpoint",
-					Label:       "Endpoint",
-					Placeholder: "s3-us-west-1.amazonaws.com",
-					Required:    true,
-					Type:        component.InputTypeText,
-				}),
-
-				component.InputControl(component.InputControlParams{
-					Name:        "region",
-					Label:       "Region",
-					Placeholder: "us-west-1",
-					Required:    true,
-					Type:        component.InputTypeText,
-				}),
-
-				component.InputControl(component.InputControlParams{
-					Name:        "access_key",
-					Label:       "Access key",
-					Placeholder: "Access key",
-					Required:    true,
-					Type:        component.InputTypeText,
-					HelpText:    "It will be stored securely using PGP encryption.",
-				}),
-
-				component.InputControl(component.InputControlParams{
-					Name:        "secret_key",
-					Label:       "Secret key",
-					Placeholder: "Secret key",
-					Required:    true,
-					Type:        component.InputTypeText,
-					HelpText:    "It will be stored securely using PGP encryption.",
+					Name:               "backup_encryption_key",
+					Label:              "Backup encryption key",
+					Placeholder:        "Leave empty to store the backups unencrypted",
+					Type:               component.InputTypeText,
+					HelpButtonChildren: backupEncryptionKeyHelp(),
+					Children: []nodx.Node{
+						nodx.Minlength(fmt.Sprintf("%d", encryption.MinKeyLength)),
+					},
 				}),
 			),
 
@@ -159,3 +170,29 @@ func createDestinationButton() nodx.Node {
 		button,
 	)
 }
+
+func backupEncryptionKeyHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(fmt.Sprintf(`
+				When set, the backups are encrypted with AES-256-GCM before they are
+				uploaded to the destination. The key must be at least %d characters
+				long and it will be stored securely using PGP encryption.
+			`, encryption.MinKeyLength)),
+
+			component.PText(`
+				Restorations and downloads decrypt the backups automatically. Every
+				backup records the fingerprint of the key used to encrypt it, if the
+				key is changed the backups encrypted with the previous key can't be
+				restored or downloaded until the previous key is configured again.
+			`),
+
+			component.PText(`
+				Store the key in a safe place, without it the backups can't be
+				decrypted.
+			`),
+		),
+	}
+}
@@ -2,8 +2,11 @@ package destinations
 
 import (
 	"database/sql"
+	"fmt"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/encryption"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
 	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
 	"github.com/eduardolat/pgbackweb/internal/validate"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
@@ -35,11 +38,31 @@ func (h *handlers) editDestinationHandler(c echo.Context) error {
 		ctx, dbgen.DestinationsServiceUpdateDestinationParams{
 			ID:         destinationID,
 			Name:       sql.NullString{String: formData.Name, Valid: true},
+			Type:       sql.NullString{String: formData.Type, Valid: true},
 			BucketName: sql.NullString{String: formData.BucketName, Valid: true},
 			Region:     sql.NullString{String: formData.Region, Valid: true},
 			Endpoint:   sql.NullString{String: formData.Endpoint, Valid: true},
 			AccessKey:  sql.NullString{String: formData.AccessKey, Valid: true},
 			SecretKey:  sql.NullString{String: formData.SecretKey, Valid: true},
+			BasePath:   sql.NullString{String: formData.BasePath, Valid: true},
+			PrivateKey: sql.NullString{String: formData.PrivateKey, Valid: true},
+			HostKey:    sql.NullString{String: formData.HostKey, Valid: true},
+
+			S3CredentialsMode:      sql.NullString{String: formData.S3CredentialsMode, Valid: true},
+			S3RoleArn:              sql.NullString{String: formData.S3RoleArn, Valid: true},
+			S3ExternalID:           sql.NullString{String: formData.S3ExternalID, Valid: true},
+			S3ServerSideEncryption: sql.NullString{String: formData.S3ServerSideEncryption, Valid: true},
+			S3KmsKeyID:             sql.NullString{String: formData.S3KmsKeyID, Valid: true},
+			S3SseCustomerKey:       sql.NullString{String: formData.S3SseCustomerKey, Valid: true},
+			S3StorageClass:         sql.NullString{String: formData.S3StorageClass, Valid: true},
+			S3VirtualHostedStyle:   sql.NullBool{Bool: formData.S3VirtualHostedStyle, Valid: true},
+			S3ObjectLockMode:       sql.NullString{String: formData.S3ObjectLockMode, Valid: true},
+			S3ObjectLockDays:       sql.NullInt32{Int32: formData.S3ObjectLockDays, Valid: true},
+			S3ObjectLockLegalHold:  sql.NullBool{Bool: formData.S3ObjectLockLegalHold, Valid: true},
+
+			BackupEncryptionKey: sql.NullString{
+				String: formData.BackupEncryptionKey, Valid: true,
+			},
 		},
 	)
 	if err != nil {
@@ -87,60 +110,40 @@ func editDestinationButton(
 					},
 				}),
 
-				component.InputControl(component.InputControlParams{
-					Name:        "bucket_name",
-					Label:       "Bucket name",
-					Placeholder: "my-bucket",
-					Required:    true,
-					Type:        component.InputTypeText,
-					Children: []nodx.Node{
-						nodx.Value(destination.BucketName),
-					},
-				}),
-
-				component.InputControl(component.InputControlParams{
-					Name:        "endpoint",
-					Label:       "Endpoint",
-					Placeholder: "s3-us-west-1.amazonaws.com",
-					Required:    true,
-					Type:        component.InputTypeText,
-					Children: []nodx.Node{
-						nodx.Value(destination.Endpoint),
+				storageFields(storage.Config{
+					Type:       destination.Type,
+					BucketName: destination.BucketName,
+					Region:     destination.Region,
+					Endpoint:   destination.Endpoint,
+					AccessKey:  destination.DecryptedAccessKey,
+					SecretKey:  destination.DecryptedSecretKey,
+					BasePath:   destination.BasePath,
+					PrivateKey: destination.DecryptedPrivateKey,
+					HostKey:    destination.HostKey,
+					S3: storage.S3Options{
+						CredentialsMode:      destination.S3CredentialsMode,
+						RoleARN:              destination.S3RoleArn,
+						ExternalID:           destination.S3ExternalID,
+						ServerSideEncryption: destination.S3ServerSideEncryption,
+						KMSKeyID:             destination.S3KmsKeyID,
+						SSECustomerKey:       destination.DecryptedS3SseCustomerKey,
+						StorageClass:         destination.S3StorageClass,
+						VirtualHostedStyle:   destination.S3VirtualHostedStyle,
+						ObjectLockMode:       destination.S3ObjectLockMode,
+						ObjectLockDays:       destination.S3ObjectLockDays,
+						ObjectLockLegalHold:  destination.S3ObjectLockLegalHold,
 					},
 				}),
 
 				component.InputControl(component.InputControlParams{
-					Name:        "region",
-					Label:       "Region",
-					Placeholder: "us-west-1",
-					Required:    true,
-					Type:        component.InputTypeText,
-					Children: []nodx.Node{
-						nodx.Value(destination.Region),
-					},
-				}),
-
-				component.InputControl(component.InputControlParams{
-					Name:        "access_key",
-					Label:       "Access key",
-					Placeholder: "Access key",
-					Required:    true,
-					Type:        component.InputTypeText,
-					HelpText:    "It will be stored securely using PGP encryption.",
-					Children: []nodx.Node{
-						nodx.Value(destination.DecryptedAccessKey),
-					},
-				}),
-
-				component.InputControl(component.InputControlParams{
-					Name:        "secret_key",
-					Label:       "Secret key",
-					Placeholder: "Secret key",
-					Required:    true,
-					Type:        component.InputTypeText,
-					HelpText:    "It will be stored securely using PGP encryption.",
+					Name:               "backup_encryption_key",
+					Label:              "Backup encryption key",
+					Placeholder:        "Leave empty to store the backups unencrypted",
+					Type:               component.InputTypeText,
+					HelpButtonChildren: backupEncryptionKeyHelp(),
 					Children: []nodx.Node{
-						nodx.Value(destination.DecryptedSecretKey),
+						nodx.Minlength(fmt.Sprintf("%d", encryption.MinKeyLength)),
+						nodx.Value(destination.DecryptedBackupEncryptionKey),
 					},
 				}),
 			),
@@ -23,10 +23,12 @@ func indexPage(reqCtx reqctx.Ctx) nodx.Node {
 		nodx.Div(
 			nodx.Class("flex justify-between items-start space-x-2"),
 			nodx.Div(
-				component.H1Text("S3 Destinations"),
+				component.H1Text("Destinations"),
 				component.PText(`
-					Here you can manage your S3 destinations. You can skip creating a S3
-					destination if you want to use the local storage for your backups.
+					Here you can manage your destinations: S3 compatible buckets, SFTP
+					and WebDAV servers or directories of this server. You can skip
+					creating a destination if you want to use the local storage for your
+					backups.
 				`),
 			),
 			nodx.Div(
@@ -46,16 +48,19 @@ func indexPage(reqCtx reqctx.Ctx) nodx.Node {
 							nodx.Tr(
 								nodx.Th(nodx.Class("w-1")),
 								nodx.Th(component.SpanText("Name")),
+								nodx.Th(component.SpanText("Type")),
 								nodx.Th(component.SpanText("Bucket name")),
 								nodx.Th(component.SpanText("Endpoint")),
 								nodx.Th(component.SpanText("Region")),
+								nodx.Th(component.SpanText("Directory")),
 								nodx.Th(component.SpanText("Access key")),
 								nodx.Th(component.SpanText("Secret key")),
+								nodx.Th(component.SpanText("Encryption")),
 								nodx.Th(component.SpanText("Created at")),
 							),
 						),
 						nodx.Tbody(
-							component.SkeletonTr(8),
+							component.SkeletonTr(10),
 							htmx.HxGet(pathutil.BuildPath("/dashboard/destinations/list?page=1")),
 							htmx.HxTrigger("load"),
 						),
@@ -66,7 +71,7 @@ func indexPage(reqCtx reqctx.Ctx) nodx.Node {
 	}
 
 	return layout.Dashboard(reqCtx, layout.DashboardParams{
-		Title: "S3 Destinations",
+		Title: "Destinations",
 		Body:  content,
 	})
 }
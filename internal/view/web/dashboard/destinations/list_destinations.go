@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/storage"
 	"github.com/eduardolat/pgbackweb/internal/service/destinations"
 	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
 	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
@@ -88,6 +89,7 @@ func listDestinations(
 					component.SpanText(destination.Name),
 				),
 			),
+			nodx.Td(component.SpanText(storage.StorageTypes[destination.Type])),
 			nodx.Td(
 				nodx.Div(
 					nodx.Class("flex items-center space-x-1"),
@@ -109,6 +111,13 @@ func listDestinations(
 					component.SpanText(destination.Region),
 				),
 			),
+			nodx.Td(
+				nodx.Div(
+					nodx.Class("flex items-center space-x-1"),
+					component.CopyButtonSm(destination.BasePath),
+					component.SpanText(destination.BasePath),
+				),
+			),
 			nodx.Td(
 				nodx.Div(
 					nodx.Class("flex items-center space-x-1"),
@@ -123,6 +132,20 @@ func listDestinations(
 					component.SpanText("**********"),
 				),
 			),
+			nodx.Td(
+				nodx.If(
+					destination.DecryptedBackupEncryptionKey == "",
+					component.SpanText("None"),
+				),
+				nodx.If(
+					destination.DecryptedBackupEncryptionKey != "",
+					nodx.Div(
+						nodx.Class("flex items-center space-x-1"),
+						component.CopyButtonSm(destination.DecryptedBackupEncryptionKey),
+						component.SpanText("AES-256-GCM"),
+					),
+				),
+			),
 			nodx.Td(component.SpanText(
 				destination.CreatedAt.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
 			)),
@@ -2,6 +2,7 @@ package destinations
 
 import (
 	"github.com/eduardolat/pgbackweb/internal/service"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
 	"github.com/eduardolat/pgbackweb/internal/view/middleware"
 	"github.com/labstack/echo/v4"
 )
@@ -18,12 +19,13 @@ func MountRouter(
 	parent *echo.Group, mids *middleware.Middleware, servs *service.Service,
 ) {
 	h := newHandlers(servs)
+	admin := mids.RequireRole(users.RoleAdmin)
 
 	parent.GET("", h.indexPageHandler)
 	parent.GET("/list", h.listDestinationsHandler)
-	parent.POST("", h.createDestinationHandler)
-	parent.POST("/test", h.testDestinationHandler)
-	parent.DELETE("/:destinationID", h.deleteDestinationHandler)
-	parent.POST("/:destinationID/edit", h.editDestinationHandler)
-	parent.POST("/:destinationID/test", h.testExistingDestinationHandler)
+	parent.POST("", h.createDestinationHandler, admin)
+	parent.POST("/test", h.testDestinationHandler, admin)
+	parent.DELETE("/:destinationID", h.deleteDestinationHandler, admin)
+	parent.POST("/:destinationID/edit", h.editDestinationHandler, admin)
+	parent.POST("/:destinationID/test", h.testExistingDestinationHandler, admin)
 }
@@ -0,0 +1,173 @@
+package drills
+
+import (
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/staticdata"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/google/uuid"
+	nodx "github.com/nodxdev/nodxgo"
+)
+
+// drillFormDTO holds the fields shared by the create and edit forms.
+type drillFormDTO struct {
+	Name           string    `form:"name" validate:"required"`
+	DatabaseID     uuid.UUID `form:"database_id" validate:"required,uuid"`
+	CronExpression string    `form:"cron_expression" validate:"required"`
+	TimeZone       string    `form:"time_zone" validate:"required"`
+	IsActive       string    `form:"is_active" validate:"required,oneof=true false"`
+	Assertions     string    `form:"assertions"`
+}
+
+func drillFormFields(
+	drill dbgen.RestoreDrill,
+	databases []dbgen.DatabasesServiceGetAllDatabasesRow,
+) nodx.Node {
+	timeZone := drill.TimeZone
+	if timeZone == "" {
+		timeZone = time.Now().Location().String()
+	}
+
+	return nodx.Group(
+		component.InputControl(component.InputControlParams{
+			Name:        "name",
+			Label:       "Name",
+			Placeholder: "Weekly restore drill",
+			Required:    true,
+			Type:        component.InputTypeText,
+			Children: []nodx.Node{
+				nodx.Value(drill.Name),
+			},
+		}),
+
+		component.SelectControl(component.SelectControlParams{
+			Name:               "database_id",
+			Label:              "Drill server",
+			Required:           true,
+			Placeholder:        "Select a database",
+			HelpText:           "Scratch databases are created and dropped on the server of this database",
+			HelpButtonChildren: drillServerHelp(),
+			Children: []nodx.Node{
+				nodx.Map(
+					databases,
+					func(db dbgen.DatabasesServiceGetAllDatabasesRow) nodx.Node {
+						if db.DatabaseType != database.DatabaseTypePostgreSQL {
+							return nil
+						}
+						return nodx.Option(
+							nodx.Value(db.ID.String()),
+							nodx.Text(db.Name),
+							nodx.If(db.ID == drill.DatabaseID, nodx.Selected("")),
+						)
+					},
+				),
+			},
+		}),
+
+		component.InputControl(component.InputControlParams{
+			Name:        "cron_expression",
+			Label:       "Cron expression",
+			Placeholder: "0 3 * * 0",
+			Required:    true,
+			Type:        component.InputTypeText,
+			HelpText:    "The cron expression to schedule the drill",
+			Pattern:     `^\S+\s+\S+\s+\S+\s+\S+\s+\S+$`,
+			Children: []nodx.Node{
+				nodx.Value(drill.CronExpression),
+			},
+		}),
+
+		component.SelectControl(component.SelectControlParams{
+			Name:        "time_zone",
+			Label:       "Time zone",
+			Required:    true,
+			Placeholder: "Select a time zone",
+			Children: []nodx.Node{
+				nodx.Map(
+					staticdata.Timezones,
+					func(tz staticdata.Timezone) nodx.Node {
+						return nodx.Option(
+							nodx.Value(tz.TzCode),
+							nodx.Text(tz.Label),
+							nodx.If(tz.TzCode == timeZone, nodx.Selected("")),
+						)
+					},
+				),
+			},
+		}),
+
+		component.SelectControl(component.SelectControlParams{
+			Name:     "is_active",
+			Label:    "Activate drill",
+			Required: true,
+			Children: []nodx.Node{
+				nodx.Option(
+					nodx.Value("true"), nodx.Text("Yes"),
+					nodx.If(drill.IsActive, nodx.Selected("")),
+				),
+				nodx.Option(
+					nodx.Value("false"), nodx.Text("No"),
+					nodx.If(!drill.IsActive, nodx.Selected("")),
+				),
+			},
+		}),
+
+		component.TextareaControl(component.TextareaControlParams{
+			Name:               "assertions",
+			Label:              "Assertions",
+			Placeholder:        "SELECT count(*) > 0 FROM users;\nSELECT max(created_at) > now() - interval '1 day' FROM orders;",
+			HelpText:           "SQL queries separated by semicolons, each one must return true",
+			HelpButtonChildren: assertionsHelp(),
+			Children: []nodx.Node{
+				nodx.Class("font-mono h-40"),
+				nodx.Text(drill.Assertions),
+			},
+		}),
+	)
+}
+
+func drillServerHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+			component.PText(`
+				Every drill run creates a new database named pbw_drill_<id> on the
+				server of the selected database, restores the latest successful
+				execution of the backup into it and drops it when the run finishes.
+			`),
+			component.PText(`
+				The user of the connection string must be allowed to create
+				databases. The selected database itself is never modified, but a
+				server that is not used in production is recommended.
+			`),
+		),
+	}
+}
+
+func assertionsHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+			component.PText(`
+				Assertions are SQL queries separated by semicolons that are run
+				against the restored scratch database in a read only transaction.
+				Every query must return a single true value, otherwise the drill
+				fails. Lines starting with -- are comments.
+			`),
+			nodx.Pre(
+				nodx.Class("bg-base-200 p-2 rounded text-sm"),
+				nodx.Text(`-- The users table is not empty
+SELECT count(*) > 0 FROM users;
+
+-- The backup contains recent orders
+SELECT max(created_at) > now() - interval '1 day' FROM orders;`),
+			),
+			component.PText(`
+				Without assertions the drill only checks that the backup can be
+				restored.
+			`),
+		),
+	}
+}
@@ -0,0 +1,138 @@
+package drills
+
+import (
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) createDrillHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var formData struct {
+		BackupID uuid.UUID `form:"backup_id" validate:"required,uuid"`
+		drillFormDTO
+	}
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	_, err := h.servs.DrillsService.CreateDrill(
+		ctx, dbgen.DrillsServiceCreateDrillParams{
+			BackupID:       formData.BackupID,
+			DatabaseID:     formData.DatabaseID,
+			Name:           formData.Name,
+			CronExpression: formData.CronExpression,
+			TimeZone:       formData.TimeZone,
+			IsActive:       formData.IsActive == "true",
+			Assertions:     formData.Assertions,
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.Redirect(c, pathutil.BuildPath("/dashboard/drills"))
+}
+
+func (h *handlers) createDrillFormHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	backups, err := h.servs.BackupsService.GetAllBackups(ctx)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	databases, err := h.servs.DatabasesService.GetAllDatabases(ctx)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(
+		c, http.StatusOK, createDrillForm(backups, databases),
+	)
+}
+
+func createDrillForm(
+	backups []dbgen.Backup,
+	databases []dbgen.DatabasesServiceGetAllDatabasesRow,
+) nodx.Node {
+	return nodx.FormEl(
+		htmx.HxPost(pathutil.BuildPath("/dashboard/drills")),
+		htmx.HxDisabledELT("find button"),
+		nodx.Class("space-y-2 text-base"),
+
+		component.SelectControl(component.SelectControlParams{
+			Name:        "backup_id",
+			Label:       "Backup task",
+			Required:    true,
+			Placeholder: "Select a backup task",
+			HelpText:    "The latest successful execution of this backup is restored",
+			Children: []nodx.Node{
+				nodx.Map(
+					backups,
+					func(backup dbgen.Backup) nodx.Node {
+						return nodx.Option(
+							nodx.Value(backup.ID.String()), nodx.Text(backup.Name),
+						)
+					},
+				),
+			},
+		}),
+
+		drillFormFields(dbgen.RestoreDrill{IsActive: true}, databases),
+
+		nodx.Div(
+			nodx.Class("flex justify-end items-center space-x-2 pt-2"),
+			component.HxLoadingMd(),
+			nodx.Button(
+				nodx.Class("btn btn-primary"),
+				nodx.Type("submit"),
+				component.SpanText("Save"),
+				lucide.Save(),
+			),
+		),
+	)
+}
+
+func createDrillButton() nodx.Node {
+	mo := component.Modal(component.ModalParams{
+		Size:  component.SizeLg,
+		Title: "Create restore drill",
+		Content: []nodx.Node{
+			nodx.Div(
+				htmx.HxGet(pathutil.BuildPath("/dashboard/drills/create-form")),
+				htmx.HxSwap("outerHTML"),
+				htmx.HxTrigger("intersect once"),
+				nodx.Class("p-10 flex justify-center"),
+				component.HxLoadingMd(),
+			),
+		},
+	})
+
+	button := nodx.Button(
+		mo.OpenerAttr,
+		nodx.Class("btn btn-primary"),
+		component.SpanText("Create restore drill"),
+		lucide.Plus(),
+	)
+
+	return nodx.Div(
+		nodx.Class("inline-block"),
+		mo.HTML,
+		button,
+	)
+}
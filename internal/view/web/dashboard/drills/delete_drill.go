@@ -0,0 +1,38 @@
+package drills
+
+import (
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) deleteDrillHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	drillID, err := uuid.Parse(c.Param("drillID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	if err = h.servs.DrillsService.DeleteDrill(ctx, drillID); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.Refresh(c)
+}
+
+func deleteDrillButton(drillID uuid.UUID) nodx.Node {
+	return component.OptionsDropdownButton(
+		htmx.HxDelete(pathutil.BuildPath(fmt.Sprintf("/dashboard/drills/%s", drillID))),
+		htmx.HxConfirm("Are you sure you want to delete this restore drill and its runs?"),
+		lucide.Trash(),
+		component.SpanText("Delete drill"),
+	)
+}
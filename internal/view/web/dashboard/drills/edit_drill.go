@@ -0,0 +1,123 @@
+package drills
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) editDrillHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	drillID, err := uuid.Parse(c.Param("drillID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	var formData drillFormDTO
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	_, err = h.servs.DrillsService.UpdateDrill(
+		ctx, dbgen.DrillsServiceUpdateDrillParams{
+			ID:             drillID,
+			DatabaseID:     uuid.NullUUID{UUID: formData.DatabaseID, Valid: true},
+			Name:           sql.NullString{String: formData.Name, Valid: true},
+			CronExpression: sql.NullString{String: formData.CronExpression, Valid: true},
+			TimeZone:       sql.NullString{String: formData.TimeZone, Valid: true},
+			IsActive:       sql.NullBool{Bool: formData.IsActive == "true", Valid: true},
+			Assertions:     sql.NullString{String: formData.Assertions, Valid: true},
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.AlertWithRefresh(c, "Restore drill updated")
+}
+
+func (h *handlers) editDrillFormHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	drillID, err := uuid.Parse(c.Param("drillID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	drill, err := h.servs.DrillsService.GetDrill(ctx, drillID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	databases, err := h.servs.DatabasesService.GetAllDatabases(ctx)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, editDrillForm(drill, databases))
+}
+
+func editDrillForm(
+	drill dbgen.RestoreDrill,
+	databases []dbgen.DatabasesServiceGetAllDatabasesRow,
+) nodx.Node {
+	return nodx.FormEl(
+		htmx.HxPost(pathutil.BuildPath(fmt.Sprintf("/dashboard/drills/%s/edit", drill.ID))),
+		htmx.HxDisabledELT("find button[type='submit']"),
+		nodx.Class("space-y-2 text-base"),
+
+		drillFormFields(drill, databases),
+
+		nodx.Div(
+			nodx.Class("flex justify-end items-center space-x-2 pt-2"),
+			component.HxLoadingMd(),
+			nodx.Button(
+				nodx.Class("btn btn-primary"),
+				nodx.Type("submit"),
+				component.SpanText("Save"),
+				lucide.Save(),
+			),
+		),
+	)
+}
+
+func editDrillButton(drillID uuid.UUID) nodx.Node {
+	mo := component.Modal(component.ModalParams{
+		Size:  component.SizeLg,
+		Title: "Edit restore drill",
+		Content: []nodx.Node{
+			nodx.Div(
+				htmx.HxGet(pathutil.BuildPath(fmt.Sprintf("/dashboard/drills/%s/edit", drillID))),
+				htmx.HxSwap("outerHTML"),
+				htmx.HxTrigger("intersect once"),
+				nodx.Class("p-10 flex justify-center"),
+				component.HxLoadingMd(),
+			),
+		},
+	})
+
+	return nodx.Div(
+		mo.HTML,
+		component.OptionsDropdownButton(
+			mo.OpenerAttr,
+			lucide.Pencil(),
+			component.SpanText("Edit drill"),
+		),
+	)
+}
@@ -0,0 +1,121 @@
+package drills
+
+import (
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/layout"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+)
+
+type drillsQueryData struct {
+	Drill uuid.UUID `query:"drill" validate:"omitempty,uuid"`
+}
+
+func (h *handlers) indexPageHandler(c echo.Context) error {
+	reqCtx := reqctx.GetCtx(c)
+
+	var queryData drillsQueryData
+	if err := c.Bind(&queryData); err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	if err := validate.Struct(&queryData); err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, indexPage(reqCtx, queryData))
+}
+
+func indexPage(reqCtx reqctx.Ctx, queryData drillsQueryData) nodx.Node {
+	content := []nodx.Node{
+		nodx.Div(
+			nodx.Class("flex justify-between items-start"),
+			nodx.Div(
+				component.H1Text("Restore drills"),
+				component.PText(`
+					Restore drills periodically restore the latest backup into a
+					scratch database and check it with your own SQL assertions.
+				`),
+			),
+			createDrillButton(),
+		),
+		component.CardBox(component.CardBoxParams{
+			Class: "mt-4",
+			Children: []nodx.Node{
+				nodx.Div(
+					nodx.Class("overflow-x-auto"),
+					nodx.Table(
+						nodx.Class("table text-nowrap"),
+						nodx.Thead(
+							nodx.Tr(
+								nodx.Th(nodx.Class("w-1")),
+								nodx.Th(component.SpanText("Name")),
+								nodx.Th(component.SpanText("Backup")),
+								nodx.Th(component.SpanText("Drill server")),
+								nodx.Th(component.SpanText("Schedule")),
+								nodx.Th(component.SpanText("Last run")),
+								nodx.Th(component.SpanText("Created at")),
+							),
+						),
+						nodx.Tbody(
+							component.SkeletonTr(8),
+							htmx.HxGet(pathutil.BuildPath("/dashboard/drills/list?page=1")),
+							htmx.HxTrigger("load"),
+						),
+					),
+				),
+			},
+		}),
+
+		nodx.Div(
+			nodx.Class("mt-6"),
+			component.H2Text("Drill runs"),
+		),
+		component.CardBox(component.CardBoxParams{
+			Class: "mt-4",
+			Children: []nodx.Node{
+				nodx.Div(
+					nodx.Class("overflow-x-auto"),
+					nodx.Table(
+						nodx.Class("table text-nowrap"),
+						nodx.Thead(
+							nodx.Tr(
+								nodx.Th(nodx.Class("w-1")),
+								nodx.Th(component.SpanText("Status")),
+								nodx.Th(component.SpanText("Drill")),
+								nodx.Th(component.SpanText("Backup")),
+								nodx.Th(component.SpanText("Assertions")),
+								nodx.Th(component.SpanText("Started at")),
+								nodx.Th(component.SpanText("Duration")),
+							),
+						),
+						nodx.Tbody(
+							component.SkeletonTr(8),
+							htmx.HxGet(func() string {
+								url := pathutil.BuildPath("/dashboard/drills/runs?page=1")
+								if queryData.Drill != uuid.Nil {
+									url = strutil.AddQueryParamToUrl(url, "drill", queryData.Drill.String())
+								}
+								return url
+							}()),
+							htmx.HxTrigger("load"),
+						),
+					),
+				),
+			},
+		}),
+	}
+
+	return layout.Dashboard(reqCtx, layout.DashboardParams{
+		Title: "Restore drills",
+		Body:  content,
+	})
+}
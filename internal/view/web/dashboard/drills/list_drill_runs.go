@@ -0,0 +1,120 @@
+package drills
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/drills"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+)
+
+type listDrillRunsQueryData struct {
+	Drill uuid.UUID `query:"drill" validate:"omitempty,uuid"`
+	Page  int       `query:"page" validate:"required,min=1"`
+}
+
+func (h *handlers) listDrillRunsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryData listDrillRunsQueryData
+	if err := c.Bind(&queryData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&queryData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	pagination, runs, err := h.servs.DrillsService.PaginateDrillRuns(
+		ctx, drills.PaginateDrillRunsParams{
+			DrillFilter: uuid.NullUUID{
+				UUID: queryData.Drill, Valid: queryData.Drill != uuid.Nil,
+			},
+			Page:  queryData.Page,
+			Limit: 20,
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(
+		c, http.StatusOK, listDrillRuns(queryData, pagination, runs),
+	)
+}
+
+func listDrillRuns(
+	queryData listDrillRunsQueryData,
+	pagination paginateutil.PaginateResponse,
+	runs []dbgen.DrillsServicePaginateDrillRunsRow,
+) nodx.Node {
+	if len(runs) < 1 {
+		return component.EmptyResultsTr(component.EmptyResultsParams{
+			Title:    "No drill runs found",
+			Subtitle: "Wait for the first restore drill to run",
+		})
+	}
+
+	trs := []nodx.Node{}
+	for _, run := range runs {
+		results := drills.ParseAssertionResults(run.AssertionResults)
+		passed := 0
+		for _, result := range results {
+			if result.Passed {
+				passed++
+			}
+		}
+
+		trs = append(trs, nodx.Tr(
+			nodx.Td(showDrillRunButton(run, results)),
+			nodx.Td(component.StatusBadge(run.Status)),
+			nodx.Td(component.SpanText(run.DrillName)),
+			nodx.Td(component.SpanText(run.BackupName)),
+			nodx.Td(
+				nodx.If(
+					len(results) > 0,
+					component.SpanText(fmt.Sprintf("%d/%d passed", passed, len(results))),
+				),
+			),
+			nodx.Td(component.SpanText(
+				run.StartedAt.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
+			)),
+			nodx.Td(
+				nodx.If(
+					run.FinishedAt.Valid,
+					component.SpanText(
+						run.FinishedAt.Time.Sub(run.StartedAt).String(),
+					),
+				),
+			),
+		))
+	}
+
+	if pagination.HasNextPage {
+		trs = append(trs, nodx.Tr(
+			htmx.HxGet(func() string {
+				url := pathutil.BuildPath("/dashboard/drills/runs")
+				url = strutil.AddQueryParamToUrl(url, "page", fmt.Sprintf("%d", pagination.NextPage))
+				if queryData.Drill != uuid.Nil {
+					url = strutil.AddQueryParamToUrl(url, "drill", queryData.Drill.String())
+				}
+				return url
+			}()),
+			htmx.HxTrigger("intersect once"),
+			htmx.HxSwap("afterend"),
+		))
+	}
+
+	return component.RenderableGroup(trs)
+}
@@ -0,0 +1,120 @@
+package drills
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/drills"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/paginateutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) listDrillsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryData struct {
+		Page int `query:"page" validate:"required,min=1"`
+	}
+	if err := c.Bind(&queryData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&queryData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	pagination, drills, err := h.servs.DrillsService.PaginateDrills(
+		ctx, drills.PaginateDrillsParams{
+			Page:  queryData.Page,
+			Limit: 20,
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, listDrills(pagination, drills))
+}
+
+func listDrills(
+	pagination paginateutil.PaginateResponse,
+	drills []dbgen.DrillsServicePaginateDrillsRow,
+) nodx.Node {
+	if len(drills) < 1 {
+		return component.EmptyResultsTr(component.EmptyResultsParams{
+			Title:    "No restore drills found",
+			Subtitle: "Create a restore drill to check your backups periodically",
+		})
+	}
+
+	trs := []nodx.Node{}
+	for _, drill := range drills {
+		trs = append(trs, nodx.Tr(
+			nodx.Td(component.OptionsDropdown(
+				component.OptionsDropdownA(
+					nodx.Href(pathutil.BuildPath(
+						fmt.Sprintf("/dashboard/drills?drill=%s", drill.ID),
+					)),
+					lucide.List(),
+					component.SpanText("Show drill runs"),
+				),
+				runDrillButton(drill.ID),
+				editDrillButton(drill.ID),
+				deleteDrillButton(drill.ID),
+			)),
+			nodx.Td(
+				nodx.Div(
+					nodx.Class("flex items-center space-x-2"),
+					component.IsActivePing(drill.IsActive),
+					component.SpanText(drill.Name),
+				),
+			),
+			nodx.Td(component.SpanText(drill.BackupName)),
+			nodx.Td(component.SpanText(drill.DatabaseName)),
+			nodx.Td(
+				nodx.Div(
+					nodx.Class("flex flex-col items-start text-xs"),
+					component.SpanText(drill.CronExpression),
+					component.SpanText(drill.TimeZone),
+				),
+			),
+			nodx.Td(
+				nodx.If(
+					drill.LastRunStatus.Valid,
+					nodx.Div(
+						nodx.Class("flex flex-col items-start space-y-1 text-xs"),
+						component.StatusBadge(drill.LastRunStatus.String),
+						component.SpanText(
+							drill.LastRunStartedAt.Time.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
+						),
+					),
+				),
+				nodx.If(!drill.LastRunStatus.Valid, component.SpanText("Never")),
+			),
+			nodx.Td(component.SpanText(
+				drill.CreatedAt.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
+			)),
+		))
+	}
+
+	if pagination.HasNextPage {
+		trs = append(trs, nodx.Tr(
+			htmx.HxGet(pathutil.BuildPath(
+				fmt.Sprintf("/dashboard/drills/list?page=%d", pagination.NextPage),
+			)),
+			htmx.HxTrigger("intersect once"),
+			htmx.HxSwap("afterend"),
+		))
+	}
+
+	return component.RenderableGroup(trs)
+}
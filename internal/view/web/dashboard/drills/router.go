@@ -0,0 +1,34 @@
+package drills
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/service"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
+	"github.com/eduardolat/pgbackweb/internal/view/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+type handlers struct {
+	servs *service.Service
+}
+
+func newHandlers(servs *service.Service) *handlers {
+	return &handlers{servs: servs}
+}
+
+func MountRouter(
+	parent *echo.Group, mids *middleware.Middleware, servs *service.Service,
+) {
+	h := newHandlers(servs)
+	admin := mids.RequireRole(users.RoleAdmin)
+	operator := mids.RequireRole(users.RoleAdmin, users.RoleOperator)
+
+	parent.GET("", h.indexPageHandler)
+	parent.GET("/list", h.listDrillsHandler)
+	parent.GET("/runs", h.listDrillRunsHandler)
+	parent.GET("/create-form", h.createDrillFormHandler, admin)
+	parent.POST("", h.createDrillHandler, admin)
+	parent.GET("/:drillID/edit", h.editDrillFormHandler, admin)
+	parent.POST("/:drillID/edit", h.editDrillHandler, admin)
+	parent.POST("/:drillID/run", h.runDrillHandler, operator)
+	parent.DELETE("/:drillID", h.deleteDrillHandler, admin)
+}
@@ -0,0 +1,37 @@
+package drills
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) runDrillHandler(c echo.Context) error {
+	drillID, err := uuid.Parse(c.Param("drillID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	go func() {
+		_ = h.servs.DrillsService.RunDrill(context.Background(), drillID)
+	}()
+
+	return respondhtmx.ToastSuccess(c, "Restore drill started, check the drill runs for more details")
+}
+
+func runDrillButton(drillID uuid.UUID) nodx.Node {
+	return component.OptionsDropdownButton(
+		htmx.HxPost(pathutil.BuildPath(fmt.Sprintf("/dashboard/drills/%s/run", drillID))),
+		htmx.HxDisabledELT("this"),
+		lucide.Zap(),
+		component.SpanText("Run drill now"),
+	)
+}
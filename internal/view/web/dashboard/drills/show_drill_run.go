@@ -0,0 +1,125 @@
+package drills
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/drills"
+	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	nodx "github.com/nodxdev/nodxgo"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func showDrillRunButton(
+	run dbgen.DrillsServicePaginateDrillRunsRow,
+	results []drills.AssertionResult,
+) nodx.Node {
+	mo := component.Modal(component.ModalParams{
+		Title: "Drill run details",
+		Size:  component.SizeLg,
+		Content: []nodx.Node{
+			nodx.Div(
+				nodx.Class("overflow-x-auto"),
+				nodx.Table(
+					nodx.Class("table [&_th]:text-nowrap"),
+					nodx.Tr(
+						nodx.Th(component.SpanText("ID")),
+						nodx.Td(component.SpanText(run.ID.String())),
+					),
+					nodx.Tr(
+						nodx.Th(component.SpanText("Status")),
+						nodx.Td(component.StatusBadge(run.Status)),
+					),
+					nodx.Tr(
+						nodx.Th(component.SpanText("Drill")),
+						nodx.Td(component.SpanText(run.DrillName)),
+					),
+					nodx.Tr(
+						nodx.Th(component.SpanText("Backup")),
+						nodx.Td(component.SpanText(run.BackupName)),
+					),
+					nodx.If(
+						run.ExecutionID.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Execution")),
+							nodx.Td(component.SpanText(run.ExecutionID.UUID.String())),
+						),
+					),
+					nodx.If(
+						run.ScratchDatabase != "",
+						nodx.Tr(
+							nodx.Th(component.SpanText("Scratch database")),
+							nodx.Td(component.SpanText(run.ScratchDatabase)),
+						),
+					),
+					nodx.If(
+						run.Message.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Message")),
+							nodx.Td(
+								nodx.Class("break-all"),
+								component.SpanText(run.Message.String),
+							),
+						),
+					),
+					nodx.Tr(
+						nodx.Th(component.SpanText("Started At")),
+						nodx.Td(component.SpanText(
+							run.StartedAt.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
+						)),
+					),
+					nodx.If(
+						run.FinishedAt.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Took")),
+							nodx.Td(component.SpanText(
+								run.FinishedAt.Time.Sub(run.StartedAt).String(),
+							)),
+						),
+					),
+				),
+			),
+
+			nodx.If(
+				len(results) > 0,
+				nodx.Div(
+					nodx.Class("mt-4 space-y-2"),
+					component.H3Text("Assertions"),
+					nodx.Map(results, func(result drills.AssertionResult) nodx.Node {
+						return nodx.Div(
+							nodx.Class("rounded-box border border-base-300 p-2 space-y-1"),
+							nodx.Div(
+								nodx.Class("flex items-center space-x-2"),
+								nodx.If(result.Passed, lucide.CircleCheck(nodx.Class("text-success"))),
+								nodx.If(!result.Passed, lucide.CircleX(nodx.Class("text-error"))),
+								nodx.Pre(
+									nodx.Class("text-sm whitespace-pre-wrap break-all"),
+									nodx.Text(result.Query),
+								),
+							),
+							nodx.If(
+								!result.Passed,
+								nodx.Pre(
+									nodx.Class("text-xs whitespace-pre-wrap break-all opacity-70"),
+									nodx.Text(result.Output),
+								),
+							),
+						)
+					}),
+				),
+			),
+		},
+	})
+
+	button := nodx.Button(
+		mo.OpenerAttr,
+		nodx.Class("btn btn-square btn-sm btn-ghost"),
+		lucide.Eye(),
+	)
+
+	return nodx.Div(
+		nodx.Class("inline-block tooltip tooltip-right"),
+		nodx.Data("tip", "Show details"),
+		mo.HTML,
+		button,
+	)
+}
@@ -0,0 +1,41 @@
+package executions
+
+import (
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) cancelExecutionHandler(c echo.Context) error {
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	err = h.servs.ExecutionsService.CancelExecution(executionID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.AlertWithRefresh(
+		c, "The execution is being cancelled, its partial files will be removed",
+	)
+}
+
+func cancelExecutionButton(executionID uuid.UUID) nodx.Node {
+	return nodx.Button(
+		htmx.HxPost(pathutil.BuildPath(fmt.Sprintf("/dashboard/executions/%s/cancel", executionID))),
+		htmx.HxDisabledELT("this"),
+		htmx.HxConfirm("Are you sure you want to cancel this execution? The backup being created will be discarded."),
+		nodx.Class("btn btn-warning btn-outline"),
+		component.SpanText("Cancel execution"),
+		lucide.CircleStop(),
+	)
+}
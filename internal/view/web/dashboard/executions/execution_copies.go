@@ -0,0 +1,100 @@
+package executions
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+)
+
+func (h *handlers) listExecutionCopiesHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	copies, err := h.servs.ExecutionsService.ListExecutionCopies(ctx, executionID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, executionCopiesTable(copies))
+}
+
+// executionCopiesLoader lazy loads the copies of an execution when the
+// details modal is opened.
+func executionCopiesLoader(executionID uuid.UUID) nodx.Node {
+	return nodx.Div(
+		htmx.HxGet(pathutil.BuildPath(fmt.Sprintf("/dashboard/executions/%s/copies", executionID))),
+		htmx.HxSwap("outerHTML"),
+		htmx.HxTrigger("intersect once"),
+		nodx.Class("p-4 flex justify-center"),
+		component.HxLoadingMd(),
+	)
+}
+
+func executionCopiesTable(
+	copies []dbgen.ExecutionsServiceListExecutionCopiesRow,
+) nodx.Node {
+	if len(copies) < 1 {
+		return nil
+	}
+
+	return nodx.Div(
+		nodx.Class("overflow-x-auto"),
+		component.H3Text("Copies"),
+		nodx.Table(
+			nodx.Class("table table-sm"),
+			nodx.Thead(
+				nodx.Tr(
+					nodx.Th(component.SpanText("Location")),
+					nodx.Th(component.SpanText("Status")),
+					nodx.Th(component.SpanText("Size")),
+					nodx.Th(component.SpanText("Message")),
+				),
+			),
+			nodx.Tbody(
+				nodx.Map(
+					copies,
+					func(cp dbgen.ExecutionsServiceListExecutionCopiesRow) nodx.Node {
+						location := executions.CopyLocation(cp.IsLocal, cp.DestinationName)
+						if cp.IsPrimary {
+							location += " (primary)"
+						}
+
+						return nodx.Tr(
+							nodx.Td(component.SpanText(location)),
+							nodx.Td(
+								nodx.Div(
+									nodx.Class("flex items-center space-x-1"),
+									component.StatusBadge(cp.Status),
+									integrityBadge(cp.VerifyStatus),
+								),
+							),
+							nodx.Td(nodx.If(
+								cp.FileSize.Valid,
+								component.SpanText(strutil.FormatFileSize(cp.FileSize.Int64)),
+							)),
+							nodx.Td(nodx.If(
+								cp.Message.Valid,
+								component.SpanText(cp.Message.String),
+							)),
+						)
+					},
+				),
+			),
+		),
+	)
+}
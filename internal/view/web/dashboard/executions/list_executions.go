@@ -81,7 +81,13 @@ func listExecutions(
 				showExecutionButton(execution),
 				restoreExecutionButton(execution),
 			)),
-			nodx.Td(component.StatusBadge(execution.Status)),
+			nodx.Td(
+				nodx.Div(
+					nodx.Class("flex items-center space-x-1"),
+					component.StatusBadge(execution.Status),
+					integrityBadge(execution.VerifyStatus),
+				),
+			),
 			nodx.Td(component.SpanText(execution.BackupName)),
 			nodx.Td(component.SpanText(execution.DatabaseName)),
 			nodx.Td(component.PrettyDestinationName(
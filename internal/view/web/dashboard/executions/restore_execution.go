@@ -6,9 +6,13 @@ import (
 	"net/http"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/service/restorations"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
 	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
 	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
 	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
 	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
 	"github.com/google/uuid"
@@ -21,11 +25,16 @@ import (
 
 func (h *handlers) restoreExecutionHandler(c echo.Context) error {
 	ctx := c.Request().Context()
+	reqCtx := reqctx.GetCtx(c)
 
 	var formData struct {
 		ExecutionID uuid.UUID `form:"execution_id" validate:"required,uuid"`
 		DatabaseID  uuid.UUID `form:"database_id" validate:"omitempty,uuid"`
 		ConnString  string    `form:"conn_string" validate:"omitempty"`
+		Hooks       component.HooksFormData
+		Selection   restoreSelectionFormData
+		NewDatabase newDatabaseFormData
+		Guards      restoreGuardsFormData
 	}
 	if err := c.Bind(&formData); err != nil {
 		return respondhtmx.ToastError(c, err.Error())
@@ -46,6 +55,34 @@ func (h *handlers) restoreExecutionHandler(c echo.Context) error {
 		)
 	}
 
+	err := h.servs.RestorationsService.ValidateRestoreTarget(
+		ctx, reqCtx.User.Role, uuid.NullUUID{
+			Valid: formData.DatabaseID != uuid.Nil,
+			UUID:  formData.DatabaseID,
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	restoreHooks := formData.Hooks.Hooks()
+	err = h.servs.RestorationsService.ValidateRestoreHooks(
+		reqCtx.User.Role, restoreHooks,
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	selection, err := formData.Selection.selection()
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	newDatabase, err := formData.NewDatabase.newDatabase()
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
 	execution, err := h.servs.ExecutionsService.GetExecution(
 		ctx, formData.ExecutionID,
 	)
@@ -62,17 +99,30 @@ func (h *handlers) restoreExecutionHandler(c echo.Context) error {
 		}
 	}
 
+	params := restorations.RunRestorationParams{
+		ExecutionID: formData.ExecutionID,
+		DatabaseID: uuid.NullUUID{
+			Valid: formData.DatabaseID != uuid.Nil,
+			UUID:  formData.DatabaseID,
+		},
+		ConnString:       formData.ConnString,
+		Hooks:            restoreHooks,
+		Selection:        selection,
+		NewDatabase:      newDatabase,
+		DryRun:           formData.Guards.DryRun == "true",
+		PreRestoreBackup: formData.Guards.PreRestoreBackup == "true",
+	}
+
+	err = h.servs.RestorationsService.CheckRestoreConfirmation(
+		ctx, params, formData.Guards.ConfirmDatabaseName,
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
 	go func() {
 		ctx := context.Background()
-		_ = h.servs.RestorationsService.RunRestoration(
-			ctx,
-			formData.ExecutionID,
-			uuid.NullUUID{
-				Valid: formData.DatabaseID != uuid.Nil,
-				UUID:  formData.DatabaseID,
-			},
-			formData.ConnString,
-		)
+		_ = h.servs.RestorationsService.RunRestoration(ctx, params)
 	}()
 
 	return respondhtmx.ToastSuccess(
@@ -99,20 +149,43 @@ func (h *handlers) restoreExecutionFormHandler(c echo.Context) error {
 	}
 
 	return echoutil.RenderNodx(c, http.StatusOK, restoreExecutionForm(
-		execution, databases,
+		execution, databases, reqctx.GetCtx(c).User.Role,
 	))
 }
 
 func restoreExecutionForm(
 	execution dbgen.ExecutionsServiceGetExecutionRow,
-	databases []dbgen.DatabasesServiceGetAllDatabasesRow,
+	databases []dbgen.DatabasesServiceGetAllDatabasesRow, role string,
 ) nodx.Node {
+	if execution.BackupMode == postgres.BackupModePhysical {
+		return nodx.Div(
+			nodx.Role("alert"),
+			nodx.Class("alert alert-info"),
+			lucide.Info(),
+			component.PText(`
+				This execution is a base backup of a physical backup task, it can't
+				be restored into a database. Use the point-in-time recovery option
+				of the backup task instead.
+			`),
+		)
+	}
+
+	pickedDatabaseID := ""
+	for _, db := range databases {
+		if db.ID == execution.DatabaseID {
+			pickedDatabaseID = db.ID.String()
+		}
+	}
+
 	return nodx.FormEl(
 		htmx.HxPost(pathutil.BuildPath(fmt.Sprintf("/dashboard/executions/%s/restore", execution.ID))),
 		htmx.HxConfirm("Are you sure you want to restore this backup?"),
 		htmx.HxDisabledELT("find button"),
 
-		alpine.XData(`{ backup_to: "database" }`),
+		alpine.XData(fmt.Sprintf(
+			`{ backup_to: "database", new_database: "false", dry_run: "false", database_id: %q, protected: %s }`,
+			pickedDatabaseID, protectedDatabases(databases),
+		)),
 
 		nodx.Input(
 			nodx.Type("hidden"),
@@ -150,6 +223,7 @@ func restoreExecutionForm(
 					Placeholder: "Select a database",
 					Required:    true,
 					Children: []nodx.Node{
+						alpine.XModel("database_id"),
 						nodx.Map(
 							databases,
 							func(db dbgen.DatabasesServiceGetAllDatabasesRow) nodx.Node {
@@ -178,6 +252,17 @@ func restoreExecutionForm(
 				}),
 			),
 
+			restoreNewDatabaseSection(execution),
+
+			restoreSelectionSection(execution),
+
+			nodx.If(
+				role == users.RoleAdmin,
+				component.HooksSection("restore", nil),
+			),
+
+			restoreGuardsSection(execution),
+
 			nodx.Div(
 				nodx.Class("pt-2"),
 				nodx.Div(
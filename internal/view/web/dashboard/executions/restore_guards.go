@@ -0,0 +1,102 @@
+package executions
+
+import (
+	"encoding/json"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	nodx "github.com/nodxdev/nodxgo"
+	alpine "github.com/nodxdev/nodxgo-alpine"
+)
+
+// restoreGuardsFormData binds the fields of restoreGuardsSection.
+type restoreGuardsFormData struct {
+	DryRun              string `form:"dry_run" validate:"omitempty,oneof=true false"`
+	PreRestoreBackup    string `form:"pre_restore_backup" validate:"omitempty,oneof=true false"`
+	ConfirmDatabaseName string `form:"confirm_database_name"`
+}
+
+// protectedDatabases returns the names of the protected databases by their
+// ID as a JavaScript object for the Alpine data of the form.
+func protectedDatabases(
+	databases []dbgen.DatabasesServiceGetAllDatabasesRow,
+) string {
+	protected := map[string]string{}
+	for _, db := range databases {
+		if db.IsProtected {
+			protected[db.ID.String()] = db.Name
+		}
+	}
+	data, _ := json.Marshal(protected)
+	return string(data)
+}
+
+// restoreGuardsSection renders the dry run, pre-restore backup and protected
+// database confirmation fields. It expects the backup_to, new_database,
+// dry_run, database_id and protected properties in the Alpine data of the
+// form.
+func restoreGuardsSection(
+	execution dbgen.ExecutionsServiceGetExecutionRow,
+) nodx.Node {
+	writesTarget := "backup_to === 'database' && new_database !== 'true' && dry_run !== 'true'"
+
+	return nodx.Div(
+		nodx.Class("space-y-2"),
+
+		nodx.If(
+			execution.DatabaseDatabaseType == database.DatabaseTypePostgreSQL,
+			component.SelectControl(component.SelectControlParams{
+				Name:     "dry_run",
+				Label:    "Mode",
+				Required: true,
+				HelpText: "A dry run checks the backup and the target without writing anything, the hooks don't run",
+				Children: []nodx.Node{
+					alpine.XModel("dry_run"),
+					nodx.Option(
+						nodx.Value("false"),
+						nodx.Text("Restore the backup"),
+						nodx.Selected(""),
+					),
+					nodx.Option(
+						nodx.Value("true"),
+						nodx.Text("Dry run"),
+					),
+				},
+			}),
+		),
+
+		alpine.Template(
+			alpine.XIf(writesTarget),
+			component.SelectControl(component.SelectControlParams{
+				Name:     "pre_restore_backup",
+				Label:    "Pre-restore backup",
+				Required: true,
+				HelpText: "Queues a run of one of the backup tasks of the database and waits for it before restoring, the backup is pinned so retention never deletes it, restore it to roll back",
+				Children: []nodx.Node{
+					nodx.Option(
+						nodx.Value("false"),
+						nodx.Text("Don't back up the database first"),
+						nodx.Selected(""),
+					),
+					nodx.Option(
+						nodx.Value("true"),
+						nodx.Text("Back up the database first"),
+					),
+				},
+			}),
+		),
+
+		alpine.Template(
+			alpine.XIf(writesTarget+" && protected[database_id]"),
+			component.InputControl(component.InputControlParams{
+				Name:        "confirm_database_name",
+				Label:       "Confirm the database name",
+				Placeholder: "Database name",
+				Type:        component.InputTypeText,
+				Required:    true,
+				HelpText:    "The picked database is protected, type its name to confirm the restoration",
+			}),
+		),
+	)
+}
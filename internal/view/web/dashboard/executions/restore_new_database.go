@@ -0,0 +1,138 @@
+package executions
+
+import (
+	"strings"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	nodx "github.com/nodxdev/nodxgo"
+	alpine "github.com/nodxdev/nodxgo-alpine"
+)
+
+// newDatabaseFormData binds the fields of restoreNewDatabaseSection, the
+// other fields are only sent when new_database is true.
+type newDatabaseFormData struct {
+	Enabled  string `form:"new_database" validate:"omitempty,oneof=true false"`
+	Name     string `form:"new_database_name"`
+	Template string `form:"new_database_template"`
+	Owner    string `form:"new_database_owner"`
+	NoOwner  string `form:"new_database_no_owner"`
+	RoleMap  string `form:"new_database_role_map"`
+}
+
+// newDatabase returns the new database settings of the form, empty when the
+// backup is restored into the target database itself.
+func (f newDatabaseFormData) newDatabase() (postgres.NewDatabase, error) {
+	if f.Enabled != "true" {
+		return postgres.NewDatabase{}, nil
+	}
+
+	roleMap, err := postgres.ParseRoleMap(f.RoleMap)
+	if err != nil {
+		return postgres.NewDatabase{}, err
+	}
+
+	newDatabase := postgres.NewDatabase{
+		Name:     strings.TrimSpace(f.Name),
+		Template: strings.TrimSpace(f.Template),
+		Owner:    strings.TrimSpace(f.Owner),
+		NoOwner:  f.NoOwner == "true",
+		RoleMap:  roleMap,
+	}
+	return newDatabase, newDatabase.Validate()
+}
+
+// restoreNewDatabaseSection renders the fields to restore into a database
+// created for it, only for PostgreSQL databases. It expects a new_database
+// property in the Alpine data of the form.
+func restoreNewDatabaseSection(
+	execution dbgen.ExecutionsServiceGetExecutionRow,
+) nodx.Node {
+	if execution.DatabaseDatabaseType != database.DatabaseTypePostgreSQL {
+		return nil
+	}
+
+	return nodx.Div(
+		nodx.Class("space-y-2"),
+
+		component.SelectControl(component.SelectControlParams{
+			Name:     "new_database",
+			Label:    "Restore into",
+			Required: true,
+			HelpText: "A new database is created in the server of the picked database or connection string, and dropped again if the restoration fails",
+			Children: []nodx.Node{
+				alpine.XModel("new_database"),
+				nodx.Option(
+					nodx.Value("false"),
+					nodx.Text("The picked database"),
+					nodx.Selected(""),
+				),
+				nodx.Option(
+					nodx.Value("true"),
+					nodx.Text("A new database"),
+				),
+			},
+		}),
+
+		alpine.Template(
+			alpine.XIf("new_database === 'true'"),
+			nodx.Div(
+				nodx.Class("grid grid-cols-2 gap-2"),
+				component.InputControl(component.InputControlParams{
+					Name:        "new_database_name",
+					Label:       "Name",
+					Placeholder: "mydb_restored",
+					Type:        component.InputTypeText,
+					Required:    true,
+					HelpText:    "It must not exist yet",
+				}),
+				component.InputControl(component.InputControlParams{
+					Name:        "new_database_template",
+					Label:       "Template",
+					Placeholder: "template0",
+					Type:        component.InputTypeText,
+					HelpText:    "Empty to use the server default",
+					Children: []nodx.Node{
+						nodx.Value("template0"),
+					},
+				}),
+				component.InputControl(component.InputControlParams{
+					Name:        "new_database_owner",
+					Label:       "Owner",
+					Placeholder: "app",
+					Type:        component.InputTypeText,
+					HelpText:    "Empty to use the role of the connection",
+				}),
+				component.SelectControl(component.SelectControlParams{
+					Name:     "new_database_no_owner",
+					Label:    "Object owners",
+					Required: true,
+					HelpText: "Without owners every object belongs to the role of the connection",
+					Children: []nodx.Node{
+						nodx.Option(
+							nodx.Value("false"), nodx.Text("Keep the owners of the backup"),
+							nodx.Selected(""),
+						),
+						nodx.Option(
+							nodx.Value("true"), nodx.Text("Don't set owners (--no-owner)"),
+						),
+					},
+				}),
+				nodx.Div(
+					nodx.Class("col-span-2"),
+					component.TextareaControl(component.TextareaControlParams{
+						Name:        "new_database_role_map",
+						Label:       "Role mapping",
+						Placeholder: "app=app_staging\nreporting=analyst",
+						HelpText:    "One old_role=new_role per line, the owners and grants of the old roles are given to the new ones",
+						Children: []nodx.Node{
+							nodx.Class("font-mono"),
+						},
+					}),
+				),
+			),
+		),
+	)
+}
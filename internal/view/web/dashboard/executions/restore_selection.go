@@ -0,0 +1,231 @@
+package executions
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/database"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+// restoreSelectionFormData binds the fields rendered by restoreObjectsList,
+// nothing is sent until the objects are loaded so the whole backup is
+// restored by default.
+type restoreSelectionFormData struct {
+	Schemas      []string `form:"restore_schemas"`
+	Tables       []string `form:"restore_tables"`
+	Content      string   `form:"restore_content"`
+	TargetSchema string   `form:"restore_target_schema"`
+}
+
+// selection returns the restore selection picked in the form.
+func (f restoreSelectionFormData) selection() (postgres.RestoreSelection, error) {
+	selection := postgres.RestoreSelection{
+		Schemas:      f.Schemas,
+		Content:      f.Content,
+		TargetSchema: strings.TrimSpace(f.TargetSchema),
+	}
+	for _, value := range f.Tables {
+		table, err := postgres.ParseTableName(value)
+		if err != nil {
+			return selection, err
+		}
+		selection.Tables = append(selection.Tables, table)
+	}
+
+	// The content and target schema fields are always sent once the objects
+	// are loaded, they only matter if something is picked
+	if selection.IsEmpty() {
+		return postgres.RestoreSelection{}, nil
+	}
+
+	return selection, selection.Validate()
+}
+
+func (h *handlers) restoreObjectsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	objects, err := h.servs.RestorationsService.ListExecutionObjects(
+		ctx, executionID,
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, restoreObjectsList(objects))
+}
+
+// restoreSelectionSection renders the button that lists the objects of the
+// backup so some of them can be picked, only for the logical backups of
+// PostgreSQL databases.
+func restoreSelectionSection(
+	execution dbgen.ExecutionsServiceGetExecutionRow,
+) nodx.Node {
+	if execution.DatabaseDatabaseType != database.DatabaseTypePostgreSQL {
+		return nil
+	}
+
+	return nodx.Div(
+		nodx.Class("pt-4"),
+		nodx.Div(
+			nodx.Class("flex justify-start items-center space-x-1"),
+			component.H2Text("Objects"),
+			component.HelpButtonModal(component.HelpButtonModalParams{
+				ModalTitle: "Selective restore",
+				Children:   restoreSelectionHelp(),
+			}),
+		),
+		nodx.Div(
+			nodx.Id("restore-objects"),
+			nodx.Class("mt-2 space-y-2"),
+			component.PText("The whole backup is restored."),
+			nodx.Button(
+				htmx.HxGet(pathutil.BuildPath(fmt.Sprintf(
+					"/dashboard/executions/%s/restore-objects", execution.ID,
+				))),
+				htmx.HxTarget("#restore-objects"),
+				htmx.HxSwap("innerHTML"),
+				htmx.HxDisabledELT("this"),
+				nodx.Type("button"),
+				nodx.Class("btn btn-neutral btn-outline btn-sm"),
+				component.SpanText("Pick schemas and tables"),
+				lucide.ListChecks(),
+			),
+		),
+	)
+}
+
+func restoreObjectsList(objects []postgres.ArchiveObject) nodx.Node {
+	if len(objects) == 0 {
+		return component.PText(
+			"No schemas or tables were found in the backup, it is restored whole.",
+		)
+	}
+
+	checkbox := func(name, value, label string) nodx.Node {
+		return nodx.LabelEl(
+			nodx.Class("flex items-center space-x-2 cursor-pointer"),
+			nodx.Input(
+				nodx.Type("checkbox"),
+				nodx.Class("checkbox checkbox-sm"),
+				nodx.Name(name),
+				nodx.Value(value),
+			),
+			nodx.SpanEl(nodx.Class("font-mono text-sm"), nodx.Text(label)),
+		)
+	}
+
+	schemas := []nodx.Node{}
+	tables := []nodx.Node{}
+	for _, object := range objects {
+		if object.Type == postgres.ArchiveObjectSchema {
+			schemas = append(schemas, checkbox(
+				"restore_schemas", object.Name, object.Name,
+			))
+			continue
+		}
+		table := postgres.TableName{Schema: object.Schema, Name: object.Name}
+		tables = append(tables, checkbox(
+			"restore_tables", table.String(), object.Schema+"."+object.Name,
+		))
+	}
+
+	contentOptions := []nodx.Node{}
+	for _, content := range []string{
+		postgres.RestoreContentAll, postgres.RestoreContentSchema,
+		postgres.RestoreContentData,
+	} {
+		contentOptions = append(contentOptions, nodx.Option(
+			nodx.Value(content), nodx.Text(postgres.RestoreContents[content]),
+		))
+	}
+
+	return nodx.Div(
+		nodx.Class("space-y-2"),
+		component.PText(
+			"Pick the schemas and tables to restore, nothing picked restores the whole backup.",
+		),
+		nodx.Div(
+			nodx.Class("grid grid-cols-2 gap-2"),
+			nodx.Div(
+				component.BText("Schemas"),
+				nodx.Div(
+					nodx.Class("mt-1 max-h-60 overflow-y-auto space-y-1"),
+					nodx.Group(schemas...),
+				),
+			),
+			nodx.Div(
+				component.BText("Tables"),
+				nodx.Div(
+					nodx.Class("mt-1 max-h-60 overflow-y-auto space-y-1"),
+					nodx.Group(tables...),
+				),
+			),
+		),
+		nodx.Div(
+			nodx.Class("grid grid-cols-2 gap-2"),
+			component.SelectControl(component.SelectControlParams{
+				Name:     "restore_content",
+				Label:    "Content",
+				Required: true,
+				Children: contentOptions,
+			}),
+			component.InputControl(component.InputControlParams{
+				Name:        "restore_target_schema",
+				Label:       "Target schema",
+				Placeholder: "restored",
+				Type:        component.InputTypeText,
+				HelpText:    "Empty to keep the schema of the objects",
+			}),
+		),
+	)
+}
+
+func restoreSelectionHelp() []nodx.Node {
+	return []nodx.Node{
+		nodx.Div(
+			nodx.Class("space-y-2"),
+
+			component.PText(`
+				By default the whole backup is restored. Pick schemas and tables to
+				restore only them: a schema brings every object in it, a table
+				brings its data, indexes, constraints, triggers, comments, grants
+				and the sequences it owns.
+			`),
+
+			component.PText(`
+				The content restores the definitions and the data, only the
+				definitions, or only the data into tables that already exist in
+				the target database.
+			`),
+
+			component.PText(`
+				The target schema restores the picked objects into another schema,
+				useful to bring back a table next to the current one to compare
+				them. All the picked objects must be in the same schema, and the
+				target schema must not exist unless only the data is restored.
+			`),
+
+			component.PText(`
+				The selection is restored in a single transaction, if anything
+				fails nothing is restored.
+			`),
+		),
+	}
+}
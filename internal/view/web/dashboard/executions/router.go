@@ -2,6 +2,7 @@ package executions
 
 import (
 	"github.com/eduardolat/pgbackweb/internal/service"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
 	"github.com/eduardolat/pgbackweb/internal/view/middleware"
 	"github.com/labstack/echo/v4"
 )
@@ -18,11 +19,18 @@ func MountRouter(
 	parent *echo.Group, mids *middleware.Middleware, servs *service.Service,
 ) {
 	h := newHandlers(servs)
+	admin := mids.RequireRole(users.RoleAdmin)
+	operator := mids.RequireRole(users.RoleAdmin, users.RoleOperator)
 
 	parent.GET("", h.indexPageHandler)
 	parent.GET("/list", h.listExecutionsHandler)
-	parent.GET("/:executionID/download", h.downloadExecutionHandler)
-	parent.DELETE("/:executionID", h.deleteExecutionHandler)
-	parent.GET("/:executionID/restore-form", h.restoreExecutionFormHandler)
-	parent.POST("/:executionID/restore", h.restoreExecutionHandler)
+	parent.GET("/:executionID/download", h.downloadExecutionHandler, operator)
+	parent.GET("/:executionID/copies", h.listExecutionCopiesHandler)
+	parent.DELETE("/:executionID", h.deleteExecutionHandler, admin)
+	parent.POST("/:executionID/verify", h.verifyExecutionHandler, operator)
+	parent.POST("/:executionID/cancel", h.cancelExecutionHandler, operator)
+	parent.POST("/:executionID/unpin", h.unpinExecutionHandler, admin)
+	parent.GET("/:executionID/restore-form", h.restoreExecutionFormHandler, operator)
+	parent.GET("/:executionID/restore-objects", h.restoreObjectsHandler, operator)
+	parent.POST("/:executionID/restore", h.restoreExecutionHandler, operator)
 }
@@ -1,12 +1,15 @@
 package executions
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
 
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
 	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/util/strutil"
 	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
 	"github.com/google/uuid"
@@ -26,15 +29,33 @@ func (h *handlers) downloadExecutionHandler(c echo.Context) error {
 	isLocal, link, err := h.servs.ExecutionsService.GetExecutionDownloadLinkOrPath(
 		ctx, executionID,
 	)
-	if err != nil {
+	if err == nil && isLocal {
+		return c.Attachment(link, filepath.Base(link))
+	}
+	if err == nil {
+		return c.Redirect(http.StatusFound, link)
+	}
+	if !errors.Is(err, executions.ErrNoDirectLink) {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
 
-	if isLocal {
-		return c.Attachment(link, filepath.Base(link))
+	// Encrypted files are decrypted on the fly, they can't be served with a
+	// direct link to the storage
+	file, fileName, err := h.servs.ExecutionsService.OpenExecutionFile(
+		ctx, executionID,
+	)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
 	}
+	defer file.Close()
 
-	return c.Redirect(http.StatusFound, link)
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=%q", fileName),
+	)
+	return c.Stream(
+		http.StatusOK, strutil.GetContentTypeFromFileName(fileName), file,
+	)
 }
 
 func showExecutionButton(
@@ -66,6 +87,27 @@ func showExecutionButton(
 							execution.BackupIsLocal, execution.DestinationName,
 						)),
 					),
+					nodx.If(
+						execution.Attempt > 1,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Attempt")),
+							nodx.Td(component.SpanText(fmt.Sprintf("%d", execution.Attempt))),
+						),
+					),
+					nodx.If(
+						execution.IsPinned,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Pinned")),
+							nodx.Td(component.SpanText("Yes, retention never deletes it")),
+						),
+					),
+					nodx.If(
+						execution.ParentExecutionID.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Retry of")),
+							nodx.Td(component.SpanText(execution.ParentExecutionID.UUID.String())),
+						),
+					),
 					nodx.If(
 						execution.Message.Valid,
 						nodx.Tr(
@@ -76,6 +118,18 @@ func showExecutionButton(
 							),
 						),
 					),
+					nodx.If(
+						execution.Log.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Hooks log")),
+							nodx.Td(
+								nodx.Pre(
+									nodx.Class("text-xs whitespace-pre-wrap break-all"),
+									nodx.Text(execution.Log.String),
+								),
+							),
+						),
+					),
 					nodx.Tr(
 						nodx.Th(component.SpanText("Started at")),
 						nodx.Td(component.SpanText(
@@ -116,12 +170,67 @@ func showExecutionButton(
 							nodx.Td(component.PrettyFileSize(execution.FileSize)),
 						),
 					),
+					nodx.If(
+						execution.Checksum.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("SHA-256")),
+							nodx.Td(
+								nodx.Class("break-all"),
+								component.SpanText(execution.Checksum.String),
+							),
+						),
+					),
+					nodx.If(
+						execution.VerifiedAt.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Integrity")),
+							nodx.Td(
+								nodx.Class("break-all space-y-1"),
+								nodx.Div(
+									nodx.Class("flex items-center space-x-1"),
+									integrityBadge(execution.VerifyStatus),
+									component.SpanText(
+										execution.VerifiedAt.Time.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
+									),
+								),
+								nodx.If(
+									execution.VerifyMessage.Valid,
+									component.SpanText(execution.VerifyMessage.String),
+								),
+							),
+						),
+					),
+					nodx.If(
+						execution.EncryptionKeyFingerprint.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Encryption key")),
+							nodx.Td(component.SpanText(
+								execution.EncryptionKeyFingerprint.String,
+							)),
+						),
+					),
+				),
+				executionCopiesLoader(execution.ID),
+				nodx.If(
+					execution.Status == "running",
+					nodx.Div(
+						nodx.Class("flex justify-end items-center space-x-2"),
+						cancelExecutionButton(execution.ID),
+					),
 				),
 				nodx.If(
 					execution.Status == "success",
 					nodx.Div(
 						nodx.Class("flex justify-end items-center space-x-2"),
 						deleteExecutionButton(execution.ID),
+						nodx.If(
+							execution.IsPinned && !execution.DeletedAt.Valid,
+							unpinExecutionButton(execution.ID),
+						),
+						nodx.If(
+							execution.Checksum.Valid && !execution.DeletedAt.Valid,
+							verifyExecutionButton(execution.ID),
+						),
 						nodx.A(
 							nodx.Href(pathutil.BuildPath(fmt.Sprintf("/dashboard/executions/%s/download", execution.ID))),
 							nodx.Target("_blank"),
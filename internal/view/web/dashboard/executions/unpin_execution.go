@@ -0,0 +1,43 @@
+package executions
+
+import (
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) unpinExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	err = h.servs.ExecutionsService.UnpinExecution(ctx, executionID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.AlertWithRefresh(
+		c, "Execution unpinned, the retention policy of its backup applies to it again",
+	)
+}
+
+func unpinExecutionButton(executionID uuid.UUID) nodx.Node {
+	return nodx.Button(
+		htmx.HxPost(pathutil.BuildPath(fmt.Sprintf("/dashboard/executions/%s/unpin", executionID))),
+		htmx.HxDisabledELT("this"),
+		htmx.HxConfirm("Are you sure you want to unpin this execution? The retention policy of its backup may delete it."),
+		nodx.Class("btn btn-neutral btn-outline"),
+		component.SpanText("Unpin"),
+		lucide.PinOff(),
+	)
+}
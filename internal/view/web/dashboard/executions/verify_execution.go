@@ -0,0 +1,62 @@
+package executions
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/service/executions"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) verifyExecutionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	executionID, err := uuid.Parse(c.Param("executionID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	status, err := h.servs.ExecutionsService.VerifyExecution(ctx, executionID)
+	if err != nil && status == "" {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err != nil {
+		return respondhtmx.AlertWithRefresh(
+			c, "Integrity check failed: "+err.Error(),
+		)
+	}
+
+	return respondhtmx.AlertWithRefresh(c, "The backup file is intact")
+}
+
+func verifyExecutionButton(executionID uuid.UUID) nodx.Node {
+	return nodx.Button(
+		htmx.HxPost(pathutil.BuildPath(fmt.Sprintf("/dashboard/executions/%s/verify", executionID))),
+		htmx.HxDisabledELT("this"),
+		nodx.Class("btn btn-neutral btn-outline"),
+		component.SpanText("Verify"),
+		lucide.ShieldCheck(),
+	)
+}
+
+// integrityBadge shows the result of the last integrity verification of an
+// execution file, nothing is shown while the file was never verified.
+func integrityBadge(verifyStatus sql.NullString) nodx.Node {
+	switch verifyStatus.String {
+	case executions.VerifyStatusOk:
+		return nodx.SpanEl(nodx.Class("badge badge-success badge-outline"), nodx.Text("verified"))
+	case executions.VerifyStatusMismatch:
+		return nodx.SpanEl(nodx.Class("badge badge-error"), nodx.Text("corrupted"))
+	case executions.VerifyStatusError:
+		return nodx.SpanEl(nodx.Class("badge badge-warning"), nodx.Text("unreadable"))
+	default:
+		return nil
+	}
+}
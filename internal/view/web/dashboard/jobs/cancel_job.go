@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"fmt"
+
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) cancelJobHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	jobID, err := uuid.Parse(c.Param("jobID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	if err := h.servs.JobsService.CancelJob(ctx, jobID); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.Refresh(c)
+}
+
+func cancelJobButton(jobID uuid.UUID) nodx.Node {
+	return component.OptionsDropdownButton(
+		htmx.HxDelete(pathutil.BuildPath(fmt.Sprintf("/dashboard/jobs/%s", jobID))),
+		htmx.HxConfirm("Are you sure you want to remove this job from the queue?"),
+		htmx.HxDisabledELT("this"),
+		lucide.CircleX(),
+		component.SpanText("Cancel job"),
+	)
+}
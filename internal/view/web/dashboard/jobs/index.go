@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/layout"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+)
+
+func (h *handlers) indexPageHandler(c echo.Context) error {
+	reqCtx := reqctx.GetCtx(c)
+	maxRunning, maxRunningPerServer := h.servs.JobsService.Limits()
+
+	return echoutil.RenderNodx(
+		c, http.StatusOK, indexPage(reqCtx, maxRunning, maxRunningPerServer),
+	)
+}
+
+func indexPage(
+	reqCtx reqctx.Ctx, maxRunning int, maxRunningPerServer int,
+) nodx.Node {
+	content := []nodx.Node{
+		nodx.Div(
+			component.H1Text("Jobs queue"),
+			component.PText(fmt.Sprintf(
+				"Every scheduled or manual backup run is queued here. Up to %d backups "+
+					"run at the same time and up to %d per database server, the queued "+
+					"jobs with a higher priority start first. Failed jobs wait before "+
+					"being retried.",
+				maxRunning, maxRunningPerServer,
+			)),
+		),
+		component.CardBox(component.CardBoxParams{
+			Class: "mt-4",
+			Children: []nodx.Node{
+				nodx.Div(
+					nodx.Class("overflow-x-auto"),
+					nodx.Table(
+						nodx.Class("table text-nowrap"),
+						nodx.Thead(
+							nodx.Tr(
+								nodx.Th(nodx.Class("w-1")),
+								nodx.Th(component.SpanText("Status")),
+								nodx.Th(component.SpanText("Backup")),
+								nodx.Th(component.SpanText("Database")),
+								nodx.Th(component.SpanText("Server")),
+								nodx.Th(component.SpanText("Source")),
+								nodx.Th(component.SpanText("Priority")),
+								nodx.Th(component.SpanText("Attempts")),
+								nodx.Th(component.SpanText("Queued at")),
+								nodx.Th(component.SpanText("Starts at")),
+								nodx.Th(component.SpanText("Message")),
+							),
+						),
+						nodx.Tbody(
+							component.SkeletonTr(8),
+							htmx.HxGet(pathutil.BuildPath("/dashboard/jobs/list")),
+							htmx.HxTrigger("load, every 5s"),
+						),
+					),
+				),
+			},
+		}),
+	}
+
+	return layout.Dashboard(reqCtx, layout.DashboardParams{
+		Title: "Jobs queue",
+		Body:  content,
+	})
+}
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/jobs"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) listJobsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	pending, err := h.servs.JobsService.ListPendingJobs(ctx)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, listJobs(pending))
+}
+
+func listJobs(pending []dbgen.JobsServiceListPendingJobsRow) nodx.Node {
+	if len(pending) < 1 {
+		return component.EmptyResultsTr(component.EmptyResultsParams{
+			Title:    "The queue is empty",
+			Subtitle: "The backup runs appear here while they are queued or running",
+		})
+	}
+
+	trs := []nodx.Node{}
+	for _, job := range pending {
+		trs = append(trs, nodx.Tr(
+			nodx.Td(component.OptionsDropdown(
+				component.OptionsDropdownA(
+					nodx.Href(pathutil.BuildPath(
+						fmt.Sprintf("/dashboard/executions?backup=%s", job.BackupID),
+					)),
+					nodx.Target("_blank"),
+					lucide.List(),
+					component.SpanText("Show executions"),
+				),
+				nodx.If(
+					job.Status != jobs.StatusRunning,
+					cancelJobButton(job.ID),
+				),
+			)),
+			nodx.Td(component.StatusBadge(job.Status)),
+			nodx.Td(component.SpanText(job.BackupName)),
+			nodx.Td(component.SpanText(job.DatabaseName)),
+			nodx.Td(component.SpanText(job.Server)),
+			nodx.Td(component.SpanText(job.Source)),
+			nodx.Td(component.SpanText(fmt.Sprintf("%d", job.Priority))),
+			nodx.Td(component.SpanText(
+				fmt.Sprintf("%d / %d", job.Attempts, job.MaxAttempts),
+			)),
+			nodx.Td(component.SpanText(
+				job.CreatedAt.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
+			)),
+			nodx.Td(component.SpanText(func() string {
+				if job.Status == jobs.StatusRunning && job.StartedAt.Valid {
+					return "Started " + job.StartedAt.Time.Local().Format(
+						timeutil.LayoutYYYYMMDDHHMMSSPretty,
+					)
+				}
+				return job.RunAfter.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty)
+			}())),
+			nodx.Td(
+				nodx.Class("max-w-md whitespace-normal break-words"),
+				component.SpanText(job.Message.String),
+			),
+		))
+	}
+
+	return component.RenderableGroup(trs)
+}
@@ -0,0 +1,224 @@
+package profile
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/auth"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) createAPITokenHandler(c echo.Context) error {
+	reqCtx := reqctx.GetCtx(c)
+	ctx := c.Request().Context()
+
+	var formData struct {
+		Name          string `form:"name" validate:"required"`
+		Scope         string `form:"scope" validate:"required"`
+		ExpiresInDays int    `form:"expires_in_days" validate:"min=0"`
+	}
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	expiresAt := sql.NullTime{}
+	if formData.ExpiresInDays > 0 {
+		expiresAt = sql.NullTime{
+			Valid: true,
+			Time:  time.Now().AddDate(0, 0, formData.ExpiresInDays),
+		}
+	}
+
+	token, _, err := h.servs.AuthService.CreateAPIToken(
+		ctx, auth.CreateAPITokenParams{
+			UserID:    reqCtx.User.ID,
+			Name:      formData.Name,
+			Scope:     formData.Scope,
+			ExpiresAt: expiresAt,
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	tokens, err := h.servs.AuthService.GetUserAPITokens(ctx, reqCtx.User.ID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(c, http.StatusOK, apiTokensCard(tokens, token))
+}
+
+func (h *handlers) deleteAPITokenHandler(c echo.Context) error {
+	reqCtx := reqctx.GetCtx(c)
+	ctx := c.Request().Context()
+
+	tokenID, err := uuid.Parse(c.Param("tokenID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	err = h.servs.AuthService.DeleteAPIToken(ctx, reqCtx.User.ID, tokenID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.Refresh(c)
+}
+
+// apiTokensCard renders the API tokens section of the profile page. If
+// newToken is not empty it is shown once so the user can copy it.
+func apiTokensCard(tokens []dbgen.ApiToken, newToken string) nodx.Node {
+	prettyTime := func(t sql.NullTime, fallback string) string {
+		if !t.Valid {
+			return fallback
+		}
+		return t.Time.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty)
+	}
+
+	return nodx.Div(
+		nodx.Id("api-tokens-card"),
+		component.CardBox(component.CardBoxParams{
+			Children: []nodx.Node{
+				component.H2Text("API tokens"),
+				component.PText(
+					"Use API tokens to access the REST API with an " +
+						"\"Authorization: Bearer <token>\" header.",
+				),
+
+				nodx.If(
+					newToken != "",
+					nodx.Div(
+						nodx.Class("alert alert-success mt-2 flex flex-col items-start"),
+						component.SpanText(
+							"Copy your new token now, you won't be able to see it again.",
+						),
+						nodx.Div(
+							nodx.Class("flex items-center space-x-2 w-full"),
+							nodx.CodeEl(
+								nodx.Class("break-all"),
+								nodx.Text(newToken),
+							),
+							component.CopyButtonSm(newToken),
+						),
+					),
+				),
+
+				nodx.FormEl(
+					htmx.HxPost(pathutil.BuildPath("/dashboard/profile/api-tokens")),
+					htmx.HxTarget("#api-tokens-card"),
+					htmx.HxSwap("outerHTML"),
+					htmx.HxDisabledELT("find button"),
+					nodx.Class("mt-2 space-y-2"),
+
+					component.InputControl(component.InputControlParams{
+						Name:        "name",
+						Label:       "Name",
+						Placeholder: "CI pipeline",
+						Required:    true,
+						Type:        component.InputTypeText,
+						HelpText:    "A name to easily identify the token",
+					}),
+
+					component.SelectControl(component.SelectControlParams{
+						Name:     "scope",
+						Label:    "Scope",
+						Required: true,
+						HelpText: "Read only tokens can only list and download, run backups tokens can also start backups, admin tokens can do everything",
+						Children: []nodx.Node{
+							nodx.Option(nodx.Value(auth.APITokenScopeReadOnly), nodx.Text(auth.APITokenScopes[auth.APITokenScopeReadOnly])),
+							nodx.Option(nodx.Value(auth.APITokenScopeRunBackups), nodx.Text(auth.APITokenScopes[auth.APITokenScopeRunBackups])),
+							nodx.Option(nodx.Value(auth.APITokenScopeAdmin), nodx.Text(auth.APITokenScopes[auth.APITokenScopeAdmin])),
+						},
+					}),
+
+					component.InputControl(component.InputControlParams{
+						Name:        "expires_in_days",
+						Label:       "Expires in (days)",
+						Placeholder: "30",
+						Type:        component.InputTypeNumber,
+						HelpText:    "Leave empty or set to 0 for a token that never expires",
+						Children: []nodx.Node{
+							nodx.Min("0"),
+						},
+					}),
+
+					nodx.Div(
+						nodx.Class("flex justify-end items-center space-x-2 pt-2"),
+						component.HxLoadingMd(),
+						nodx.Button(
+							nodx.Class("btn btn-primary"),
+							nodx.Type("submit"),
+							component.SpanText("Create token"),
+							lucide.KeyRound(),
+						),
+					),
+				),
+
+				nodx.Div(nodx.Class("divider")),
+
+				nodx.Div(
+					nodx.Class("overflow-x-auto"),
+					nodx.Table(
+						nodx.Class("table"),
+						nodx.Thead(
+							nodx.Tr(
+								nodx.Th(component.SpanText("Name")),
+								nodx.Th(component.SpanText("Scope")),
+								nodx.Th(component.SpanText("Expires")),
+								nodx.Th(component.SpanText("Last used")),
+								nodx.Th(),
+							),
+						),
+						nodx.Tbody(
+							nodx.If(
+								len(tokens) == 0,
+								nodx.Tr(nodx.Td(
+									nodx.Colspan("5"),
+									component.SpanText("No API tokens yet"),
+								)),
+							),
+							nodx.Map(tokens, func(token dbgen.ApiToken) nodx.Node {
+								return nodx.Tr(
+									nodx.Td(component.SpanText(token.Name)),
+									nodx.Td(component.SpanText(auth.APITokenScopes[token.Scope])),
+									nodx.Td(component.SpanText(prettyTime(token.ExpiresAt, "Never"))),
+									nodx.Td(component.SpanText(prettyTime(token.LastUsedAt, "Never"))),
+									nodx.Td(
+										nodx.Button(
+											htmx.HxDelete(pathutil.BuildPath(
+												fmt.Sprintf("/dashboard/profile/api-tokens/%s", token.ID),
+											)),
+											htmx.HxDisabledELT("this"),
+											htmx.HxConfirm("Are you sure you want to revoke this token?"),
+											nodx.Class("btn btn-error btn-sm btn-ghost"),
+											component.SpanText("Revoke"),
+											lucide.Trash(),
+										),
+									),
+								)
+							}),
+						),
+					),
+				),
+			},
+		}),
+	)
+}
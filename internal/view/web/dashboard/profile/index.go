@@ -23,12 +23,20 @@ func (h *handlers) indexPageHandler(c echo.Context) error {
 		return c.String(http.StatusInternalServerError, "failed to get user sessions")
 	}
 
+	tokens, err := h.servs.AuthService.GetUserAPITokens(ctx, reqCtx.User.ID)
+	if err != nil {
+		logger.Error("failed to get user api tokens", logger.KV{"err": err})
+		return c.String(http.StatusInternalServerError, "failed to get user api tokens")
+	}
+
 	return echoutil.RenderNodx(
-		c, http.StatusOK, indexPage(reqCtx, sessions),
+		c, http.StatusOK, indexPage(reqCtx, sessions, tokens),
 	)
 }
 
-func indexPage(reqCtx reqctx.Ctx, sessions []dbgen.Session) nodx.Node {
+func indexPage(
+	reqCtx reqctx.Ctx, sessions []dbgen.Session, tokens []dbgen.ApiToken,
+) nodx.Node {
 	content := []nodx.Node{
 		component.H1Text("Profile"),
 
@@ -37,6 +45,11 @@ func indexPage(reqCtx reqctx.Ctx, sessions []dbgen.Session) nodx.Node {
 			nodx.Div(updateUserForm(reqCtx.User)),
 			nodx.Div(closeAllSessionsForm(sessions)),
 		),
+
+		nodx.Div(
+			nodx.Class("mt-4"),
+			apiTokensCard(tokens, ""),
+		),
 	}
 
 	return layout.Dashboard(reqCtx, layout.DashboardParams{
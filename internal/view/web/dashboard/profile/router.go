@@ -21,4 +21,6 @@ func MountRouter(
 
 	parent.GET("", h.indexPageHandler)
 	parent.POST("", h.updateUserHandler)
+	parent.POST("/api-tokens", h.createAPITokenHandler)
+	parent.DELETE("/api-tokens/:tokenID", h.deleteAPITokenHandler)
 }
@@ -82,6 +82,9 @@ func listRestorations(
 				if restoration.DatabaseName.Valid {
 					return restoration.DatabaseName.String
 				}
+				if restoration.DataDirectory.Valid {
+					return restoration.DataDirectory.String
+				}
 				return "Other database"
 			}())),
 			nodx.Td(component.SpanText(restoration.ExecutionID.String())),
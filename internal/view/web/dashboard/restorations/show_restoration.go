@@ -2,6 +2,7 @@ package restorations
 
 import (
 	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/integration/postgres"
 	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
 	"github.com/eduardolat/pgbackweb/internal/view/web/component"
 	nodx "github.com/nodxdev/nodxgo"
@@ -11,6 +12,10 @@ import (
 func showRestorationButton(
 	restoration dbgen.RestorationsServicePaginateRestorationsRow,
 ) nodx.Node {
+	// Settings that can't be parsed are shown as the defaults
+	selection, _ := postgres.ParseRestoreSelection(restoration.Selection)
+	newDatabase, _ := postgres.ParseNewDatabase(restoration.NewDatabase)
+
 	mo := component.Modal(component.ModalParams{
 		Title: "Restoration details",
 		Size:  component.SizeMd,
@@ -37,9 +42,59 @@ func showRestorationButton(
 							if restoration.DatabaseName.Valid {
 								return restoration.DatabaseName.String
 							}
+							if restoration.DataDirectory.Valid {
+								return "Data directory " + restoration.DataDirectory.String
+							}
 							return "Other database"
 						}())),
 					),
+					nodx.If(
+						!newDatabase.IsEmpty(),
+						nodx.Tr(
+							nodx.Th(component.SpanText("New database")),
+							nodx.Td(component.SpanText(newDatabase.Describe())),
+						),
+					),
+					nodx.If(
+						restoration.DryRun,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Dry run")),
+							nodx.Td(component.SpanText("Yes, nothing was restored")),
+						),
+					),
+					nodx.If(
+						restoration.PreRestoreExecutionID.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Pre-restore backup")),
+							nodx.Td(component.SpanText(
+								restoration.PreRestoreExecutionID.UUID.String(),
+							)),
+						),
+					),
+					nodx.If(
+						!selection.IsEmpty(),
+						nodx.Tr(
+							nodx.Th(component.SpanText("Selection")),
+							nodx.Td(component.SpanText(selection.Describe())),
+						),
+					),
+					nodx.If(
+						restoration.DataDirectory.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Recovery target")),
+							nodx.Td(component.SpanText(func() string {
+								if restoration.RecoveryTargetTime.Valid {
+									return restoration.RecoveryTargetTime.Time.Local().Format(
+										timeutil.LayoutYYYYMMDDHHMMSSPretty,
+									)
+								}
+								if restoration.RecoveryTargetLsn.Valid {
+									return "LSN " + restoration.RecoveryTargetLsn.String
+								}
+								return "Last archived WAL file"
+							}())),
+						),
+					),
 					nodx.If(
 						restoration.Message.Valid,
 						nodx.Tr(
@@ -50,6 +105,18 @@ func showRestorationButton(
 							),
 						),
 					),
+					nodx.If(
+						restoration.Log.Valid,
+						nodx.Tr(
+							nodx.Th(component.SpanText("Hooks log")),
+							nodx.Td(
+								nodx.Pre(
+									nodx.Class("text-xs whitespace-pre-wrap break-all"),
+									nodx.Text(restoration.Log.String),
+								),
+							),
+						),
+					),
 					nodx.Tr(
 						nodx.Th(component.SpanText("Started At")),
 						nodx.Td(component.SpanText(
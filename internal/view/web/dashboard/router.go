@@ -7,10 +7,13 @@ import (
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/backups"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/databases"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/destinations"
+	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/drills"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/executions"
+	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/jobs"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/profile"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/restorations"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/summary"
+	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/users"
 	"github.com/eduardolat/pgbackweb/internal/view/web/dashboard/webhooks"
 	"github.com/labstack/echo/v4"
 )
@@ -25,8 +28,11 @@ func MountRouter(
 	destinations.MountRouter(parent.Group("/destinations"), mids, servs)
 	backups.MountRouter(parent.Group("/backups"), mids, servs)
 	executions.MountRouter(parent.Group("/executions"), mids, servs)
+	jobs.MountRouter(parent.Group("/jobs"), mids, servs)
 	restorations.MountRouter(parent.Group("/restorations"), mids, servs)
+	drills.MountRouter(parent.Group("/drills"), mids, servs)
 	webhooks.MountRouter(parent.Group("/webhooks"), mids, servs)
+	users.MountRouter(parent.Group("/users"), mids, servs)
 	profile.MountRouter(parent.Group("/profile"), mids, servs)
 	about.MountRouter(parent.Group("/about"), mids, servs)
 }
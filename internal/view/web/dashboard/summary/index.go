@@ -151,6 +151,8 @@ func indexPage(
 		redColor    = "#ff5861"
 		yellowColor = "#ffbe00"
 		blueColor   = "#00b6ff"
+		grayColor   = "#a6adbb"
+		orangeColor = "#ff8a3d"
 	)
 
 	content := []nodx.Node{
@@ -179,13 +181,17 @@ func indexPage(
 				BgColors: []string{greenColor, redColor},
 			}),
 			countCard("Executions", executionsQty.All, ChartData{
-				Label:  "Status",
-				Labels: []string{"Running", "Success", "Failed", "Deleted"},
+				Label: "Status",
+				Labels: []string{
+					"Running", "Success", "Failed", "Deleted", "Cancelled", "Timed out",
+				},
 				Data: []int32{
 					executionsQty.Running, executionsQty.Success, executionsQty.Failed,
-					executionsQty.Deleted,
+					executionsQty.Deleted, executionsQty.Cancelled, executionsQty.TimedOut,
+				},
+				BgColors: []string{
+					blueColor, greenColor, redColor, yellowColor, grayColor, orangeColor,
 				},
-				BgColors: []string{blueColor, greenColor, redColor, yellowColor},
 			}),
 			countCard("Restorations", restorationsQty.All, ChartData{
 				Label:  "Status",
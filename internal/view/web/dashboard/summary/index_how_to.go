@@ -58,13 +58,13 @@ func indexHowTo() nodx.Node {
 
 				nodx.Div(
 					alpine.XShow("currentSlide === 2"),
-					component.H3Text("Add S3 destination (optional)"),
+					component.H3Text("Add destination (optional)"),
 					component.PText(`
 						To add a destination, click on the "Destinations" menu item on
 						the left sidebar. Then click on the "Add destination" button.
 						Fill in the form and click on the "Save" button. You can add
 						as many destinations as you want to store the backups. If you
-						don't want to use S3 destinations and store the backups locally,
+						don't want to use destinations and store the backups locally,
 						you can skip this step.
 					`),
 				),
@@ -0,0 +1,135 @@
+package users
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) createUserHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var formData struct {
+		Name     string `form:"name" validate:"required"`
+		Email    string `form:"email" validate:"required,email"`
+		Password string `form:"password" validate:"required,min=6"`
+		Role     string `form:"role" validate:"required"`
+	}
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	_, err := h.servs.UsersService.CreateUser(
+		ctx, dbgen.UsersServiceCreateUserParams{
+			Name:     formData.Name,
+			Email:    formData.Email,
+			Password: formData.Password,
+			Role:     formData.Role,
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return respondhtmx.Redirect(c, pathutil.BuildPath("/dashboard/users"))
+}
+
+// roleSelectOptions renders the options for a role select input, with the
+// given role selected.
+func roleSelectOptions(selected string) nodx.Node {
+	return nodx.Group(
+		nodx.Map(
+			[]string{users.RoleAdmin, users.RoleOperator, users.RoleViewer},
+			func(role string) nodx.Node {
+				return nodx.Option(
+					nodx.Value(role),
+					nodx.Text(users.Roles[role]),
+					nodx.If(role == selected, nodx.Selected("")),
+				)
+			},
+		),
+	)
+}
+
+func createUserButton() nodx.Node {
+	mo := component.Modal(component.ModalParams{
+		Size:  component.SizeMd,
+		Title: "Invite user",
+		Content: []nodx.Node{
+			nodx.FormEl(
+				htmx.HxPost(pathutil.BuildPath("/dashboard/users")),
+				htmx.HxDisabledELT("find button"),
+				nodx.Class("space-y-2"),
+
+				component.InputControl(component.InputControlParams{
+					Name:        "name",
+					Label:       "Name",
+					Placeholder: "John Doe",
+					Required:    true,
+					Type:        component.InputTypeText,
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:        "email",
+					Label:       "Email",
+					Placeholder: "john@example.com",
+					Required:    true,
+					Type:        component.InputTypeEmail,
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:        "password",
+					Label:       "Password",
+					Placeholder: "******",
+					Required:    true,
+					Type:        component.InputTypePassword,
+					HelpText:    "Share it with the user, they can change it later from their profile",
+				}),
+
+				component.SelectControl(component.SelectControlParams{
+					Name:     "role",
+					Label:    "Role",
+					Required: true,
+					HelpText: "Admins can do everything, operators can run, download and restore backups into non production databases, viewers can only look",
+					Children: []nodx.Node{
+						roleSelectOptions(users.RoleViewer),
+					},
+				}),
+
+				nodx.Div(
+					nodx.Class("flex justify-end items-center space-x-2 pt-2"),
+					component.HxLoadingMd(),
+					nodx.Button(
+						nodx.Class("btn btn-primary"),
+						nodx.Type("submit"),
+						component.SpanText("Invite user"),
+						lucide.Save(),
+					),
+				),
+			),
+		},
+	})
+
+	button := nodx.Button(
+		mo.OpenerAttr,
+		nodx.Class("btn btn-primary"),
+		component.SpanText("Invite user"),
+		lucide.UserPlus(),
+	)
+
+	return nodx.Div(
+		nodx.Class("inline-block"),
+		mo.HTML,
+		button,
+	)
+}
@@ -0,0 +1,177 @@
+package users
+
+import (
+	"database/sql"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/validate"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) editUserHandler(c echo.Context) error {
+	reqCtx := reqctx.GetCtx(c)
+	ctx := c.Request().Context()
+
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	var formData struct {
+		Name     string `form:"name" validate:"required"`
+		Email    string `form:"email" validate:"required,email"`
+		Password string `form:"password" validate:"omitempty,min=6"`
+		Role     string `form:"role" validate:"required"`
+		IsActive string `form:"is_active" validate:"required,oneof=true false"`
+	}
+	if err := c.Bind(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+	if err := validate.Struct(&formData); err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	isActive := formData.IsActive == "true"
+	if userID == reqCtx.User.ID && (formData.Role != users.RoleAdmin || !isActive) {
+		return respondhtmx.ToastError(c, "You can't demote or disable yourself")
+	}
+
+	_, err = h.servs.UsersService.UpdateUser(
+		ctx, dbgen.UsersServiceUpdateUserParams{
+			ID:       userID,
+			Name:     sql.NullString{String: formData.Name, Valid: true},
+			Email:    sql.NullString{String: formData.Email, Valid: true},
+			Password: sql.NullString{String: formData.Password, Valid: formData.Password != ""},
+			Role:     sql.NullString{String: formData.Role, Valid: true},
+			IsActive: sql.NullBool{Bool: isActive, Valid: true},
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	if !isActive {
+		err = h.servs.AuthService.DeleteAllUserSessions(ctx, userID)
+		if err != nil {
+			return respondhtmx.ToastError(c, err.Error())
+		}
+	}
+
+	return respondhtmx.AlertWithRefresh(c, "User updated")
+}
+
+func editUserButton(user dbgen.User, isCurrentUser bool) nodx.Node {
+	roleHelpText := ""
+	if isCurrentUser {
+		roleHelpText = "You can't change your own role or disable yourself"
+	}
+
+	mo := component.Modal(component.ModalParams{
+		Size:  component.SizeMd,
+		Title: "Edit user",
+		Content: []nodx.Node{
+			nodx.FormEl(
+				htmx.HxPost(pathutil.BuildPath("/dashboard/users/"+user.ID.String()+"/edit")),
+				htmx.HxDisabledELT("find button"),
+				nodx.Class("space-y-2"),
+
+				component.InputControl(component.InputControlParams{
+					Name:        "name",
+					Label:       "Name",
+					Placeholder: "John Doe",
+					Required:    true,
+					Type:        component.InputTypeText,
+					Children: []nodx.Node{
+						nodx.Value(user.Name),
+					},
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:        "email",
+					Label:       "Email",
+					Placeholder: "john@example.com",
+					Required:    true,
+					Type:        component.InputTypeEmail,
+					Children: []nodx.Node{
+						nodx.Value(user.Email),
+					},
+				}),
+
+				component.InputControl(component.InputControlParams{
+					Name:        "password",
+					Label:       "New password",
+					Placeholder: "******",
+					Type:        component.InputTypePassword,
+					HelpText:    "Leave empty to keep the current password",
+				}),
+
+				component.SelectControl(component.SelectControlParams{
+					Name:     "role",
+					Label:    "Role",
+					Required: true,
+					HelpText: roleHelpText,
+					Children: []nodx.Node{
+						roleSelectOptions(user.Role),
+						nodx.If(isCurrentUser, nodx.Disabled("")),
+					},
+				}),
+
+				component.SelectControl(component.SelectControlParams{
+					Name:     "is_active",
+					Label:    "Active",
+					Required: true,
+					HelpText: "Disabled users can't log in and their sessions and API tokens stop working",
+					Children: []nodx.Node{
+						nodx.Option(
+							nodx.Value("true"),
+							nodx.Text("Yes"),
+							nodx.If(user.IsActive, nodx.Selected("")),
+						),
+						nodx.Option(
+							nodx.Value("false"),
+							nodx.Text("No"),
+							nodx.If(!user.IsActive, nodx.Selected("")),
+						),
+						nodx.If(isCurrentUser, nodx.Disabled("")),
+					},
+				}),
+
+				// Disabled inputs are not submitted, so the current values are
+				// sent as hidden inputs when editing yourself
+				nodx.If(isCurrentUser, nodx.Group(
+					nodx.Input(nodx.Type("hidden"), nodx.Name("role"), nodx.Value(user.Role)),
+					nodx.Input(nodx.Type("hidden"), nodx.Name("is_active"), nodx.Value("true")),
+				)),
+
+				nodx.Div(
+					nodx.Class("flex justify-end items-center space-x-2 pt-2"),
+					component.HxLoadingMd(),
+					nodx.Button(
+						nodx.Class("btn btn-primary"),
+						nodx.Type("submit"),
+						component.SpanText("Save"),
+						lucide.Save(),
+					),
+				),
+			),
+		},
+	})
+
+	return nodx.Div(
+		mo.HTML,
+		component.OptionsDropdownButton(
+			mo.OpenerAttr,
+			lucide.Pencil(),
+			component.SpanText("Edit user"),
+		),
+	)
+}
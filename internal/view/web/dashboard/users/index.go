@@ -0,0 +1,71 @@
+package users
+
+import (
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/layout"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+)
+
+func (h *handlers) indexPageHandler(c echo.Context) error {
+	reqCtx := reqctx.GetCtx(c)
+	return echoutil.RenderNodx(c, http.StatusOK, indexPage(reqCtx))
+}
+
+func indexPage(reqCtx reqctx.Ctx) nodx.Node {
+	content := []nodx.Node{
+		nodx.Div(
+			nodx.Class("flex justify-between items-start space-x-2"),
+			nodx.Div(
+				component.H1Text("Users"),
+				component.PText(`
+					Here you can manage the users that have access to PG Back Web.
+					Admins can do everything, operators can run, download and restore
+					backups into non production databases, and viewers can only look.
+				`),
+			),
+			nodx.Div(
+				nodx.Class("flex-none"),
+				createUserButton(),
+			),
+		),
+
+		component.CardBox(component.CardBoxParams{
+			Class: "mt-4",
+			Children: []nodx.Node{
+				nodx.Div(
+					nodx.Class("overflow-x-auto"),
+					nodx.Table(
+						nodx.Class("table text-nowrap"),
+						nodx.Thead(
+							nodx.Tr(
+								nodx.Th(nodx.Class("w-1")),
+								nodx.Th(component.SpanText("Name")),
+								nodx.Th(component.SpanText("Email")),
+								nodx.Th(component.SpanText("Role")),
+								nodx.Th(component.SpanText("Status")),
+								nodx.Th(component.SpanText("Created at")),
+							),
+						),
+						nodx.Tbody(
+							component.SkeletonTr(6),
+							htmx.HxGet(pathutil.BuildPath("/dashboard/users/list")),
+							htmx.HxTrigger("load"),
+						),
+					),
+				),
+			},
+		}),
+	}
+
+	return layout.Dashboard(reqCtx, layout.DashboardParams{
+		Title: "Users",
+		Body:  content,
+	})
+}
@@ -0,0 +1,106 @@
+package users
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
+	"github.com/eduardolat/pgbackweb/internal/util/echoutil"
+	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/util/timeutil"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
+	"github.com/eduardolat/pgbackweb/internal/view/web/component"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/labstack/echo/v4"
+	nodx "github.com/nodxdev/nodxgo"
+	htmx "github.com/nodxdev/nodxgo-htmx"
+	lucide "github.com/nodxdev/nodxgo-lucide"
+)
+
+func (h *handlers) listUsersHandler(c echo.Context) error {
+	reqCtx := reqctx.GetCtx(c)
+	ctx := c.Request().Context()
+
+	allUsers, err := h.servs.UsersService.GetAllUsers(ctx)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	return echoutil.RenderNodx(
+		c, http.StatusOK, listUsers(reqCtx.User, allUsers),
+	)
+}
+
+func listUsers(currentUser dbgen.User, allUsers []dbgen.User) nodx.Node {
+	if len(allUsers) < 1 {
+		return component.EmptyResultsTr(component.EmptyResultsParams{
+			Title:    "No users found",
+			Subtitle: "Wait for the first user to appear here",
+		})
+	}
+
+	trs := []nodx.Node{}
+	for _, user := range allUsers {
+		isCurrentUser := user.ID == currentUser.ID
+
+		toggleVerb := "disable"
+		toggleText := "Disable user"
+		toggleIcon := lucide.UserX
+		if !user.IsActive {
+			toggleVerb = "enable"
+			toggleText = "Enable user"
+			toggleIcon = lucide.UserCheck
+		}
+
+		trs = append(trs, nodx.Tr(
+			nodx.Td(component.OptionsDropdown(
+				editUserButton(user, isCurrentUser),
+				nodx.If(
+					!isCurrentUser,
+					component.OptionsDropdownButton(
+						htmx.HxPost(pathutil.BuildPath(
+							fmt.Sprintf("/dashboard/users/%s/toggle-active", user.ID),
+						)),
+						htmx.HxDisabledELT("this"),
+						htmx.HxConfirm(fmt.Sprintf(
+							"Are you sure you want to %s %s?", toggleVerb, user.Email,
+						)),
+						toggleIcon(),
+						component.SpanText(toggleText),
+					),
+				),
+			)),
+			nodx.Td(
+				component.SpanText(user.Name),
+				nodx.If(isCurrentUser, nodx.SpanEl(
+					nodx.Class("badge badge-neutral ml-2"),
+					nodx.Text("You"),
+				)),
+			),
+			nodx.Td(
+				nodx.Div(
+					nodx.Class("flex items-center space-x-1"),
+					component.CopyButtonSm(user.Email),
+					component.SpanText(user.Email),
+				),
+			),
+			nodx.Td(component.SpanText(users.Roles[user.Role])),
+			nodx.Td(
+				nodx.If(user.IsActive, nodx.SpanEl(
+					nodx.Class("badge badge-success"),
+					nodx.Text("Active"),
+				)),
+				nodx.If(!user.IsActive, nodx.SpanEl(
+					nodx.Class("badge badge-error"),
+					nodx.Text("Disabled"),
+				)),
+			),
+			nodx.Td(component.SpanText(
+				user.CreatedAt.Local().Format(timeutil.LayoutYYYYMMDDHHMMSSPretty),
+			)),
+		))
+	}
+
+	return component.RenderableGroup(trs)
+}
@@ -0,0 +1,29 @@
+package users
+
+import (
+	"github.com/eduardolat/pgbackweb/internal/service"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
+	"github.com/eduardolat/pgbackweb/internal/view/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+type handlers struct {
+	servs *service.Service
+}
+
+func newHandlers(servs *service.Service) *handlers {
+	return &handlers{servs: servs}
+}
+
+func MountRouter(
+	parent *echo.Group, mids *middleware.Middleware, servs *service.Service,
+) {
+	h := newHandlers(servs)
+	parent.Use(mids.RequireRole(users.RoleAdmin))
+
+	parent.GET("", h.indexPageHandler)
+	parent.GET("/list", h.listUsersHandler)
+	parent.POST("", h.createUserHandler)
+	parent.POST("/:userID/edit", h.editUserHandler)
+	parent.POST("/:userID/toggle-active", h.toggleUserActiveHandler)
+}
@@ -0,0 +1,49 @@
+package users
+
+import (
+	"database/sql"
+
+	"github.com/eduardolat/pgbackweb/internal/database/dbgen"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
+	"github.com/eduardolat/pgbackweb/internal/view/web/respondhtmx"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handlers) toggleUserActiveHandler(c echo.Context) error {
+	reqCtx := reqctx.GetCtx(c)
+	ctx := c.Request().Context()
+
+	userID, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	if userID == reqCtx.User.ID {
+		return respondhtmx.ToastError(c, "You can't disable yourself")
+	}
+
+	user, err := h.servs.UsersService.GetUser(ctx, userID)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	_, err = h.servs.UsersService.UpdateUser(
+		ctx, dbgen.UsersServiceUpdateUserParams{
+			ID:       userID,
+			IsActive: sql.NullBool{Bool: !user.IsActive, Valid: true},
+		},
+	)
+	if err != nil {
+		return respondhtmx.ToastError(c, err.Error())
+	}
+
+	if user.IsActive {
+		err = h.servs.AuthService.DeleteAllUserSessions(ctx, userID)
+		if err != nil {
+			return respondhtmx.ToastError(c, err.Error())
+		}
+	}
+
+	return respondhtmx.Refresh(c)
+}
@@ -120,12 +120,15 @@ func createAndUpdateWebhookForm(
 	})
 
 	eventTypeSelects := map[string]nodx.Node{
-		webhooks.EventTypeDatabaseHealthy.Value.Key:      databaseSelect,
-		webhooks.EventTypeDatabaseUnhealthy.Value.Key:    databaseSelect,
-		webhooks.EventTypeDestinationHealthy.Value.Key:   destinationSelect,
-		webhooks.EventTypeDestinationUnhealthy.Value.Key: destinationSelect,
-		webhooks.EventTypeExecutionSuccess.Value.Key:     backupSelect,
-		webhooks.EventTypeExecutionFailed.Value.Key:      backupSelect,
+		webhooks.EventTypeDatabaseHealthy.Value.Key:          databaseSelect,
+		webhooks.EventTypeDatabaseUnhealthy.Value.Key:        databaseSelect,
+		webhooks.EventTypeDestinationHealthy.Value.Key:       destinationSelect,
+		webhooks.EventTypeDestinationUnhealthy.Value.Key:     destinationSelect,
+		webhooks.EventTypeExecutionSuccess.Value.Key:         backupSelect,
+		webhooks.EventTypeExecutionFailed.Value.Key:          backupSelect,
+		webhooks.EventTypeExecutionIntegrityFailed.Value.Key: backupSelect,
+		webhooks.EventTypeRestoreDrillSuccess.Value.Key:      backupSelect,
+		webhooks.EventTypeRestoreDrillFailed.Value.Key:       backupSelect,
 	}
 
 	targetIdsSelect := []nodx.Node{}
@@ -338,7 +341,7 @@ func createAndUpdateWebhookForm(
 			Name:        "body",
 			Label:       "Body",
 			Placeholder: `{ "key": "value" }`,
-			HelpText:    `By default it will send an empty json object {}.`,
+			HelpText:    `By default it will send an empty json object {}. The execution events replace {{execution_id}}, {{attempts}} and {{max_attempts}} inside the body strings.`,
 			Children: []nodx.Node{
 				alpine.XRef("bodyTextarea"),
 				alpine.XOn("click.outside", "formatBodyTextarea()"),
@@ -2,6 +2,7 @@ package webhooks
 
 import (
 	"github.com/eduardolat/pgbackweb/internal/service"
+	"github.com/eduardolat/pgbackweb/internal/service/users"
 	"github.com/eduardolat/pgbackweb/internal/view/middleware"
 	"github.com/labstack/echo/v4"
 )
@@ -18,15 +19,16 @@ func MountRouter(
 	parent *echo.Group, mids *middleware.Middleware, servs *service.Service,
 ) {
 	h := newHandlers(servs)
+	admin := mids.RequireRole(users.RoleAdmin)
 
 	parent.GET("", h.indexPageHandler)
 	parent.GET("/list", h.listWebhooksHandler)
-	parent.GET("/create", h.createWebhookFormHandler)
-	parent.POST("/create", h.createWebhookHandler)
-	parent.GET("/:webhookID/edit", h.editWebhookFormHandler)
-	parent.POST("/:webhookID/edit", h.editWebhookHandler)
-	parent.POST("/:webhookID/run", h.runWebhookHandler)
-	parent.POST("/:webhookID/duplicate", h.duplicateWebhookHandler)
+	parent.GET("/create", h.createWebhookFormHandler, admin)
+	parent.POST("/create", h.createWebhookHandler, admin)
+	parent.GET("/:webhookID/edit", h.editWebhookFormHandler, admin)
+	parent.POST("/:webhookID/edit", h.editWebhookHandler, admin)
+	parent.POST("/:webhookID/run", h.runWebhookHandler, admin)
+	parent.POST("/:webhookID/duplicate", h.duplicateWebhookHandler, admin)
 	parent.GET("/:webhookID/executions", h.paginateWebhookExecutionsHandler)
-	parent.DELETE("/:webhookID", h.deleteWebhookHandler)
+	parent.DELETE("/:webhookID", h.deleteWebhookHandler, admin)
 }
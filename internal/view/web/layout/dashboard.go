@@ -27,7 +27,7 @@ func Dashboard(reqCtx reqctx.Ctx, params DashboardParams) nodx.Node {
 			"w-screen h-screen bg-base-200":      true,
 			"flex justify-start overflow-hidden": true,
 		},
-		dashboardAside(),
+		dashboardAside(reqCtx),
 		nodx.Div(
 			nodx.Class("flex-grow overflow-y-auto"),
 			dashboardHeader(),
@@ -3,14 +3,16 @@ package layout
 import (
 	"fmt"
 
+	"github.com/eduardolat/pgbackweb/internal/service/users"
 	"github.com/eduardolat/pgbackweb/internal/util/pathutil"
+	"github.com/eduardolat/pgbackweb/internal/view/reqctx"
 	nodx "github.com/nodxdev/nodxgo"
 	alpine "github.com/nodxdev/nodxgo-alpine"
 	htmx "github.com/nodxdev/nodxgo-htmx"
 	lucide "github.com/nodxdev/nodxgo-lucide"
 )
 
-func dashboardAside() nodx.Node {
+func dashboardAside(reqCtx reqctx.Ctx) nodx.Node {
 	return nodx.Aside(
 		nodx.Id("dashboard-aside"),
 		nodx.ClassMap{
@@ -78,6 +80,13 @@ func dashboardAside() nodx.Node {
 				false,
 			),
 
+			dashboardAsideItem(
+				lucide.ListOrdered,
+				"Jobs queue",
+				pathutil.BuildPath("/dashboard/jobs"),
+				false,
+			),
+
 			dashboardAsideItem(
 				lucide.ArchiveRestore,
 				"Restorations",
@@ -85,6 +94,13 @@ func dashboardAside() nodx.Node {
 				false,
 			),
 
+			dashboardAsideItem(
+				lucide.FlaskConical,
+				"Restore drills",
+				pathutil.BuildPath("/dashboard/drills"),
+				false,
+			),
+
 			dashboardAsideItem(
 				lucide.Webhook,
 				"Webhooks",
@@ -92,6 +108,16 @@ func dashboardAside() nodx.Node {
 				false,
 			),
 
+			nodx.If(
+				reqCtx.User.Role == users.RoleAdmin,
+				dashboardAsideItem(
+					lucide.Users,
+					"Users",
+					pathutil.BuildPath("/dashboard/users"),
+					false,
+				),
+			),
+
 			dashboardAsideItem(
 				lucide.User,
 				"Profile",
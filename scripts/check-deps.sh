@@ -19,7 +19,6 @@ check_command "node --version" "Node.js"
 
 # Check software installed from apt install
 check_command "wget --version" "wget"
-check_command "unzip -v" "unzip"
 check_command "dpkg -s tzdata" "tzdata"
 check_command "git --version" "git"
 